@@ -0,0 +1,160 @@
+package aml
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+)
+
+// Engine evaluates posted transfers against the monitoring rules.
+type Engine struct {
+	store db.Querier
+	rules []Rule
+}
+
+// NewEngine creates an engine evaluating the rules, which were validated when they were parsed.
+func NewEngine(store db.Querier, rules []Rule) *Engine {
+	return &Engine{store: store, rules: rules}
+}
+
+// subject is the customer side of a transfer, whose activity the rules look at
+type subject struct {
+	account db.GetAMLAccountRow
+	sent    bool // whether the customer sent the transfer, or received it from the bank
+}
+
+// Evaluate returns the alerts the rules raise for a posted transfer. Transfers are looked at from the sender's side,
+// or the recipient's when the money came from the bank, such as cash deposits. Transfers between the bank's own
+// ledger accounts are not monitored. Time windows end at the transfer, so screening late gives the same result.
+func (engine *Engine) Evaluate(ctx context.Context, transfer db.Transfer) ([]db.CreateAMLAlertParams, error) {
+	from, err := engine.store.GetAMLAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := engine.store.GetAMLAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sub subject
+	switch {
+	case !from.Internal:
+		sub = subject{account: from, sent: true}
+	case !to.Internal:
+		sub = subject{account: to, sent: false}
+	default:
+		return nil, nil
+	}
+
+	alerts := []db.CreateAMLAlertParams{}
+	for _, rule := range engine.rules {
+		if rule.Disabled || (rule.Currency != "" && rule.Currency != sub.account.Currency) {
+			continue
+		}
+
+		details, err := engine.match(ctx, rule, transfer, sub, to)
+		if err != nil {
+			return nil, err
+		}
+		if details == nil {
+			continue
+		}
+
+		details["amount"] = transfer.Amount
+		data, err := json.Marshal(details)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, db.CreateAMLAlertParams{
+			TransferID: transfer.ID,
+			AccountID:  sub.account.ID,
+			Owner:      sub.account.Owner,
+			Rule:       rule.Name,
+			Severity:   rule.Severity,
+			Details:    data,
+		})
+	}
+	return alerts, nil
+}
+
+// match returns the figures a rule matched the transfer on, or nil when it does not match.
+func (engine *Engine) match(ctx context.Context, rule Rule, transfer db.Transfer, sub subject, to db.GetAMLAccountRow) (map[string]any, error) {
+	since := transfer.CreatedAt.Add(-rule.Window)
+
+	switch rule.Type {
+	case RuleLargeTransfer:
+		if transfer.Amount < rule.MinAmount {
+			return nil, nil
+		}
+		return map[string]any{"min_amount": rule.MinAmount}, nil
+
+	case RuleRoundAmount:
+		if transfer.Amount < rule.MinAmount || transfer.Amount%rule.Multiple != 0 {
+			return nil, nil
+		}
+		return map[string]any{"multiple": rule.Multiple}, nil
+
+	case RuleStructuring:
+		floor := rule.Threshold - rule.Threshold*rule.MarginPercent/100
+		if transfer.Amount < floor || transfer.Amount >= rule.Threshold {
+			return nil, nil
+		}
+		count, err := engine.store.CountOwnerTransfersInRange(ctx, db.CountOwnerTransfersInRangeParams{
+			Sent:      sub.sent,
+			Owner:     sub.account.Owner,
+			Currency:  sub.account.Currency,
+			Since:     since,
+			Until:     transfer.CreatedAt,
+			MinAmount: floor,
+			MaxAmount: rule.Threshold,
+		})
+		if err != nil || count < rule.MinCount {
+			return nil, err
+		}
+		return map[string]any{"threshold": rule.Threshold, "count": count, "window": rule.Window.String()}, nil
+
+	case RuleRapidMovement:
+		if !sub.sent {
+			return nil, nil
+		}
+		received, err := engine.store.SumAccountTransfersInWindow(ctx, db.SumAccountTransfersInWindowParams{
+			Sent:      false,
+			AccountID: sub.account.ID,
+			Currency:  sub.account.Currency,
+			Since:     since,
+			Until:     transfer.CreatedAt,
+		})
+		if err != nil || received < rule.MinAmount {
+			return nil, err
+		}
+		sent, err := engine.store.SumAccountTransfersInWindow(ctx, db.SumAccountTransfersInWindowParams{
+			Sent:      true,
+			AccountID: sub.account.ID,
+			Currency:  sub.account.Currency,
+			Since:     since,
+			Until:     transfer.CreatedAt,
+		})
+		if err != nil || sent*100 < received*rule.Percent {
+			return nil, err
+		}
+		return map[string]any{"received": received, "sent": sent, "window": rule.Window.String()}, nil
+
+	case RuleNewCounterparties:
+		if !sub.sent || to.Internal || to.Owner == sub.account.Owner {
+			return nil, nil
+		}
+		counterparties, err := engine.store.ListNewCounterparties(ctx, db.ListNewCounterpartiesParams{
+			Owner: sub.account.Owner,
+			Since: since,
+			Until: transfer.CreatedAt,
+		})
+		// Only a transfer to one of the new counterparties raises the alert, not every later one
+		if err != nil || int64(len(counterparties)) < rule.MinCount || !slices.Contains(counterparties, transfer.ToAccountID) {
+			return nil, err
+		}
+		return map[string]any{"new_counterparties": len(counterparties), "window": rule.Window.String()}, nil
+	}
+	return nil, nil
+}
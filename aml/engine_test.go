@@ -0,0 +1,269 @@
+package aml
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+var (
+	customer    = db.GetAMLAccountRow{ID: 1, Owner: "alice", Currency: util.USD}
+	counterpart = db.GetAMLAccountRow{ID: 2, Owner: "bob", Currency: util.USD}
+	cash        = db.GetAMLAccountRow{ID: 3, Owner: db.SystemUsername, Currency: util.USD, Internal: true}
+	fees        = db.GetAMLAccountRow{ID: 4, Owner: db.SystemUsername, Currency: util.USD, Internal: true}
+)
+
+// expectAccounts makes the mock store return the accounts of the tests.
+func expectAccounts(store *mockdb.MockStore) {
+	for _, account := range []db.GetAMLAccountRow{customer, counterpart, cash, fees} {
+		store.EXPECT().GetAMLAccount(gomock.Any(), gomock.Eq(account.ID)).AnyTimes().Return(account, nil)
+	}
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	transfer := func(from, to db.GetAMLAccountRow, amount int64) db.Transfer {
+		return db.Transfer{ID: util.RandomInt(1, 1000), FromAccountID: from.ID, ToAccountID: to.ID, Amount: amount, CreatedAt: now, Status: db.TransferStatusPosted}
+	}
+
+	testCases := []struct {
+		name       string
+		rule       Rule
+		transfer   db.Transfer
+		buildStubs func(store *mockdb.MockStore)
+		subject    *db.GetAMLAccountRow // nil when no alert is raised
+		details    map[string]any
+	}{
+		{
+			name:     "LargeTransfer",
+			rule:     Rule{Name: "large", Type: RuleLargeTransfer, MinAmount: 1000},
+			transfer: transfer(customer, counterpart, 1000),
+			subject:  &customer,
+			details:  map[string]any{"amount": float64(1000), "min_amount": float64(1000)},
+		},
+		{
+			name:     "SmallTransfer",
+			rule:     Rule{Name: "large", Type: RuleLargeTransfer, MinAmount: 1000},
+			transfer: transfer(customer, counterpart, 999),
+		},
+		{
+			name:     "LargeDeposit",
+			rule:     Rule{Name: "large", Type: RuleLargeTransfer, MinAmount: 1000},
+			transfer: transfer(cash, customer, 5000),
+			subject:  &customer,
+		},
+		{
+			name:     "InternalTransfer",
+			rule:     Rule{Name: "large", Type: RuleLargeTransfer, MinAmount: 1000},
+			transfer: transfer(cash, fees, 5000),
+		},
+		{
+			name:     "OtherCurrency",
+			rule:     Rule{Name: "large", Type: RuleLargeTransfer, MinAmount: 1000, Currency: util.EUR},
+			transfer: transfer(customer, counterpart, 5000),
+		},
+		{
+			name:     "Disabled",
+			rule:     Rule{Name: "large", Type: RuleLargeTransfer, MinAmount: 1000, Disabled: true},
+			transfer: transfer(customer, counterpart, 5000),
+		},
+		{
+			name:     "RoundAmount",
+			rule:     Rule{Name: "round", Type: RuleRoundAmount, MinAmount: 1000, Multiple: 500},
+			transfer: transfer(customer, counterpart, 2500),
+			subject:  &customer,
+			details:  map[string]any{"amount": float64(2500), "multiple": float64(500)},
+		},
+		{
+			name:     "NotRoundAmount",
+			rule:     Rule{Name: "round", Type: RuleRoundAmount, MinAmount: 1000, Multiple: 500},
+			transfer: transfer(customer, counterpart, 2501),
+		},
+		{
+			name:     "Structuring",
+			rule:     Rule{Name: "structuring", Type: RuleStructuring, Threshold: 10000, MarginPercent: 10, MinCount: 3, Window: 24 * time.Hour},
+			transfer: transfer(customer, counterpart, 9500),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CountOwnerTransfersInRange(gomock.Any(), gomock.Eq(db.CountOwnerTransfersInRangeParams{
+						Sent:      true,
+						Owner:     customer.Owner,
+						Currency:  customer.Currency,
+						Since:     now.Add(-24 * time.Hour),
+						Until:     now,
+						MinAmount: 9000,
+						MaxAmount: 10000,
+					})).
+					Times(1).
+					Return(int64(3), nil)
+			},
+			subject: &customer,
+			details: map[string]any{"amount": float64(9500), "threshold": float64(10000), "count": float64(3), "window": "24h0m0s"},
+		},
+		{
+			name:     "StructuredDeposits",
+			rule:     Rule{Name: "structuring", Type: RuleStructuring, Threshold: 10000, MarginPercent: 10, MinCount: 3, Window: 24 * time.Hour},
+			transfer: transfer(cash, customer, 9900),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CountOwnerTransfersInRange(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CountOwnerTransfersInRangeParams) (int64, error) {
+						require.False(t, arg.Sent)
+						return 4, nil
+					})
+			},
+			subject: &customer,
+		},
+		{
+			name:     "StructuringTooFew",
+			rule:     Rule{Name: "structuring", Type: RuleStructuring, Threshold: 10000, MarginPercent: 10, MinCount: 3, Window: 24 * time.Hour},
+			transfer: transfer(customer, counterpart, 9500),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountOwnerTransfersInRange(gomock.Any(), gomock.Any()).Times(1).Return(int64(2), nil)
+			},
+		},
+		{
+			name:     "StructuringOutsideBand",
+			rule:     Rule{Name: "structuring", Type: RuleStructuring, Threshold: 10000, MarginPercent: 10, MinCount: 3, Window: 24 * time.Hour},
+			transfer: transfer(customer, counterpart, 10000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountOwnerTransfersInRange(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:     "RapidMovement",
+			rule:     Rule{Name: "rapid", Type: RuleRapidMovement, MinAmount: 5000, Percent: 90, Window: 48 * time.Hour},
+			transfer: transfer(customer, counterpart, 4600),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SumAccountTransfersInWindowParams{AccountID: customer.ID, Currency: customer.Currency, Since: now.Add(-48 * time.Hour), Until: now}
+				store.EXPECT().SumAccountTransfersInWindow(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(5000), nil)
+				arg.Sent = true
+				store.EXPECT().SumAccountTransfersInWindow(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(4600), nil)
+			},
+			subject: &customer,
+			details: map[string]any{"amount": float64(4600), "received": float64(5000), "sent": float64(4600), "window": "48h0m0s"},
+		},
+		{
+			name:     "MoneyKept",
+			rule:     Rule{Name: "rapid", Type: RuleRapidMovement, MinAmount: 5000, Percent: 90, Window: 48 * time.Hour},
+			transfer: transfer(customer, counterpart, 1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SumAccountTransfersInWindow(gomock.Any(), gomock.Any()).Times(1).Return(int64(5000), nil)
+				store.EXPECT().SumAccountTransfersInWindow(gomock.Any(), gomock.Any()).Times(1).Return(int64(1000), nil)
+			},
+		},
+		{
+			name:     "LittleReceived",
+			rule:     Rule{Name: "rapid", Type: RuleRapidMovement, MinAmount: 5000, Percent: 90, Window: 48 * time.Hour},
+			transfer: transfer(customer, counterpart, 1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SumAccountTransfersInWindow(gomock.Any(), gomock.Any()).Times(1).Return(int64(1000), nil)
+			},
+		},
+		{
+			name:     "NewCounterparties",
+			rule:     Rule{Name: "new", Type: RuleNewCounterparties, MinCount: 3, Window: 24 * time.Hour},
+			transfer: transfer(customer, counterpart, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListNewCounterpartiesParams{Owner: customer.Owner, Since: now.Add(-24 * time.Hour), Until: now}
+				store.EXPECT().ListNewCounterparties(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]int64{counterpart.ID, 10, 11}, nil)
+			},
+			subject: &customer,
+			details: map[string]any{"amount": float64(100), "new_counterparties": float64(3), "window": "24h0m0s"},
+		},
+		{
+			name:     "KnownCounterparty",
+			rule:     Rule{Name: "new", Type: RuleNewCounterparties, MinCount: 3, Window: 24 * time.Hour},
+			transfer: transfer(customer, counterpart, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListNewCounterparties(gomock.Any(), gomock.Any()).Times(1).Return([]int64{10, 11, 12}, nil)
+			},
+		},
+		{
+			name:     "WithdrawalNotCounterparty",
+			rule:     Rule{Name: "new", Type: RuleNewCounterparties, MinCount: 1, Window: 24 * time.Hour},
+			transfer: transfer(customer, cash, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListNewCounterparties(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAccounts(store)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			tc.rule.Severity = db.AMLSeverityHigh
+			require.NoError(t, tc.rule.Validate())
+			engine := NewEngine(store, []Rule{tc.rule})
+
+			alerts, err := engine.Evaluate(context.Background(), tc.transfer)
+			require.NoError(t, err)
+			if tc.subject == nil {
+				require.Empty(t, alerts)
+				return
+			}
+
+			require.Len(t, alerts, 1)
+			alert := alerts[0]
+			require.Equal(t, tc.transfer.ID, alert.TransferID)
+			require.Equal(t, tc.subject.ID, alert.AccountID)
+			require.Equal(t, tc.subject.Owner, alert.Owner)
+			require.Equal(t, tc.rule.Name, alert.Rule)
+			require.Equal(t, db.AMLSeverityHigh, alert.Severity)
+			if tc.details != nil {
+				var details map[string]any
+				require.NoError(t, json.Unmarshal(alert.Details, &details))
+				require.Equal(t, tc.details, details)
+			}
+		})
+	}
+}
+
+func TestEngineEvaluateSeveralRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectAccounts(store)
+
+	engine := NewEngine(store, []Rule{
+		{Name: "large", Type: RuleLargeTransfer, Severity: db.AMLSeverityHigh, MinAmount: 1000},
+		{Name: "round", Type: RuleRoundAmount, Severity: db.AMLSeverityLow, MinAmount: 1000, Multiple: 1000},
+	})
+
+	alerts, err := engine.Evaluate(context.Background(), db.Transfer{ID: 1, FromAccountID: customer.ID, ToAccountID: counterpart.ID, Amount: 5000})
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	require.Equal(t, "large", alerts[0].Rule)
+	require.Equal(t, "round", alerts[1].Rule)
+	require.Equal(t, db.AMLSeverityLow, alerts[1].Severity)
+}
+
+func TestEngineEvaluateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAMLAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.GetAMLAccountRow{}, sql.ErrConnDone)
+
+	engine := NewEngine(store, []Rule{{Name: "large", Type: RuleLargeTransfer, Severity: db.AMLSeverityHigh, MinAmount: 1000}})
+	_, err := engine.Evaluate(context.Background(), db.Transfer{ID: 1, FromAccountID: customer.ID, ToAccountID: counterpart.ID, Amount: 5000})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
@@ -0,0 +1,150 @@
+package aml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"gopkg.in/yaml.v3"
+)
+
+// Types of rules the monitor can evaluate
+const (
+	RuleLargeTransfer     = "large_transfer"     // a single transfer of at least min_amount
+	RuleStructuring       = "structuring"        // at least min_count transfers just under threshold within window
+	RuleRapidMovement     = "rapid_movement"     // at least percent of min_amount or more received within window sent out again
+	RuleNewCounterparties = "new_counterparties" // money sent to at least min_count accounts never paid before within window
+	RuleRoundAmount       = "round_amount"       // a transfer of at least min_amount that is a multiple of multiple
+)
+
+// Rule is one rule of the monitoring rules file. Which fields are needed depends on the type:
+//
+//	large_transfer:     min_amount
+//	structuring:        threshold, margin_percent, window, min_count
+//	rapid_movement:     min_amount, percent, window
+//	new_counterparties: min_count, window
+//	round_amount:       min_amount, multiple
+//
+// Amounts are in the smallest unit of the currency, like every amount of the ledger.
+type Rule struct {
+	Name     string `yaml:"name"`     // unique, recorded on the alerts the rule raises
+	Type     string `yaml:"type"`     // one of the Rule* types
+	Severity string `yaml:"severity"` // low, medium or high, medium when left out
+	Disabled bool   `yaml:"disabled"` // keeps the rule in the file without evaluating it
+	Currency string `yaml:"currency"` // only evaluates transfers in this currency, every currency when empty
+
+	MinAmount     int64         `yaml:"min_amount"`
+	Threshold     int64         `yaml:"threshold"`      // reporting threshold that structuring stays under
+	MarginPercent int64         `yaml:"margin_percent"` // how far under the threshold, in percent of it, counts as just under
+	Multiple      int64         `yaml:"multiple"`
+	Percent       int64         `yaml:"percent"`
+	MinCount      int64         `yaml:"min_count"`
+	Window        time.Duration `yaml:"window"` // such as 24h, looking back from each transfer
+}
+
+// rulesFile is the layout of the rules file
+type rulesFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// LoadRules reads the monitoring rules from a YAML file. JSON files work as well, JSON being a subset of YAML.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read AML rules: %w", err)
+	}
+	return ParseRules(data)
+}
+
+// ParseRules parses and validates monitoring rules. Unknown fields are errors, so a misspelt setting
+// cannot silently turn a rule off.
+func ParseRules(data []byte) ([]Rule, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file rulesFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("cannot parse AML rules: %w", err)
+	}
+
+	names := make(map[string]bool, len(file.Rules))
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		if rule.Severity == "" {
+			rule.Severity = db.AMLSeverityMedium
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("AML rule %q is defined twice", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return file.Rules, nil
+}
+
+// Validate checks that the rule has a known type, a severity and the settings its type needs.
+func (rule Rule) Validate() error {
+	if rule.Name == "" {
+		return errors.New("AML rule without a name")
+	}
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("AML rule %q: %s", rule.Name, fmt.Sprintf(format, args...))
+	}
+
+	switch rule.Severity {
+	case db.AMLSeverityLow, db.AMLSeverityMedium, db.AMLSeverityHigh:
+	default:
+		return invalid("unknown severity %q", rule.Severity)
+	}
+
+	switch rule.Type {
+	case RuleLargeTransfer:
+		if rule.MinAmount <= 0 {
+			return invalid("min_amount must be positive")
+		}
+	case RuleStructuring:
+		if rule.Threshold <= 0 {
+			return invalid("threshold must be positive")
+		}
+		if rule.MarginPercent <= 0 || rule.MarginPercent >= 100 {
+			return invalid("margin_percent must be between 1 and 99")
+		}
+		if rule.MinCount < 2 {
+			return invalid("min_count must be at least 2")
+		}
+	case RuleRapidMovement:
+		if rule.MinAmount <= 0 {
+			return invalid("min_amount must be positive")
+		}
+		if rule.Percent <= 0 {
+			return invalid("percent must be positive")
+		}
+	case RuleNewCounterparties:
+		if rule.MinCount <= 0 {
+			return invalid("min_count must be positive")
+		}
+	case RuleRoundAmount:
+		if rule.MinAmount <= 0 {
+			return invalid("min_amount must be positive")
+		}
+		if rule.Multiple <= 1 {
+			return invalid("multiple must be greater than 1")
+		}
+	default:
+		return invalid("unknown type %q", rule.Type)
+	}
+
+	switch rule.Type {
+	case RuleStructuring, RuleRapidMovement, RuleNewCounterparties:
+		if rule.Window <= 0 {
+			return invalid("window must be positive")
+		}
+	}
+	return nil
+}
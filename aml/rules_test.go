@@ -0,0 +1,87 @@
+package aml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+)
+
+func TestLoadRules(t *testing.T) {
+	// The rules shipped with the server must stay valid
+	rules, err := LoadRules("../aml_rules.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, rules)
+
+	types := map[string]bool{}
+	for _, rule := range rules {
+		types[rule.Type] = true
+	}
+	for _, ruleType := range []string{RuleLargeTransfer, RuleStructuring, RuleRapidMovement, RuleNewCounterparties, RuleRoundAmount} {
+		require.True(t, types[ruleType], ruleType)
+	}
+
+	_, err = LoadRules("missing.yaml")
+	require.Error(t, err)
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+rules:
+  - name: structuring
+    type: structuring
+    severity: high
+    threshold: 1000000
+    margin_percent: 10
+    window: 72h
+    min_count: 3
+  - name: large_eur
+    type: large_transfer
+    currency: EUR
+    min_amount: 500000
+`))
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, 72*time.Hour, rules[0].Window)
+	require.Equal(t, db.AMLSeverityHigh, rules[0].Severity)
+	require.Equal(t, db.AMLSeverityMedium, rules[1].Severity)
+	require.Equal(t, "EUR", rules[1].Currency)
+
+	// JSON is read as YAML
+	rules, err = ParseRules([]byte(`{"rules": [{"name": "round", "type": "round_amount", "min_amount": 100000, "multiple": 10000, "severity": "low"}]}`))
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, int64(10000), rules[0].Multiple)
+
+	rules, err = ParseRules(nil)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules string
+	}{
+		{"UnknownField", "rules:\n  - name: large\n    type: large_transfer\n    min_amont: 100\n"},
+		{"UnknownType", "rules:\n  - name: odd\n    type: odd_hours\n"},
+		{"NoName", "rules:\n  - type: large_transfer\n    min_amount: 100\n"},
+		{"UnknownSeverity", "rules:\n  - name: large\n    type: large_transfer\n    severity: critical\n    min_amount: 100\n"},
+		{"Duplicate", "rules:\n  - name: large\n    type: large_transfer\n    min_amount: 100\n  - name: large\n    type: large_transfer\n    min_amount: 200\n"},
+		{"NoMinAmount", "rules:\n  - name: large\n    type: large_transfer\n"},
+		{"NoWindow", "rules:\n  - name: new\n    type: new_counterparties\n    min_count: 5\n"},
+		{"InvalidWindow", "rules:\n  - name: new\n    type: new_counterparties\n    min_count: 5\n    window: one day\n"},
+		{"InvalidMargin", "rules:\n  - name: structuring\n    type: structuring\n    threshold: 1000\n    margin_percent: 100\n    window: 24h\n    min_count: 3\n"},
+		{"SingleStructuringTransfer", "rules:\n  - name: structuring\n    type: structuring\n    threshold: 1000\n    margin_percent: 10\n    window: 24h\n    min_count: 1\n"},
+		{"NoPercent", "rules:\n  - name: rapid\n    type: rapid_movement\n    min_amount: 1000\n    window: 24h\n"},
+		{"NoMultiple", "rules:\n  - name: round\n    type: round_amount\n    min_amount: 1000\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(tc.rules))
+			require.Error(t, err)
+		})
+	}
+}
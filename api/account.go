@@ -2,12 +2,18 @@ package api
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http" // Package for HTTP utilities like status codes
+	"time"
 
 	"github.com/gin-gonic/gin" // Gin framework for building web applications
 	"github.com/lib/pq"
 	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc" // Importing the db package to access SQLC-generated code for database queries
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
 )
 
 // The `createAccountRequest` struct is used to represent the structure of the incoming JSON payload
@@ -16,6 +22,7 @@ import (
 type createAccountRequest struct {
 	Owner    string `json:"owner" binding:"required"`                  // `Owner` represents the account owner's name, required field
 	Currency string `json:"currency" binding:"required,oneof=USD EUR"` // `Currency` field restricted to USD or EUR using the `oneof` validator
+	Product  string `json:"product"`                                   // Code of the account product, a checking account when left out
 }
 
 // The `createAccount` function handles the creation of a new account.
@@ -33,18 +40,46 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	// The `db.CreateAccountParams` struct is an SQLC-generated struct that defines the parameters required
-	// to create a new account in the database. In this case, it requires the `Owner`, `Currency`, and an initial `Balance`.
-	arg := db.CreateAccountParams{
-		Owner:    req.Owner,    // Setting the owner of the account from the request data
-		Currency: req.Currency, // Setting the currency of the account from the request data
-		Balance:  0,            // The initial balance is always set to 0
+	// Customers open accounts for themselves, tellers open them on behalf of any customer
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.Owner != authPayload.Username {
+		allowed, err := server.hasPermission(ctx, util.PermOpenAnyAccount)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !allowed {
+			err := errors.New("cannot open an account for another user")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 	}
 
-	// `server.store.CreateAccount` is the actual function that interacts with the database to create the account.
+	// The product decides the account's overdraft limit and how many of them an owner can hold.
+	// New accounts always start with a balance of 0.
+	arg := db.CreateAccountTxParams{
+		Owner:       req.Owner,    // Setting the owner of the account from the request data
+		Currency:    req.Currency, // Setting the currency of the account from the request data
+		ProductCode: req.Product,
+	}
+	if arg.ProductCode == "" {
+		arg.ProductCode = db.ProductChecking
+	}
+
+	// `server.store.CreateAccountTx` is the actual function that interacts with the database to create the account.
 	// The `ctx` is passed to allow for context-based cancellations and timeouts, which can be useful in high-load scenarios.
-	account, err := server.store.CreateAccount(ctx, arg)
+	result, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrUnknownProduct), errors.Is(err, sql.ErrNoRows):
+			// An unknown product or owner is the client's mistake
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrTooManyAccounts), errors.Is(err, db.ErrKYCAccountLimit):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
 		// If there is a database error, check if it's a PostgreSQL-specific error.
 		if pqErr, ok := err.(*pq.Error); ok {
 			// Log the error code and message for debugging purposes.
@@ -69,7 +104,18 @@ func (server *Server) createAccount(ctx *gin.Context) {
 
 	// If everything is successful, return the created account with a `200 OK` status code.
 	// The account object will be automatically marshaled into JSON format by Gin.
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, result.Account)
+}
+
+// listAccountProducts returns the catalogue of account products that can be opened.
+func (server *Server) listAccountProducts(ctx *gin.Context) {
+	products, err := server.store.ListAccountProducts(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, products)
 }
 
 type getAccountRequest struct {
@@ -104,6 +150,11 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
+	// Customers only see their own accounts; staff with the permission see anyone's.
+	if !server.authorizeAccount(ctx, account, util.PermViewAnyAccount) {
+		return
+	}
+
 	// If the account is found, return it with a `200 OK` response.
 	ctx.JSON(http.StatusOK, account)
 }
@@ -140,6 +191,17 @@ func (server *Server) listAccount(ctx *gin.Context) {
 		Offset: (req.PageID - 1) * req.PageSize, // Calculate the offset for pagination.
 	}
 
+	// Customers only list their own accounts; staff with the permission list everyone's.
+	viewAny, err := server.hasPermission(ctx, util.PermViewAnyAccount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !viewAny {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		arg.Owner = sql.NullString{String: authPayload.Username, Valid: true}
+	}
+
 	// Fetch the paginated list of accounts from the database.
 	accounts, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
@@ -153,6 +215,155 @@ func (server *Server) listAccount(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, accounts)
 }
 
+// freezeAccount stops an active account from being debited. It can still be credited.
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, db.AccountActive, db.AccountFrozen)
+}
+
+// unfreezeAccount makes a frozen account active again.
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, db.AccountFrozen, db.AccountActive)
+}
+
+// changeAccountStatus moves the account in the URI from one status to another,
+// answering 409 Conflict when the account is not in the expected status.
+func (server *Server) changeAccountStatus(ctx *gin.Context, from, to string) {
+	var req getAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.UpdateAccountStatus(ctx, db.UpdateAccountStatusParams{
+		Status:     to,
+		ID:         req.ID,
+		FromStatus: from,
+	})
+	if err == nil {
+		ctx.JSON(http.StatusOK, account)
+		return
+	}
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// No row was updated: either the account does not exist, or it is not in the expected status
+	account, err = server.store.GetAccount(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("account %d is %s", account.ID, account.Status)))
+}
+
+// closeAccountRequest is the optional JSON payload for closing an account.
+// `sweep_to_account_id` receives the remaining balance; without it the balance must be zero.
+type closeAccountRequest struct {
+	SweepToAccountID int64 `json:"sweep_to_account_id" binding:"omitempty,min=1"`
+}
+
+// closeAccount closes an account of the logged in user for good. Closed accounts keep their history,
+// so they can still be fetched and listed, but no money can move on them anymore.
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// The body can be left out when there is nothing to sweep
+	var req closeAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, "") {
+		return
+	}
+
+	if req.SweepToAccountID == account.ID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("cannot sweep an account into itself")))
+		return
+	}
+	if req.SweepToAccountID != 0 && !server.validAccount(ctx, req.SweepToAccountID, account.Currency) {
+		return
+	}
+
+	result, err := server.store.CloseAccountTx(ctx, db.CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: req.SweepToAccountID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrAccountNotEmpty), errors.Is(err, db.ErrAccountInUse), isAccountStatusError(err):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type getAccountBalanceRequest struct {
+	At time.Time `form:"at" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"` // RFC 3339 timestamp
+}
+
+// getAccountBalance returns the balance an account had at a point in time.
+// It is available to the account's owner and to staff who can view any account.
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getAccountBalanceRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.PermViewAnyAccount) {
+		return
+	}
+
+	balance, err := db.BalanceAt(ctx, server.store, account.ID, req.At)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balance)
+}
+
 /*
 ## Concepts to Master:
 
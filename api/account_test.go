@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -9,7 +10,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
@@ -26,12 +29,16 @@ func TestGetAccountAPI(t *testing.T) {
 	testCases := []struct {
 		name          string                                                  // Name of the test case
 		accountID     int64                                                   // ID of the account to retrieve
+		username      string                                                  // User making the request, no token when empty
+		role          string                                                  // Role of that user
 		buildStubs    func(store *mockdb.MockStore)                           // Function to set up mock behavior
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder) // Function to verify the response
 	}{
 		{
 			name:      "OK",       // Test case where everything works as expected
 			accountID: account.ID, // Use the ID of the random account
+			username:  account.Owner,
+			role:      util.CustomerRole,
 			buildStubs: func(store *mockdb.MockStore) {
 				// Set up expected behavior on the mock store
 				// Expect GetAccount to be called with any context and the specific account ID
@@ -51,6 +58,8 @@ func TestGetAccountAPI(t *testing.T) {
 		{
 			name:      "NotFound", // Test case where account is not found
 			accountID: account.ID, // Use the ID of the random account
+			username:  account.Owner,
+			role:      util.CustomerRole,
 			buildStubs: func(store *mockdb.MockStore) {
 				// Set up expected behavior on the mock store
 				// Expect GetAccount to be called with any context and the specific account ID
@@ -68,6 +77,8 @@ func TestGetAccountAPI(t *testing.T) {
 		{
 			name:      "InternalError", // Test case for internal server error
 			accountID: account.ID,      // Use the ID of the random account
+			username:  account.Owner,
+			role:      util.CustomerRole,
 			buildStubs: func(store *mockdb.MockStore) {
 				// Set up expected behavior on the mock store
 				// Expect GetAccount to be called with any context and the specific account ID
@@ -85,6 +96,8 @@ func TestGetAccountAPI(t *testing.T) {
 		{
 			name:      "InvalidID", // Test case for invalid account ID
 			accountID: 0,           // Use an invalid ID (0 is invalid, as IDs start from 1)
+			username:  account.Owner,
+			role:      util.CustomerRole,
 			buildStubs: func(store *mockdb.MockStore) {
 				// No need to set up any expectations on the mock store
 				// The request should be rejected before reaching the store
@@ -97,6 +110,41 @@ func TestGetAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name:      "Teller",
+			accountID: account.ID,
+			username:  util.RandomOwner(),
+			role:      util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:      "OtherCustomer",
+			accountID: account.ID,
+			username:  util.RandomOwner(),
+			role:      util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	// Loop over each test case and run it as a subtest
@@ -115,7 +163,7 @@ func TestGetAccountAPI(t *testing.T) {
 			tc.buildStubs(store)
 
 			// Start a new server with the mock store
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			// Create a response recorder to capture the response
 			recorder := httptest.NewRecorder()
 
@@ -125,6 +173,10 @@ func TestGetAccountAPI(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			if tc.username != "" {
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			}
+
 			// Serve the HTTP request
 			server.router.ServeHTTP(recorder, request)
 			// Check the response using the provided function
@@ -140,6 +192,7 @@ func randomAccount() db.Account {
 		Owner:    util.RandomOwner(),         // Random account owner
 		Balance:  util.RandomInt(0, 1000000), // Random balance
 		Currency: util.RandomCurrency(),      // Random currency
+		Status:   db.AccountActive,
 	}
 }
 
@@ -157,3 +210,533 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	// Check that the account matches what we expect
 	require.Equal(t, account, gotAccount)
 }
+
+func TestFreezeAccountAPI(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateAccountStatusParams{
+					Status:     db.AccountFrozen,
+					ID:         account.ID,
+					FromStatus: db.AccountActive,
+				}
+				frozen := account
+				frozen.Status = db.AccountFrozen
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Eq(arg)).Times(1).Return(frozen, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Closed",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := account
+				closed.Status = db.AccountClosed
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(closed, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotAnAdmin",
+			role: util.ApproverRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/freeze", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCloseAccountAPI(t *testing.T) {
+	account := randomAccount()
+	sweepAccount := randomAccount()
+	sweepAccount.ID = account.ID + 1
+	sweepAccount.Owner = account.Owner
+	sweepAccount.Currency = account.Currency
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Sweep",
+			body:     gin.H{"sweep_to_account_id": sweepAccount.ID},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sweepAccount.ID)).Times(1).Return(sweepAccount, nil)
+
+				arg := db.CloseAccountTxParams{AccountID: account.ID, SweepToAccountID: sweepAccount.ID}
+				closed := account
+				closed.Status = db.AccountClosed
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CloseAccountTxResult{Account: closed}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NoBody",
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(db.CloseAccountTxParams{AccountID: account.ID})).
+					Times(1).
+					Return(db.CloseAccountTxResult{}, db.ErrAccountNotEmpty)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "SweepToClosedAccount",
+			body:     gin.H{"sweep_to_account_id": sweepAccount.ID},
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := sweepAccount
+				closed.Status = db.AccountClosed
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sweepAccount.ID)).Times(1).Return(closed, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "NotTheOwner",
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body io.Reader = http.NoBody
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			}
+
+			url := fmt.Sprintf("/accounts/%d/close", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.CustomerRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetAccountBalanceAPI(t *testing.T) {
+	account := randomAccount()
+	at := time.Date(2026, time.March, 31, 12, 0, 0, 0, time.UTC)
+	snapshotDate := time.Date(2026, time.March, 30, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "FromSnapshot",
+			query:    "at=2026-03-31T12:00:00Z",
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetLatestDailyBalance(gomock.Any(), gomock.Eq(db.GetLatestDailyBalanceParams{AccountID: account.ID, ThroughDate: snapshotDate})).
+					Times(1).
+					Return(db.DailyBalance{AccountID: account.ID, BalanceDate: snapshotDate, Balance: 500}, nil)
+				store.EXPECT().
+					SumEntriesBetween(gomock.Any(), gomock.Eq(db.SumEntriesBetweenParams{AccountID: account.ID, FromTime: snapshotDate.AddDate(0, 0, 1), ToTime: at})).
+					Times(1).
+					Return(int64(-120), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var balance db.PointInTimeBalance
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &balance))
+				require.Equal(t, int64(380), balance.Balance)
+				require.NotNil(t, balance.SnapshotDate)
+				require.True(t, balance.SnapshotDate.Equal(snapshotDate))
+			},
+		},
+		{
+			name:     "WithoutSnapshot",
+			query:    "at=2026-03-31T14:00:00%2B02:00",
+			username: util.RandomOwner(),
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetLatestDailyBalance(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DailyBalance{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetAccountBalanceAt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.GetAccountBalanceAtParams) (int64, error) {
+						require.True(t, arg.At.Equal(at))
+						return 700, nil
+					})
+				store.EXPECT().SumEntriesBetween(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var balance db.PointInTimeBalance
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &balance))
+				require.Equal(t, int64(700), balance.Balance)
+				require.Nil(t, balance.SnapshotDate)
+			},
+		},
+		{
+			name:     "MissingTimestamp",
+			query:    "",
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidTimestamp",
+			query:    "at=2026-03-31",
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotTheOwner",
+			query:    "at=2026-03-31T12:00:00Z",
+			username: util.RandomOwner(),
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetLatestDailyBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			query:    "at=2026-03-31T12:00:00Z",
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/balance?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateAccountAPI(t *testing.T) {
+	account := randomAccount()
+	account.Currency = util.USD
+	account.Balance = 0
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "DefaultProduct",
+			body:     gin.H{"owner": account.Owner, "currency": account.Currency},
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountTxParams{
+					Owner:       account.Owner,
+					Currency:    account.Currency,
+					ProductCode: db.ProductChecking,
+				}
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:     "Savings",
+			body:     gin.H{"owner": account.Owner, "currency": account.Currency, "product": db.ProductSavings},
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountTxParams{
+					Owner:       account.Owner,
+					Currency:    account.Currency,
+					ProductCode: db.ProductSavings,
+				}
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UnknownProduct",
+			body:     gin.H{"owner": account.Owner, "currency": account.Currency, "product": "pension"},
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, fmt.Errorf("%w %q", db.ErrUnknownProduct, "pension"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "TooManyAccounts",
+			body:     gin.H{"owner": account.Owner, "currency": account.Currency},
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, db.ErrTooManyAccounts)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCurrency",
+			body:     gin.H{"owner": account.Owner, "currency": "XYZ"},
+			username: account.Owner,
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "TellerForCustomer",
+			body:     gin.H{"owner": account.Owner, "currency": account.Currency},
+			username: util.RandomOwner(),
+			role:     util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ForAnotherCustomer",
+			body:     gin.H{"owner": account.Owner, "currency": account.Currency},
+			username: util.RandomOwner(),
+			role:     util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAccountAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name  string
+		role  string
+		owner sql.NullString
+	}{
+		{
+			name:  "CustomerSeesOwnAccounts",
+			role:  util.CustomerRole,
+			owner: sql.NullString{String: username, Valid: true},
+		},
+		{
+			name: "TellerSeesEveryAccount",
+			role: util.TellerRole,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			arg := db.ListAccountsParams{Owner: tc.owner, Limit: 5, Offset: 0}
+			store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Account{}, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?page_id=1&page_size=5", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
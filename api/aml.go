@@ -0,0 +1,113 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+type listAMLAlertsRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=open investigating escalated closed"`
+	Rule     string `form:"rule"`
+	Owner    string `form:"owner"`
+	AfterID  int64  `form:"after_id" binding:"min=0"` // ID of the last alert of the previous page
+	PageSize int32  `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+// listAMLAlerts returns a page of the AML alerts matching the filters, oldest first.
+func (server *Server) listAMLAlerts(ctx *gin.Context) {
+	var req listAMLAlertsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	alerts, err := server.store.ListAMLAlerts(ctx, db.ListAMLAlertsParams{
+		AfterID: req.AfterID,
+		Status:  sql.NullString{String: req.Status, Valid: req.Status != ""},
+		Rule:    sql.NullString{String: req.Rule, Valid: req.Rule != ""},
+		Owner:   sql.NullString{String: req.Owner, Valid: req.Owner != ""},
+		Limit:   req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alerts)
+}
+
+type amlAlertURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getAMLAlert returns an AML alert with the figures its rule matched on.
+func (server *Server) getAMLAlert(ctx *gin.Context) {
+	var uri amlAlertURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	alert, err := server.store.GetAMLAlert(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alert)
+}
+
+type updateAMLAlertRequest struct {
+	Status     string `json:"status" binding:"required,oneof=investigating escalated closed"`
+	Resolution string `json:"resolution" binding:"required_if=Status closed,omitempty,oneof=false_positive reported"`
+	Notes      string `json:"notes" binding:"max=2000"`
+}
+
+// updateAMLAlert moves an AML alert along its case. The investigator who takes an alert is assigned to it.
+func (server *Server) updateAMLAlert(ctx *gin.Context) {
+	var uri amlAlertURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req updateAMLAlertRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	alert, err := server.store.UpdateAMLAlertTx(ctx, db.UpdateAMLAlertTxParams{
+		ID:           uri.ID,
+		Status:       req.Status,
+		Investigator: authPayload.Username,
+		Resolution:   req.Resolution,
+		Notes:        req.Notes,
+		Now:          time.Now(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrInvalidAMLAlertTransition):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrAMLResolutionRequired):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alert)
+}
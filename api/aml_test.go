@@ -0,0 +1,337 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func randomAMLAlert() db.AmlAlert {
+	return db.AmlAlert{
+		ID:         util.RandomInt(1, 1000),
+		TransferID: util.RandomInt(1, 1000),
+		AccountID:  util.RandomInt(1, 1000),
+		Owner:      util.RandomOwner(),
+		Rule:       "large_transfer",
+		Severity:   db.AMLSeverityHigh,
+		Details:    json.RawMessage(`{"amount": 2000000, "min_amount": 1000000}`),
+		Status:     db.AMLAlertStatusOpen,
+	}
+}
+
+func TestListAMLAlertsAPI(t *testing.T) {
+	investigator := util.RandomOwner()
+	alert := randomAMLAlert()
+
+	testCases := []struct {
+		name          string
+		query         string
+		actorRole     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			query:     "?status=open&owner=" + alert.Owner + "&after_id=5&page_size=10",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAMLAlertsParams{
+					AfterID: 5,
+					Status:  sql.NullString{String: db.AMLAlertStatusOpen, Valid: true},
+					Owner:   sql.NullString{String: alert.Owner, Valid: true},
+					Limit:   10,
+				}
+				store.EXPECT().ListAMLAlerts(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.AmlAlert{alert}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var alerts []db.AmlAlert
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &alerts))
+				require.Len(t, alerts, 1)
+				require.Equal(t, alert.ID, alerts[0].ID)
+			},
+		},
+		{
+			name:      "InvalidStatus",
+			query:     "?status=pending&page_size=10",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAMLAlerts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			query:     "?page_size=10",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAMLAlerts(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAnInvestigator",
+			query:     "?page_size=10",
+			actorRole: util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAMLAlerts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/aml_alerts"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, investigator, tc.actorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetAMLAlertAPI(t *testing.T) {
+	investigator := util.RandomOwner()
+	alert := randomAMLAlert()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAMLAlert(gomock.Any(), gomock.Eq(alert.ID)).Times(1).Return(alert, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AmlAlert
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, alert.Rule, got.Rule)
+				require.JSONEq(t, string(alert.Details), string(got.Details))
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAMLAlert(gomock.Any(), gomock.Eq(alert.ID)).Times(1).Return(db.AmlAlert{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/aml_alerts/%d", alert.ID), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, investigator, util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateAMLAlertAPI(t *testing.T) {
+	investigator := util.RandomOwner()
+	alert := randomAMLAlert()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		actorRole     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Investigate",
+			body:      gin.H{"status": db.AMLAlertStatusInvestigating, "notes": "asked for the source of funds"},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAMLAlertTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateAMLAlertTxParams) (db.AmlAlert, error) {
+						require.Equal(t, alert.ID, arg.ID)
+						require.Equal(t, db.AMLAlertStatusInvestigating, arg.Status)
+						require.Equal(t, investigator, arg.Investigator)
+						require.Equal(t, "asked for the source of funds", arg.Notes)
+						require.WithinDuration(t, time.Now(), arg.Now, time.Second)
+
+						updated := alert
+						updated.Status = arg.Status
+						updated.AssignedTo = sql.NullString{String: investigator, Valid: true}
+						return updated, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AmlAlert
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.AMLAlertStatusInvestigating, got.Status)
+				require.Equal(t, investigator, got.AssignedTo.String)
+			},
+		},
+		{
+			name:      "Close",
+			body:      gin.H{"status": db.AMLAlertStatusClosed, "resolution": db.AMLResolutionReported},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAMLAlertTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateAMLAlertTxParams) (db.AmlAlert, error) {
+						require.Equal(t, db.AMLResolutionReported, arg.Resolution)
+						return alert, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "CloseWithoutResolution",
+			body:      gin.H{"status": db.AMLAlertStatusClosed},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAMLAlertTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidResolution",
+			body:      gin.H{"status": db.AMLAlertStatusClosed, "resolution": "ignored"},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAMLAlertTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "Reopen",
+			body:      gin.H{"status": db.AMLAlertStatusOpen},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAMLAlertTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidTransition",
+			body:      gin.H{"status": db.AMLAlertStatusEscalated},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAMLAlertTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AmlAlert{}, fmt.Errorf("%w: closed to escalated", db.ErrInvalidAMLAlertTransition))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			body:      gin.H{"status": db.AMLAlertStatusInvestigating},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAMLAlertTx(gomock.Any(), gomock.Any()).Times(1).Return(db.AmlAlert{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAnInvestigator",
+			body:      gin.H{"status": db.AMLAlertStatusInvestigating},
+			actorRole: util.ApproverRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAMLAlertTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/aml_alerts/%d", alert.ID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, investigator, tc.actorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
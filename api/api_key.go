@@ -0,0 +1,265 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+const (
+	// apiKeyPrefix starts every API key, so leaked keys are easy to recognize.
+	// A key is gbp_<prefix>_<secret>; the prefix finds the key and only the secret's hash is stored.
+	apiKeyPrefix = "gbp_"
+	// apiKeyLastUsedInterval is how often the last use of a key is written, rather than on every request
+	apiKeyLastUsedInterval = time.Minute
+)
+
+// errInvalidAPIKey is the only answer to an unknown, wrong, revoked or expired API key
+var errInvalidAPIKey = errors.New("API key is invalid, revoked or expired")
+
+// routeScopes is the scope an API key needs for each route it can reach, by method and route path.
+// The other routes, such as logging in or managing API keys, need a bearer token.
+var routeScopes = map[string]string{
+	"POST /accounts":                    util.ScopeAccountsWrite,
+	"GET /accounts/:id":                 util.ScopeAccountsRead,
+	"GET /accounts":                     util.ScopeAccountsRead,
+	"POST /accounts/:id/close":          util.ScopeAccountsWrite,
+	"GET /accounts/:id/balance":         util.ScopeAccountsRead,
+	"POST /transfers":                   util.ScopeTransfersWrite,
+	"GET /transfers":                    util.ScopeTransfersRead,
+	"GET /transfers/:id":                util.ScopeTransfersRead,
+	"POST /transfers/batch":             util.ScopeTransfersWrite,
+	"POST /transfers/quote":             util.ScopeTransfersRead,
+	"GET /transfers/batch/:id":          util.ScopeTransfersRead,
+	"POST /scheduled_transfers":         util.ScopeTransfersWrite,
+	"GET /scheduled_transfers":          util.ScopeTransfersRead,
+	"GET /scheduled_transfers/:id":      util.ScopeTransfersRead,
+	"PATCH /scheduled_transfers/:id":    util.ScopeTransfersWrite,
+	"DELETE /scheduled_transfers/:id":   util.ScopeTransfersWrite,
+	"GET /scheduled_transfers/:id/runs": util.ScopeTransfersRead,
+	"POST /holds":                       util.ScopeHoldsWrite,
+	"GET /holds/:id":                    util.ScopeHoldsRead,
+	"POST /holds/:id/capture":           util.ScopeHoldsWrite,
+	"POST /holds/:id/release":           util.ScopeHoldsWrite,
+	"POST /withdrawals":                 util.ScopeCashWrite,
+	"POST /deposits":                    util.ScopeCashWrite,
+}
+
+// checkRouteScope returns an error unless the API key has the scope of the request's route.
+func checkRouteScope(ctx *gin.Context, key db.ApiKey) error {
+	scope, ok := routeScopes[ctx.Request.Method+" "+ctx.FullPath()]
+	if !ok {
+		return errors.New("this route cannot be used with an API key")
+	}
+	if !slices.Contains(key.Scopes, scope) {
+		return fmt.Errorf("API key does not have the %s scope", scope)
+	}
+	return nil
+}
+
+// verifyAPIKey finds the key of an Authorization: ApiKey header and checks its secret, revocation and expiry.
+// It returns errInvalidAPIKey for keys that cannot be used, and other errors when the store fails.
+func verifyAPIKey(ctx *gin.Context, store db.Store, apiKey string) (db.ApiKey, error) {
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(apiKey, apiKeyPrefix), "_")
+	if !ok || !strings.HasPrefix(apiKey, apiKeyPrefix) {
+		return db.ApiKey{}, errInvalidAPIKey
+	}
+
+	key, err := store.GetAPIKeyByPrefix(ctx, prefix)
+	if err == sql.ErrNoRows {
+		return db.ApiKey{}, errInvalidAPIKey
+	}
+	if err != nil {
+		return db.ApiKey{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(hashSecret(secret))) != 1 {
+		return db.ApiKey{}, errInvalidAPIKey
+	}
+	if key.RevokedAt.Valid || (key.ExpiresAt.Valid && !time.Now().Before(key.ExpiresAt.Time)) {
+		return db.ApiKey{}, errInvalidAPIKey
+	}
+
+	// Knowing when a key was last used helps find the unused ones, to the minute is enough
+	if !key.LastUsedAt.Valid || time.Since(key.LastUsedAt.Time) >= apiKeyLastUsedInterval {
+		err := store.UpdateAPIKeyLastUsed(ctx, db.UpdateAPIKeyLastUsedParams{LastUsedAt: sql.NullTime{Time: time.Now(), Valid: true}, ID: key.ID})
+		if err != nil {
+			log.Printf("cannot update the last use of API key %d: %v", key.ID, err)
+		}
+	}
+	return key, nil
+}
+
+// apiKeyPayload makes the authorization payload of a request made with an API key, which acts as its owner.
+func apiKeyPayload(key db.ApiKey, user db.User) *token.Payload {
+	payload := &token.Payload{
+		ID:       apiKeyPrefix + key.Prefix,
+		Username: user.Username,
+		Role:     user.Role,
+		IssuedAt: key.CreatedAt,
+	}
+	if key.ExpiresAt.Valid {
+		payload.ExpiredAt = key.ExpiresAt.Time
+	}
+	return payload
+}
+
+type apiKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// newAPIKeyResponse leaves out the hash of the key's secret.
+func newAPIKeyResponse(key db.ApiKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  nullTimePtr(key.ExpiresAt),
+		LastUsedAt: nullTimePtr(key.LastUsedAt),
+		RevokedAt:  nullTimePtr(key.RevokedAt),
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// nullTimePtr returns nil for a null time, which is sent as null.
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+type createAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// createAPIKeyResponse carries the key itself, which is only ever sent here.
+type createAPIKeyResponse struct {
+	Key string `json:"key"`
+	apiKeyResponse
+}
+
+// createAPIKey gives the authenticated user a new API key for services that cannot log in.
+// The key can do what the user can on the routes of its scopes. Users who enabled two-factor
+// authentication confirm it with a code.
+func (server *Server) createAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("expires_at must be in the future")))
+		return
+	}
+
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	if !server.checkStepUp(ctx, user) {
+		return
+	}
+
+	prefixBytes := make([]byte, 6)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	prefix := hex.EncodeToString(prefixBytes)
+	secret, err := newSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	arg := db.CreateAPIKeyParams{
+		Owner:      user.Username,
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: hashSecret(secret),
+		Scopes:     slices.Compact(scopes),
+	}
+	if req.ExpiresAt != nil {
+		arg.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	key, err := server.store.CreateAPIKey(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createAPIKeyResponse{
+		Key:            apiKeyPrefix + prefix + "_" + secret,
+		apiKeyResponse: newAPIKeyResponse(key),
+	})
+}
+
+// listAPIKeys returns the authenticated user's API keys, revoked ones included, without their secrets.
+func (server *Server) listAPIKeys(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	keys, err := server.store.ListAPIKeys(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]apiKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		rsp = append(rsp, newAPIKeyResponse(key))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type revokeAPIKeyRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// revokeAPIKey stops one of the authenticated user's API keys from working. Keys of other users are not found.
+func (server *Server) revokeAPIKey(ctx *gin.Context) {
+	var req revokeAPIKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	key, err := server.store.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{
+		RevokedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		ID:        req.ID,
+		Owner:     authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("API key not found or already revoked")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAPIKeyResponse(key))
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+)
+
+// auditExportPageSize is the number of audit events read at a time while exporting.
+const auditExportPageSize = 1000
+
+// auditEventFilter narrows the audit events down. Every filter is optional.
+type auditEventFilter struct {
+	Actor        string    `form:"actor"`
+	Action       string    `form:"action"`
+	ResourceType string    `form:"resource_type"`
+	ResourceID   string    `form:"resource_id"`
+	RequestID    string    `form:"request_id"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"` // included
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`   // excluded
+}
+
+func (filter auditEventFilter) params(afterID int64, limit int32) db.ListAuditEventsParams {
+	return db.ListAuditEventsParams{
+		AfterID:      afterID,
+		Actor:        sql.NullString{String: filter.Actor, Valid: filter.Actor != ""},
+		Action:       sql.NullString{String: filter.Action, Valid: filter.Action != ""},
+		ResourceType: sql.NullString{String: filter.ResourceType, Valid: filter.ResourceType != ""},
+		ResourceID:   sql.NullString{String: filter.ResourceID, Valid: filter.ResourceID != ""},
+		RequestID:    sql.NullString{String: filter.RequestID, Valid: filter.RequestID != ""},
+		FromTime:     sql.NullTime{Time: filter.From, Valid: !filter.From.IsZero()},
+		ToTime:       sql.NullTime{Time: filter.To, Valid: !filter.To.IsZero()},
+		Limit:        limit,
+	}
+}
+
+type listAuditEventsRequest struct {
+	auditEventFilter
+	AfterID  int64 `form:"after_id" binding:"min=0"` // ID of the last event of the previous page
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+// listAuditEvents returns a page of the audit events matching the filters, oldest first.
+func (server *Server) listAuditEvents(ctx *gin.Context) {
+	var req listAuditEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	events, err := server.store.ListAuditEvents(ctx, req.params(req.AfterID, req.PageSize))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+// exportAuditEvents streams every audit event matching the filters as JSON Lines, one event per line, oldest first.
+func (server *Server) exportAuditEvents(ctx *gin.Context) {
+	var filter auditEventFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := filter.params(0, auditExportPageSize)
+	events, err := server.store.ListAuditEvents(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Type", "application/x-ndjson")
+	ctx.Header("Content-Disposition", `attachment; filename="audit_events.jsonl"`)
+	ctx.Status(http.StatusOK)
+
+	// Once the first line is sent the status cannot change, so a later failure ends the export early
+	encoder := json.NewEncoder(ctx.Writer)
+	for {
+		for _, event := range events {
+			if err := encoder.Encode(event); err != nil {
+				log.Printf("export audit events: %v", err)
+				return
+			}
+		}
+		ctx.Writer.Flush()
+
+		if len(events) < auditExportPageSize {
+			return
+		}
+		arg.AfterID = events[len(events)-1].ID
+
+		events, err = server.store.ListAuditEvents(ctx, arg)
+		if err != nil {
+			log.Printf("export audit events: %v", err)
+			return
+		}
+	}
+}
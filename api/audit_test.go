@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func randomAuditEvent(id int64) db.AuditEvent {
+	return db.AuditEvent{
+		ID:           id,
+		Actor:        util.RandomOwner(),
+		Action:       "account.create",
+		ResourceType: db.AuditAccount,
+		ResourceID:   "1",
+		Before:       json.RawMessage("null"),
+		After:        json.RawMessage(`{"id":1}`),
+		RequestID:    util.RandomString(32),
+		CreatedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestListAuditEventsAPI(t *testing.T) {
+	events := []db.AuditEvent{randomAuditEvent(11), randomAuditEvent(12)}
+	from := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "actor=alice&resource_type=account&from=2026-03-01T00:00:00Z&after_id=10&page_size=2",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditEventsParams{
+					AfterID:      10,
+					Actor:        sql.NullString{String: "alice", Valid: true},
+					ResourceType: sql.NullString{String: db.AuditAccount, Valid: true},
+					FromTime:     sql.NullTime{Time: from, Valid: true},
+					Limit:        2,
+				}
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, got db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+						require.True(t, got.FromTime.Time.Equal(from))
+						got.FromTime.Time = from
+						require.Equal(t, arg, got)
+						return events, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []db.AuditEvent
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Len(t, got, 2)
+				require.Equal(t, events[0].ID, got[0].ID)
+			},
+		},
+		{
+			name:  "MissingPageSize",
+			query: "actor=alice",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidTime",
+			query: "from=yesterday&page_size=10",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAnAdmin",
+			query: "page_size=10",
+			role:  util.ApproverRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/audit_events?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestExportAuditEventsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// a full page is followed by a short one, which ends the export
+	firstPage := make([]db.AuditEvent, auditExportPageSize)
+	for i := range firstPage {
+		firstPage[i] = randomAuditEvent(int64(i + 1))
+	}
+	secondPage := []db.AuditEvent{randomAuditEvent(auditExportPageSize + 1)}
+
+	first := db.ListAuditEventsParams{
+		Action: sql.NullString{String: "account.create", Valid: true},
+		Limit:  auditExportPageSize,
+	}
+	second := first
+	second.AfterID = auditExportPageSize
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(first)).Return(firstPage, nil),
+		store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(second)).Return(secondPage, nil),
+	)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/audit_events/export?action=account.create", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+
+	lines := 0
+	scanner := bufio.NewScanner(bytes.NewReader(recorder.Body.Bytes()))
+	for scanner.Scan() {
+		var event db.AuditEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		lines++
+		require.Equal(t, int64(lines), event.ID)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, auditExportPageSize+1, lines)
+}
+
+func TestExportAuditEventsAPIError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/audit_events/export", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+)
+
+// conversionRequest moves an amount between two accounts of the user in different currencies.
+// Currency is the currency of the source account and of the amount, ToCurrency that of the target account.
+type conversionRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	ToCurrency    string `json:"to_currency" binding:"required,currency,nefield=Currency"`
+}
+
+// conversionQuoteResponse is what a conversion would cost the user and what it would pay out.
+type conversionQuoteResponse struct {
+	FromAccountID   int64  `json:"from_account_id"`
+	ToAccountID     int64  `json:"to_account_id"`
+	Amount          int64  `json:"amount"`
+	Spread          int64  `json:"spread"`
+	Total           int64  `json:"total"`
+	Currency        string `json:"currency"`
+	Rate            string `json:"rate"`
+	ConvertedAmount int64  `json:"converted_amount"`
+	ToCurrency      string `json:"to_currency"`
+}
+
+// createConversion converts money between two accounts of the authenticated user at the bank's exchange rate,
+// charging the FX spread of the source currency on top of the amount.
+func (server *Server) createConversion(ctx *gin.Context) {
+	var req conversionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.stepUpForAmount(ctx, req.Amount) {
+		return
+	}
+	if !server.validConversionAccounts(ctx, req) {
+		return
+	}
+
+	result, err := server.store.ConvertTx(ctx, db.ConvertTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	})
+	if err != nil {
+		conversionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// quoteConversion previews the rate and the spread of a conversion with the same checks as createConversion,
+// without moving any money.
+func (server *Server) quoteConversion(ctx *gin.Context) {
+	var req conversionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.validConversionAccounts(ctx, req) {
+		return
+	}
+
+	quote, err := db.QuoteConversion(ctx, server.store, req.Currency, req.ToCurrency, req.Amount)
+	if err != nil {
+		conversionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conversionQuoteResponse{
+		FromAccountID:   req.FromAccountID,
+		ToAccountID:     req.ToAccountID,
+		Amount:          req.Amount,
+		Spread:          quote.Spread,
+		Total:           req.Amount + quote.Spread,
+		Currency:        req.Currency,
+		Rate:            quote.Rate,
+		ConvertedAmount: quote.ConvertedAmount,
+		ToCurrency:      req.ToCurrency,
+	})
+}
+
+// validConversionAccounts checks that both accounts of a conversion belong to the authenticated user and are
+// in the requested currencies, and that money can be taken out of the source account.
+func (server *Server) validConversionAccounts(ctx *gin.Context, req conversionRequest) bool {
+	if !server.validSourceAccount(ctx, req.FromAccountID, req.Currency, "") {
+		return false
+	}
+
+	account, status, err := server.fetchAccount(ctx, req.ToAccountID)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return false
+	}
+	if !server.authorizeAccount(ctx, account, "") {
+		return false
+	}
+	if status, err := checkAccountUse(account, req.ToCurrency, false); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// conversionError answers with the HTTP status that fits an error of ConvertTx or QuoteConversion.
+func conversionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrNoExchangeRate),
+		errors.Is(err, db.ErrConversionTooSmall),
+		errors.Is(err, db.ErrSameCurrency),
+		errors.Is(err, db.ErrInsufficientFunds):
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	case isAccountStatusError(err):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case isKYCLimitError(err):
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+// listExchangeRates returns the exchange rates of every currency pair.
+func (server *Server) listExchangeRates(ctx *gin.Context) {
+	rates, err := server.store.ListExchangeRates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+// exchangeRateRequest sets the rate from one currency to another, in minor units of the quote currency
+// per minor unit of the base currency, as a decimal string such as "1.0845".
+type exchangeRateRequest struct {
+	BaseCurrency  string `json:"base_currency" binding:"required,currency"`
+	QuoteCurrency string `json:"quote_currency" binding:"required,currency,nefield=BaseCurrency"`
+	Rate          string `json:"rate" binding:"required,numeric"`
+}
+
+// setExchangeRate creates or replaces the exchange rate of a currency pair. It applies to conversions made from then on.
+func (server *Server) setExchangeRate(ctx *gin.Context) {
+	var req exchangeRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if rate, ok := new(big.Rat).SetString(req.Rate); !ok || rate.Sign() <= 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("rate must be a positive decimal")))
+		return
+	}
+
+	rate, err := server.store.UpsertExchangeRate(ctx, db.UpsertExchangeRateParams{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rate)
+}
+
+type deleteExchangeRateRequest struct {
+	BaseCurrency  string `uri:"base_currency" binding:"required,currency"`
+	QuoteCurrency string `uri:"quote_currency" binding:"required,currency"`
+}
+
+// deleteExchangeRate removes the exchange rate of a currency pair, which stops conversions between them, and returns it.
+func (server *Server) deleteExchangeRate(ctx *gin.Context) {
+	var req deleteExchangeRateRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rate, err := server.store.DeleteExchangeRate(ctx, db.DeleteExchangeRateParams{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rate)
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestCreateConversionAPI(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account2.ID = account1.ID + 1
+	account2.Owner = account1.Owner
+	account2.Currency = otherCurrency(account1.Currency)
+
+	body := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          100,
+		"currency":        account1.Currency,
+		"to_currency":     account2.Currency,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				arg := db.ConvertTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 100}
+				store.EXPECT().
+					ConvertTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ConvertTxResult{Conversion: db.Conversion{ID: 1, Rate: "1.5"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.ConvertTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Equal(t, "1.5", result.Conversion.Rate)
+			},
+		},
+		{
+			name: "SameCurrency",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          100,
+				"currency":        account1.Currency,
+				"to_currency":     account1.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ConvertTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotOwnTargetAccount",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account2
+				other.Owner = util.RandomOwner()
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(other, nil)
+				store.EXPECT().ConvertTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NoExchangeRate",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ConvertTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConvertTxResult{}, fmt.Errorf("%s to %s: %w", account1.Currency, account2.Currency, db.ErrNoExchangeRate))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TransferLimitExceeded",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ConvertTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConvertTxResult{}, db.ErrTransferLimitExceeded)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/conversions", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account1.Owner, util.CustomerRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestQuoteConversionAPI(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account2.ID = account1.ID + 1
+	account2.Owner = account1.Owner
+	account2.Currency = otherCurrency(account1.Currency)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	store.EXPECT().
+		GetExchangeRate(gomock.Any(), gomock.Eq(db.GetExchangeRateParams{BaseCurrency: account1.Currency, QuoteCurrency: account2.Currency})).
+		Times(1).
+		Return(db.ExchangeRate{BaseCurrency: account1.Currency, QuoteCurrency: account2.Currency, Rate: "1.0845"}, nil)
+	store.EXPECT().
+		GetFeeSchedule(gomock.Any(), gomock.Eq(db.GetFeeScheduleParams{FeeType: db.FeeFXSpread, Currency: account1.Currency})).
+		Times(1).
+		Return(db.FeeSchedule{FeeType: db.FeeFXSpread, Currency: account1.Currency, Method: db.FeePercentage, RateBps: 50}, nil)
+	store.EXPECT().ConvertTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          1000,
+		"currency":        account1.Currency,
+		"to_currency":     account2.Currency,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/conversions/quote", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account1.Owner, util.CustomerRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var quote conversionQuoteResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &quote))
+	require.Equal(t, int64(1084), quote.ConvertedAmount) // rounded down
+	require.Equal(t, int64(5), quote.Spread)
+	require.Equal(t, int64(1005), quote.Total)
+}
+
+func TestSetExchangeRateAPI(t *testing.T) {
+	arg := db.UpsertExchangeRateParams{
+		BaseCurrency:  util.USD,
+		QuoteCurrency: util.EUR,
+		Rate:          "0.92",
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: util.AdminRole,
+			body: gin.H{"base_currency": arg.BaseCurrency, "quote_currency": arg.QuoteCurrency, "rate": arg.Rate},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertExchangeRate(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ExchangeRate{BaseCurrency: arg.BaseCurrency, QuoteCurrency: arg.QuoteCurrency, Rate: arg.Rate}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ZeroRate",
+			role: util.AdminRole,
+			body: gin.H{"base_currency": arg.BaseCurrency, "quote_currency": arg.QuoteCurrency, "rate": "0"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SameCurrency",
+			role: util.AdminRole,
+			body: gin.H{"base_currency": arg.BaseCurrency, "quote_currency": arg.BaseCurrency, "rate": arg.Rate},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAnAdmin",
+			role: util.CustomerRole,
+			body: gin.H{"base_currency": arg.BaseCurrency, "quote_currency": arg.QuoteCurrency, "rate": arg.Rate},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/exchange_rates", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+)
+
+// transferQuoteRequest describes a transfer to price without making it.
+type transferQuoteRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+// transferQuoteResponse is what a transfer would cost the sender: the amount plus the fee.
+type transferQuoteResponse struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Fee           int64  `json:"fee"`
+	Total         int64  `json:"total"`
+	Currency      string `json:"currency"`
+}
+
+// quoteTransfer previews the fee of a transfer with the same checks as createTransfer, without moving any money.
+func (server *Server) quoteTransfer(ctx *gin.Context) {
+	var req transferQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.validSourceAccount(ctx, req.FromAccountID, req.Currency, "") {
+		return
+	}
+	if !server.validAccount(ctx, req.ToAccountID, req.Currency) {
+		return
+	}
+
+	fee, err := db.QuoteFee(ctx, server.store, db.FeeTransfer, req.Currency, req.Amount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transferQuoteResponse{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Fee:           fee,
+		Total:         req.Amount + fee,
+		Currency:      req.Currency,
+	})
+}
+
+// listFeeSchedules returns the fee schedules of every currency.
+func (server *Server) listFeeSchedules(ctx *gin.Context) {
+	schedules, err := server.store.ListFeeSchedules(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+// feeScheduleRequest sets how a type of fee is priced in a currency.
+// Flat fees only use FlatAmount; percentage fees use RateBps, kept between MinFee and MaxFee (0 for no cap).
+type feeScheduleRequest struct {
+	FeeType    string `json:"fee_type" binding:"required,oneof=transfer fx_spread"`
+	Currency   string `json:"currency" binding:"required,currency"`
+	Method     string `json:"method" binding:"required,oneof=flat percentage"`
+	FlatAmount int64  `json:"flat_amount" binding:"min=0"`
+	RateBps    int32  `json:"rate_bps" binding:"min=0,max=10000"`
+	MinFee     int64  `json:"min_fee" binding:"min=0"`
+	MaxFee     int64  `json:"max_fee" binding:"min=0"`
+}
+
+// setFeeSchedule creates or replaces the schedule of a type of fee in a currency. It applies to transfers made from then on.
+func (server *Server) setFeeSchedule(ctx *gin.Context) {
+	var req feeScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.MaxFee > 0 && req.MaxFee < req.MinFee {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("max_fee must not be below min_fee")))
+		return
+	}
+
+	schedule, err := server.store.UpsertFeeSchedule(ctx, db.UpsertFeeScheduleParams{
+		FeeType:    req.FeeType,
+		Currency:   req.Currency,
+		Method:     req.Method,
+		FlatAmount: req.FlatAmount,
+		RateBps:    req.RateBps,
+		MinFee:     req.MinFee,
+		MaxFee:     req.MaxFee,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+type deleteFeeScheduleRequest struct {
+	FeeType  string `uri:"fee_type" binding:"required,oneof=transfer fx_spread"`
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+// deleteFeeSchedule removes the schedule of a type of fee in a currency, which makes that fee free, and returns it.
+func (server *Server) deleteFeeSchedule(ctx *gin.Context) {
+	var req deleteFeeScheduleRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule, err := server.store.DeleteFeeSchedule(ctx, db.DeleteFeeScheduleParams{
+		FeeType:  req.FeeType,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+// defaultHoldTTL is used when neither the request nor the config sets when a hold expires
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+// createHoldRequest represents the JSON payload for reserving money on an account (an authorization).
+type createHoldRequest struct {
+	AccountID   int64      `json:"account_id" binding:"required,min=1"`
+	Amount      int64      `json:"amount" binding:"required,gt=0"`
+	Currency    string     `json:"currency" binding:"required,currency"`
+	Description string     `json:"description"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// createHold reserves money on an account. The hold lowers the available balance but not the ledger balance.
+func (server *Server) createHold(ctx *gin.Context) {
+	var req createHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	expiresAt := time.Now().Add(server.holdTTL())
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("expires_at must be in the future")))
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	if !server.stepUpForAmount(ctx, req.Amount) {
+		return
+	}
+
+	if !server.validSourceAccount(ctx, req.AccountID, req.Currency, "") {
+		return
+	}
+
+	result, err := server.store.CreateHoldTx(ctx, db.CreateHoldTxParams{
+		AccountID:   req.AccountID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		holdError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type getHoldRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getHold returns a single hold by ID.
+func (server *Server) getHold(ctx *gin.Context) {
+	var req getHoldRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, _, ok := server.authorizeHold(ctx, req.ID, util.PermViewAnyAccount)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+// authorizeHold loads a hold and its account, checking that the account belongs to the authenticated user
+// or that their role has anyAccountPermission. Otherwise it writes an error response and returns false.
+func (server *Server) authorizeHold(ctx *gin.Context, holdID int64, anyAccountPermission string) (db.Hold, db.Account, bool) {
+	hold, err := server.store.GetHold(ctx, holdID)
+	if err != nil {
+		holdError(ctx, err)
+		return hold, db.Account{}, false
+	}
+
+	account, err := server.store.GetAccount(ctx, hold.AccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, account, false
+	}
+
+	return hold, account, server.authorizeAccount(ctx, account, anyAccountPermission)
+}
+
+// captureHoldRequest holds the optional capture details. Without an amount the whole hold is captured,
+// and without a destination account the money is withdrawn.
+type captureHoldRequest struct {
+	Amount      int64 `json:"amount" binding:"omitempty,gt=0"`
+	ToAccountID int64 `json:"to_account_id" binding:"omitempty,min=1"`
+}
+
+// captureHold takes the money reserved by a hold, turning it into a transfer or a withdrawal.
+// Like createTransfer, it holds payments above the approval threshold until a different user approves them,
+// answering with 202 Accepted.
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri getHoldRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, account, ok := server.authorizeHold(ctx, uri.ID, "")
+	if !ok {
+		return
+	}
+
+	// Paying another account needs it to be in the same currency as the held account
+	if req.ToAccountID != 0 && !server.validAccount(ctx, req.ToAccountID, account.Currency) {
+		return
+	}
+
+	arg := db.CaptureHoldTxParams{
+		HoldID:      uri.ID,
+		Amount:      req.Amount,
+		ToAccountID: req.ToAccountID,
+		Now:         time.Now(),
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if req.ToAccountID != 0 && server.needsTransferApproval(amount) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		arg.RequestedBy = authPayload.Username
+		arg.ApprovalExpiresAt = server.transferApprovalExpiry()
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, arg)
+	if err != nil {
+		holdError(ctx, err)
+		return
+	}
+
+	if result.Approval != nil {
+		ctx.JSON(http.StatusAccepted, result)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// releaseHold cancels a hold and makes its money available again.
+func (server *Server) releaseHold(ctx *gin.Context) {
+	var req getHoldRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, _, ok := server.authorizeHold(ctx, req.ID, ""); !ok {
+		return
+	}
+
+	result, err := server.store.ReleaseHoldTx(ctx, req.ID)
+	if err != nil {
+		holdError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// withdrawalRequest represents the JSON payload for taking money out of an account.
+type withdrawalRequest struct {
+	AccountID int64  `json:"account_id" binding:"required,min=1"`
+	Amount    int64  `json:"amount" binding:"required,gt=0"`
+	Currency  string `json:"currency" binding:"required,currency"`
+}
+
+// createWithdrawal debits an account, as long as its available balance covers the amount.
+func (server *Server) createWithdrawal(ctx *gin.Context) {
+	var req withdrawalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.stepUpForAmount(ctx, req.Amount) {
+		return
+	}
+
+	// Customers withdraw from their own accounts, tellers pay out from any account at the counter
+	if !server.validSourceAccount(ctx, req.AccountID, req.Currency, util.PermWithdrawAnyCash) {
+		return
+	}
+
+	result, err := server.store.WithdrawTx(ctx, db.WithdrawTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+	})
+	if err != nil {
+		holdError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// depositRequest represents the JSON payload for paying cash into an account.
+type depositRequest struct {
+	AccountID int64  `json:"account_id" binding:"required,min=1"`
+	Amount    int64  `json:"amount" binding:"required,gt=0"`
+	Currency  string `json:"currency" binding:"required,currency"`
+}
+
+// createDeposit credits an account with cash taken at the counter. Frozen accounts can still receive it.
+func (server *Server) createDeposit(ctx *gin.Context) {
+	var req depositRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.validAccount(ctx, req.AccountID, req.Currency) {
+		return
+	}
+
+	result, err := server.store.DepositTx(ctx, db.DepositTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+	})
+	if err != nil {
+		holdError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// holdTTL returns how long a hold lasts when the request does not say.
+func (server *Server) holdTTL() time.Duration {
+	if server.config.HoldTTL > 0 {
+		return server.config.HoldTTL
+	}
+	return defaultHoldTTL
+}
+
+// holdError maps the errors of the hold and withdrawal store methods to HTTP responses.
+func holdError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrHoldNotActive), errors.Is(err, db.ErrHoldExpired), isAccountStatusError(err):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case isKYCLimitError(err):
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+	case errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrCaptureExceedsHold),
+		errors.Is(err, db.ErrCaptureToHoldAccount):
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
@@ -0,0 +1,397 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+// defaultKYCMaxFileSize is used when the largest KYC document is not configured
+const defaultKYCMaxFileSize = 5 << 20
+
+// kycMinAge is the age from which users can open a verified profile
+const kycMinAge = 18
+
+// kycContentTypes are the types of the KYC documents that can be uploaded, sniffed from their content
+var kycContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// kycDocumentResponse is a KYC document without its key in the blob store.
+type kycDocumentResponse struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Sha256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newKYCDocumentResponse(document db.KycDocument) kycDocumentResponse {
+	return kycDocumentResponse{
+		ID:          document.ID,
+		Kind:        document.Kind,
+		ContentType: document.ContentType,
+		Size:        document.Size,
+		Sha256:      document.Sha256,
+		CreatedAt:   document.CreatedAt,
+	}
+}
+
+// kycResponse is the KYC status of a user, with the limits of their tier, their profile once submitted and their documents.
+type kycResponse struct {
+	Username  string                `json:"username"`
+	Status    string                `json:"status"`
+	Tier      db.KycTier            `json:"tier"`
+	Profile   *db.KycProfile        `json:"profile"`
+	Documents []kycDocumentResponse `json:"documents"`
+}
+
+// fetchKYC collects the KYC response of a user. Otherwise it writes an error response and returns false.
+func (server *Server) fetchKYC(ctx *gin.Context, user db.User) (kycResponse, bool) {
+	rsp := kycResponse{Username: user.Username, Status: user.KycStatus}
+
+	var err error
+	rsp.Tier, err = server.store.GetKYCTier(ctx, user.KycTier)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return rsp, false
+	}
+
+	profile, err := server.store.GetKYCProfile(ctx, user.Username)
+	switch {
+	case err == nil:
+		rsp.Profile = &profile
+	case !errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return rsp, false
+	}
+
+	documents, err := server.store.ListKYCDocuments(ctx, user.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return rsp, false
+	}
+	rsp.Documents = make([]kycDocumentResponse, len(documents))
+	for i, document := range documents {
+		rsp.Documents[i] = newKYCDocumentResponse(document)
+	}
+	return rsp, true
+}
+
+// listKYCTiers returns the KYC tiers and their limits.
+func (server *Server) listKYCTiers(ctx *gin.Context) {
+	tiers, err := server.store.ListKYCTiers(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tiers)
+}
+
+// getCurrentKYC returns the KYC status, limits, profile and documents of the authenticated user.
+func (server *Server) getCurrentKYC(ctx *gin.Context) {
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	rsp, ok := server.fetchKYC(ctx, user)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type submitKYCRequest struct {
+	DateOfBirth  string `json:"date_of_birth" binding:"required,datetime=2006-01-02"`
+	AddressLine1 string `json:"address_line1" binding:"required,max=200"`
+	AddressLine2 string `json:"address_line2" binding:"max=200"`
+	City         string `json:"city" binding:"required,max=100"`
+	PostalCode   string `json:"postal_code" binding:"required,max=20"`
+	Country      string `json:"country" binding:"required,iso3166_1_alpha2"`
+}
+
+// submitKYC submits the KYC profile of the authenticated user for review, along with the documents they upload.
+func (server *Server) submitKYC(ctx *gin.Context) {
+	var req submitKYCRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	dateOfBirth, _ := time.Parse(time.DateOnly, req.DateOfBirth)
+	if dateOfBirth.AddDate(kycMinAge, 0, 0).After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("users must be at least %d years old", kycMinAge)))
+		return
+	}
+
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	result, err := server.store.SubmitKYCProfileTx(ctx, db.SubmitKYCProfileTxParams{
+		Username:     user.Username,
+		DateOfBirth:  dateOfBirth,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		City:         req.City,
+		PostalCode:   req.PostalCode,
+		Country:      req.Country,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrKYCVerified) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp, ok := server.fetchKYC(ctx, result.User)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type uploadKYCDocumentRequest struct {
+	Kind string `form:"kind" binding:"required,oneof=passport id_card driving_licence proof_of_address"`
+}
+
+// uploadKYCDocument stores a document of the authenticated user's KYC profile, sent as the "file" of a
+// multipart form, in the blob store. Only PDF, JPEG and PNG files up to the configured size are accepted.
+func (server *Server) uploadKYCDocument(ctx *gin.Context) {
+	var req uploadKYCDocumentRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	maxSize := server.config.KYCMaxFileSize
+	if maxSize <= 0 {
+		maxSize = defaultKYCMaxFileSize
+	}
+	if header.Size > maxSize {
+		err := fmt.Errorf("file is larger than %d bytes", maxSize)
+		ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	defer file.Close()
+
+	// The type is sniffed from the content, as the one the client sends cannot be trusted
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	if !kycContentTypes[contentType] {
+		err := fmt.Errorf("unsupported file type %s, upload a PDF, JPEG or PNG file", contentType)
+		ctx.JSON(http.StatusUnsupportedMediaType, errorResponse(err))
+		return
+	}
+
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	key, err := newKYCBlobKey(user.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	hash := sha256.New()
+	size, err := server.blobs.Put(ctx, key, io.TeeReader(io.MultiReader(bytes.NewReader(head), file), hash))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	document, err := server.store.AddKYCDocumentTx(ctx, db.CreateKYCDocumentParams{
+		Username:    user.Username,
+		Kind:        req.Kind,
+		BlobKey:     key,
+		ContentType: contentType,
+		Size:        size,
+		Sha256:      hex.EncodeToString(hash.Sum(nil)),
+	})
+	if err != nil {
+		// Nothing refers to the blob, so it is removed
+		if deleteErr := server.blobs.Delete(ctx, key); deleteErr != nil {
+			log.Printf("cannot delete KYC document %s: %v", key, deleteErr)
+		}
+		if errors.Is(err, db.ErrKYCVerified) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newKYCDocumentResponse(document))
+}
+
+// newKYCBlobKey returns a random key for a KYC document of a user, so documents cannot be found by guessing.
+func newKYCBlobKey(username string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "kyc/" + username + "/" + hex.EncodeToString(b), nil
+}
+
+// fetchUserForKYC reads the user of a KYC review route. Otherwise it writes an error response and returns false.
+func (server *Server) fetchUserForKYC(ctx *gin.Context) (db.User, bool) {
+	var uri userDataURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.User{}, false
+	}
+
+	user, err := server.store.GetUser(ctx, uri.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return user, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+	return user, true
+}
+
+// getUserKYC returns the KYC status, limits, profile and documents of any user, for reviewers.
+func (server *Server) getUserKYC(ctx *gin.Context) {
+	user, ok := server.fetchUserForKYC(ctx)
+	if !ok {
+		return
+	}
+	rsp, ok := server.fetchKYC(ctx, user)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type kycDocumentURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+	ID       int64  `uri:"id" binding:"required,min=1"`
+}
+
+// downloadKYCDocument sends a document of a user's KYC profile from the blob store, for reviewers.
+func (server *Server) downloadKYCDocument(ctx *gin.Context) {
+	var uri kycDocumentURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	document, err := server.store.GetKYCDocument(ctx, uri.ID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && document.Username != uri.Username) {
+		ctx.JSON(http.StatusNotFound, errorResponse(errors.New("KYC document not found")))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	file, err := server.blobs.Get(ctx, document.BlobKey)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	defer file.Close()
+
+	ctx.DataFromReader(http.StatusOK, document.Size, document.ContentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s-%s-%d"`, document.Username, document.Kind, document.ID),
+	})
+}
+
+type approveKYCRequest struct {
+	Tier string `json:"tier"` // the standard tier when left out
+}
+
+type rejectKYCRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// approveKYC verifies a user's pending KYC profile, which raises their limits to those of the tier.
+func (server *Server) approveKYC(ctx *gin.Context) {
+	var req approveKYCRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	server.reviewKYC(ctx, true, req.Tier, "")
+}
+
+// rejectKYC rejects a user's pending KYC profile, with a reason the user can read, and puts them on the basic tier.
+func (server *Server) rejectKYC(ctx *gin.Context) {
+	var req rejectKYCRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	server.reviewKYC(ctx, false, "", req.Reason)
+}
+
+func (server *Server) reviewKYC(ctx *gin.Context, approve bool, tier, reason string) {
+	var uri userDataURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.ReviewKYCTx(ctx, db.ReviewKYCTxParams{
+		Username: uri.Username,
+		Reviewer: authPayload.Username,
+		Approve:  approve,
+		Tier:     tier,
+		Reason:   reason,
+		Now:      time.Now(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrKYCSelfReview):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		case errors.Is(err, db.ErrUnknownKYCTier):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrKYCNotPending), errors.Is(err, db.ErrKYCNoDocuments):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	rsp, ok := server.fetchKYC(ctx, result.User)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,627 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/blob"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+var testPDF = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n%%EOF\n")
+
+func basicKYCTier() db.KycTier {
+	return db.KycTier{Code: db.KYCTierBasic, Name: "Basic", MaxOpenAccounts: 1, DailyTransferLimit: 100000, MaxBalance: 500000}
+}
+
+func TestGetCurrentKYCAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t)
+	user.KycStatus = db.KYCStatusPending
+	document := db.KycDocument{
+		ID:          util.RandomInt(1, 1000),
+		Username:    user.Username,
+		Kind:        db.KYCDocumentPassport,
+		BlobKey:     "kyc/" + user.Username + "/secret",
+		ContentType: "application/pdf",
+		Size:        int64(len(testPDF)),
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().GetKYCTier(gomock.Any(), gomock.Eq(db.KYCTierBasic)).Times(1).Return(basicKYCTier(), nil)
+	store.EXPECT().GetKYCProfile(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.KycProfile{Username: user.Username, City: "Leeds"}, nil)
+	store.EXPECT().ListKYCDocuments(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.KycDocument{document}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/users/me/kyc", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), document.BlobKey)
+
+	var rsp kycResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Equal(t, db.KYCStatusPending, rsp.Status)
+	require.Equal(t, basicKYCTier(), rsp.Tier)
+	require.NotNil(t, rsp.Profile)
+	require.Equal(t, "Leeds", rsp.Profile.City)
+	require.Len(t, rsp.Documents, 1)
+	require.Equal(t, document.ID, rsp.Documents[0].ID)
+}
+
+func TestSubmitKYCAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	adult := time.Now().AddDate(-30, 0, 0).Format(time.DateOnly)
+	minor := time.Now().AddDate(-17, 0, 0).Format(time.DateOnly)
+	validBody := func() gin.H {
+		return gin.H{
+			"date_of_birth": adult,
+			"address_line1": "1 High Street",
+			"city":          "Leeds",
+			"postal_code":   "LS1 1AA",
+			"country":       "GB",
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          func() gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: validBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				pending := user
+				pending.KycStatus = db.KYCStatusPending
+				store.EXPECT().
+					SubmitKYCProfileTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.SubmitKYCProfileTxParams) (db.KYCProfileTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, adult, arg.DateOfBirth.Format(time.DateOnly))
+						require.Equal(t, "GB", arg.Country)
+						return db.KYCProfileTxResult{Profile: db.KycProfile{Username: user.Username}, User: pending}, nil
+					})
+				store.EXPECT().GetKYCTier(gomock.Any(), gomock.Eq(db.KYCTierBasic)).Times(1).Return(basicKYCTier(), nil)
+				store.EXPECT().GetKYCProfile(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.KycProfile{Username: user.Username}, nil)
+				store.EXPECT().ListKYCDocuments(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.KycDocument{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp kycResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, db.KYCStatusPending, rsp.Status)
+			},
+		},
+		{
+			name: "Minor",
+			body: func() gin.H {
+				body := validBody()
+				body["date_of_birth"] = minor
+				return body
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitKYCProfileTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDateOfBirth",
+			body: func() gin.H {
+				body := validBody()
+				body["date_of_birth"] = "01/02/1990"
+				return body
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitKYCProfileTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCountry",
+			body: func() gin.H {
+				body := validBody()
+				body["country"] = "XX"
+				return body
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitKYCProfileTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			body: validBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitKYCProfileTx(gomock.Any(), gomock.Any()).Times(1).Return(db.KYCProfileTxResult{}, db.ErrKYCVerified)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: validBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitKYCProfileTx(gomock.Any(), gomock.Any()).Times(1).Return(db.KYCProfileTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/users/me/kyc", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// newKYCUploadRequest builds the multipart form of a KYC document upload.
+func newKYCUploadRequest(t *testing.T, kind string, content []byte) *http.Request {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("kind", kind))
+	file, err := form.CreateFormFile("file", "document")
+	require.NoError(t, err)
+	_, err = file.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	request, err := http.NewRequest(http.MethodPost, "/users/me/kyc/documents", &body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	return request
+}
+
+func TestUploadKYCDocumentAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		kind          string
+		content       []byte
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStore)
+	}{
+		{
+			name:    "OK",
+			kind:    db.KYCDocumentPassport,
+			content: testPDF,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AddKYCDocumentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateKYCDocumentParams) (db.KycDocument, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, db.KYCDocumentPassport, arg.Kind)
+						require.Equal(t, "application/pdf", arg.ContentType)
+						require.Equal(t, int64(len(testPDF)), arg.Size)
+						require.Len(t, arg.Sha256, 64)
+						return db.KycDocument{
+							ID:          1,
+							Username:    arg.Username,
+							Kind:        arg.Kind,
+							BlobKey:     arg.BlobKey,
+							ContentType: arg.ContentType,
+							Size:        arg.Size,
+							Sha256:      arg.Sha256,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStore) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "blob_key")
+
+				keys := blobs.Keys()
+				require.Len(t, keys, 1)
+				require.Contains(t, keys[0], "kyc/"+user.Username+"/")
+				r, err := blobs.Get(context.Background(), keys[0])
+				require.NoError(t, err)
+				data, err := io.ReadAll(r)
+				require.NoError(t, err)
+				require.Equal(t, testPDF, data)
+			},
+		},
+		{
+			name:    "UnsupportedType",
+			kind:    db.KYCDocumentPassport,
+			content: []byte("#!/bin/sh\necho hello\n"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AddKYCDocumentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStore) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+				require.Empty(t, blobs.Keys())
+			},
+		},
+		{
+			name:    "TooLarge",
+			kind:    db.KYCDocumentPassport,
+			content: append(append([]byte{}, testPDF...), make([]byte, 1024)...),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AddKYCDocumentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStore) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+				require.Empty(t, blobs.Keys())
+			},
+		},
+		{
+			name:    "InvalidKind",
+			kind:    "selfie",
+			content: testPDF,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AddKYCDocumentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStore) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "AlreadyVerified",
+			kind:    db.KYCDocumentProofOfAddress,
+			content: testPDF,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AddKYCDocumentTx(gomock.Any(), gomock.Any()).Times(1).Return(db.KycDocument{}, db.ErrKYCVerified)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStore) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.Empty(t, blobs.Keys())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.KYCMaxFileSize = 1024
+			blobs := server.blobs.(*blob.MemoryStore)
+			recorder := httptest.NewRecorder()
+
+			request := newKYCUploadRequest(t, tc.kind, tc.content)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, blobs)
+		})
+	}
+}
+
+func TestDownloadKYCDocumentAPI(t *testing.T) {
+	admin := util.RandomOwner()
+	user, _ := randomUser(t)
+	document := db.KycDocument{
+		ID:          util.RandomInt(1, 1000),
+		Username:    user.Username,
+		Kind:        db.KYCDocumentPassport,
+		BlobKey:     "kyc/" + user.Username + "/passport",
+		ContentType: "application/pdf",
+		Size:        int64(len(testPDF)),
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		actorRole     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			username:  user.Username,
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetKYCDocument(gomock.Any(), gomock.Eq(document.ID)).Times(1).Return(document, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), "attachment")
+				require.Equal(t, testPDF, recorder.Body.Bytes())
+			},
+		},
+		{
+			name:      "OtherUser",
+			username:  util.RandomOwner(),
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetKYCDocument(gomock.Any(), gomock.Eq(document.ID)).Times(1).Return(document, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			username:  user.Username,
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetKYCDocument(gomock.Any(), gomock.Eq(document.ID)).Times(1).Return(db.KycDocument{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAReviewer",
+			username:  user.Username,
+			actorRole: util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetKYCDocument(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			_, err := server.blobs.Put(context.Background(), document.BlobKey, bytes.NewReader(testPDF))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%s/kyc/documents/%d", tc.username, document.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, tc.actorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReviewKYCAPI(t *testing.T) {
+	admin := util.RandomOwner()
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		actorRole     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Approve",
+			action:    "approve",
+			body:      gin.H{"tier": db.KYCTierEnhanced},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := user
+				verified.KycStatus = db.KYCStatusVerified
+				verified.KycTier = db.KYCTierEnhanced
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ReviewKYCTxParams) (db.KYCProfileTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, admin, arg.Reviewer)
+						require.True(t, arg.Approve)
+						require.Equal(t, db.KYCTierEnhanced, arg.Tier)
+						require.WithinDuration(t, time.Now(), arg.Now, time.Second)
+						return db.KYCProfileTxResult{User: verified}, nil
+					})
+				store.EXPECT().GetKYCTier(gomock.Any(), gomock.Eq(db.KYCTierEnhanced)).Times(1).Return(db.KycTier{Code: db.KYCTierEnhanced}, nil)
+				store.EXPECT().GetKYCProfile(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.KycProfile{Username: user.Username}, nil)
+				store.EXPECT().ListKYCDocuments(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.KycDocument{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp kycResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, db.KYCStatusVerified, rsp.Status)
+				require.Equal(t, db.KYCTierEnhanced, rsp.Tier.Code)
+			},
+		},
+		{
+			name:      "Reject",
+			action:    "reject",
+			body:      gin.H{"reason": "document is expired"},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				rejected := user
+				rejected.KycStatus = db.KYCStatusRejected
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ReviewKYCTxParams) (db.KYCProfileTxResult, error) {
+						require.False(t, arg.Approve)
+						require.Equal(t, "document is expired", arg.Reason)
+						return db.KYCProfileTxResult{User: rejected}, nil
+					})
+				store.EXPECT().GetKYCTier(gomock.Any(), gomock.Eq(db.KYCTierBasic)).Times(1).Return(basicKYCTier(), nil)
+				store.EXPECT().GetKYCProfile(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.KycProfile{}, sql.ErrNoRows)
+				store.EXPECT().ListKYCDocuments(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.KycDocument{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp kycResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, db.KYCStatusRejected, rsp.Status)
+				require.Nil(t, rsp.Profile)
+			},
+		},
+		{
+			name:      "RejectWithoutReason",
+			action:    "reject",
+			body:      gin.H{},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewKYCTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "SelfReview",
+			action:    "approve",
+			body:      gin.H{},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewKYCTx(gomock.Any(), gomock.Any()).Times(1).Return(db.KYCProfileTxResult{}, db.ErrKYCSelfReview)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NotPending",
+			action:    "approve",
+			body:      gin.H{},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewKYCTx(gomock.Any(), gomock.Any()).Times(1).Return(db.KYCProfileTxResult{}, db.ErrKYCNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "NoDocuments",
+			action:    "approve",
+			body:      gin.H{},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewKYCTx(gomock.Any(), gomock.Any()).Times(1).Return(db.KYCProfileTxResult{}, db.ErrKYCNoDocuments)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "UnknownTier",
+			action:    "approve",
+			body:      gin.H{"tier": "platinum"},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewKYCTx(gomock.Any(), gomock.Any()).Times(1).Return(db.KYCProfileTxResult{}, db.ErrUnknownKYCTier)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			action:    "approve",
+			body:      gin.H{},
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewKYCTx(gomock.Any(), gomock.Any()).Times(1).Return(db.KYCProfileTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAReviewer",
+			action:    "approve",
+			body:      gin.H{},
+			actorRole: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewKYCTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%s/kyc/%s", user.Username, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, tc.actorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+const (
+	// Brute-force settings used when they are not configured. The progressive delay is off without a base.
+	defaultLoginMaxFailures     = 5
+	defaultLoginLockoutDuration = 15 * time.Minute
+	defaultLoginMaxIPFailures   = 50
+	defaultLoginMaxDelay        = 4 * time.Second
+	// maxUserAgentLength caps the user agent kept in the login history
+	maxUserAgentLength = 512
+)
+
+// errInvalidCredentials is the only answer to a wrong username or password, so that logins do not tell which users exist
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// errIncorrectPassword answers a wrong password from an authenticated user confirming a change
+var errIncorrectPassword = errors.New("password is incorrect")
+
+// loginLockoutDuration returns how long failed logins count towards a lockout.
+func (server *Server) loginLockoutDuration() time.Duration {
+	if server.config.LoginLockoutDuration > 0 {
+		return server.config.LoginLockoutDuration
+	}
+	return defaultLoginLockoutDuration
+}
+
+// checkLoginAllowed refuses logins from an IP address or for a username with too many recent failures.
+// It returns the recent failures of the username, or false after sending the response.
+// Unknown usernames are counted and locked out like existing ones.
+func (server *Server) checkLoginAllowed(ctx *gin.Context, username string, user *db.User, now time.Time) (int64, bool) {
+	since := now.Add(-server.loginLockoutDuration())
+
+	maxIPFailures := server.config.LoginMaxIPFailures
+	if maxIPFailures <= 0 {
+		maxIPFailures = defaultLoginMaxIPFailures
+	}
+	ipFailures, err := server.store.CountRecentLoginFailuresByIP(ctx, db.CountRecentLoginFailuresByIPParams{
+		ClientIp: ctx.ClientIP(),
+		Since:    since,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return 0, false
+	}
+	if ipFailures >= maxIPFailures {
+		ctx.Header("Retry-After", strconv.Itoa(int(server.loginLockoutDuration().Seconds())))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errors.New("too many failed logins from this address, try again later")))
+		return 0, false
+	}
+
+	// Failures before an admin unlocked the user no longer count
+	if user != nil && user.LoginUnlockedAt.After(since) {
+		since = user.LoginUnlockedAt
+	}
+
+	maxFailures := server.config.LoginMaxFailures
+	if maxFailures <= 0 {
+		maxFailures = defaultLoginMaxFailures
+	}
+	failures, err := server.store.CountRecentLoginFailures(ctx, db.CountRecentLoginFailuresParams{
+		Username: username,
+		Since:    since,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return 0, false
+	}
+	if failures >= maxFailures {
+		ctx.JSON(http.StatusLocked, errorResponse(errors.New("too many failed logins, the user is locked out for a while")))
+		return 0, false
+	}
+
+	return failures, true
+}
+
+// delayLogin waits before a password is checked, twice as long for each recent failure,
+// which slows down guessing without locking the user out.
+func (server *Server) delayLogin(ctx *gin.Context, failures int64) {
+	base := server.config.LoginDelayBase
+	if failures <= 0 || base <= 0 {
+		return
+	}
+	maxDelay := server.config.LoginMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultLoginMaxDelay
+	}
+
+	delay := maxDelay
+	if failures < 32 && base<<(failures-1) < maxDelay {
+		delay = base << (failures - 1)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Request.Context().Done():
+	}
+}
+
+// recordLogin adds a login attempt to the history. It sends the response and returns false when it fails,
+// as an attempt that is not recorded would not count towards the lockout.
+func (server *Server) recordLogin(ctx *gin.Context, username string, success bool) bool {
+	userAgent := ctx.Request.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	_, err := server.store.CreateLoginAttempt(ctx, db.CreateLoginAttemptParams{
+		Username:  username,
+		ClientIp:  ctx.ClientIP(),
+		UserAgent: userAgent,
+		Success:   success,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// checkCurrentPassword checks the password an authenticated user confirms a sensitive change with.
+// Wrong passwords are recorded as failed logins and count towards the same lockout, so that a stolen
+// access token cannot be used to guess the password. It returns false after sending the response.
+func (server *Server) checkCurrentPassword(ctx *gin.Context, user db.User, password string) bool {
+	failures, ok := server.checkLoginAllowed(ctx, user.Username, &user, time.Now())
+	if !ok {
+		return false
+	}
+	server.delayLogin(ctx, failures)
+
+	if err := util.CheckPassword(password, user.HashedPassword); err != nil {
+		if server.recordLogin(ctx, user.Username, false) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errIncorrectPassword))
+		}
+		return false
+	}
+	return true
+}
+
+type listLoginHistoryRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// listLoginHistory returns the authenticated user's login attempts, newest first, so they can spot logins that were not theirs.
+func (server *Server) listLoginHistory(ctx *gin.Context) {
+	var req listLoginHistoryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	attempts, err := server.store.ListLoginAttempts(ctx, db.ListLoginAttemptsParams{
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempts)
+}
+
+type unlockUserURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// unlockUser lets a user who was locked out after failed logins try again straight away.
+func (server *Server) unlockUser(ctx *gin.Context) {
+	var uri unlockUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.UnlockUserLogin(ctx, db.UnlockUserLoginParams{
+		LoginUnlockedAt: time.Now(),
+		Username:        uri.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
@@ -1,12 +1,80 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/blob"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/notify"
 )
 
+// testRolePermissions mirrors the role_permissions seeded by the migrations
+var testRolePermissions = []db.RolePermission{
+	{Role: util.TellerRole, Permission: util.PermViewAnyAccount},
+	{Role: util.TellerRole, Permission: util.PermOpenAnyAccount},
+	{Role: util.TellerRole, Permission: util.PermDepositCash},
+	{Role: util.TellerRole, Permission: util.PermWithdrawAnyCash},
+	{Role: util.ApproverRole, Permission: util.PermReviewTransfers},
+	{Role: util.AdminRole, Permission: util.PermViewAnyAccount},
+	{Role: util.AdminRole, Permission: util.PermFreezeAccounts},
+	{Role: util.AdminRole, Permission: util.PermManageFees},
+	{Role: util.AdminRole, Permission: util.PermViewReports},
+	{Role: util.AdminRole, Permission: util.PermViewAudit},
+	{Role: util.AdminRole, Permission: util.PermAssignRoles},
+	{Role: util.AdminRole, Permission: util.PermUnlockUsers},
+	{Role: util.AdminRole, Permission: util.PermExportUsers},
+	{Role: util.AdminRole, Permission: util.PermEraseUsers},
+	{Role: util.AdminRole, Permission: util.PermReviewKYC},
+	{Role: util.AdminRole, Permission: util.PermInvestigateAML},
+}
+
+// newTestServer creates a server with a random token key, for tests that do not load app.env.
+// Its notifier keeps messages in memory.
+// A mock store serves the seeded role permissions, which the server reads whenever it checks one,
+// users who never changed their password and have the role of their last token, which the auth middleware
+// reads, and no two-factor secrets.
+// Expectations set before calling newTestServer take precedence.
+func newTestServer(t *testing.T, store db.Store) *Server {
+	if mock, ok := store.(*mockdb.MockStore); ok {
+		mock.EXPECT().ListRolePermissions(gomock.Any()).AnyTimes().Return(testRolePermissions, nil)
+		mock.EXPECT().GetUser(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+			func(_ context.Context, username string) (db.User, error) {
+				role, ok := testUserRoles.Load(username)
+				if !ok {
+					role = util.CustomerRole
+				}
+				return db.User{Username: username, Role: role.(string)}, nil
+			})
+		mock.EXPECT().GetUserTOTP(gomock.Any(), gomock.Any()).AnyTimes().Return(db.UserTotp{}, sql.ErrNoRows)
+	}
+
+	config := util.Config{
+		TokenSymmetricKey:         util.RandomString(32),
+		TOTPEncryptionKey:         util.RandomString(32),
+		AccessTokenDuration:       time.Minute,
+		TransferApprovalThreshold: 1000,
+		TransferApprovalTTL:       time.Hour,
+		PasswordResetTokenTTL:     time.Hour,
+		BlobDir:                   t.TempDir(),
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+	server.notifier = notify.NewMemoryNotifier()
+	server.blobs = blob.NewMemoryStore()
+
+	return server
+}
+
 // config gin to use test mode to shorten test result statements
 // very similar to previous main_test file in db package
 
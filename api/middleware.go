@@ -0,0 +1,157 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationTypeAPIKey = "apikey"
+	authorizationPayloadKey = "authorization_payload"
+	authorizationUserKey    = "authorization_user"
+	authorizationAPIKeyKey  = "authorization_api_key"
+	requestIDHeaderKey      = "X-Request-ID"
+	maxRequestIDLength      = 128
+	anonymousActor          = "anonymous" // audit actor of requests made without an access token
+)
+
+// auditMiddleware creates a gin middleware that puts the request ID and client address in the request's context,
+// for the store to record with the changes the request makes. authMiddleware adds the authenticated user.
+// A request ID sent by the client is kept, so that the audit log can be matched with the client's own logs.
+func auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		ctx.Header(requestIDHeaderKey, requestID)
+
+		setAuditActor(ctx, db.AuditActor{
+			Username:   anonymousActor,
+			RequestID:  requestID,
+			RemoteAddr: ctx.ClientIP(),
+		})
+		ctx.Next()
+	}
+}
+
+// setAuditActor replaces the audit actor of the request's context.
+func setAuditActor(ctx *gin.Context, actor db.AuditActor) {
+	ctx.Request = ctx.Request.WithContext(db.WithAuditActor(ctx.Request.Context(), actor))
+}
+
+// newRequestID returns a random 128-bit request ID in hexadecimal.
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// authMiddleware creates a gin middleware that rejects requests without a valid bearer token or API key.
+// Tokens issued before the user's last password change are rejected too, which ends every other session,
+// and so are the requests of deactivated users.
+// API keys act as their owner, on the routes of their scopes only, see routeScopes.
+// The token payload and the user are stored in the context under authorizationPayloadKey and
+// authorizationUserKey for the handlers, and the API key, if any, under authorizationAPIKeyKey.
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			err := errors.New("authorization header is not provided")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) < 2 {
+			err := errors.New("invalid authorization header format")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		var username string
+		var payload *token.Payload
+		var apiKey *db.ApiKey
+		authorizationType := strings.ToLower(fields[0])
+		switch authorizationType {
+		case authorizationTypeBearer:
+			var err error
+			payload, err = tokenMaker.VerifyToken(fields[1])
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			username = payload.Username
+		case authorizationTypeAPIKey:
+			key, err := verifyAPIKey(ctx, store, fields[1])
+			if err == errInvalidAPIKey {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			if err := checkRouteScope(ctx, key); err != nil {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+			username = key.Owner
+			apiKey = &key
+		default:
+			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		user, err := store.GetUser(ctx, username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("user no longer exists")))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if user.DeletedAt.Valid {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(db.ErrUserDeleted))
+			return
+		}
+
+		if apiKey != nil {
+			// The key takes the owner's current role, so it loses what the owner loses
+			payload = apiKeyPayload(*apiKey, user)
+			ctx.Set(authorizationAPIKeyKey, *apiKey)
+		} else {
+			if payload.IssuedAt.Before(user.PasswordChangedAt) {
+				err := errors.New("token was issued before the password was changed")
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			// Permissions follow the user's current role, so a demoted user loses them before the token expires
+			live := *payload
+			live.Role = user.Role
+			payload = &live
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Set(authorizationUserKey, user)
+
+		actor := db.AuditActorFrom(ctx.Request.Context())
+		actor.Username = payload.Username
+		actor.Role = payload.Role
+		setAuditActor(ctx, actor)
+		ctx.Next()
+	}
+}
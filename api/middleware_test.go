@@ -0,0 +1,264 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+// testUserRoles holds the role of every user addAuthorization made a token for. The users the mock store of
+// newTestServer returns have that role, as the auth middleware takes permissions from the user's current role.
+var testUserRoles sync.Map
+
+// addAuthorization sets a bearer token for the given user and role on the request
+func addAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	username string,
+	role string,
+	duration time.Duration,
+) {
+	accessToken, payload, err := tokenMaker.CreateToken(username, role, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+	testUserRoles.Store(username, role)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, accessToken)
+	request.Header.Set(authorizationHeaderKey, authorizationHeader)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.ApproverRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, "unsupported", username, util.ApproverRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.ApproverRole, -time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "PasswordChangedAfterLogin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.ApproverRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				user := db.User{Username: username, PasswordChangedAt: time.Now().Add(time.Second)}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "PasswordChangedBeforeLogin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.ApproverRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				user := db.User{Username: username, Role: util.ApproverRole, PasswordChangedAt: time.Now().Add(-time.Second)}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RoleChangedAfterLogin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.ApproverRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				user := db.User{Username: username, Role: util.CustomerRole}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UserDeleted",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.ApproverRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserDeactivated",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.ApproverRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				user := db.User{Username: username, DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingPermission",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.CustomerRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+			server := newTestServer(t, store)
+
+			authPath := "/auth"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker, server.store),
+				server.requirePermission(util.PermReviewTransfers),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name         string
+		path         string
+		setupRequest func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		checkActor   func(t *testing.T, actor db.AuditActor, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Authenticated",
+			path: "/audited/auth",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.AdminRole, time.Minute)
+				request.Header.Set(requestIDHeaderKey, "client-request-1")
+			},
+			checkActor: func(t *testing.T, actor db.AuditActor, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, username, actor.Username)
+				require.Equal(t, util.AdminRole, actor.Role)
+				require.Equal(t, "client-request-1", actor.RequestID)
+				require.Equal(t, "client-request-1", recorder.Header().Get(requestIDHeaderKey))
+				require.NotEmpty(t, actor.RemoteAddr)
+			},
+		},
+		{
+			name:         "Anonymous",
+			path:         "/audited",
+			setupRequest: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			checkActor: func(t *testing.T, actor db.AuditActor, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, anonymousActor, actor.Username)
+				require.Empty(t, actor.Role)
+				require.Len(t, actor.RequestID, 32)
+				require.Equal(t, actor.RequestID, recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+			// The handler reads the actor through the gin context, as the store does
+			handler := func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, db.AuditActorFrom(ctx))
+			}
+			server.router.GET("/audited", handler)
+			server.router.GET("/audited/auth", authMiddleware(server.tokenMaker, server.store), handler)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			request.RemoteAddr = "203.0.113.7:41000"
+
+			tc.setupRequest(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var actor db.AuditActor
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &actor))
+			tc.checkActor(t, actor, recorder)
+		})
+	}
+}
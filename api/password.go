@@ -0,0 +1,227 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/notify"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+// defaultPasswordResetTokenTTL is used when the reset token TTL is not configured
+const defaultPasswordResetTokenTTL = 30 * time.Minute
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// changePassword sets a new password for the authenticated user, who must confirm the current one
+// and, with two-factor authentication enabled, send a code.
+// Tokens issued before the change stop working, so a new access token is sent back.
+func (server *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.checkCurrentPassword(ctx, user, req.CurrentPassword) {
+		return
+	}
+	if !server.checkStepUp(ctx, user) {
+		return
+	}
+	if req.NewPassword == req.CurrentPassword {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("new password must differ from the current one")))
+		return
+	}
+	if err := server.config.PasswordPolicy().Validate(req.NewPassword); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := server.hasher.Hash(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err = server.store.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: passwordChangeTime(),
+		Username:          user.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.sendLoginResponse(ctx, user)
+}
+
+type requestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// requestPasswordReset sends a password reset token to the user with the given email. The response
+// is the same whether the email is known or not, so the endpoint cannot be used to find users.
+func (server *Server) requestPasswordReset(ctx *gin.Context) {
+	var req requestPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	accepted := gin.H{"message": "if the email belongs to a user, a password reset token was sent to it"}
+
+	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusAccepted, accepted)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if user.Username == db.SystemUsername || user.DeletedAt.Valid {
+		ctx.JSON(http.StatusAccepted, accepted)
+		return
+	}
+
+	resetToken, err := newSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ttl := server.config.PasswordResetTokenTTL
+	if ttl <= 0 {
+		ttl = defaultPasswordResetTokenTTL
+	}
+
+	stored, err := server.store.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		Username:  user.Username,
+		TokenHash: hashSecret(resetToken),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.notifier.Send(ctx, notify.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Use this token to choose a new password: %s\nIt can be used once and expires at %s.",
+			resetToken, stored.ExpiresAt.Format(time.RFC1123)),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, accepted)
+}
+
+type confirmPasswordResetRequest struct {
+	Token       string `json:"token" binding:"required,hexadecimal"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// confirmPasswordReset sets a new password with a reset token, which is used up whatever the outcome.
+// Like a password change, it ends the user's sessions; the user logs in again with the new password.
+func (server *Server) confirmPasswordReset(ctx *gin.Context) {
+	var req confirmPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := server.config.PasswordPolicy().Validate(req.NewPassword); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := server.hasher.Hash(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:      hashSecret(req.Token),
+		HashedPassword: hashedPassword,
+		Now:            passwordChangeTime(),
+	})
+	if err != nil {
+		if err == db.ErrResetTokenInvalid || err == db.ErrResetTokenExpired {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// newSecret returns a random 256-bit secret in hexadecimal, such as a password reset token.
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// hashSecret returns the SHA-256 of a secret from newSecret in hexadecimal, which is what the database keeps.
+// The secrets are random enough that a fast unsalted hash is safe.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// passwordChangeTime returns the time to record for a password change. Postgres keeps microseconds,
+// so the time is truncated to make sure tokens issued right after the change are not rejected.
+func passwordChangeTime() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// rehashPassword replaces the user's password hash when the hasher would not make it any more, such as a
+// bcrypt hash after the switch to Argon2id or a hash of a lower cost. It is called once the password is
+// known to be right. The password does not change, so the user's sessions stay valid, and a failure only
+// postpones the upgrade to the next login.
+func (server *Server) rehashPassword(ctx *gin.Context, user db.User, password string) {
+	if !server.hasher.NeedsRehash(user.HashedPassword) {
+		return
+	}
+
+	hashedPassword, err := server.hasher.Hash(password)
+	if err == nil {
+		_, err = server.store.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+			NewHashedPassword: hashedPassword,
+			Username:          user.Username,
+			OldHashedPassword: user.HashedPassword,
+		})
+	}
+	// No rows means the password was changed by another request, whose hash is up to date
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("cannot rehash the password of %s: %v", user.Username, err)
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+// permissionCacheTTL is how long the permissions of the roles are kept before they are read again.
+const permissionCacheTTL = time.Minute
+
+// permissionCache keeps the role_permissions table in memory, so that checking a route's permission
+// does not cost a query per request. Changes to the table are picked up within permissionCacheTTL.
+// The table is read outside of the lock: while one request reloads it, the others keep using the
+// permissions already loaded, and only wait for the first load.
+type permissionCache struct {
+	store db.Store
+
+	mu       sync.Mutex
+	byRole   map[string]map[string]bool
+	loadedAt time.Time
+	loading  chan struct{} // closed once the reload in progress, if any, is done
+}
+
+func newPermissionCache(store db.Store) *permissionCache {
+	return &permissionCache{store: store}
+}
+
+// has reports whether a role has a permission, reloading the permissions when they are stale.
+func (cache *permissionCache) has(ctx context.Context, role, permission string) (bool, error) {
+	for {
+		cache.mu.Lock()
+		byRole, loading := cache.byRole, cache.loading
+		stale := byRole == nil || time.Since(cache.loadedAt) > permissionCacheTTL
+		if stale && loading == nil {
+			cache.loading = make(chan struct{})
+		}
+		cache.mu.Unlock()
+
+		switch {
+		case !stale || (loading != nil && byRole != nil):
+			return byRole[role][permission], nil
+		case loading != nil:
+			select {
+			case <-loading:
+				continue
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+
+		byRole, err := cache.load(ctx)
+		if err != nil {
+			return false, err
+		}
+		return byRole[role][permission], nil
+	}
+}
+
+// load reads the permissions of every role and replaces the cached ones. Requests waiting for it try again
+// whether it succeeds or not.
+func (cache *permissionCache) load(ctx context.Context) (map[string]map[string]bool, error) {
+	rows, err := cache.store.ListRolePermissions(ctx)
+	var byRole map[string]map[string]bool
+	if err == nil {
+		byRole = make(map[string]map[string]bool)
+		for _, row := range rows {
+			if byRole[row.Role] == nil {
+				byRole[row.Role] = make(map[string]bool)
+			}
+			byRole[row.Role][row.Permission] = true
+		}
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	close(cache.loading)
+	cache.loading = nil
+	if err != nil {
+		return nil, err
+	}
+	cache.byRole = byRole
+	cache.loadedAt = time.Now()
+	return byRole, nil
+}
+
+// hasPermission reports whether the authenticated user's role has a permission.
+func (server *Server) hasPermission(ctx *gin.Context, permission string) (bool, error) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	return server.permissions.has(ctx, authPayload.Role, permission)
+}
+
+// authorizeAccount checks that the authenticated user owns the account, or else that their role has the
+// permission to act on any customer's account. An empty permission leaves the account to its owner.
+// Otherwise it writes an error response and returns false.
+func (server *Server) authorizeAccount(ctx *gin.Context, account db.Account, anyAccountPermission string) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner == authPayload.Username {
+		return true
+	}
+
+	allowed := false
+	if anyAccountPermission != "" {
+		var err error
+		allowed, err = server.hasPermission(ctx, anyAccountPermission)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+	}
+	if !allowed {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// authorizeAccountID is authorizeAccount for an account that is not loaded yet.
+func (server *Server) authorizeAccountID(ctx *gin.Context, accountID int64, anyAccountPermission string) bool {
+	account, status, err := server.fetchAccount(ctx, accountID)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return false
+	}
+	return server.authorizeAccount(ctx, account, anyAccountPermission)
+}
+
+// requirePermission creates a gin middleware that only lets through users whose role has the permission.
+// It must run after authMiddleware.
+func (server *Server) requirePermission(permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		allowed, err := server.hasPermission(ctx, permission)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !allowed {
+			err := fmt.Errorf("this action requires the %s permission", permission)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+)
+
+// getCurrentUser returns the authenticated user.
+func (server *Server) getCurrentUser(ctx *gin.Context) {
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type updateCurrentUserRequest struct {
+	FullName *string `json:"full_name" binding:"omitempty,min=1"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+}
+
+// updateCurrentUser changes the authenticated user's full name or email. A new email has to be verified
+// again, so a verification email is sent to it, and users who enabled two-factor authentication confirm
+// the change with a code, since the email is where password reset tokens go.
+func (server *Server) updateCurrentUser(ctx *gin.Context) {
+	var req updateCurrentUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.FullName == nil && req.Email == nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("nothing to update")))
+		return
+	}
+
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	emailChanged := req.Email != nil && *req.Email != user.Email
+	if emailChanged && !server.checkStepUp(ctx, user) {
+		return
+	}
+
+	arg := db.UpdateUserParams{Username: user.Username}
+	if req.FullName != nil {
+		arg.FullName = sql.NullString{String: *req.FullName, Valid: true}
+	}
+	if req.Email != nil {
+		arg.Email = sql.NullString{String: *req.Email, Valid: true}
+	}
+
+	user, err := server.store.UpdateUser(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrEmailTaken) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(errors.New("email is already in use")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// The user can ask for another verification email if this one fails, so it does not fail the change
+	if emailChanged {
+		if err := server.sendVerifyEmail(ctx, user); err != nil {
+			log.Printf("cannot send verification email to %s: %v", user.Username, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type deleteCurrentUserRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type deleteCurrentUserResponse struct {
+	User                        createUserResponse     `json:"user"`
+	ClosedAccounts              []db.Account           `json:"closed_accounts"`
+	OpenAccounts                []db.Account           `json:"open_accounts"`
+	CancelledScheduledTransfers []db.ScheduledTransfer `json:"cancelled_scheduled_transfers"`
+	CancelledTransferApprovals  []db.TransferApproval  `json:"cancelled_transfer_approvals"`
+	ReleasedHolds               []db.Hold              `json:"released_holds"`
+}
+
+// deleteCurrentUser deactivates the authenticated user, who confirms it with their password and, if they
+// enabled it, a two-factor code. Their pending transfer approvals and holds are cancelled, their zero-balance
+// accounts are closed, those holding funds stay open for the bank to pay out, and the user can no longer log in. The user and their history are kept for regulatory retention.
+func (server *Server) deleteCurrentUser(ctx *gin.Context) {
+	var req deleteCurrentUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	if !server.checkCurrentPassword(ctx, user, req.Password) {
+		return
+	}
+	if !server.checkStepUp(ctx, user) {
+		return
+	}
+
+	result, err := server.store.DeleteUserTx(ctx, db.DeleteUserTxParams{
+		Username: user.Username,
+		Now:      time.Now(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrUserDeleted):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deleteCurrentUserResponse{
+		User:                        newUserResponse(result.User),
+		ClosedAccounts:              result.ClosedAccounts,
+		OpenAccounts:                result.OpenAccounts,
+		CancelledScheduledTransfers: result.CancelledScheduledTransfers,
+		CancelledTransferApprovals:  result.CancelledTransferApprovals,
+		ReleasedHolds:               result.ReleasedHolds,
+	})
+}
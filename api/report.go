@@ -0,0 +1,167 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+)
+
+// listChartOfAccounts returns every account product, customer and internal, with its ledger class.
+func (server *Server) listChartOfAccounts(ctx *gin.Context) {
+	products, err := server.store.ListChartOfAccounts(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, products)
+}
+
+// trialBalanceLine is the total balance of the accounts of one product, as a debit or a credit.
+type trialBalanceLine struct {
+	LedgerClass string `json:"ledger_class"`
+	ProductCode string `json:"product_code"`
+	Accounts    int64  `json:"accounts"`
+	Debit       int64  `json:"debit"`
+	Credit      int64  `json:"credit"`
+}
+
+// trialBalanceLedger is the trial balance of one currency. Its debits and credits match when Balanced is true.
+type trialBalanceLedger struct {
+	Currency    string             `json:"currency"`
+	Lines       []trialBalanceLine `json:"lines"`
+	TotalDebit  int64              `json:"total_debit"`
+	TotalCredit int64              `json:"total_credit"`
+	Balanced    bool               `json:"balanced"`
+}
+
+type trialBalanceResponse struct {
+	Ledgers  []trialBalanceLedger `json:"ledgers"`
+	Balanced bool                 `json:"balanced"` // every currency balances
+}
+
+// getTrialBalance reports the balances of the whole ledger by currency and product.
+// Negative balances are debits and positive ones credits, so each currency balances when nothing is missing.
+func (server *Server) getTrialBalance(ctx *gin.Context) {
+	rows, err := server.store.GetTrialBalance(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTrialBalance(rows))
+}
+
+// newTrialBalance groups the rows of GetTrialBalance, which are ordered by currency, into one ledger per currency.
+func newTrialBalance(rows []db.GetTrialBalanceRow) trialBalanceResponse {
+	response := trialBalanceResponse{Ledgers: []trialBalanceLedger{}, Balanced: true}
+
+	for _, row := range rows {
+		if n := len(response.Ledgers); n == 0 || response.Ledgers[n-1].Currency != row.Currency {
+			response.Ledgers = append(response.Ledgers, trialBalanceLedger{Currency: row.Currency})
+		}
+		ledger := &response.Ledgers[len(response.Ledgers)-1]
+
+		line := trialBalanceLine{
+			LedgerClass: row.LedgerClass,
+			ProductCode: row.ProductCode,
+			Accounts:    row.Accounts,
+		}
+		if row.Balance < 0 {
+			line.Debit = -row.Balance
+		} else {
+			line.Credit = row.Balance
+		}
+
+		ledger.Lines = append(ledger.Lines, line)
+		ledger.TotalDebit += line.Debit
+		ledger.TotalCredit += line.Credit
+	}
+
+	for i := range response.Ledgers {
+		ledger := &response.Ledgers[i]
+		ledger.Balanced = ledger.TotalDebit == ledger.TotalCredit
+		response.Balanced = response.Balanced && ledger.Balanced
+	}
+	return response
+}
+
+type verifyLedgerRequest struct {
+	AccountID int64 `form:"account_id" binding:"omitempty,min=1"` // verify one account only
+}
+
+// verifyLedger walks the hash chains of the ledger entries and reports the first broken link, if any.
+// A broken chain is a finding, not a server error, so it is reported with 200 OK and valid set to false.
+func (server *Server) verifyLedger(ctx *gin.Context) {
+	var req verifyLedgerRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var result db.LedgerVerification
+	var err error
+	if req.AccountID != 0 {
+		result, err = db.VerifyAccountChain(ctx, server.store, req.AccountID, db.DefaultChainPageSize)
+	} else {
+		result, err = db.VerifyLedger(ctx, server.store, db.DefaultChainPageSize)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type endOfDayReportRequest struct {
+	Date time.Time `form:"date" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+}
+
+// endOfDayLine is the total end-of-day balance of the accounts of one currency.
+type endOfDayLine struct {
+	Currency string `json:"currency"`
+	Accounts int64  `json:"accounts"`
+	Debit    int64  `json:"debit"`
+	Credit   int64  `json:"credit"`
+	Balance  int64  `json:"balance"`
+}
+
+type endOfDayReportResponse struct {
+	Date       string         `json:"date"`
+	Currencies []endOfDayLine `json:"currencies"`
+}
+
+// getEndOfDayReport totals the end-of-day balance snapshots of a day by currency.
+// A day that has not been snapshotted yet has no currencies.
+func (server *Server) getEndOfDayReport(ctx *gin.Context) {
+	var req endOfDayReportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rows, err := server.store.GetEndOfDayReport(ctx, req.Date)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := endOfDayReportResponse{
+		Date:       req.Date.Format(time.DateOnly),
+		Currencies: make([]endOfDayLine, 0, len(rows)),
+	}
+	for _, row := range rows {
+		response.Currencies = append(response.Currencies, endOfDayLine{
+			Currency: row.Currency,
+			Accounts: row.Accounts,
+			Debit:    -row.Debit,
+			Credit:   row.Credit,
+			Balance:  row.Balance,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
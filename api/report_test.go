@@ -0,0 +1,313 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestGetTrialBalanceAPI(t *testing.T) {
+	rows := []db.GetTrialBalanceRow{
+		{Currency: util.EUR, LedgerClass: db.LedgerAsset, ProductCode: db.ProductCashClearing, Accounts: 1, Balance: -700},
+		{Currency: util.EUR, LedgerClass: db.LedgerLiability, ProductCode: db.ProductChecking, Accounts: 2, Balance: 500},
+		{Currency: util.USD, LedgerClass: db.LedgerAsset, ProductCode: db.ProductCashClearing, Accounts: 1, Balance: -1200},
+		{Currency: util.USD, LedgerClass: db.LedgerIncome, ProductCode: db.ProductFeeIncome, Accounts: 1, Balance: 200},
+		{Currency: util.USD, LedgerClass: db.LedgerLiability, ProductCode: db.ProductChecking, Accounts: 3, Balance: 1000},
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTrialBalance(gomock.Any()).Times(1).Return(rows, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var report trialBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+				require.False(t, report.Balanced)
+				require.Len(t, report.Ledgers, 2)
+
+				// EUR is missing 200 of credits, USD balances
+				require.Equal(t, util.EUR, report.Ledgers[0].Currency)
+				require.Equal(t, int64(700), report.Ledgers[0].TotalDebit)
+				require.Equal(t, int64(500), report.Ledgers[0].TotalCredit)
+				require.False(t, report.Ledgers[0].Balanced)
+
+				require.Equal(t, util.USD, report.Ledgers[1].Currency)
+				require.Len(t, report.Ledgers[1].Lines, 3)
+				require.Equal(t, int64(1200), report.Ledgers[1].Lines[0].Debit)
+				require.Equal(t, int64(1200), report.Ledgers[1].TotalCredit)
+				require.True(t, report.Ledgers[1].Balanced)
+			},
+		},
+		{
+			name: "InternalError",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTrialBalance(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NotAnAdmin",
+			role: util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTrialBalance(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/reports/trial_balance", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyLedgerAPI(t *testing.T) {
+	hash1 := []byte("hash of entry 1")
+	hash2 := []byte("hash of entry 2")
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Valid",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountIDs(gomock.Any(), gomock.Any()).Times(1).Return([]int64{1, 2}, nil)
+				store.EXPECT().
+					ListEntryChain(gomock.Any(), gomock.Eq(db.ListEntryChainParams{AccountID: 1, Limit: db.DefaultChainPageSize})).
+					Times(1).
+					Return([]db.ListEntryChainRow{
+						{ID: 1, AccountID: 1, Hash: hash1, ExpectedHash: hash1},
+						{ID: 3, AccountID: 1, PrevHash: hash1, Hash: hash2, ExpectedHash: hash2},
+					}, nil)
+				store.EXPECT().
+					ListEntryChain(gomock.Any(), gomock.Eq(db.ListEntryChainParams{AccountID: 2, Limit: db.DefaultChainPageSize})).
+					Times(1).
+					Return([]db.ListEntryChainRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.LedgerVerification
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.True(t, result.Valid)
+				require.Equal(t, int64(2), result.Accounts)
+				require.Equal(t, int64(2), result.Entries)
+			},
+		},
+		{
+			name:  "EditedEntry",
+			query: "?account_id=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountIDs(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					ListEntryChain(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListEntryChainRow{
+						{ID: 1, AccountID: 1, Hash: hash1, ExpectedHash: hash1},
+						{ID: 3, AccountID: 1, PrevHash: hash1, Hash: hash2, ExpectedHash: []byte("recomputed")},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.LedgerVerification
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.False(t, result.Valid)
+				require.Equal(t, &db.BrokenLink{AccountID: 1, EntryID: 3, Reason: "hash does not match the entry's contents"}, result.BrokenLink)
+			},
+		},
+		{
+			name:  "RemovedEntry",
+			query: "?account_id=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListEntryChain(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListEntryChainRow{
+						{ID: 3, AccountID: 1, PrevHash: hash1, Hash: hash2, ExpectedHash: hash2},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.LedgerVerification
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.False(t, result.Valid)
+				require.Equal(t, int64(3), result.BrokenLink.EntryID)
+			},
+		},
+		{
+			name:  "InvalidAccountID",
+			query: "?account_id=-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEntryChain(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/reports/ledger_verification"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestEndOfDayReportAPI(t *testing.T) {
+	date := time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)
+	rows := []db.GetEndOfDayReportRow{
+		{Currency: util.EUR, Accounts: 3, Credit: 900, Debit: -400, Balance: 500},
+		{Currency: util.USD, Accounts: 1, Credit: 250, Balance: 250},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "date=2026-03-31",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetEndOfDayReport(gomock.Any(), gomock.Eq(date)).Times(1).Return(rows, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var report endOfDayReportResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+				require.Equal(t, "2026-03-31", report.Date)
+				require.Len(t, report.Currencies, 2)
+				require.Equal(t, util.EUR, report.Currencies[0].Currency)
+				require.Equal(t, int64(400), report.Currencies[0].Debit)
+				require.Equal(t, int64(900), report.Currencies[0].Credit)
+				require.Equal(t, int64(500), report.Currencies[0].Balance)
+			},
+		},
+		{
+			name:  "NotSnapshotted",
+			query: "date=2026-04-01",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetEndOfDayReport(gomock.Any(), gomock.Any()).Times(1).Return([]db.GetEndOfDayReportRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var report endOfDayReportResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+				require.Empty(t, report.Currencies)
+			},
+		},
+		{
+			name:  "InvalidDate",
+			query: "date=31-03-2026",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetEndOfDayReport(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAnAdmin",
+			query: "date=2026-03-31",
+			role:  util.CustomerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetEndOfDayReport(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/reports/end_of_day?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
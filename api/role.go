@@ -0,0 +1,111 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+// roleResponse is a role together with the permissions it grants.
+type roleResponse struct {
+	db.Role
+	Permissions []string `json:"permissions"`
+}
+
+// listRoles returns every role and its permissions.
+func (server *Server) listRoles(ctx *gin.Context) {
+	roles, err := server.store.ListRoles(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	rolePermissions, err := server.store.ListRolePermissions(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	permissions := make(map[string][]string)
+	for _, row := range rolePermissions {
+		permissions[row.Role] = append(permissions[row.Role], row.Permission)
+	}
+
+	rsp := make([]roleResponse, 0, len(roles))
+	for _, role := range roles {
+		granted := permissions[role.Name]
+		if granted == nil {
+			granted = []string{}
+		}
+		rsp = append(rsp, roleResponse{Role: role, Permissions: granted})
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type assignRoleURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type assignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// assignRole gives a user another role. Users cannot change their own role, so an admin cannot
+// lock the bank out of its last admin by mistake, and the system user and role are off limits.
+func (server *Server) assignRole(ctx *gin.Context) {
+	var uri assignRoleURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req assignRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if uri.Username == authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("cannot change your own role")))
+		return
+	}
+	if uri.Username == db.SystemUsername {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("cannot change the role of the system user")))
+		return
+	}
+
+	role, err := server.store.GetRole(ctx, req.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("unknown role %q", req.Role)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !role.Assignable {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("role %s cannot be assigned", role.Name)))
+		return
+	}
+
+	user, err := server.store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+		Role:     role.Name,
+		Username: uri.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestListRolesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListRoles(gomock.Any()).
+		Times(1).
+		Return([]db.Role{
+			{Name: util.AdminRole, Assignable: true},
+			{Name: util.CustomerRole, Assignable: true},
+			{Name: util.TellerRole, Assignable: true},
+		}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/roles", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.AdminRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var roles []roleResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &roles))
+	require.Len(t, roles, 3)
+	require.Contains(t, roles[0].Permissions, util.PermAssignRoles)
+	require.Empty(t, roles[1].Permissions)
+	require.NotNil(t, roles[1].Permissions)
+	require.Contains(t, roles[2].Permissions, util.PermDepositCash)
+}
+
+func TestAssignRoleAPI(t *testing.T) {
+	admin := util.RandomOwner()
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		actorRole     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			username:  user.Username,
+			role:      util.TellerRole,
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRole(gomock.Any(), gomock.Eq(util.TellerRole)).Times(1).Return(db.Role{Name: util.TellerRole, Assignable: true}, nil)
+
+				teller := user
+				teller.Role = util.TellerRole
+				arg := db.UpdateUserRoleParams{Role: util.TellerRole, Username: user.Username}
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Eq(arg)).Times(1).Return(teller, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp createUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, util.TellerRole, rsp.Role)
+			},
+		},
+		{
+			name:      "UnknownRole",
+			username:  user.Username,
+			role:      "janitor",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRole(gomock.Any(), gomock.Eq("janitor")).Times(1).Return(db.Role{}, sql.ErrNoRows)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAssignable",
+			username:  user.Username,
+			role:      util.SystemRole,
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRole(gomock.Any(), gomock.Eq(util.SystemRole)).Times(1).Return(db.Role{Name: util.SystemRole}, nil)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			username:  user.Username,
+			role:      util.TellerRole,
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRole(gomock.Any(), gomock.Any()).Times(1).Return(db.Role{Name: util.TellerRole, Assignable: true}, nil)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "OwnRole",
+			username:  admin,
+			role:      util.CustomerRole,
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAnAdmin",
+			username:  user.Username,
+			role:      util.AdminRole,
+			actorRole: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRole(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"role": tc.role})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%s/role", tc.username)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, tc.actorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestPermissionCacheReload tests that checks keep using the loaded permissions while they are read again.
+func TestPermissionCacheReload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	release := make(chan struct{})
+	store.EXPECT().
+		ListRolePermissions(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context) ([]db.RolePermission, error) {
+			<-release
+			return []db.RolePermission{{Role: util.TellerRole, Permission: util.PermOpenAnyAccount}}, nil
+		})
+
+	cache := newPermissionCache(store)
+	cache.byRole = map[string]map[string]bool{util.TellerRole: {util.PermViewAnyAccount: true}}
+	cache.loadedAt = time.Now().Add(-2 * permissionCacheTTL)
+
+	reloaded := make(chan bool)
+	go func() {
+		allowed, err := cache.has(context.Background(), util.TellerRole, util.PermOpenAnyAccount)
+		require.NoError(t, err)
+		reloaded <- allowed
+	}()
+
+	// the reload is waiting on the store, the stale permissions still answer
+	require.Eventually(t, func() bool {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		return cache.loading != nil
+	}, time.Second, time.Millisecond)
+	allowed, err := cache.has(context.Background(), util.TellerRole, util.PermViewAnyAccount)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	close(release)
+	require.True(t, <-reloaded)
+	allowed, err = cache.has(context.Background(), util.TellerRole, util.PermViewAnyAccount)
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// createScheduledTransferRequest represents the JSON payload for creating a scheduled transfer (standing order).
+// One-off transfers run once at `run_at`. Cron transfers run on every occurrence of `cron_expression` (UTC),
+// starting after `run_at` (or now) and stopping at `end_at` or after `max_runs` successful executions.
+type createScheduledTransferRequest struct {
+	FromAccountID  int64      `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID    int64      `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount         int64      `json:"amount" binding:"required,gt=0"`
+	Currency       string     `json:"currency" binding:"required,currency"`
+	ScheduleType   string     `json:"schedule_type" binding:"required,oneof=once cron"`
+	RunAt          *time.Time `json:"run_at"`
+	CronExpression string     `json:"cron_expression" binding:"required_if=ScheduleType cron"`
+	EndAt          *time.Time `json:"end_at"`
+	MaxRuns        *int32     `json:"max_runs" binding:"omitempty,min=1"`
+}
+
+// createScheduledTransfer validates the schedule and both accounts, then stores the scheduled transfer.
+// The transfer itself is executed later by the background scheduler.
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	nextRunAt, err := firstScheduledRun(req, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.schedulableAmount(ctx, req.Amount) {
+		return
+	}
+
+	if !server.stepUpForAmount(ctx, req.Amount) {
+		return
+	}
+
+	if !server.validSourceAccount(ctx, req.FromAccountID, req.Currency, "") {
+		return
+	}
+
+	if !server.validAccount(ctx, req.ToAccountID, req.Currency) {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		FromAccountID:  req.FromAccountID,
+		ToAccountID:    req.ToAccountID,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		ScheduleType:   req.ScheduleType,
+		CronExpression: req.CronExpression,
+		NextRunAt:      nextRunAt,
+	}
+	if req.EndAt != nil {
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+	if req.MaxRuns != nil {
+		arg.MaxRuns = sql.NullInt32{Int32: *req.MaxRuns, Valid: true}
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			log.Printf("PostgreSQL Error Code: %s, Message: %s", pqErr.Code, pqErr.Message)
+
+			if pqErr.Code.Name() == "foreign_key_violation" {
+				ctx.JSON(http.StatusBadRequest, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+// schedulableAmount rejects amounts above the approval threshold with 400 Bad Request.
+// Nobody is there to approve a scheduled transfer when it runs, so such transfers must be sent on their own.
+func (server *Server) schedulableAmount(ctx *gin.Context, amount int64) bool {
+	if server.needsTransferApproval(amount) {
+		err := fmt.Errorf("amount %d is above the approval threshold and cannot be scheduled", amount)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// firstScheduledRun works out when a new scheduled transfer should run for the first time.
+func firstScheduledRun(req createScheduledTransferRequest, now time.Time) (time.Time, error) {
+	var nextRunAt time.Time
+
+	switch req.ScheduleType {
+	case db.ScheduleTypeOnce:
+		if req.RunAt == nil {
+			return nextRunAt, errors.New("run_at is required for one-off transfers")
+		}
+		if req.CronExpression != "" || req.MaxRuns != nil || req.EndAt != nil {
+			return nextRunAt, errors.New("cron_expression, end_at and max_runs only apply to cron transfers")
+		}
+		if !req.RunAt.After(now) {
+			return nextRunAt, errors.New("run_at must be in the future")
+		}
+		nextRunAt = *req.RunAt
+	case db.ScheduleTypeCron:
+		schedule, err := util.ParseCronSchedule(req.CronExpression)
+		if err != nil {
+			return nextRunAt, err
+		}
+		start := now
+		if req.RunAt != nil && req.RunAt.After(now) {
+			start = *req.RunAt
+		}
+		nextRunAt = schedule.Next(start.UTC())
+		if nextRunAt.IsZero() {
+			return nextRunAt, fmt.Errorf("cron expression %q never fires", req.CronExpression)
+		}
+	}
+
+	if req.EndAt != nil && nextRunAt.After(*req.EndAt) {
+		return nextRunAt, errors.New("end_at is before the first run")
+	}
+
+	return nextRunAt, nil
+}
+
+type getScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getScheduledTransfer returns a single scheduled transfer by ID.
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, ok := server.fetchScheduledTransfer(ctx, req.ID, util.PermViewAnyAccount)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type listScheduledTransfersRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+	PageID    int32 `form:"page_id" binding:"required,min=1"`
+	PageSize  int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listScheduledTransfers lists the scheduled transfers debiting an account, paginated like listAccount.
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.authorizeAccountID(ctx, req.AccountID, util.PermViewAnyAccount) {
+		return
+	}
+
+	arg := db.ListScheduledTransfersParams{
+		FromAccountID: req.AccountID,
+		Limit:         req.PageSize,
+		Offset:        (req.PageID - 1) * req.PageSize,
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfers)
+}
+
+// updateScheduledTransferRequest holds the fields of a scheduled transfer that can be changed.
+// Setting `status` to paused suspends the transfer; setting it back to active resumes it.
+type updateScheduledTransferRequest struct {
+	Amount *int64  `json:"amount" binding:"omitempty,gt=0"`
+	Status *string `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+// updateScheduledTransfer changes the amount of a scheduled transfer, or pauses and resumes it.
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Amount != nil && (!server.schedulableAmount(ctx, *req.Amount) || !server.stepUpForAmount(ctx, *req.Amount)) {
+		return
+	}
+
+	scheduledTransfer, ok := server.fetchScheduledTransfer(ctx, uri.ID, "")
+	if !ok {
+		return
+	}
+	if !scheduledTransferIsOpen(ctx, scheduledTransfer) {
+		return
+	}
+
+	arg := db.UpdateScheduledTransferParams{
+		ID: uri.ID,
+	}
+	if req.Amount != nil {
+		arg.Amount = sql.NullInt64{Int64: *req.Amount, Valid: true}
+	}
+	if req.Status != nil {
+		arg.Status = sql.NullString{String: *req.Status, Valid: true}
+		// Resuming a transfer that was paused after repeated failures gives it a fresh start
+		if *req.Status == db.ScheduledTransferActive {
+			arg.FailureCount = sql.NullInt32{Int32: 0, Valid: true}
+		}
+	}
+
+	scheduledTransfer, err := server.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+// cancelScheduledTransfer stops a scheduled transfer for good. The row and its runs are kept for history.
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, ok := server.fetchScheduledTransfer(ctx, req.ID, "")
+	if !ok {
+		return
+	}
+	if !scheduledTransferIsOpen(ctx, scheduledTransfer) {
+		return
+	}
+
+	scheduledTransfer, err := server.store.UpdateScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:     req.ID,
+		Status: sql.NullString{String: db.ScheduledTransferCancelled, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type listScheduledTransferRunsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listScheduledTransferRuns lists the executions (successful and failed) of a scheduled transfer.
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listScheduledTransferRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.fetchScheduledTransfer(ctx, uri.ID, util.PermViewAnyAccount); !ok {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: uri.ID,
+		Limit:               req.PageSize,
+		Offset:              (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// fetchScheduledTransfer loads a scheduled transfer, writing a 404 or 500 response if that fails.
+// The account it debits must belong to the authenticated user, unless their role has anyAccountPermission.
+func (server *Server) fetchScheduledTransfer(ctx *gin.Context, id int64, anyAccountPermission string) (db.ScheduledTransfer, bool) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduledTransfer, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduledTransfer, false
+	}
+	return scheduledTransfer, server.authorizeAccountID(ctx, scheduledTransfer.FromAccountID, anyAccountPermission)
+}
+
+// scheduledTransferIsOpen rejects changes to scheduled transfers that have already completed or been cancelled.
+func scheduledTransferIsOpen(ctx *gin.Context, scheduledTransfer db.ScheduledTransfer) bool {
+	switch scheduledTransfer.Status {
+	case db.ScheduledTransferCompleted, db.ScheduledTransferCancelled:
+		err := fmt.Errorf("scheduled transfer %d is %s", scheduledTransfer.ID, scheduledTransfer.Status)
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return false
+	}
+	return true
+}
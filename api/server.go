@@ -1,42 +1,190 @@
 package api
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin" // Gin framework for HTTP routing and middleware
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/suleimanodetoro/Go-Bank-Pro/blob"
 	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc" // SQLC-generated package for database interaction
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/notify"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
 )
 
 // The `Server` struct represents the core of the application, holding dependencies like the database store and the router.
 // It serves HTTP requests, delegating the actual work to the underlying database via the store interface.
 type Server struct {
-	store  db.Store    // Store is the interface to the database where SQLC-generated methods are available for interaction.
-	router *gin.Engine // Router is used to define HTTP routes and handle incoming HTTP requests using the Gin framework.
+	config        util.Config         // Config holds the settings the handlers depend on, such as the approval threshold.
+	store         db.Store            // Store is the interface to the database where SQLC-generated methods are available for interaction.
+	tokenMaker    token.Maker         // TokenMaker creates and verifies the access tokens of logged in users.
+	permissions   *permissionCache    // Permissions of the roles, checked by the routes that need one.
+	notifier      notify.Notifier     // Notifier delivers messages such as password reset tokens to users.
+	blobs         blob.Store          // Blobs keeps uploaded files such as KYC documents.
+	totpEncrypter *util.Encrypter     // TotpEncrypter seals the users' TOTP secrets in the database.
+	hasher        util.PasswordHasher // Hasher hashes new passwords and tells which stored hashes are outdated.
+	router        *gin.Engine         // Router is used to define HTTP routes and handle incoming HTTP requests using the Gin framework.
 }
 
 // `NewServer` is a constructor function that creates a new instance of the `Server` struct.
-// It takes in a `store` (the database handler) and sets up the HTTP routing for the server.
-func NewServer(store db.Store) *Server {
+// It takes in the `config` and a `store` (the database handler) and sets up the HTTP routing for the server.
+func NewServer(config util.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+	totpEncrypter, err := util.NewEncrypter(config.TOTPEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create TOTP encrypter: %w", err)
+	}
+	hasher, err := config.PasswordHasher()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+	notifier, err := notify.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create notifier: %w", err)
+	}
+	blobs, err := blob.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create blob store: %w", err)
+	}
+
 	server := &Server{
-		store: store, // Inject the database store into the server.
+		config:        config,
+		store:         store, // Inject the database store into the server.
+		tokenMaker:    tokenMaker,
+		permissions:   newPermissionCache(store),
+		notifier:      notifier,
+		blobs:         blobs,
+		totpEncrypter: totpEncrypter,
+		hasher:        hasher,
 	}
 
 	router := gin.Default() // Initialize a new Gin router with logging and recovery middleware.
 
+	// Handlers pass the gin context to the store, which reads the audit actor from the request's context
+	router.ContextWithFallback = true
+	router.Use(auditMiddleware())
+
 	// Force the validator to initialize
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("scope", validScope)
 	}
 
 	// Define the routes for the server, mapping HTTP methods to handler functions.
-	router.POST("/accounts", server.createAccount)   // Route for creating an account.
-	router.GET("/accounts/:id", server.getAccount)   // Route for fetching a single account by ID.
-	router.GET("/accounts", server.listAccount)      // Route for listing accounts with optional pagination.
-	router.POST("/transfers", server.createTransfer) // Route for creating a transfer
-	router.POST("/users", server.createUser)         // Route for creating a user
+	router.GET("/account_products", server.listAccountProducts) // Route for the catalogue of account products.
+	router.POST("/users", server.createUser)                    // Route for creating a user
+	router.POST("/users/login", server.loginUser)               // Route for logging in and getting an access token
+
+	// Users who forgot their password get a single-use reset token through the notifier
+	router.POST("/users/password_reset", server.requestPasswordReset)
+	router.POST("/users/password_reset/confirm", server.confirmPasswordReset)
+	router.GET("/verify_email", server.verifyEmail) // Target of the link in the verification email
+
+	// Every other route needs a logged in user. Customers act on their own accounts; the handlers check
+	// ownership and let staff whose role has the matching permission act on any customer's accounts.
+	// Opening accounts and moving money can be held back until the user's email is verified.
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store))
+	authRoutes.GET("/users/me", server.getCurrentUser)
+	authRoutes.PATCH("/users/me", server.updateCurrentUser)       // A new email has to be verified again
+	authRoutes.DELETE("/users/me", server.deleteCurrentUser)      // Deactivated users are kept for retention but cannot log in
+	authRoutes.PATCH("/users/me/password", server.changePassword) // Changing the password logs out every other session
+	authRoutes.POST("/users/me/verify_email", server.resendVerifyEmail)
+	authRoutes.GET("/users/me/login_history", server.listLoginHistory)
+
+	// Users on the basic KYC tier have low limits until a reviewer verifies their profile and documents
+	authRoutes.GET("/kyc_tiers", server.listKYCTiers)
+	authRoutes.GET("/users/me/kyc", server.getCurrentKYC)
+	authRoutes.PUT("/users/me/kyc", server.submitKYC)
+	authRoutes.POST("/users/me/kyc/documents", server.uploadKYCDocument)
+
+	// API keys let back-office services call the routes of their scopes without logging in,
+	// with an Authorization: ApiKey header. Managing them needs a bearer token.
+	authRoutes.POST("/users/me/api_keys", server.createAPIKey)
+	authRoutes.GET("/users/me/api_keys", server.listAPIKeys)
+	authRoutes.DELETE("/users/me/api_keys/:id", server.revokeAPIKey)
+
+	// Two-factor authentication is optional. Once enabled, logging in, changing the password and
+	// transfers above the step-up threshold need a TOTP or recovery code.
+	authRoutes.POST("/users/me/totp", server.startTOTPEnrolment)
+	authRoutes.POST("/users/me/totp/confirm", server.confirmTOTPEnrolment)
+	authRoutes.POST("/users/me/totp/disable", server.disableTOTP)
+
+	authRoutes.POST("/accounts", server.requireVerifiedEmail(), server.createAccount) // Route for creating an account.
+	authRoutes.GET("/accounts/:id", server.getAccount)                                // Route for fetching a single account by ID.
+	authRoutes.GET("/accounts", server.listAccount)                                   // Route for listing accounts with optional pagination.
+	authRoutes.POST("/accounts/:id/close", server.closeAccount)                       // Owners close their own accounts; closed accounts stay queryable for history
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)                 // Route for an account's balance at a point in time
+
+	// Transfers need to know who is asking, so large ones can be held for a second user's approval
+	authRoutes.POST("/transfers", server.requireVerifiedEmail(), server.createTransfer) // Route for creating a transfer
+	authRoutes.GET("/transfers", server.listTransfers)                                  // Route for an account's transfer history
+	authRoutes.GET("/transfers/:id", server.getTransfer)                                // Route for fetching a single transfer and its status
+	authRoutes.POST("/transfers/batch", server.requireVerifiedEmail(), server.createTransferBatch)
+	authRoutes.POST("/transfers/quote", server.quoteTransfer) // Route for previewing the fee of a transfer
+	authRoutes.GET("/transfers/batch/:id", server.getTransferBatch)
+
+	// Conversions move money between two accounts of a user in different currencies at the bank's exchange rates
+	authRoutes.POST("/conversions", server.requireVerifiedEmail(), server.createConversion)
+	authRoutes.POST("/conversions/quote", server.quoteConversion) // Route for previewing the rate and spread of a conversion
+
+	// Holds reserve money before it is captured, like card authorizations
+	authRoutes.POST("/holds", server.createHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/release", server.releaseHold)
+	authRoutes.POST("/withdrawals", server.createWithdrawal)
+
+	// Routes for scheduled transfers (standing orders), executed by the background scheduler
+	authRoutes.POST("/scheduled_transfers", server.requireVerifiedEmail(), server.createScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled_transfers/:id", server.getScheduledTransfer)
+	authRoutes.PATCH("/scheduled_transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled_transfers/:id", server.cancelScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers/:id/runs", server.listScheduledTransferRuns)
+
+	// Back-office routes, each reserved to the roles with its permission. Tellers take cash deposits,
+	// approvers review held transfers and reverse posted ones, and admins freeze accounts while they are
+	// investigated, price transfers and conversions with fee schedules and exchange rates, read the ledger
+	// reports and the audit log, assign roles, unlock users locked out after failed logins, review the KYC
+	// profiles of users and investigate the alerts raised by transaction monitoring.
+	authRoutes.POST("/deposits", server.requirePermission(util.PermDepositCash), server.createDeposit)
+	authRoutes.GET("/transfer_approvals", server.requirePermission(util.PermReviewTransfers), server.listTransferApprovals)
+	authRoutes.POST("/transfers/:id/approve", server.requirePermission(util.PermReviewTransfers), server.approveTransfer)
+	authRoutes.POST("/transfers/:id/reject", server.requirePermission(util.PermReviewTransfers), server.rejectTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.requirePermission(util.PermReviewTransfers), server.reverseTransfer)
+	authRoutes.POST("/accounts/:id/freeze", server.requirePermission(util.PermFreezeAccounts), server.freezeAccount)
+	authRoutes.POST("/accounts/:id/unfreeze", server.requirePermission(util.PermFreezeAccounts), server.unfreezeAccount)
+	authRoutes.GET("/fee_schedules", server.requirePermission(util.PermManageFees), server.listFeeSchedules)
+	authRoutes.PUT("/fee_schedules", server.requirePermission(util.PermManageFees), server.setFeeSchedule)
+	authRoutes.DELETE("/fee_schedules/:fee_type/:currency", server.requirePermission(util.PermManageFees), server.deleteFeeSchedule)
+	authRoutes.GET("/exchange_rates", server.requirePermission(util.PermManageFees), server.listExchangeRates)
+	authRoutes.PUT("/exchange_rates", server.requirePermission(util.PermManageFees), server.setExchangeRate)
+	authRoutes.DELETE("/exchange_rates/:base_currency/:quote_currency", server.requirePermission(util.PermManageFees), server.deleteExchangeRate)
+	authRoutes.GET("/chart_of_accounts", server.requirePermission(util.PermViewReports), server.listChartOfAccounts)
+	authRoutes.GET("/reports/trial_balance", server.requirePermission(util.PermViewReports), server.getTrialBalance)
+	authRoutes.GET("/reports/ledger_verification", server.requirePermission(util.PermViewReports), server.verifyLedger)
+	authRoutes.GET("/reports/end_of_day", server.requirePermission(util.PermViewReports), server.getEndOfDayReport)
+	authRoutes.GET("/audit_events", server.requirePermission(util.PermViewAudit), server.listAuditEvents)
+	authRoutes.GET("/audit_events/export", server.requirePermission(util.PermViewAudit), server.exportAuditEvents)
+	authRoutes.GET("/roles", server.requirePermission(util.PermAssignRoles), server.listRoles)
+	authRoutes.PUT("/users/:username/role", server.requirePermission(util.PermAssignRoles), server.assignRole)
+	authRoutes.POST("/users/:username/unlock", server.requirePermission(util.PermUnlockUsers), server.unlockUser)
+	authRoutes.GET("/users/:username/export", server.requirePermission(util.PermExportUsers), server.exportUserData) // Subject access requests
+	authRoutes.POST("/users/:username/erase", server.requirePermission(util.PermEraseUsers), server.eraseUser)       // Only deactivated users can be erased
+	authRoutes.GET("/users/:username/kyc", server.requirePermission(util.PermReviewKYC), server.getUserKYC)
+	authRoutes.GET("/users/:username/kyc/documents/:id", server.requirePermission(util.PermReviewKYC), server.downloadKYCDocument)
+	authRoutes.POST("/users/:username/kyc/approve", server.requirePermission(util.PermReviewKYC), server.approveKYC)
+	authRoutes.POST("/users/:username/kyc/reject", server.requirePermission(util.PermReviewKYC), server.rejectKYC)
+	authRoutes.GET("/aml_alerts", server.requirePermission(util.PermInvestigateAML), server.listAMLAlerts)
+	authRoutes.GET("/aml_alerts/:id", server.requirePermission(util.PermInvestigateAML), server.getAMLAlert)
+	authRoutes.PATCH("/aml_alerts/:id", server.requirePermission(util.PermInvestigateAML), server.updateAMLAlert)
 
 	server.router = router // Assign the router to the server instance.
-	return server
+	return server, nil
 }
 
 // `Start` is a method on the `Server` struct that starts the HTTP server, binding it to a specific address and port.
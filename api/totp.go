@@ -0,0 +1,264 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+const (
+	// totpCodeHeaderKey carries the TOTP or recovery code of requests that need a step-up,
+	// so that it works the same for JSON bodies and CSV uploads
+	totpCodeHeaderKey = "X-TOTP-Code"
+	// recoveryCodeCount is the number of recovery codes a user gets when enabling two-factor authentication
+	recoveryCodeCount = 10
+	// defaultTOTPIssuer names the bank in authenticator apps when the issuer is not configured
+	defaultTOTPIssuer = "Go Bank Pro"
+)
+
+// totpEnrolmentResponse is what an authenticator app needs, usually scanned as a QR code of the URI.
+type totpEnrolmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// startTOTPEnrolment creates a new TOTP secret for the authenticated user. It is not used until
+// the user confirms it with a code, and can be replaced by starting again until then.
+func (server *Server) startTOTPEnrolment(ctx *gin.Context) {
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+
+	secret, err := util.NewTOTPSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	encrypted, err := server.totpEncrypter.Encrypt([]byte(secret), []byte(user.Username))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.StartUserTOTPEnrolment(ctx, db.StartUserTOTPEnrolmentParams{
+		Username:        user.Username,
+		SecretEncrypted: encrypted,
+	})
+	if err != nil {
+		// The upsert leaves an enabled secret alone and returns no row
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrTOTPAlreadyEnabled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	issuer := server.config.TOTPIssuer
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+	ctx.JSON(http.StatusOK, totpEnrolmentResponse{
+		Secret:          secret,
+		ProvisioningURI: util.TOTPProvisioningURI(issuer, user.Username, secret),
+	})
+}
+
+type confirmTOTPEnrolmentRequest struct {
+	Code string `json:"code" binding:"required,numeric,len=6"`
+}
+
+// confirmTOTPEnrolmentResponse holds the recovery codes, which are shown once and only stored hashed.
+type confirmTOTPEnrolmentResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTPEnrolment enables two-factor authentication once the user sends a valid code for the pending secret.
+func (server *Server) confirmTOTPEnrolment(ctx *gin.Context) {
+	var req confirmTOTPEnrolmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	totp, err := server.store.GetUserTOTP(ctx, user.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrTOTPNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if totp.EnabledAt.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(db.ErrTOTPAlreadyEnabled))
+		return
+	}
+
+	secret, err := server.totpEncrypter.Decrypt(totp.SecretEncrypted, []byte(user.Username))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	now := time.Now()
+	step, ok := util.ValidateTOTP(string(secret), req.Code, now)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("invalid two-factor code")))
+		return
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+	codeHashes := make([]string, recoveryCodeCount)
+	for i := range recoveryCodes {
+		recoveryCodes[i], err = util.NewRecoveryCode()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		codeHashes[i] = hashSecret(util.NormalizeRecoveryCode(recoveryCodes[i]))
+	}
+
+	_, err = server.store.EnableTOTPTx(ctx, db.EnableTOTPTxParams{
+		Username:           user.Username,
+		Step:               step,
+		RecoveryCodeHashes: codeHashes,
+		Now:                now,
+	})
+	if err != nil {
+		if err == db.ErrTOTPNotPending || err == db.ErrTOTPAlreadyEnabled {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTOTPEnrolmentResponse{RecoveryCodes: recoveryCodes})
+}
+
+type disableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// disableTOTP turns two-factor authentication off. It takes the password and, once enabled, a code,
+// so that a stolen access token is not enough to remove the second factor.
+func (server *Server) disableTOTP(ctx *gin.Context) {
+	var req disableTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	if !server.checkCurrentPassword(ctx, user, req.Password) {
+		return
+	}
+	if !server.checkStepUp(ctx, user) {
+		return
+	}
+
+	if err := server.store.DisableTOTPTx(ctx, user.Username); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("two-factor authentication is not set up")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "two-factor authentication is disabled"})
+}
+
+// checkSecondFactor checks the TOTP or recovery code of a user who enabled two-factor authentication,
+// and lets users without it through. Each code is accepted once. On failure it sends failStatus,
+// telling the client when the code was missing, and returns false. wrongCode reports whether it failed
+// because the code did not match, rather than because it was missing or the store failed, as only
+// wrong codes count towards the login lockout.
+func (server *Server) checkSecondFactor(ctx *gin.Context, user db.User, code string, failStatus int) (ok bool, wrongCode bool) {
+	totp, err := server.store.GetUserTOTP(ctx, user.Username)
+	if err == sql.ErrNoRows || (err == nil && !totp.EnabledAt.Valid) {
+		return true, false
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false, false
+	}
+
+	if code == "" {
+		ctx.JSON(failStatus, gin.H{"error": "a two-factor code is required", "totp_required": true})
+		return false, false
+	}
+
+	if len(code) == util.TOTPDigits {
+		secret, err := server.totpEncrypter.Decrypt(totp.SecretEncrypted, []byte(user.Username))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false, false
+		}
+		step, ok := util.ValidateTOTP(string(secret), code, time.Now())
+		if ok {
+			_, err = server.store.UseTOTPStep(ctx, db.UseTOTPStepParams{LastUsedStep: step, Username: user.Username})
+		}
+		if !ok || err == sql.ErrNoRows {
+			ctx.JSON(failStatus, errorResponse(errors.New("invalid or already used two-factor code")))
+			return false, true
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false, false
+		}
+		return true, false
+	}
+
+	_, err = server.store.UseTOTPRecoveryCode(ctx, db.UseTOTPRecoveryCodeParams{
+		UsedAt:   sql.NullTime{Time: time.Now(), Valid: true},
+		Username: user.Username,
+		CodeHash: hashSecret(util.NormalizeRecoveryCode(code)),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(failStatus, errorResponse(errors.New("invalid or already used recovery code")))
+			return false, true
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false, false
+	}
+	return true, false
+}
+
+// checkStepUp asks an authenticated user for the second factor sent in the TOTP code header before a
+// sensitive action. Wrong codes are recorded as failed logins and count towards the same lockout, so that
+// a stolen access token cannot be used to guess codes. It returns false after sending the response when
+// the check fails.
+func (server *Server) checkStepUp(ctx *gin.Context, user db.User) bool {
+	code := ctx.GetHeader(totpCodeHeaderKey)
+	if code != "" {
+		failures, ok := server.checkLoginAllowed(ctx, user.Username, &user, time.Now())
+		if !ok {
+			return false
+		}
+		server.delayLogin(ctx, failures)
+	}
+
+	ok, wrongCode := server.checkSecondFactor(ctx, user, code, http.StatusForbidden)
+	// A wrong code counts as a failure, a missing one only asks for it
+	if wrongCode {
+		server.recordLogin(ctx, user.Username, false)
+	}
+	return ok
+}
+
+// stepUpForAmount asks the authenticated user for a second factor when an amount is above the
+// step-up threshold. It returns false after sending the response when the check fails.
+func (server *Server) stepUpForAmount(ctx *gin.Context, amount int64) bool {
+	threshold := server.config.TransferStepUpThreshold
+	if threshold <= 0 || amount <= threshold {
+		return true
+	}
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	return server.checkStepUp(ctx, user)
+}
@@ -0,0 +1,632 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// randomUserTOTP returns a TOTP secret and its row for the user, sealed with the encrypter
+func randomUserTOTP(t *testing.T, encrypter *util.Encrypter, username string, enabled bool) (string, db.UserTotp) {
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+	encrypted, err := encrypter.Encrypt([]byte(secret), []byte(username))
+	require.NoError(t, err)
+
+	totp := db.UserTotp{Username: username, SecretEncrypted: encrypted}
+	if enabled {
+		totp.EnabledAt = sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+	}
+	return secret, totp
+}
+
+// currentTOTPCode returns the code of the secret for the current time step
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+func newTestEncrypter(t *testing.T) *util.Encrypter {
+	encrypter, err := util.NewEncrypter(util.RandomString(32))
+	require.NoError(t, err)
+	return encrypter
+}
+
+func TestStartTOTPEnrolmentAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	encrypter := newTestEncrypter(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, encrypted *[]byte)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, encrypted []byte)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, encrypted *[]byte) {
+				store.EXPECT().
+					StartUserTOTPEnrolment(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.StartUserTOTPEnrolmentParams) (db.UserTotp, error) {
+						require.Equal(t, user.Username, arg.Username)
+						*encrypted = arg.SecretEncrypted
+						return db.UserTotp{Username: arg.Username, SecretEncrypted: arg.SecretEncrypted}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, encrypted []byte) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp totpEnrolmentResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, strings.HasPrefix(rsp.ProvisioningURI, "otpauth://totp/"))
+				require.Contains(t, rsp.ProvisioningURI, "secret="+rsp.Secret)
+
+				// Only the sealed secret is stored, bound to the user
+				require.NotContains(t, string(encrypted), rsp.Secret)
+				secret, err := encrypter.Decrypt(encrypted, []byte(user.Username))
+				require.NoError(t, err)
+				require.Equal(t, rsp.Secret, string(secret))
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStubs: func(store *mockdb.MockStore, encrypted *[]byte) {
+				store.EXPECT().StartUserTOTPEnrolment(gomock.Any(), gomock.Any()).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, encrypted []byte) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var encrypted []byte
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, &encrypted)
+
+			server := newTestServer(t, store)
+			server.totpEncrypter = encrypter
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, encrypted)
+		})
+	}
+}
+
+func TestConfirmTOTPEnrolmentAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	encrypter := newTestEncrypter(t)
+	secret, pending := randomUserTOTP(t, encrypter, user.Username, false)
+	_, enabled := randomUserTOTP(t, encrypter, user.Username, true)
+
+	testCases := []struct {
+		name          string
+		code          string
+		buildStubs    func(store *mockdb.MockStore, hashes *[]string)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, hashes []string)
+	}{
+		{
+			name: "OK",
+			code: currentTOTPCode(t, secret),
+			buildStubs: func(store *mockdb.MockStore, hashes *[]string) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(pending, nil)
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.EnableTOTPTxParams) (db.UserTotp, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, util.TOTPStep(time.Now()), arg.Step, 1)
+						*hashes = arg.RecoveryCodeHashes
+						return enabled, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes []string) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp confirmTOTPEnrolmentResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.RecoveryCodes, recoveryCodeCount)
+				require.Len(t, hashes, recoveryCodeCount)
+				for i, code := range rsp.RecoveryCodes {
+					require.Equal(t, hashSecret(util.NormalizeRecoveryCode(code)), hashes[i])
+				}
+			},
+		},
+		{
+			name: "WrongCode",
+			code: "000000",
+			buildStubs: func(store *mockdb.MockStore, hashes *[]string) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(pending, nil)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes []string) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotPending",
+			code: currentTOTPCode(t, secret),
+			buildStubs: func(store *mockdb.MockStore, hashes *[]string) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes []string) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			code: currentTOTPCode(t, secret),
+			buildStubs: func(store *mockdb.MockStore, hashes *[]string) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes []string) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: "12ab56",
+			buildStubs: func(store *mockdb.MockStore, hashes *[]string) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, hashes []string) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var hashes []string
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, &hashes)
+
+			server := newTestServer(t, store)
+			server.totpEncrypter = encrypter
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": tc.code})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, hashes)
+		})
+	}
+}
+
+func TestLoginWithTOTPAPI(t *testing.T) {
+	user, password := randomUser(t)
+	encrypter := newTestEncrypter(t)
+	secret, enabled := randomUserTOTP(t, encrypter, user.Username, true)
+	recoveryCode, err := util.NewRecoveryCode()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		totpCode      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			totpCode: currentTOTPCode(t, secret),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UseTOTPStepParams) (db.UserTotp, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, util.TOTPStep(time.Now()), arg.LastUsedStep, 1)
+						return enabled, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "CodeRequired",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+				var rsp struct {
+					TOTPRequired bool `json:"totp_required"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.TOTPRequired)
+			},
+		},
+		{
+			name:     "WrongCode",
+			totpCode: "000000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "ReplayedCode",
+			totpCode: currentTOTPCode(t, secret),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "RecoveryCode",
+			totpCode: strings.ToUpper(recoveryCode),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseTOTPRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UseTOTPRecoveryCodeParams) (db.TotpRecoveryCode, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, hashSecret(util.NormalizeRecoveryCode(recoveryCode)), arg.CodeHash)
+						return db.TotpRecoveryCode{Username: arg.Username, CodeHash: arg.CodeHash, UsedAt: arg.UsedAt}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UsedRecoveryCode",
+			totpCode: recoveryCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UseTOTPRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.TotpRecoveryCode{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+			stubLoginFailures(store, 0, 0)
+			store.EXPECT().CreateLoginAttempt(gomock.Any(), gomock.Any()).MaxTimes(1).Return(db.LoginAttempt{}, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.totpEncrypter = encrypter
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"username": user.Username, "password": password, "totp_code": tc.totpCode})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDisableTOTPAPI(t *testing.T) {
+	user, password := randomUser(t)
+	encrypter := newTestEncrypter(t)
+	secret, enabled := randomUserTOTP(t, encrypter, user.Username, true)
+
+	testCases := []struct {
+		name          string
+		password      string
+		totpCode      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			password: password,
+			totpCode: currentTOTPCode(t, secret),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+				store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(enabled, nil)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "WrongPassword",
+			password: util.RandomString(6),
+			totpCode: currentTOTPCode(t, secret),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateLoginAttemptParams) (db.LoginAttempt, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.False(t, arg.Success)
+						return db.LoginAttempt{}, nil
+					})
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "WrongCode",
+			password: password,
+			totpCode: "000000",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+				store.EXPECT().
+					CreateLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateLoginAttemptParams) (db.LoginAttempt, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.False(t, arg.Success)
+						return db.LoginAttempt{}, nil
+					})
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "LockedOut",
+			password: password,
+			totpCode: currentTOTPCode(t, secret),
+			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginFailures(store, 0, defaultLoginMaxFailures)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusLocked, recorder.Code)
+			},
+		},
+		{
+			name:     "CodeRequired",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotSetUp",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).AnyTimes().Return(user, nil)
+			tc.buildStubs(store)
+			stubNoLoginFailures(store)
+
+			server := newTestServer(t, store)
+			server.totpEncrypter = encrypter
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"password": tc.password})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp/disable", bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.totpCode != "" {
+				request.Header.Set(totpCodeHeaderKey, tc.totpCode)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTransferStepUpAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	encrypter := newTestEncrypter(t)
+	_, enabled := randomUserTOTP(t, encrypter, user.Username, true)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).AnyTimes().Return(user, nil)
+	store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	server.totpEncrypter = encrypter
+	server.config.TransferStepUpThreshold = 100
+
+	data, err := json.Marshal(gin.H{"from_account_id": 1, "to_account_id": 2, "amount": 101, "currency": util.USD})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "totp_required")
+}
+
+// TestCashStepUpAPI tests that withdrawals and holds above the step-up threshold need a second factor.
+func TestCashStepUpAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	encrypter := newTestEncrypter(t)
+	_, enabled := randomUserTOTP(t, encrypter, user.Username, true)
+
+	testCases := []struct {
+		name          string
+		path          string
+		totpCode      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "WithdrawalCodeRequired",
+			path: "/withdrawals",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "totp_required")
+			},
+		},
+		{
+			name:     "WithdrawalWrongCode",
+			path:     "/withdrawals",
+			totpCode: "000000",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+				store.EXPECT().CreateLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginAttempt{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "WithdrawalStoreError",
+			path:     "/withdrawals",
+			totpCode: "000000",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginFailures(store, 0, 0)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{}, sql.ErrConnDone)
+				// an outage is not a wrong code, it does not count towards the lockout
+				store.EXPECT().CreateLoginAttempt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:     "WithdrawalLockedOut",
+			path:     "/withdrawals",
+			totpCode: "000000",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginFailures(store, 0, defaultLoginMaxFailures)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusLocked, recorder.Code)
+			},
+		},
+		{
+			name: "HoldCodeRequired",
+			path: "/holds",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "totp_required")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).AnyTimes().Return(user, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.totpEncrypter = encrypter
+			server.config.TransferStepUpThreshold = 100
+
+			data, err := json.Marshal(gin.H{"account_id": 1, "amount": 101, "currency": util.USD})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.totpCode != "" {
+				request.Header.Set(totpCodeHeaderKey, tc.totpCode)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -2,14 +2,21 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
 )
 
+// defaultTransferApprovalTTL is used when the approval TTL is not configured
+const defaultTransferApprovalTTL = 24 * time.Hour
+
 // The `transferRequest` struct represents the structure of the incoming JSON payload
 // for creating a new transfer. We use `binding` tags to enforce validation rules
 // and ensure only valid data reaches our application.
@@ -18,8 +25,14 @@ type transferRequest struct {
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`   // ID of the account to transfer to, must be positive
 	Amount        int64  `json:"amount" binding:"required,gt=0"`           // Amount to transfer, must be greater than 0
 	Currency      string `json:"currency" binding:"required,currency"`     // Currency of the transfer, limited to USD, EUR, or CAD
+	// Mode is "instant" (the default) to post the transfer straight away, or "pending" to debit the sender now
+	// and credit the recipient when the settlement worker posts the transfer
+	Mode string `json:"mode" binding:"omitempty,oneof=instant pending"`
 }
 
+// transferModePending asks createTransfer for a transfer that settles asynchronously
+const transferModePending = "pending"
+
 // The `createTransfer` function handles the creation of a new transfer.
 // It's a method on the `Server` struct, allowing it to access `Server` fields, such as the `store` for database operations.
 // The `ctx` parameter is a Gin context that provides request-specific information and handles the response back to the client.
@@ -35,7 +48,11 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if !server.validAccount(ctx, req.FromAccountID, req.Currency) {
+	if !server.stepUpForAmount(ctx, req.Amount) {
+		return
+	}
+
+	if !server.validSourceAccount(ctx, req.FromAccountID, req.Currency, "") {
 		return
 	}
 
@@ -43,6 +60,13 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	// Transfers above the approval threshold are held until a different user approves them (four-eyes control).
+	// Once approved they are posted straight away, whatever the requested mode.
+	if server.needsTransferApproval(req.Amount) {
+		server.requestTransferApproval(ctx, req)
+		return
+	}
+
 	// The `db.TransferTxParams` struct is an SQLC-generated struct that defines the parameters required
 	// to create a new transfer in the database. It includes the source and destination account IDs and the transfer amount.
 	arg := db.TransferTxParams{
@@ -51,10 +75,48 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		Amount:        req.Amount,
 	}
 
+	// Pending transfers only debit the sender for now; they are answered with `202 Accepted` as settlement happens later
+	if req.Mode == transferModePending {
+		result, err := server.store.PendingTransferTx(ctx, arg)
+		if err != nil {
+			if errors.Is(err, db.ErrInsufficientFunds) {
+				ctx.JSON(http.StatusBadRequest, errorResponse(err))
+				return
+			}
+			if isAccountStatusError(err) {
+				ctx.JSON(http.StatusConflict, errorResponse(err))
+				return
+			}
+			if isKYCLimitError(err) {
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusAccepted, result)
+		return
+	}
+
 	// `server.store.TransferTx` is the actual function that interacts with the database to perform the transfer.
 	// The `ctx` is passed to allow for context-based cancellations and timeouts, which can be useful in high-load scenarios.
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
+		// Spending more than the available balance is the client's mistake, not a server error
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		// The account may have been frozen or closed since it was checked
+		if isAccountStatusError(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		// The sender or the recipient has reached a limit of their KYC tier
+		if isKYCLimitError(err) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		// If the database transaction fails, return a `500 Internal Server Error` HTTP status code along with the error message.
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -65,32 +127,242 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, result)
 }
 
-// validAccount checks if an account exists and has the correct currency.
+// needsTransferApproval reports whether a transfer of the amount must be approved by a second user (four-eyes control).
+func (server *Server) needsTransferApproval(amount int64) bool {
+	return server.config.TransferApprovalThreshold > 0 && amount > server.config.TransferApprovalThreshold
+}
+
+// transferApprovalExpiry returns when a transfer requested now stops waiting for approval.
+func (server *Server) transferApprovalExpiry() time.Time {
+	ttl := server.config.TransferApprovalTTL
+	if ttl <= 0 {
+		ttl = defaultTransferApprovalTTL
+	}
+	return time.Now().Add(ttl)
+}
+
+// requestTransferApproval puts the amount of a large transfer on hold and records it for approval.
+// It responds with 202 Accepted, as the money only moves once an approver has approved the transfer.
+func (server *Server) requestTransferApproval(ctx *gin.Context, req transferRequest) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.RequestTransferApprovalTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		RequestedBy:   authPayload.Username,
+		ExpiresAt:     server.transferApprovalExpiry(),
+	}
+
+	result, err := server.store.RequestTransferApprovalTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if isAccountStatusError(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		// The sender has sent as much as their KYC tier allows today
+		if isKYCLimitError(err) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, result)
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getTransfer returns a single transfer by ID, including its status.
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Both the sender and the recipient can see a transfer
+	toAccount, status, err := server.fetchAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if toAccount.Owner != authPayload.Username && !server.authorizeAccountID(ctx, transfer.FromAccountID, util.PermViewAnyAccount) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// listTransfersRequest lists the transfers in and out of an account, optionally only those in a given status.
+type listTransfersRequest struct {
+	AccountID int64  `form:"account_id" binding:"required,min=1"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending_approval pending posted failed reversed cancelled"`
+	PageID    int32  `form:"page_id" binding:"required,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listTransfers returns the transfer history of an account, paginated like listAccount.
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.authorizeAccountID(ctx, req.AccountID, util.PermViewAnyAccount) {
+		return
+	}
+
+	arg := db.ListTransfersParams{
+		FromAccountID: req.AccountID,
+		ToAccountID:   req.AccountID,
+		Status:        sql.NullString{String: req.Status, Valid: req.Status != ""},
+		Limit:         req.PageSize,
+		Offset:        (req.PageID - 1) * req.PageSize,
+	}
+
+	transfers, err := server.store.ListTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+// reverseTransfer undoes a posted transfer with compensating entries. Only posted transfers can be reversed,
+// and only while the recipient can still pay the money back.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrInvalidTransferTransition), errors.Is(err, db.ErrInsufficientFunds), isAccountStatusError(err):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// validAccount checks if an account exists, has the correct currency and can be credited.
 // It returns true if the account is valid, false otherwise.
 // This function also handles sending appropriate error responses via the Gin context.
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) bool {
+	if status, err := server.checkAccount(ctx, accountID, currency, false); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return false
+	}
+
+	// If all checks pass, return true indicating a valid account
+	return true
+}
+
+// validSourceAccount is validAccount for the account money is taken from, which must not be frozen either.
+// The account must also belong to the authenticated user, unless their role has anyAccountPermission.
+func (server *Server) validSourceAccount(ctx *gin.Context, accountID int64, currency string, anyAccountPermission string) bool {
+	account, status, err := server.fetchAccount(ctx, accountID)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return false
+	}
+
+	if !server.authorizeAccount(ctx, account, anyAccountPermission) {
+		return false
+	}
+
+	if status, err := checkAccountUse(account, currency, true); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// checkAccount does the checks of validAccount, or validSourceAccount when debit is true, without writing a response.
+// On failure it returns the HTTP status that fits the error.
+func (server *Server) checkAccount(ctx *gin.Context, accountID int64, currency string, debit bool) (int, error) {
+	account, status, err := server.fetchAccount(ctx, accountID)
+	if err != nil {
+		return status, err
+	}
+
+	return checkAccountUse(account, currency, debit)
+}
+
+// fetchAccount loads an account, returning the HTTP status that fits the error when it cannot.
+func (server *Server) fetchAccount(ctx *gin.Context, accountID int64) (db.Account, int, error) {
 	// Attempt to retrieve the account from the database
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// If the account doesn't exist, return a 404 Not Found error
-			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("account %d not found", accountID)))
-			return false
+			return account, http.StatusNotFound, fmt.Errorf("account %d not found", accountID)
 		}
 		// For any other database error, return a 500 Internal Server Error
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("error fetching account %d: %v", accountID, err)))
-		return false
+		return account, http.StatusInternalServerError, fmt.Errorf("error fetching account %d: %v", accountID, err)
 	}
 
+	return account, http.StatusOK, nil
+}
+
+// checkAccountUse does the checks of checkAccount on an account that was already loaded.
+func checkAccountUse(account db.Account, currency string, debit bool) (int, error) {
+	accountID := account.ID
+
 	// Check if the account's currency matches the transfer currency
 	// Using EqualFold for case-insensitive string comparison
 	if !strings.EqualFold(account.Currency, currency) {
 		// If currencies don't match, return a 400 Bad Request error
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		return http.StatusBadRequest, fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
 	}
 
-	// If all checks pass, return true indicating a valid account
-	return true
+	// Closed accounts cannot move money at all, frozen ones can still receive it
+	switch {
+	case account.Status == db.AccountClosed:
+		return http.StatusConflict, fmt.Errorf("account %d: %w", accountID, db.ErrAccountClosed)
+	case debit && account.Status == db.AccountFrozen:
+		return http.StatusConflict, fmt.Errorf("account %d: %w", accountID, db.ErrAccountFrozen)
+	}
+
+	return http.StatusOK, nil
+}
+
+// isAccountStatusError reports whether the store refused to move money because of an account's status.
+func isAccountStatusError(err error) bool {
+	return errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed)
+}
+
+// isKYCLimitError reports whether a movement was refused by the limits of a KYC tier, which a verified
+// profile or a higher tier lifts.
+func isKYCLimitError(err error) bool {
+	return errors.Is(err, db.ErrTransferLimitExceeded) || errors.Is(err, db.ErrBalanceCapExceeded)
 }
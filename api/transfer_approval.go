@@ -0,0 +1,105 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+type reviewTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// approveTransfer posts a transfer that was held for approval. The approver must not be the user who requested it.
+func (server *Server) approveTransfer(ctx *gin.Context) {
+	arg, ok := reviewTransferParams(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.ApproveTransferTx(ctx, arg)
+	if err != nil {
+		reviewTransferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// rejectTransfer cancels a transfer that was held for approval and releases the held funds.
+func (server *Server) rejectTransfer(ctx *gin.Context) {
+	arg, ok := reviewTransferParams(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.RejectTransferTx(ctx, arg)
+	if err != nil {
+		reviewTransferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type listTransferApprovalsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listTransferApprovals lists the transfers waiting for approval, oldest first.
+func (server *Server) listTransferApprovals(ctx *gin.Context) {
+	var req listTransferApprovalsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approvals, err := server.store.ListPendingTransferApprovals(ctx, db.ListPendingTransferApprovalsParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, approvals)
+}
+
+// reviewTransferParams builds the review parameters from the URI and the authenticated reviewer.
+func reviewTransferParams(ctx *gin.Context) (db.ReviewTransferTxParams, bool) {
+	var req reviewTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.ReviewTransferTxParams{}, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	return db.ReviewTransferTxParams{
+		TransferID: req.ID,
+		ReviewedBy: authPayload.Username,
+		Now:        time.Now(),
+	}, true
+}
+
+// reviewTransferError maps the errors of ApproveTransferTx and RejectTransferTx to HTTP responses.
+func reviewTransferError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrSelfApproval):
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+	case errors.Is(err, db.ErrApprovalNotPending), errors.Is(err, db.ErrApprovalExpired), isAccountStatusError(err),
+		errors.Is(err, db.ErrInsufficientFunds), // the sender can no longer pay the transfer fee
+		errors.Is(err, db.ErrBalanceCapExceeded):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/token"
+)
+
+// maxBatchItems caps the number of transfers in one batch
+const maxBatchItems = 1000
+
+// batchTransferItem is one transfer of a batch. It follows the same rules as `transferRequest`.
+type batchTransferItem struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+// batchTransferRequest represents the JSON payload for a batch of transfers.
+// In "atomic" mode all the transfers go through or none does; in "best_effort" mode each one is attempted on its own.
+type batchTransferRequest struct {
+	Mode  string              `json:"mode" form:"mode" binding:"required,oneof=atomic best_effort"`
+	Items []batchTransferItem `json:"items" binding:"required,min=1,max=1000,dive"`
+}
+
+// batchItemError reports why an item of a batch was rejected before anything was executed.
+type batchItemError struct {
+	Position int    `json:"position"`
+	Error    string `json:"error"`
+}
+
+// createTransferBatch executes a batch of transfers, sent as JSON or as a CSV file upload.
+// Every item is validated like a single transfer first; if any is invalid, nothing is executed.
+// The batch is recorded with its status and the outcome of each item, and can be fetched again later.
+func (server *Server) createTransferBatch(ctx *gin.Context) {
+	var req batchTransferRequest
+	var err error
+	if ctx.ContentType() == binding.MIMEMultipartPOSTForm {
+		err = bindTransferBatchCSV(ctx, &req)
+	} else {
+		err = ctx.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// A batch steps up on its total, so a large amount cannot slip through split in small items
+	var total int64
+	for _, item := range req.Items {
+		total += item.Amount
+	}
+	if !server.stepUpForAmount(ctx, total) {
+		return
+	}
+
+	itemErrors, status, err := server.validateTransferBatch(ctx, req.Items)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	if len(itemErrors) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid batch items", "items": itemErrors})
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.BatchTransferTxParams{
+		RequestedBy: authPayload.Username,
+		Mode:        req.Mode,
+		Items:       make([]db.TransferTxParams, len(req.Items)),
+	}
+	for i, item := range req.Items {
+		arg.Items[i] = db.TransferTxParams{
+			FromAccountID: item.FromAccountID,
+			ToAccountID:   item.ToAccountID,
+			Amount:        item.Amount,
+		}
+	}
+
+	result, err := server.store.BatchTransferTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// validateTransferBatch applies the checks of createTransfer to every item and collects the failures.
+// Accounts are looked up once, however many items use them. An error is only returned when the lookup itself fails.
+func (server *Server) validateTransferBatch(ctx *gin.Context, items []batchTransferItem) ([]batchItemError, int, error) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// A nil entry records an account that does not exist
+	accounts := map[int64]*db.Account{}
+
+	var itemErrors []batchItemError
+	for i, item := range items {
+		// Large transfers need a second user's approval, which a batch cannot give
+		if server.needsTransferApproval(item.Amount) {
+			err := fmt.Errorf("amount %d is above the approval threshold and must be sent on its own", item.Amount)
+			itemErrors = append(itemErrors, batchItemError{Position: i, Error: err.Error()})
+			continue
+		}
+
+		for _, accountID := range []int64{item.FromAccountID, item.ToAccountID} {
+			account, ok := accounts[accountID]
+			if !ok {
+				fetched, err := server.store.GetAccount(ctx, accountID)
+				switch {
+				case err == nil:
+					account = &fetched
+				case err != sql.ErrNoRows:
+					return nil, http.StatusInternalServerError, fmt.Errorf("error fetching account %d: %v", accountID, err)
+				}
+				accounts[accountID] = account
+			}
+
+			var err error
+			switch {
+			case account == nil:
+				err = fmt.Errorf("account %d not found", accountID)
+			case accountID == item.FromAccountID && account.Owner != authPayload.Username:
+				err = fmt.Errorf("account %d doesn't belong to the authenticated user", accountID)
+			default:
+				_, err = checkAccountUse(*account, item.Currency, accountID == item.FromAccountID)
+			}
+			if err != nil {
+				itemErrors = append(itemErrors, batchItemError{Position: i, Error: err.Error()})
+				break
+			}
+		}
+	}
+
+	return itemErrors, http.StatusOK, nil
+}
+
+// bindTransferBatchCSV reads a batch uploaded as a multipart form: the mode as a form field and the items
+// as a CSV file in the "file" field, with the header from_account_id,to_account_id,amount,currency.
+func bindTransferBatchCSV(ctx *gin.Context, req *batchTransferRequest) error {
+	req.Mode = ctx.PostForm("mode")
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	req.Items, err = parseTransferBatchCSV(file)
+	if err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(req)
+}
+
+// transferBatchCSVHeader lists the columns expected in a CSV batch, in order
+var transferBatchCSVHeader = []string{"from_account_id", "to_account_id", "amount", "currency"}
+
+// parseTransferBatchCSV parses the items of a CSV batch. Validation is left to the binding rules of batchTransferItem.
+func parseTransferBatchCSV(r io.Reader) ([]batchTransferItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(transferBatchCSVHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv file is empty")
+		}
+		return nil, err
+	}
+	for i, column := range transferBatchCSVHeader {
+		if header[i] != column {
+			return nil, fmt.Errorf("csv header must be %v", transferBatchCSVHeader)
+		}
+	}
+
+	var items []batchTransferItem
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == maxBatchItems {
+			return nil, fmt.Errorf("a batch cannot hold more than %d transfers", maxBatchItems)
+		}
+
+		var item batchTransferItem
+		if item.FromAccountID, err = strconv.ParseInt(record[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid from_account_id: %w", line, err)
+		}
+		if item.ToAccountID, err = strconv.ParseInt(record[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid to_account_id: %w", line, err)
+		}
+		if item.Amount, err = strconv.ParseInt(record[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount: %w", line, err)
+		}
+		item.Currency = record[3]
+
+		items = append(items, item)
+	}
+}
+
+type getTransferBatchRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getTransferBatch returns a batch with the outcome of each of its transfers.
+func (server *Server) getTransferBatch(ctx *gin.Context) {
+	var req getTransferBatchRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	batch, err := server.store.GetTransferBatch(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// A batch is seen by whoever sent it, or by staff who can view any account
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if batch.RequestedBy != authPayload.Username {
+		allowed, err := server.hasPermission(ctx, util.PermViewAnyAccount)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !allowed {
+			ctx.JSON(http.StatusForbidden, errorResponse(errors.New("batch doesn't belong to the authenticated user")))
+			return
+		}
+	}
+
+	items, err := server.store.ListTransferBatchItems(ctx, batch.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.BatchTransferTxResult{Batch: batch, Items: items})
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"time"
@@ -14,7 +16,7 @@ import (
 // createUserRequest represents the structure of the incoming JSON payload for creating a new user.
 type createUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"` // Username must be alphanumeric and is required
-	Password string `json:"password" binding:"required,min=6"`    // Password must have a minimum length of 6 characters
+	Password string `json:"password" binding:"required"`          // Password must follow the configured password policy
 	FullName string `json:"full_name" binding:"required"`         // Full name is required
 	Email    string `json:"email" binding:"required,email"`       // Email must be a valid email address and is required
 }
@@ -25,6 +27,10 @@ type createUserResponse struct {
 	// HashedPassword    string    `json:"hashed_password"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	KYCStatus         string    `json:"kyc_status"`
+	KYCTier           string    `json:"kyc_tier"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -38,9 +44,13 @@ func (server *Server) createUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if err := server.config.PasswordPolicy().Validate(req.Password); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
 	// Hash the user's password
-	hashedPassword, err := util.HashPassword(req.Password)
+	hashedPassword, err := server.hasher.Hash(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -55,8 +65,18 @@ func (server *Server) createUser(ctx *gin.Context) {
 	}
 
 	// Create the user in the database
+	// Users sign up on their own, so an anonymous request is audited as the new user's
+	if actor := db.AuditActorFrom(ctx.Request.Context()); actor.Username == anonymousActor {
+		actor.Username = req.Username
+		setAuditActor(ctx, actor)
+	}
+
 	user, err := server.store.CreateUser(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrEmailTaken) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		// Handle PostgreSQL-specific errors
 		if pqErr, ok := err.(*pq.Error); ok {
 			log.Printf("PostgreSQL Error Code: %s, Message: %s", pqErr.Code, pqErr.Message)
@@ -73,15 +93,111 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	// create the customer api response without hashedPassword
-	response := createUserResponse{
+	// The user can ask for another verification email if this one fails, so it does not fail the sign-up
+	if err := server.sendVerifyEmail(ctx, user); err != nil {
+		log.Printf("cannot send verification email to %s: %v", user.Username, err)
+	}
+
+	// Return the created user with a 200 OK status
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// newUserResponse creates the customer api response without hashedPassword
+func newUserResponse(user db.User) createUserResponse {
+	return createUserResponse{
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		KYCStatus:         user.KycStatus,
+		KYCTier:           user.KycTier,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
+}
 
-	// Return the created user with a 200 OK status
-	ctx.JSON(http.StatusOK, response)
+// loginUserRequest represents the credentials sent to log in.
+// Users who enabled two-factor authentication also send a TOTP or recovery code.
+type loginUserRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Password string `json:"password" binding:"required"`
+	TOTPCode string `json:"totp_code"`
+}
+
+// loginUserResponse contains the access token to send as a bearer token, and the logged in user.
+type loginUserResponse struct {
+	AccessToken          string             `json:"access_token"`
+	AccessTokenExpiresAt time.Time          `json:"access_token_expires_at"`
+	User                 createUserResponse `json:"user"`
+}
+
+// loginUser checks the user's credentials and returns an access token carrying their username and role.
+// Every attempt goes to the login history, and too many failures lock the username or the client's IP
+// address out for a while. Unknown usernames get the same answer, in the same time, as wrong passwords.
+func (server *Server) loginUser(ctx *gin.Context) {
+	var req loginUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, req.Username)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	// Deactivated users cannot log in, and are answered like unknown usernames
+	exists := err == nil && !user.DeletedAt.Valid
+
+	var knownUser *db.User
+	if exists {
+		knownUser = &user
+	}
+	failures, ok := server.checkLoginAllowed(ctx, req.Username, knownUser, time.Now())
+	if !ok {
+		return
+	}
+	server.delayLogin(ctx, failures)
+
+	if exists {
+		err = util.CheckPassword(req.Password, user.HashedPassword)
+	} else {
+		err = util.CheckPasswordWithoutUser(server.hasher, req.Password)
+	}
+	if err != nil {
+		if server.recordLogin(ctx, req.Username, false) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+		}
+		return
+	}
+
+	if ok, wrongCode := server.checkSecondFactor(ctx, user, req.TOTPCode, http.StatusUnauthorized); !ok {
+		// A wrong code counts as a failure, a missing one is the first step of a two-factor login
+		if wrongCode {
+			server.recordLogin(ctx, req.Username, false)
+		}
+		return
+	}
+	if !server.recordLogin(ctx, req.Username, true) {
+		return
+	}
+	server.rehashPassword(ctx, user, req.Password)
+
+	server.sendLoginResponse(ctx, user)
+}
+
+// sendLoginResponse issues a new access token for the user and sends it with the user.
+func (server *Server) sendLoginResponse(ctx *gin.Context, user db.User) {
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.AccessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginUserResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+		User:                 newUserResponse(user),
+	})
 }
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+)
+
+type userDataURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type exportUserDataRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json zip"` // json by default
+}
+
+// exportUserData answers a subject access request with everything the bank holds about a user,
+// as a single JSON document or a ZIP archive of JSON files. The export is recorded in the audit log.
+func (server *Server) exportUserData(ctx *gin.Context) {
+	var uri userDataURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req exportUserDataRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	export, err := server.store.ExportUserDataTx(ctx, uri.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s", uri.Username, export.ExportedAt.UTC().Format("20060102T150405Z"))
+	if req.Format != "zip" {
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		ctx.JSON(http.StatusOK, export)
+		return
+	}
+
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	ctx.Status(http.StatusOK)
+	// Once the archive is being sent the status cannot change, so a failure leaves it truncated
+	if err := export.WriteZip(ctx.Writer); err != nil {
+		log.Printf("export user data of %s: %v", uri.Username, err)
+	}
+}
+
+// eraseUser pseudonymizes the personal data of a deactivated user, whose financial records are kept.
+func (server *Server) eraseUser(ctx *gin.Context) {
+	var uri userDataURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.EraseUserTx(ctx, db.EraseUserTxParams{
+		Username: uri.Username,
+		Now:      time.Now(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrUserNotDeleted), errors.Is(err, db.ErrUserErased):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
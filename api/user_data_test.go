@@ -0,0 +1,274 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// randomUserDataExport returns an export of a user with one account and one entry.
+func randomUserDataExport(user db.User) db.UserDataExport {
+	account := randomAccount()
+	account.Owner = user.Username
+	return db.UserDataExport{
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Profile: db.UserProfile{
+			Username: user.Username,
+			FullName: user.FullName,
+			Email:    user.Email,
+			Role:     user.Role,
+		},
+		Accounts:    []db.Account{account},
+		Entries:     []db.Entry{{ID: util.RandomInt(1, 1000), AccountID: account.ID, Amount: account.Balance}},
+		Transfers:   []db.Transfer{},
+		Sessions:    []db.LoginAttempt{{ID: 1, Username: user.Username, ClientIp: "127.0.0.1", Success: true}},
+		AuditEvents: []db.AuditEvent{},
+	}
+}
+
+func TestExportUserDataAPI(t *testing.T) {
+	admin := util.RandomOwner()
+	user, _ := randomUser(t)
+	export := randomUserDataExport(user)
+
+	testCases := []struct {
+		name          string
+		query         string
+		actorRole     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "JSON",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExportUserDataTx(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(export, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), user.Username)
+				require.NotContains(t, recorder.Body.String(), "hashed_password")
+
+				var rsp db.UserDataExport
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, export.Profile, rsp.Profile)
+				require.Equal(t, export.Entries, rsp.Entries)
+				require.Len(t, rsp.Accounts, 1)
+				require.Len(t, rsp.Sessions, 1)
+			},
+		},
+		{
+			name:      "ZIP",
+			query:     "?format=zip",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExportUserDataTx(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(export, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+
+				body := recorder.Body.Bytes()
+				archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+				require.NoError(t, err)
+
+				files := make(map[string][]byte)
+				for _, file := range archive.File {
+					f, err := file.Open()
+					require.NoError(t, err)
+					data, err := io.ReadAll(f)
+					require.NoError(t, err)
+					files[file.Name] = data
+				}
+				require.Len(t, files, 7)
+
+				var profile db.UserProfile
+				require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+				require.Equal(t, export.Profile, profile)
+				var entries []db.Entry
+				require.NoError(t, json.Unmarshal(files["entries.json"], &entries))
+				require.Equal(t, export.Entries, entries)
+			},
+		},
+		{
+			name:      "InvalidFormat",
+			query:     "?format=csv",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExportUserDataTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExportUserDataTx(gomock.Any(), gomock.Any()).Times(1).Return(db.UserDataExport{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExportUserDataTx(gomock.Any(), gomock.Any()).Times(1).Return(db.UserDataExport{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAnAdmin",
+			actorRole: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExportUserDataTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%s/export%s", user.Username, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, tc.actorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestEraseUserAPI(t *testing.T) {
+	admin := util.RandomOwner()
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		actorRole     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				erased := user
+				erased.FullName = db.ErasedFullName
+				erased.Email = user.Username + "@erased.invalid"
+				store.EXPECT().
+					EraseUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.EraseUserTxParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.WithinDuration(t, time.Now(), arg.Now, time.Second)
+						return erased, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp createUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, user.Username, rsp.Username)
+				require.Equal(t, db.ErasedFullName, rsp.FullName)
+				require.NotEqual(t, user.Email, rsp.Email)
+			},
+		},
+		{
+			name:      "UserNotDeactivated",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().EraseUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrUserNotDeleted)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "AlreadyErased",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().EraseUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrUserErased)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			actorRole: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().EraseUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAnAdmin",
+			actorRole: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().EraseUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%s/erase", user.Username)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, tc.actorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/notify"
+)
+
+const (
+	// defaultEmailVerificationURL is used when the verification link is not configured
+	defaultEmailVerificationURL = "http://localhost:8080/verify_email"
+	// defaultEmailVerificationTTL is used when the verification link TTL is not configured
+	defaultEmailVerificationTTL = 24 * time.Hour
+	// defaultEmailVerificationResendInterval is used when the resend interval is not configured
+	defaultEmailVerificationResendInterval = time.Minute
+)
+
+// sendVerifyEmail stores a new verification code for the user's email and sends them the link to use it.
+func (server *Server) sendVerifyEmail(ctx *gin.Context, user db.User) error {
+	secretCode, err := newSecret()
+	if err != nil {
+		return err
+	}
+
+	ttl := server.config.EmailVerificationTTL
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTTL
+	}
+
+	verifyEmail, err := server.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
+		Username:       user.Username,
+		Email:          user.Email,
+		SecretCodeHash: hashSecret(secretCode),
+		ExpiresAt:      time.Now().Add(ttl),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create verify email: %w", err)
+	}
+
+	link := server.config.EmailVerificationURL
+	if link == "" {
+		link = defaultEmailVerificationURL
+	}
+	query := url.Values{}
+	query.Set("id", strconv.FormatInt(verifyEmail.ID, 10))
+	query.Set("secret_code", secretCode)
+
+	return server.notifier.Send(ctx, notify.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hello %s,\nOpen this link to verify your email: %s?%s\nIt expires at %s.",
+			user.FullName, link, query.Encode(), verifyEmail.ExpiresAt.Format(time.RFC1123)),
+	})
+}
+
+type verifyEmailRequest struct {
+	ID         int64  `form:"id" binding:"required,min=1"`
+	SecretCode string `form:"secret_code" binding:"required,hexadecimal"`
+}
+
+// verifyEmail is the target of the verification link, it marks the user's email as verified.
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.VerifyEmailTx(ctx, db.VerifyEmailTxParams{
+		ID:             req.ID,
+		SecretCodeHash: hashSecret(req.SecretCode),
+		Now:            time.Now(),
+	})
+	if err != nil {
+		if err == db.ErrVerifyEmailInvalid || err == db.ErrVerifyEmailExpired {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// resendVerifyEmail sends the authenticated user a new verification link. Users wait for the
+// resend interval between two links, so the endpoint cannot be used to flood a mailbox.
+func (server *Server) resendVerifyEmail(ctx *gin.Context) {
+	user := ctx.MustGet(authorizationUserKey).(db.User)
+	if user.IsEmailVerified {
+		ctx.JSON(http.StatusConflict, errorResponse(errors.New("email is already verified")))
+		return
+	}
+
+	interval := server.config.EmailVerificationResendInterval
+	if interval <= 0 {
+		interval = defaultEmailVerificationResendInterval
+	}
+
+	latest, err := server.store.GetLatestVerifyEmail(ctx, user.Username)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err == nil {
+		if wait := time.Until(latest.CreatedAt.Add(interval)); wait > 0 {
+			ctx.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			ctx.JSON(http.StatusTooManyRequests, errorResponse(errors.New("a verification email was sent recently, try again later")))
+			return
+		}
+	}
+
+	if err := server.sendVerifyEmail(ctx, user); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "a verification email was sent to " + user.Email})
+}
+
+// requireVerifiedEmail creates a gin middleware that rejects users who have not verified their email,
+// when the config asks for it. It must run after authMiddleware.
+func (server *Server) requireVerifiedEmail() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if server.config.RequireVerifiedEmail {
+			user := ctx.MustGet(authorizationUserKey).(db.User)
+			if !user.IsEmailVerified {
+				err := errors.New("verify your email before opening accounts or making transfers")
+				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/notify"
+)
+
+func TestVerifyEmailAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.IsEmailVerified = true
+	secretCode, err := newSecret()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: url.Values{"id": {"7"}, "secret_code": {secretCode}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.VerifyEmailTxParams) (db.User, error) {
+						require.Equal(t, int64(7), arg.ID)
+						require.Equal(t, hashSecret(secretCode), arg.SecretCodeHash)
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp createUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.IsEmailVerified)
+			},
+		},
+		{
+			name:  "InvalidLink",
+			query: url.Values{"id": {"7"}, "secret_code": {secretCode}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrVerifyEmailInvalid)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "ExpiredLink",
+			query: url.Values{"id": {"7"}, "secret_code": {secretCode}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrVerifyEmailExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingSecretCode",
+			query: url.Values{"id": {"7"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: url.Values{"id": {"7"}, "secret_code": {secretCode}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/verify_email?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestResendVerifyEmailAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	linkPattern := regexp.MustCompile(`https?://\S+`)
+
+	testCases := []struct {
+		name          string
+		verified      bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *notify.MemoryNotifier)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				latest := db.VerifyEmail{ID: 1, Username: user.Username, CreatedAt: time.Now().Add(-2 * time.Minute)}
+				store.EXPECT().GetLatestVerifyEmail(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(latest, nil)
+				store.EXPECT().
+					CreateVerifyEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateVerifyEmailParams) (db.VerifyEmail, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, user.Email, arg.Email)
+						return db.VerifyEmail{ID: 2, Username: arg.Username, Email: arg.Email, SecretCodeHash: arg.SecretCodeHash, ExpiresAt: arg.ExpiresAt}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *notify.MemoryNotifier) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				messages := notifier.Messages()
+				require.Len(t, messages, 1)
+				require.Equal(t, user.Email, messages[0].To)
+
+				link, err := url.Parse(linkPattern.FindString(messages[0].Body))
+				require.NoError(t, err)
+				require.Equal(t, "/verify_email", link.Path)
+				require.Equal(t, "2", link.Query().Get("id"))
+				require.Len(t, link.Query().Get("secret_code"), 64)
+			},
+		},
+		{
+			name: "FirstEmail",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestVerifyEmail(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.VerifyEmail{}, sql.ErrNoRows)
+				store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(1).Return(db.VerifyEmail{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *notify.MemoryNotifier) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Len(t, notifier.Messages(), 1)
+			},
+		},
+		{
+			name: "TooSoon",
+			buildStubs: func(store *mockdb.MockStore) {
+				latest := db.VerifyEmail{ID: 1, Username: user.Username, CreatedAt: time.Now().Add(-10 * time.Second)}
+				store.EXPECT().GetLatestVerifyEmail(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(latest, nil)
+				store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *notify.MemoryNotifier) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+				require.Empty(t, notifier.Messages())
+			},
+		},
+		{
+			name:     "AlreadyVerified",
+			verified: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateVerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, notifier *notify.MemoryNotifier) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			authUser := user
+			authUser.IsEmailVerified = tc.verified
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).AnyTimes().Return(authUser, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/verify_email", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.notifier.(*notify.MemoryNotifier))
+		})
+	}
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name         string
+		required     bool
+		verified     bool
+		expectedCode int
+	}{
+		{name: "Verified", required: true, verified: true, expectedCode: http.StatusOK},
+		{name: "NotVerified", required: true, verified: false, expectedCode: http.StatusForbidden},
+		{name: "NotRequired", required: false, verified: false, expectedCode: http.StatusOK},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			authUser := user
+			authUser.IsEmailVerified = tc.verified
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).AnyTimes().Return(authUser, nil)
+
+			server := newTestServer(t, store)
+			server.config.RequireVerifiedEmail = tc.required
+
+			path := "/verified"
+			server.router.GET(path, authMiddleware(server.tokenMaker, server.store), server.requireVerifiedEmail(), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestTransferNeedsVerifiedEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).AnyTimes().Return(user, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	server.config.RequireVerifiedEmail = true
+
+	data, err := json.Marshal(gin.H{"from_account_id": 1, "to_account_id": 2, "amount": 10, "currency": util.USD})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code, fmt.Sprint(recorder.Body))
+}
@@ -0,0 +1,135 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Blob store kinds that can be set in the config
+const (
+	KindFile = "file" // keeps blobs as files in a directory
+)
+
+// Errors returned by the blob stores
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+	ErrExists     = errors.New("blob already exists")
+)
+
+// validKey allows keys made of path segments of letters, digits, dots, dashes and underscores,
+// so that a key can never point outside of the store.
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*(/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`)
+
+// Store keeps uploaded files, such as KYC documents, by key. Blobs are written once and never changed.
+// The file store is meant for a single server; an object store such as S3 plugs in by implementing this interface.
+type Store interface {
+	// Put writes a new blob and returns its size. It returns ErrExists when the key is taken.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get opens a blob, which the caller closes. It returns ErrNotFound for an unknown key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob. Deleting an unknown key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the blob store chosen in the config, the file store when none is set.
+func New(config util.Config) (Store, error) {
+	switch config.BlobStore {
+	case "", KindFile:
+		if config.BlobDir == "" {
+			return nil, fmt.Errorf("the %s blob store needs BLOB_DIR", KindFile)
+		}
+		return NewFileStore(config.BlobDir), nil
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", config.BlobStore)
+	}
+}
+
+func checkKey(key string) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("%w %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// FileStore keeps blobs as files under a directory, which is created if needed.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a blob store in the directory at dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (store *FileStore) path(key string) string {
+	return filepath.Join(store.dir, filepath.FromSlash(key))
+}
+
+// Put writes the blob to a temporary file first, so a failed upload never leaves a partial blob behind.
+func (store *FileStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	if err := checkKey(key); err != nil {
+		return 0, err
+	}
+	path := store.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, fmt.Errorf("cannot create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("cannot create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot write blob: %w", err)
+	}
+
+	// Linking fails when the key is taken, unlike renaming, which would replace the blob
+	if err := os.Link(tmp.Name(), path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return 0, fmt.Errorf("%w: %s", ErrExists, key)
+		}
+		return 0, fmt.Errorf("cannot write blob: %w", err)
+	}
+	return size, nil
+}
+
+// Get opens the file of the blob.
+func (store *FileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(store.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return file, err
+}
+
+// Delete removes the file of the blob.
+func (store *FileStore) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(store.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
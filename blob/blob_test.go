@@ -0,0 +1,64 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	key := "kyc/" + util.RandomOwner() + "/passport.pdf"
+	data := util.RandomString(100)
+
+	size, err := store.Put(ctx, key, strings.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), size)
+
+	// Blobs are written once
+	_, err = store.Put(ctx, key, strings.NewReader(util.RandomString(10)))
+	require.ErrorIs(t, err, ErrExists)
+
+	r, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, data, string(got))
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, store.Delete(ctx, key))
+
+	for _, invalid := range []string{"", "../secret", "kyc/../../secret", "/etc/passwd", "kyc//a", "kyc/.hidden"} {
+		_, err = store.Put(ctx, invalid, strings.NewReader(data))
+		require.ErrorIs(t, err, ErrInvalidKey, invalid)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := New(util.Config{BlobStore: KindFile, BlobDir: t.TempDir()})
+	require.NoError(t, err)
+	testStore(t, store)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestNewStore(t *testing.T) {
+	_, err := New(util.Config{})
+	require.Error(t, err)
+
+	store, err := New(util.Config{BlobDir: t.TempDir()})
+	require.NoError(t, err)
+	require.IsType(t, &FileStore{}, store)
+
+	_, err = New(util.Config{BlobStore: "s3", BlobDir: t.TempDir()})
+	require.Error(t, err)
+}
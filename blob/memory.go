@@ -0,0 +1,73 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MemoryStore keeps blobs in memory, for tests. It is safe for concurrent use.
+type MemoryStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+// NewMemoryStore creates a blob store that keeps its blobs in memory.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blobs: make(map[string][]byte)}
+}
+
+// Put keeps a copy of the blob.
+func (store *MemoryStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	if err := checkKey(key); err != nil {
+		return 0, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.blobs[key]; ok {
+		return 0, fmt.Errorf("%w: %s", ErrExists, key)
+	}
+	store.blobs[key] = data
+	return int64(len(data)), nil
+}
+
+// Get returns a reader of the blob.
+func (store *MemoryStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, ok := store.blobs[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete forgets the blob.
+func (store *MemoryStore) Delete(ctx context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.blobs, key)
+	return nil
+}
+
+// Keys returns the keys of the blobs kept so far.
+func (store *MemoryStore) Keys() []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	keys := make([]string, 0, len(store.blobs))
+	for key := range store.blobs {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,42 @@
+CREATE TABLE "scheduled_transfers" (
+    "id" bigserial PRIMARY KEY,
+    "from_account_id" bigint NOT NULL,
+    "to_account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL CHECK ("amount" > 0),
+    "currency" varchar NOT NULL,
+    "schedule_type" varchar NOT NULL CHECK ("schedule_type" IN ('once', 'cron')),
+    "cron_expression" varchar NOT NULL DEFAULT '',
+    "next_run_at" TIMESTAMPTZ NOT NULL,
+    "end_at" TIMESTAMPTZ,
+    "max_runs" integer,
+    "run_count" integer NOT NULL DEFAULT 0,
+    "failure_count" integer NOT NULL DEFAULT 0,
+    "status" varchar NOT NULL DEFAULT 'active' CHECK ("status" IN ('active', 'paused', 'completed', 'cancelled')),
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "updated_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN "scheduled_transfers"."cron_expression" IS 'five-field cron expression, empty for one-off transfers';
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'consecutive failed executions, reset on success';
+
+CREATE TABLE "scheduled_transfer_runs" (
+    "id" bigserial PRIMARY KEY,
+    "scheduled_transfer_id" bigint NOT NULL,
+    "scheduled_for" TIMESTAMPTZ NOT NULL,
+    "status" varchar NOT NULL CHECK ("status" IN ('succeeded', 'failed')),
+    "transfer_id" bigint,
+    "error" varchar NOT NULL DEFAULT '',
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ON "scheduled_transfers" ("from_account_id");
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+-- A given occurrence of a standing order can only ever succeed once, even with several schedulers running.
+CREATE UNIQUE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "scheduled_for") WHERE "status" = 'succeeded';
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
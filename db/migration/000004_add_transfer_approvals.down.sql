@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "transfer_approvals";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "held_balance";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
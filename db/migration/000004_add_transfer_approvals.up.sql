@@ -0,0 +1,25 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE "accounts" ADD COLUMN "held_balance" bigint NOT NULL DEFAULT 0 CHECK ("held_balance" >= 0);
+COMMENT ON COLUMN "accounts"."held_balance" IS 'funds reserved for pending transfers, still part of balance';
+
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'posted'
+    CHECK ("status" IN ('pending_approval', 'posted', 'cancelled'));
+
+CREATE TABLE "transfer_approvals" (
+    "transfer_id" bigint PRIMARY KEY,
+    "requested_by" varchar NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'approved', 'rejected', 'expired')),
+    "reviewed_by" varchar,
+    "reviewed_at" TIMESTAMPTZ,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- four-eyes: the maker can never be the checker
+    CONSTRAINT "reviewer_is_not_requester" CHECK ("reviewed_by" <> "requested_by")
+);
+
+CREATE INDEX ON "transfer_approvals" ("status", "expires_at");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
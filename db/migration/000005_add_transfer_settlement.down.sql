@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "transfers_status_idx";
+
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_status_check";
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_status_check"
+    CHECK ("status" IN ('pending_approval', 'posted', 'cancelled'));
@@ -0,0 +1,7 @@
+-- Transfers can now wait for settlement, fail, or be reversed after posting.
+-- Allowed transitions are enforced by the store (see db/sqlc/transfer_status.go).
+ALTER TABLE "transfers" DROP CONSTRAINT "transfers_status_check";
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_status_check"
+    CHECK ("status" IN ('pending_approval', 'pending', 'posted', 'failed', 'reversed', 'cancelled'));
+
+CREATE INDEX "transfers_status_idx" ON "transfers" ("status");
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "holds";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "available_balance";
+COMMENT ON COLUMN "accounts"."held_balance" IS 'funds reserved for pending transfers, still part of balance';
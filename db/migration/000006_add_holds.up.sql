@@ -0,0 +1,24 @@
+-- The ledger balance ("balance") counts every posted entry; the available balance is what can still be spent.
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint NOT NULL
+    GENERATED ALWAYS AS ("balance" - "held_balance") STORED;
+COMMENT ON COLUMN "accounts"."held_balance" IS 'funds reserved by holds and transfers awaiting approval, still part of balance';
+
+CREATE TABLE "holds" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL CHECK ("amount" > 0),
+    "captured_amount" bigint NOT NULL DEFAULT 0 CHECK ("captured_amount" >= 0),
+    "description" varchar NOT NULL DEFAULT '',
+    "status" varchar NOT NULL DEFAULT 'active' CHECK ("status" IN ('active', 'captured', 'released', 'expired')),
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "updated_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT "captured_amount_within_hold" CHECK ("captured_amount" <= "amount")
+);
+
+COMMENT ON COLUMN "holds"."captured_amount" IS 'amount actually taken on capture, the rest is released';
+
+CREATE INDEX ON "holds" ("account_id");
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,36 @@
+CREATE TABLE "transfer_batches" (
+    "id" bigserial PRIMARY KEY,
+    "requested_by" varchar NOT NULL,
+    "mode" varchar NOT NULL CHECK ("mode" IN ('atomic', 'best_effort')),
+    "status" varchar NOT NULL DEFAULT 'processing'
+        CHECK ("status" IN ('processing', 'completed', 'partially_completed', 'failed')),
+    "item_count" integer NOT NULL,
+    "succeeded_count" integer NOT NULL DEFAULT 0,
+    "failed_count" integer NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "completed_at" TIMESTAMPTZ
+);
+
+CREATE TABLE "transfer_batch_items" (
+    "id" bigserial PRIMARY KEY,
+    "batch_id" bigint NOT NULL,
+    "position" integer NOT NULL,
+    "from_account_id" bigint NOT NULL,
+    "to_account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL CHECK ("amount" > 0),
+    "status" varchar NOT NULL CHECK ("status" IN ('succeeded', 'failed')),
+    "transfer_id" bigint,
+    "error" varchar NOT NULL DEFAULT '',
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN "transfer_batch_items"."position" IS 'index of the item in the submitted batch, starting at 0';
+
+CREATE INDEX ON "transfer_batches" ("requested_by");
+CREATE UNIQUE INDEX ON "transfer_batch_items" ("batch_id", "position");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
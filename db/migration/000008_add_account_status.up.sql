@@ -0,0 +1,6 @@
+-- Accounts are never deleted: closing one keeps its entries and transfers queryable
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active'
+    CHECK ("status" IN ('active', 'frozen', 'closed'));
+ALTER TABLE "accounts" ADD COLUMN "closed_at" TIMESTAMPTZ;
+
+COMMENT ON COLUMN "accounts"."status" IS 'frozen accounts can still be credited, closed accounts cannot move money at all';
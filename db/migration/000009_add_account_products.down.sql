@@ -0,0 +1,7 @@
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_product_currency_key";
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "overdraft_limit";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "product_id";
+
+DROP TABLE IF EXISTS "account_products";
@@ -0,0 +1,31 @@
+CREATE TABLE "account_products" (
+    "id" bigserial PRIMARY KEY,
+    "code" varchar UNIQUE NOT NULL,
+    "name" varchar NOT NULL,
+    "overdraft_limit" bigint NOT NULL DEFAULT 0 CHECK ("overdraft_limit" >= 0),
+    "interest_rate_bps" integer NOT NULL DEFAULT 0 CHECK ("interest_rate_bps" >= 0),
+    "monthly_fee" bigint NOT NULL DEFAULT 0 CHECK ("monthly_fee" >= 0),
+    "max_accounts_per_owner" integer NOT NULL DEFAULT 1 CHECK ("max_accounts_per_owner" > 0),
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN "account_products"."interest_rate_bps" IS 'yearly interest rate in basis points (1/100 of a percent)';
+COMMENT ON COLUMN "account_products"."max_accounts_per_owner" IS 'open accounts of this product a user can hold, across currencies';
+
+INSERT INTO "account_products" ("code", "name", "overdraft_limit", "interest_rate_bps", "monthly_fee", "max_accounts_per_owner") VALUES
+    ('checking', 'Checking account', 0, 0, 0, 3),
+    ('savings', 'Savings account', 0, 150, 0, 3),
+    ('business', 'Business account', 100000, 0, 1500, 5);
+
+-- Existing accounts become checking accounts
+ALTER TABLE "accounts" ADD COLUMN "product_id" bigint;
+UPDATE "accounts" SET "product_id" = (SELECT "id" FROM "account_products" WHERE "code" = 'checking');
+ALTER TABLE "accounts" ALTER COLUMN "product_id" SET NOT NULL;
+ALTER TABLE "accounts" ADD FOREIGN KEY ("product_id") REFERENCES "account_products" ("id");
+
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0 CHECK ("overdraft_limit" >= 0);
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far the available balance may go below zero, copied from the product when the account is opened';
+
+-- A user can hold several accounts in the same currency, as long as they are of different products
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_key";
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_product_currency_key" UNIQUE ("owner", "product_id", "currency");
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "interest_accruals";
+DROP TABLE IF EXISTS "interest_postings";
+
+ALTER TABLE "account_products" DROP COLUMN IF EXISTS "day_count";
+ALTER TABLE "account_products" DROP COLUMN IF EXISTS "interest_method";
+ALTER TABLE "account_products" DROP COLUMN IF EXISTS "internal";
@@ -0,0 +1,59 @@
+-- The bank's own ledger accounts belong to a system user. It has no password, so nobody can log in as it.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "role")
+VALUES ('system', '', 'Bank ledger', 'system@localhost', 'system')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "account_products" ADD COLUMN "internal" boolean NOT NULL DEFAULT false;
+ALTER TABLE "account_products" ADD COLUMN "interest_method" varchar NOT NULL DEFAULT 'simple'
+    CHECK ("interest_method" IN ('simple', 'compound'));
+ALTER TABLE "account_products" ADD COLUMN "day_count" varchar NOT NULL DEFAULT 'ACT/365'
+    CHECK ("day_count" IN ('ACT/365', 'ACT/360'));
+
+COMMENT ON COLUMN "account_products"."internal" IS 'internal products are the bank''s own ledger accounts and cannot be opened by users';
+COMMENT ON COLUMN "account_products"."interest_method" IS 'compound interest also accrues on interest accrued but not posted yet';
+
+UPDATE "account_products" SET "interest_method" = 'compound' WHERE "code" = 'savings';
+
+-- Interest is paid from one interest expense account per currency, opened on first use
+INSERT INTO "account_products" ("code", "name", "max_accounts_per_owner", "internal")
+VALUES ('interest_expense', 'Interest expense', 1000, true)
+ON CONFLICT ("code") DO UPDATE SET "internal" = true;
+
+CREATE TABLE "interest_postings" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "period_end" date NOT NULL,
+    "accrued_micros" bigint NOT NULL,
+    "amount" bigint NOT NULL CHECK ("amount" >= 0),
+    "remainder_micros" bigint NOT NULL,
+    "transfer_id" bigint,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN "interest_postings"."accrued_micros" IS 'interest accrued over the period plus the remainder of the previous posting';
+COMMENT ON COLUMN "interest_postings"."remainder_micros" IS 'fraction of a minor unit left unpaid, carried over to the next posting';
+
+CREATE TABLE "interest_accruals" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "accrual_date" date NOT NULL,
+    "principal_micros" bigint NOT NULL,
+    "rate_bps" integer NOT NULL,
+    "interest_method" varchar NOT NULL,
+    "day_count" varchar NOT NULL,
+    "amount_micros" bigint NOT NULL,
+    "posting_id" bigint,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'interest for the day in millionths of the minor unit';
+
+-- One accrual per account and day and one posting per account and period make re-runs harmless
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+CREATE UNIQUE INDEX ON "interest_postings" ("account_id", "period_end");
+CREATE INDEX ON "interest_accruals" ("account_id") WHERE "posting_id" IS NULL;
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("posting_id") REFERENCES "interest_postings" ("id");
@@ -0,0 +1,43 @@
+-- Fees are paid into one fee income account per currency, opened on first use
+INSERT INTO "account_products" ("code", "name", "max_accounts_per_owner", "internal")
+VALUES ('fee_income', 'Fee income', 1000, true)
+ON CONFLICT ("code") DO UPDATE SET "internal" = true;
+
+CREATE TABLE "fee_schedules" (
+    "id" bigserial PRIMARY KEY,
+    "fee_type" varchar NOT NULL CHECK ("fee_type" IN ('transfer', 'fx_spread')),
+    "currency" varchar NOT NULL,
+    "method" varchar NOT NULL CHECK ("method" IN ('flat', 'percentage')),
+    "flat_amount" bigint NOT NULL DEFAULT 0 CHECK ("flat_amount" >= 0),
+    "rate_bps" integer NOT NULL DEFAULT 0 CHECK ("rate_bps" >= 0),
+    "min_fee" bigint NOT NULL DEFAULT 0 CHECK ("min_fee" >= 0),
+    "max_fee" bigint NOT NULL DEFAULT 0 CHECK ("max_fee" >= 0),
+    "updated_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE ("fee_type", "currency")
+);
+
+COMMENT ON COLUMN "fee_schedules"."rate_bps" IS 'percentage fees in basis points (1/100 of a percent) of the amount';
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS 'cap on percentage fees, 0 for no cap';
+
+CREATE TABLE "fees" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "fee_type" varchar NOT NULL CHECK ("fee_type" IN ('transfer', 'maintenance', 'fx_spread')),
+    "amount" bigint NOT NULL CHECK ("amount" > 0),
+    "charged_transfer_id" bigint,
+    "period_end" date,
+    "transfer_id" bigint NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN "fees"."charged_transfer_id" IS 'transfer the fee was charged on, for transfer fees';
+COMMENT ON COLUMN "fees"."period_end" IS 'last day of the month a maintenance fee was charged for';
+COMMENT ON COLUMN "fees"."transfer_id" IS 'transfer that moved the fee to the fee income account';
+
+-- One maintenance fee per account and month
+CREATE UNIQUE INDEX ON "fees" ("account_id", "fee_type", "period_end");
+CREATE INDEX ON "fees" ("charged_transfer_id");
+
+ALTER TABLE "fees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "fees" ADD FOREIGN KEY ("charged_transfer_id") REFERENCES "transfers" ("id");
+ALTER TABLE "fees" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS "entries_balance" ON "entries";
+DROP FUNCTION IF EXISTS "check_entries_balance"();
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_id";
+ALTER TABLE "account_products" DROP COLUMN IF EXISTS "ledger_class";
@@ -0,0 +1,38 @@
+-- Every product belongs to a class of the chart of accounts. Balances are signed so that they sum to zero
+-- across the whole ledger: credit balances (liabilities, income) are positive, debit balances (assets, expenses) negative.
+ALTER TABLE "account_products" ADD COLUMN "ledger_class" varchar NOT NULL DEFAULT 'liability'
+    CHECK ("ledger_class" IN ('asset', 'liability', 'income', 'expense'));
+
+COMMENT ON COLUMN "account_products"."ledger_class" IS 'chart of accounts class; customer accounts are liabilities of the bank';
+
+UPDATE "account_products" SET "ledger_class" = 'expense' WHERE "code" = 'interest_expense';
+UPDATE "account_products" SET "ledger_class" = 'income' WHERE "code" = 'fee_income';
+
+-- System accounts are opened per currency on first use, owned by the system user
+INSERT INTO "account_products" ("code", "name", "max_accounts_per_owner", "internal", "ledger_class") VALUES
+    ('cash_clearing', 'Cash clearing', 1000, true, 'asset'),
+    ('transit', 'Transfers in transit', 1000, true, 'liability'),
+    ('fx_position', 'FX position', 1000, true, 'asset')
+ON CONFLICT ("code") DO UPDATE SET "internal" = true, "ledger_class" = EXCLUDED."ledger_class";
+
+-- Entries are grouped into postings by the transfer they belong to. Entries written before this migration have none.
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+CREATE INDEX ON "entries" ("transfer_id");
+
+-- The entries of a transfer must sum to zero when the database transaction that wrote them commits
+CREATE FUNCTION "check_entries_balance"() RETURNS trigger AS $$
+BEGIN
+    IF NEW."transfer_id" IS NOT NULL AND
+        (SELECT SUM("amount") FROM "entries" WHERE "transfer_id" = NEW."transfer_id") <> 0 THEN
+        RAISE EXCEPTION 'entries of transfer % do not balance', NEW."transfer_id"
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "entries_balance"
+    AFTER INSERT ON "entries"
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION "check_entries_balance"();
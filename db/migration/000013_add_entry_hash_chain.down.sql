@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS "transfers_no_truncate" ON "transfers";
+DROP TRIGGER IF EXISTS "transfers_append_only" ON "transfers";
+DROP TRIGGER IF EXISTS "entries_no_truncate" ON "entries";
+DROP TRIGGER IF EXISTS "entries_append_only" ON "entries";
+DROP FUNCTION IF EXISTS "reject_ledger_change"();
+
+DROP TRIGGER IF EXISTS "entries_chain" ON "entries";
+DROP FUNCTION IF EXISTS "chain_entry"();
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "hash";
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "prev_hash";
+DROP FUNCTION IF EXISTS "entry_hash"(bigint, bigint, bigint, bigint, timestamptz, bytea);
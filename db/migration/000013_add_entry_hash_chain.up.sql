@@ -0,0 +1,92 @@
+-- Entries form one hash chain per account: each entry's hash covers its contents and the hash of the
+-- account's previous entry, so editing or removing an entry breaks every link after it.
+CREATE FUNCTION "entry_hash"(
+    "id" bigint,
+    "account_id" bigint,
+    "amount" bigint,
+    "transfer_id" bigint,
+    "created_at" timestamptz,
+    "prev_hash" bytea
+) RETURNS bytea AS $$
+    SELECT sha256(
+        convert_to(concat_ws('|', "id", "account_id", "amount", COALESCE("transfer_id"::text, ''),
+            to_char("created_at" AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US')), 'UTF8')
+        || COALESCE("prev_hash", ''::bytea)
+    );
+$$ LANGUAGE sql IMMUTABLE;
+
+ALTER TABLE "entries" ADD COLUMN "prev_hash" bytea;
+ALTER TABLE "entries" ADD COLUMN "hash" bytea;
+
+COMMENT ON COLUMN "entries"."prev_hash" IS 'hash of the previous entry of the same account, null for the first one';
+COMMENT ON COLUMN "entries"."hash" IS 'entry_hash() of the entry, chained to prev_hash';
+
+CREATE INDEX ON "entries" ("account_id", "id");
+
+-- Chain the entries written so far, account by account in ID order
+DO $$
+DECLARE
+    e RECORD;
+    prev bytea;
+    chain bigint;
+BEGIN
+    FOR e IN SELECT * FROM "entries" ORDER BY "account_id", "id" LOOP
+        IF chain IS DISTINCT FROM e."account_id" THEN
+            chain := e."account_id";
+            prev := NULL;
+        END IF;
+        UPDATE "entries"
+        SET "prev_hash" = prev,
+            "hash" = "entry_hash"(e."id", e."account_id", e."amount", e."transfer_id", e."created_at", prev)
+        WHERE "id" = e."id"
+        RETURNING "hash" INTO prev;
+    END LOOP;
+END;
+$$;
+
+ALTER TABLE "entries" ALTER COLUMN "hash" SET NOT NULL;
+
+-- New entries are chained as they are inserted, inside the inserting transaction. The account's row is locked
+-- whenever an entry is written for it, so entries of the same account are inserted one transaction at a time.
+CREATE FUNCTION "chain_entry"() RETURNS trigger AS $$
+BEGIN
+    SELECT "hash" INTO NEW."prev_hash" FROM "entries"
+    WHERE "account_id" = NEW."account_id"
+    ORDER BY "id" DESC
+    LIMIT 1;
+
+    NEW."hash" := "entry_hash"(NEW."id", NEW."account_id", NEW."amount", NEW."transfer_id", NEW."created_at", NEW."prev_hash");
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "entries_chain"
+    BEFORE INSERT ON "entries"
+    FOR EACH ROW EXECUTE FUNCTION "chain_entry"();
+
+-- The ledger is append-only: entries can never change, transfers only change status
+CREATE FUNCTION "reject_ledger_change"() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND TG_TABLE_NAME = 'transfers' THEN
+        IF (NEW."id", NEW."from_account_id", NEW."to_account_id", NEW."amount", NEW."created_at")
+            IS NOT DISTINCT FROM (OLD."id", OLD."from_account_id", OLD."to_account_id", OLD."amount", OLD."created_at") THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+    RAISE EXCEPTION '% on % is not allowed: the ledger is append-only', TG_OP, TG_TABLE_NAME
+        USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "entries_append_only"
+    BEFORE UPDATE OR DELETE ON "entries"
+    FOR EACH ROW EXECUTE FUNCTION "reject_ledger_change"();
+CREATE TRIGGER "entries_no_truncate"
+    BEFORE TRUNCATE ON "entries"
+    FOR EACH STATEMENT EXECUTE FUNCTION "reject_ledger_change"();
+CREATE TRIGGER "transfers_append_only"
+    BEFORE UPDATE OR DELETE ON "transfers"
+    FOR EACH ROW EXECUTE FUNCTION "reject_ledger_change"();
+CREATE TRIGGER "transfers_no_truncate"
+    BEFORE TRUNCATE ON "transfers"
+    FOR EACH STATEMENT EXECUTE FUNCTION "reject_ledger_change"();
@@ -0,0 +1,13 @@
+CREATE TABLE "daily_balances" (
+    "account_id" bigint NOT NULL,
+    "balance_date" date NOT NULL,
+    "balance" bigint NOT NULL,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("account_id", "balance_date")
+);
+
+COMMENT ON COLUMN "daily_balances"."balance" IS 'balance at the end of the day, midnight UTC';
+
+CREATE INDEX ON "daily_balances" ("balance_date");
+
+ALTER TABLE "daily_balances" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
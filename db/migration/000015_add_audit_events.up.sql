@@ -0,0 +1,37 @@
+CREATE TABLE "audit_events" (
+    "id" bigserial PRIMARY KEY,
+    "actor" varchar NOT NULL,
+    "actor_role" varchar NOT NULL DEFAULT '',
+    "action" varchar NOT NULL,
+    "resource_type" varchar NOT NULL,
+    "resource_id" varchar NOT NULL,
+    "before" jsonb NOT NULL DEFAULT 'null',
+    "after" jsonb NOT NULL DEFAULT 'null',
+    "request_id" varchar NOT NULL DEFAULT '',
+    "remote_addr" varchar NOT NULL DEFAULT '',
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'username that made the change, system for background jobs';
+COMMENT ON COLUMN "audit_events"."action" IS 'resource type and verb, such as account.create';
+COMMENT ON COLUMN "audit_events"."before" IS 'resource before the change, null when it was created';
+COMMENT ON COLUMN "audit_events"."after" IS 'resource after the change, null when it was deleted';
+
+CREATE INDEX ON "audit_events" ("created_at");
+CREATE INDEX ON "audit_events" ("actor");
+CREATE INDEX ON "audit_events" ("resource_type", "resource_id");
+
+-- Like the ledger, the audit log can only grow
+CREATE FUNCTION "reject_audit_change"() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% on % is not allowed: the audit log is append-only', TG_OP, TG_TABLE_NAME
+        USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_append_only"
+    BEFORE UPDATE OR DELETE ON "audit_events"
+    FOR EACH ROW EXECUTE FUNCTION "reject_audit_change"();
+CREATE TRIGGER "audit_events_no_truncate"
+    BEFORE TRUNCATE ON "audit_events"
+    FOR EACH STATEMENT EXECUTE FUNCTION "reject_audit_change"();
@@ -0,0 +1,52 @@
+CREATE TABLE "roles" (
+    "name" varchar PRIMARY KEY,
+    "description" varchar NOT NULL,
+    "assignable" boolean NOT NULL DEFAULT true
+);
+
+COMMENT ON COLUMN "roles"."assignable" IS 'whether admins can give the role to users';
+
+CREATE TABLE "permissions" (
+    "name" varchar PRIMARY KEY,
+    "description" varchar NOT NULL
+);
+
+CREATE TABLE "role_permissions" (
+    "role" varchar NOT NULL REFERENCES "roles" ("name") ON DELETE CASCADE,
+    "permission" varchar NOT NULL REFERENCES "permissions" ("name") ON DELETE CASCADE,
+    PRIMARY KEY ("role", "permission")
+);
+
+INSERT INTO "roles" ("name", "description", "assignable") VALUES
+    ('customer', 'Manages their own accounts and transfers', true),
+    ('teller', 'Serves customers at the counter: deposits, withdrawals and opening accounts', true),
+    ('approver', 'Approves or rejects transfers that need a second pair of eyes', true),
+    ('admin', 'Runs the bank: account freezes, fees, reports, audit and roles', true),
+    ('system', 'Owns the bank''s own ledger accounts, nobody can log in with it', false);
+
+INSERT INTO "permissions" ("name", "description") VALUES
+    ('accounts.view_any', 'view any customer''s accounts, balances and transfers'),
+    ('accounts.open_any', 'open accounts for any customer'),
+    ('accounts.freeze', 'freeze and unfreeze accounts'),
+    ('cash.deposit', 'deposit cash into any account'),
+    ('cash.withdraw_any', 'withdraw cash from any account'),
+    ('transfers.review', 'approve, reject and reverse transfers'),
+    ('fees.manage', 'set and remove fee schedules'),
+    ('reports.view', 'view the ledger reports'),
+    ('audit.view', 'search and export the audit log'),
+    ('roles.assign', 'view roles and assign them to users');
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('teller', 'accounts.view_any'),
+    ('teller', 'accounts.open_any'),
+    ('teller', 'cash.deposit'),
+    ('teller', 'cash.withdraw_any'),
+    ('approver', 'transfers.review'),
+    ('admin', 'accounts.view_any'),
+    ('admin', 'accounts.freeze'),
+    ('admin', 'fees.manage'),
+    ('admin', 'reports.view'),
+    ('admin', 'audit.view'),
+    ('admin', 'roles.assign');
+
+ALTER TABLE "users" ADD FOREIGN KEY ("role") REFERENCES "roles" ("name");
@@ -0,0 +1,12 @@
+CREATE TABLE "password_reset_tokens" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar NOT NULL REFERENCES "users" ("username"),
+    "token_hash" varchar UNIQUE NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "password_reset_tokens"."token_hash" IS 'SHA-256 of the token sent to the user, the token itself is never stored';
+
+CREATE INDEX ON "password_reset_tokens" ("username");
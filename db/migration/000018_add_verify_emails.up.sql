@@ -0,0 +1,16 @@
+ALTER TABLE "users" ADD COLUMN "is_email_verified" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "verify_emails" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar NOT NULL REFERENCES "users" ("username"),
+    "email" varchar NOT NULL,
+    "secret_code_hash" varchar NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "verify_emails"."email" IS 'address the code was sent to, the user is only verified if it is still theirs';
+COMMENT ON COLUMN "verify_emails"."secret_code_hash" IS 'SHA-256 of the secret code in the verification link';
+
+CREATE INDEX ON "verify_emails" ("username", "created_at");
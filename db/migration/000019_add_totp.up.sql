@@ -0,0 +1,23 @@
+CREATE TABLE "user_totp" (
+    "username" varchar PRIMARY KEY REFERENCES "users" ("username"),
+    "secret_encrypted" bytea NOT NULL,
+    "enabled_at" timestamptz,
+    "last_used_step" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "user_totp"."secret_encrypted" IS 'AES-256-GCM sealed TOTP secret, the key is in the config';
+COMMENT ON COLUMN "user_totp"."enabled_at" IS 'NULL until the user confirms enrolment with a first code';
+COMMENT ON COLUMN "user_totp"."last_used_step" IS 'time step of the last accepted code, a code is only accepted once';
+
+CREATE TABLE "totp_recovery_codes" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar NOT NULL REFERENCES "users" ("username"),
+    "code_hash" varchar NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "totp_recovery_codes"."code_hash" IS 'SHA-256 of the normalized recovery code';
+
+CREATE UNIQUE INDEX ON "totp_recovery_codes" ("username", "code_hash");
@@ -0,0 +1,6 @@
+DELETE FROM "role_permissions" WHERE "permission" = 'users.unlock';
+DELETE FROM "permissions" WHERE "name" = 'users.unlock';
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "login_unlocked_at";
+
+DROP TABLE IF EXISTS "login_attempts";
@@ -0,0 +1,23 @@
+CREATE TABLE "login_attempts" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar NOT NULL,
+    "client_ip" varchar NOT NULL,
+    "user_agent" varchar NOT NULL,
+    "success" boolean NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "login_attempts"."username" IS 'username that was tried, which may not exist, so failures are counted the same for every username';
+
+CREATE INDEX ON "login_attempts" ("username", "created_at");
+CREATE INDEX ON "login_attempts" ("client_ip", "created_at");
+
+ALTER TABLE "users" ADD COLUMN "login_unlocked_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
+
+COMMENT ON COLUMN "users"."login_unlocked_at" IS 'last unlock by an admin, failed logins before it no longer count';
+
+INSERT INTO "permissions" ("name", "description") VALUES
+    ('users.unlock', 'unlock users locked out after failed logins');
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('admin', 'users.unlock');
@@ -0,0 +1,18 @@
+CREATE TABLE "api_keys" (
+    "id" bigserial PRIMARY KEY,
+    "owner" varchar NOT NULL REFERENCES "users" ("username"),
+    "name" varchar NOT NULL,
+    "prefix" varchar UNIQUE NOT NULL,
+    "secret_hash" varchar NOT NULL,
+    "scopes" varchar[] NOT NULL,
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "api_keys"."prefix" IS 'public part of the key, which finds it without the secret';
+COMMENT ON COLUMN "api_keys"."secret_hash" IS 'SHA-256 of the secret part of the key, the key itself is never stored';
+COMMENT ON COLUMN "api_keys"."expires_at" IS 'the key does not expire when null';
+
+CREATE INDEX ON "api_keys" ("owner");
@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "deleted_at" timestamptz;
+
+COMMENT ON COLUMN "users"."deleted_at" IS 'when the user deactivated themselves, the user and their records are kept for retention but cannot log in';
@@ -0,0 +1,4 @@
+DELETE FROM "role_permissions" WHERE "permission" IN ('users.export', 'users.erase');
+DELETE FROM "permissions" WHERE "name" IN ('users.export', 'users.erase');
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "erased_at";
@@ -0,0 +1,11 @@
+ALTER TABLE "users" ADD COLUMN "erased_at" timestamptz;
+
+COMMENT ON COLUMN "users"."erased_at" IS 'when the personal data of the deactivated user was pseudonymized, their financial records are kept';
+
+INSERT INTO "permissions" ("name", "description") VALUES
+    ('users.export', 'export the personal data of any user'),
+    ('users.erase', 'erase the personal data of deactivated users');
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('admin', 'users.export'),
+    ('admin', 'users.erase');
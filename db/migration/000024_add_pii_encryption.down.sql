@@ -0,0 +1,5 @@
+-- Users encrypted since the migration keep their ciphertext, which can no longer be read
+ALTER TABLE "users" DROP COLUMN IF EXISTS "data_key_id";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_index";
+
+DROP TABLE IF EXISTS "data_keys";
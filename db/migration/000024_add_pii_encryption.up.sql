@@ -0,0 +1,15 @@
+CREATE TABLE "data_keys" (
+    "id" bigserial PRIMARY KEY,
+    "wrapped_key" bytea NOT NULL,
+    "master_key_id" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "data_keys"."wrapped_key" IS 'AES-256-GCM data key sealed with the master key, which is only in the config';
+COMMENT ON COLUMN "data_keys"."master_key_id" IS 'fingerprint of the master key that sealed the data key';
+
+ALTER TABLE "users" ADD COLUMN "email_index" varchar UNIQUE;
+ALTER TABLE "users" ADD COLUMN "data_key_id" bigint REFERENCES "data_keys" ("id");
+
+COMMENT ON COLUMN "users"."email_index" IS 'keyed hash of the email, which finds users by email once it is encrypted';
+COMMENT ON COLUMN "users"."data_key_id" IS 'data key that encrypted full_name and email, NULL while they are in plaintext';
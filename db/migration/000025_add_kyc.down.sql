@@ -0,0 +1,10 @@
+DELETE FROM "role_permissions" WHERE "permission" = 'kyc.review';
+DELETE FROM "permissions" WHERE "name" = 'kyc.review';
+
+DROP TABLE IF EXISTS "kyc_documents";
+DROP TABLE IF EXISTS "kyc_profiles";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "kyc_tier";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "kyc_status";
+
+DROP TABLE IF EXISTS "kyc_tiers";
@@ -0,0 +1,64 @@
+CREATE TABLE "kyc_tiers" (
+    "code" varchar PRIMARY KEY,
+    "name" varchar NOT NULL,
+    "max_open_accounts" integer NOT NULL CHECK ("max_open_accounts" >= 0),
+    "daily_transfer_limit" bigint NOT NULL CHECK ("daily_transfer_limit" >= 0),
+    "max_balance" bigint NOT NULL CHECK ("max_balance" >= 0),
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "kyc_tiers"."max_open_accounts" IS 'open accounts a user can hold across products, 0 for no limit';
+COMMENT ON COLUMN "kyc_tiers"."daily_transfer_limit" IS 'total a user can send per UTC day across their accounts, 0 for no limit';
+COMMENT ON COLUMN "kyc_tiers"."max_balance" IS 'highest balance an account of the user can reach through transfers and deposits, 0 for no limit';
+
+INSERT INTO "kyc_tiers" ("code", "name", "max_open_accounts", "daily_transfer_limit", "max_balance") VALUES
+    ('basic', 'Unverified customer', 1, 100000, 500000),
+    ('standard', 'Verified customer', 5, 1000000, 10000000),
+    ('enhanced', 'Verified customer with enhanced due diligence', 0, 0, 0);
+
+ALTER TABLE "users" ADD COLUMN "kyc_status" varchar NOT NULL DEFAULT 'none';
+ALTER TABLE "users" ADD COLUMN "kyc_tier" varchar NOT NULL DEFAULT 'basic' REFERENCES "kyc_tiers" ("code");
+
+-- The bank's own ledger accounts are not limited
+UPDATE "users" SET "kyc_tier" = 'enhanced' WHERE "role" = 'system';
+
+COMMENT ON COLUMN "users"."kyc_status" IS 'none, pending, verified or rejected';
+COMMENT ON COLUMN "users"."kyc_tier" IS 'limits of the user, raised when their KYC profile is verified';
+
+CREATE TABLE "kyc_profiles" (
+    "username" varchar PRIMARY KEY REFERENCES "users" ("username"),
+    "date_of_birth" date NOT NULL,
+    "address_line1" varchar NOT NULL,
+    "address_line2" varchar NOT NULL DEFAULT '',
+    "city" varchar NOT NULL,
+    "postal_code" varchar NOT NULL,
+    "country" varchar NOT NULL,
+    "submitted_at" timestamptz NOT NULL DEFAULT (now()),
+    "reviewed_by" varchar REFERENCES "users" ("username"),
+    "reviewed_at" timestamptz,
+    "rejection_reason" varchar NOT NULL DEFAULT ''
+);
+
+COMMENT ON COLUMN "kyc_profiles"."country" IS 'ISO 3166-1 alpha-2 code';
+
+CREATE TABLE "kyc_documents" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar NOT NULL REFERENCES "users" ("username"),
+    "kind" varchar NOT NULL,
+    "blob_key" varchar UNIQUE NOT NULL,
+    "content_type" varchar NOT NULL,
+    "size" bigint NOT NULL,
+    "sha256" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "kyc_documents" ("username");
+
+COMMENT ON COLUMN "kyc_documents"."kind" IS 'passport, id_card, driving_licence or proof_of_address';
+COMMENT ON COLUMN "kyc_documents"."blob_key" IS 'key of the uploaded file in the blob store';
+
+INSERT INTO "permissions" ("name", "description") VALUES
+    ('kyc.review', 'view, approve and reject the KYC profiles of users');
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('admin', 'kyc.review');
@@ -0,0 +1,9 @@
+DELETE FROM "role_permissions" WHERE "permission" = 'aml.investigate';
+DELETE FROM "permissions" WHERE "name" = 'aml.investigate';
+
+DROP TABLE IF EXISTS "aml_alerts";
+
+DROP TRIGGER IF EXISTS "transfers_queue_aml_screening_update" ON "transfers";
+DROP TRIGGER IF EXISTS "transfers_queue_aml_screening_insert" ON "transfers";
+DROP FUNCTION IF EXISTS "queue_aml_screening"();
+DROP TABLE IF EXISTS "aml_screening_queue";
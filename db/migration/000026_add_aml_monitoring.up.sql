@@ -0,0 +1,60 @@
+-- Posted transfers are queued for the AML monitor, which screens them after the fact so transfers are never
+-- slowed down by the rules. The queue is filled by triggers inside the posting transaction, so no path that
+-- posts a transfer can skip it.
+CREATE TABLE "aml_screening_queue" (
+    "transfer_id" bigint PRIMARY KEY REFERENCES "transfers" ("id"),
+    "queued_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE FUNCTION "queue_aml_screening"() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO "aml_screening_queue" ("transfer_id") VALUES (NEW."id")
+    ON CONFLICT DO NOTHING;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "transfers_queue_aml_screening_insert"
+    AFTER INSERT ON "transfers"
+    FOR EACH ROW WHEN (NEW."status" = 'posted')
+    EXECUTE FUNCTION "queue_aml_screening"();
+CREATE TRIGGER "transfers_queue_aml_screening_update"
+    AFTER UPDATE OF "status" ON "transfers"
+    FOR EACH ROW WHEN (NEW."status" = 'posted' AND OLD."status" <> 'posted')
+    EXECUTE FUNCTION "queue_aml_screening"();
+
+CREATE TABLE "aml_alerts" (
+    "id" bigserial PRIMARY KEY,
+    "transfer_id" bigint NOT NULL REFERENCES "transfers" ("id"),
+    "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+    "owner" varchar NOT NULL REFERENCES "users" ("username"),
+    "rule" varchar NOT NULL,
+    "severity" varchar NOT NULL CHECK ("severity" IN ('low', 'medium', 'high')),
+    "details" jsonb NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'open'
+        CHECK ("status" IN ('open', 'investigating', 'escalated', 'closed')),
+    "assigned_to" varchar REFERENCES "users" ("username"),
+    "resolution" varchar NOT NULL DEFAULT '',
+    "notes" varchar NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    "closed_at" timestamptz,
+    UNIQUE ("transfer_id", "rule")
+);
+
+CREATE INDEX ON "aml_alerts" ("status", "id");
+CREATE INDEX ON "aml_alerts" ("owner");
+
+COMMENT ON COLUMN "aml_alerts"."account_id" IS 'customer account the alert is about, the sender of the transfer unless it came from the bank';
+COMMENT ON COLUMN "aml_alerts"."owner" IS 'owner of the account, the subject of the case';
+COMMENT ON COLUMN "aml_alerts"."rule" IS 'name of the rule that raised the alert';
+COMMENT ON COLUMN "aml_alerts"."details" IS 'figures the rule matched on';
+COMMENT ON COLUMN "aml_alerts"."status" IS 'open, investigating, escalated or closed';
+COMMENT ON COLUMN "aml_alerts"."assigned_to" IS 'investigator working the case';
+COMMENT ON COLUMN "aml_alerts"."resolution" IS 'false_positive or reported once closed';
+
+INSERT INTO "permissions" ("name", "description") VALUES
+    ('aml.investigate', 'view AML alerts and work them as cases');
+
+INSERT INTO "role_permissions" ("role", "permission") VALUES
+    ('admin', 'aml.investigate');
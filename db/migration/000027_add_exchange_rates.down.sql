@@ -0,0 +1,4 @@
+COMMENT ON COLUMN "fees"."transfer_id" IS 'transfer that moved the fee to the fee income account';
+
+DROP TABLE IF EXISTS "conversions";
+DROP TABLE IF EXISTS "exchange_rates";
@@ -0,0 +1,29 @@
+-- Exchange rates used to convert money between accounts in different currencies, set by the bank.
+-- Amounts are kept in minor units, so a rate converts minor units of the base currency into minor
+-- units of the quote currency: 150 cents of USD are 225 yen at a USD/JPY rate of 1.5.
+CREATE TABLE "exchange_rates" (
+    "base_currency" varchar NOT NULL,
+    "quote_currency" varchar NOT NULL,
+    "rate" numeric(20, 10) NOT NULL CHECK ("rate" > 0),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("base_currency", "quote_currency"),
+    CHECK ("base_currency" <> "quote_currency")
+);
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'minor units of the quote currency per minor unit of the base currency';
+
+-- A conversion is made of two transfers, one per currency, so that the entries of each still balance:
+-- the amount goes from the source account into the FX position account of its currency, and the
+-- converted amount from the FX position account of the other currency into the target account.
+CREATE TABLE "conversions" (
+    "id" bigserial PRIMARY KEY,
+    "from_transfer_id" bigint NOT NULL REFERENCES "transfers" ("id"),
+    "to_transfer_id" bigint NOT NULL REFERENCES "transfers" ("id"),
+    "rate" numeric(20, 10) NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "conversions" ("from_transfer_id");
+CREATE INDEX ON "conversions" ("to_transfer_id");
+
+COMMENT ON COLUMN "fees"."transfer_id" IS 'transfer that moved the fee to the fee income account, or to the FX position account for FX spreads';
@@ -0,0 +1,12 @@
+DROP FUNCTION IF EXISTS "redact_user_audit_events"(varchar, varchar, varchar);
+
+CREATE OR REPLACE FUNCTION "reject_audit_change"() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% on % is not allowed: the audit log is append-only', TG_OP, TG_TABLE_NAME
+        USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+REVOKE ALL ON "audit_events" FROM "bank_audit_redactor";
+REVOKE ALL ON "users" FROM "bank_audit_redactor";
+DROP ROLE IF EXISTS "bank_audit_redactor";
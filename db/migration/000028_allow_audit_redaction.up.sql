@@ -0,0 +1,49 @@
+-- Erasing a user redacts the personal data of their audit snapshots. Only the role owning
+-- redact_user_audit_events may do so, and only the snapshots can change: every other update,
+-- by every other role, is still rejected.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'bank_audit_redactor') THEN
+        CREATE ROLE "bank_audit_redactor" NOLOGIN;
+    END IF;
+END;
+$$;
+
+GRANT SELECT ON "users" TO "bank_audit_redactor";
+GRANT SELECT, UPDATE ON "audit_events" TO "bank_audit_redactor";
+
+CREATE OR REPLACE FUNCTION "reject_audit_change"() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND current_user = 'bank_audit_redactor'
+        AND (NEW.id, NEW.actor, NEW.actor_role, NEW.action, NEW.resource_type, NEW.resource_id,
+             NEW.request_id, NEW.remote_addr, NEW.created_at)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.actor, OLD.actor_role, OLD.action, OLD.resource_type, OLD.resource_id,
+             OLD.request_id, OLD.remote_addr, OLD.created_at)
+    THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION '% on % is not allowed: the audit log is append-only', TG_OP, TG_TABLE_NAME
+        USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+-- Replaces the full name and email of the user snapshots of a deactivated user ($1) with $2 and $3.
+-- It runs as bank_audit_redactor, so it is the only way for the application to change the audit log.
+CREATE FUNCTION "redact_user_audit_events"(varchar, varchar, varchar) RETURNS void AS $$
+    UPDATE "audit_events"
+    SET
+        "before" = CASE WHEN jsonb_typeof("before") = 'object' AND "before" ? 'email'
+            THEN "before" || jsonb_build_object('full_name', $2, 'email', $3, 'data_key_id', NULL)
+            ELSE "before" END,
+        "after" = CASE WHEN jsonb_typeof("after") = 'object' AND "after" ? 'email'
+            THEN "after" || jsonb_build_object('full_name', $2, 'email', $3, 'data_key_id', NULL)
+            ELSE "after" END
+    WHERE "resource_type" = 'user'
+    AND "resource_id" = $1
+    AND ((jsonb_typeof("before") = 'object' AND "before" ? 'email') OR (jsonb_typeof("after") = 'object' AND "after" ? 'email'))
+    AND EXISTS (SELECT 1 FROM "users" WHERE "username" = $1 AND "deleted_at" IS NOT NULL);
+$$ LANGUAGE sql SECURITY DEFINER SET search_path = public;
+
+ALTER FUNCTION "redact_user_audit_events"(varchar, varchar, varchar) OWNER TO "bank_audit_redactor";
@@ -0,0 +1,3 @@
+ALTER TABLE "verify_emails" DROP COLUMN IF EXISTS "email_index";
+
+COMMENT ON COLUMN "verify_emails"."email" IS 'address the code was sent to, the user is only verified if it is still theirs';
@@ -0,0 +1,6 @@
+ALTER TABLE "verify_emails" ADD COLUMN "email_index" varchar;
+
+-- Codes sent while emails are in plaintext keep their address, so installs without encryption are not
+-- affected. Once encryption is enabled, sealing a user also seals the codes sent to them.
+COMMENT ON COLUMN "verify_emails"."email" IS 'address the code was sent to while emails are in plaintext, empty once they are encrypted';
+COMMENT ON COLUMN "verify_emails"."email_index" IS 'blind index of the address the code was sent to, NULL while emails are in plaintext';
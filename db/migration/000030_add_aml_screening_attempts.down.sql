@@ -0,0 +1,2 @@
+ALTER TABLE "aml_screening_queue" DROP COLUMN IF EXISTS "last_error";
+ALTER TABLE "aml_screening_queue" DROP COLUMN IF EXISTS "attempts";
@@ -0,0 +1,5 @@
+ALTER TABLE "aml_screening_queue" ADD COLUMN "attempts" integer NOT NULL DEFAULT 0;
+ALTER TABLE "aml_screening_queue" ADD COLUMN "last_error" varchar NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "aml_screening_queue"."attempts" IS 'screenings that failed, the monitor gives up on the transfer after too many';
+COMMENT ON COLUMN "aml_screening_queue"."last_error" IS 'why the last screening failed, for whoever looks into a transfer left queued';
@@ -0,0 +1,5 @@
+UPDATE "transfer_approvals" SET "status" = 'rejected' WHERE "status" = 'cancelled';
+
+ALTER TABLE "transfer_approvals" DROP CONSTRAINT "transfer_approvals_status_check";
+ALTER TABLE "transfer_approvals" ADD CONSTRAINT "transfer_approvals_status_check"
+    CHECK ("status" IN ('pending', 'approved', 'rejected', 'expired'));
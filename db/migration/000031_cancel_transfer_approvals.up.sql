@@ -0,0 +1,4 @@
+-- A user who deactivates withdraws the transfers still waiting for their approval
+ALTER TABLE "transfer_approvals" DROP CONSTRAINT "transfer_approvals_status_check";
+ALTER TABLE "transfer_approvals" ADD CONSTRAINT "transfer_approvals_status_check"
+    CHECK ("status" IN ('pending', 'approved', 'rejected', 'expired', 'cancelled'));
@@ -6,7 +6,9 @@ package mockdb
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	sqlc "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
@@ -35,6 +37,21 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AccrueInterestTx mocks base method.
+func (m *MockStore) AccrueInterestTx(ctx context.Context, arg sqlc.AccrueInterestTxParams) (sqlc.AccrueInterestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccrueInterestTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.AccrueInterestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccrueInterestTx indicates an expected call of AccrueInterestTx.
+func (mr *MockStoreMockRecorder) AccrueInterestTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccrueInterestTx", reflect.TypeOf((*MockStore)(nil).AccrueInterestTx), ctx, arg)
+}
+
 // AddAccountBalance mocks base method.
 func (m *MockStore) AddAccountBalance(ctx context.Context, arg sqlc.AddAccountBalanceParams) (sqlc.Account, error) {
 	m.ctrl.T.Helper()
@@ -50,226 +67,3109 @@ func (mr *MockStoreMockRecorder) AddAccountBalance(ctx, arg interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), ctx, arg)
 }
 
-// CreateAccount mocks base method.
-func (m *MockStore) CreateAccount(ctx context.Context, arg sqlc.CreateAccountParams) (sqlc.Account, error) {
+// AddAccountHeldBalance mocks base method.
+func (m *MockStore) AddAccountHeldBalance(ctx context.Context, arg sqlc.AddAccountHeldBalanceParams) (sqlc.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAccount", ctx, arg)
+	ret := m.ctrl.Call(m, "AddAccountHeldBalance", ctx, arg)
 	ret0, _ := ret[0].(sqlc.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateAccount indicates an expected call of CreateAccount.
-func (mr *MockStoreMockRecorder) CreateAccount(ctx, arg interface{}) *gomock.Call {
+// AddAccountHeldBalance indicates an expected call of AddAccountHeldBalance.
+func (mr *MockStoreMockRecorder) AddAccountHeldBalance(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountHeldBalance", reflect.TypeOf((*MockStore)(nil).AddAccountHeldBalance), ctx, arg)
 }
 
-// CreateEntry mocks base method.
-func (m *MockStore) CreateEntry(ctx context.Context, arg sqlc.CreateEntryParams) (sqlc.Entry, error) {
+// AddKYCDocumentTx mocks base method.
+func (m *MockStore) AddKYCDocumentTx(ctx context.Context, arg sqlc.CreateKYCDocumentParams) (sqlc.KycDocument, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEntry", ctx, arg)
-	ret0, _ := ret[0].(sqlc.Entry)
+	ret := m.ctrl.Call(m, "AddKYCDocumentTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.KycDocument)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateEntry indicates an expected call of CreateEntry.
-func (mr *MockStoreMockRecorder) CreateEntry(ctx, arg interface{}) *gomock.Call {
+// AddKYCDocumentTx indicates an expected call of AddKYCDocumentTx.
+func (mr *MockStoreMockRecorder) AddKYCDocumentTx(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddKYCDocumentTx", reflect.TypeOf((*MockStore)(nil).AddKYCDocumentTx), ctx, arg)
 }
 
-// CreateTransfer mocks base method.
-func (m *MockStore) CreateTransfer(ctx context.Context, arg sqlc.CreateTransferParams) (sqlc.Transfer, error) {
+// ApproveTransferTx mocks base method.
+func (m *MockStore) ApproveTransferTx(ctx context.Context, arg sqlc.ReviewTransferTxParams) (sqlc.ApproveTransferTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransfer", ctx, arg)
-	ret0, _ := ret[0].(sqlc.Transfer)
+	ret := m.ctrl.Call(m, "ApproveTransferTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ApproveTransferTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTransfer indicates an expected call of CreateTransfer.
-func (mr *MockStoreMockRecorder) CreateTransfer(ctx, arg interface{}) *gomock.Call {
+// ApproveTransferTx indicates an expected call of ApproveTransferTx.
+func (mr *MockStoreMockRecorder) ApproveTransferTx(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveTransferTx", reflect.TypeOf((*MockStore)(nil).ApproveTransferTx), ctx, arg)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
+// BatchTransferTx mocks base method.
+func (m *MockStore) BatchTransferTx(ctx context.Context, arg sqlc.BatchTransferTxParams) (sqlc.BatchTransferTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, arg)
-	ret0, _ := ret[0].(sqlc.User)
+	ret := m.ctrl.Call(m, "BatchTransferTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.BatchTransferTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(ctx, arg interface{}) *gomock.Call {
+// BatchTransferTx indicates an expected call of BatchTransferTx.
+func (mr *MockStoreMockRecorder) BatchTransferTx(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchTransferTx", reflect.TypeOf((*MockStore)(nil).BatchTransferTx), ctx, arg)
 }
 
-// DeleteAccount mocks base method.
-func (m *MockStore) DeleteAccount(ctx context.Context, id int64) error {
+// BootstrapAdminTx mocks base method.
+func (m *MockStore) BootstrapAdminTx(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.BootstrapAdminTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccount", ctx, id)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "BootstrapAdminTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.BootstrapAdminTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteAccount indicates an expected call of DeleteAccount.
-func (mr *MockStoreMockRecorder) DeleteAccount(ctx, id interface{}) *gomock.Call {
+// BootstrapAdminTx indicates an expected call of BootstrapAdminTx.
+func (mr *MockStoreMockRecorder) BootstrapAdminTx(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BootstrapAdminTx", reflect.TypeOf((*MockStore)(nil).BootstrapAdminTx), ctx, arg)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(ctx context.Context, id int64) (sqlc.Account, error) {
+// CaptureHoldTx mocks base method.
+func (m *MockStore) CaptureHoldTx(ctx context.Context, arg sqlc.CaptureHoldTxParams) (sqlc.CaptureHoldTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", ctx, id)
-	ret0, _ := ret[0].(sqlc.Account)
+	ret := m.ctrl.Call(m, "CaptureHoldTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.CaptureHoldTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockStoreMockRecorder) GetAccount(ctx, id interface{}) *gomock.Call {
+// CaptureHoldTx indicates an expected call of CaptureHoldTx.
+func (mr *MockStoreMockRecorder) CaptureHoldTx(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHoldTx", reflect.TypeOf((*MockStore)(nil).CaptureHoldTx), ctx, arg)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockStore) GetAccountForUpdate(ctx context.Context, id int64) (sqlc.Account, error) {
+// ChargeMaintenanceFeeTx mocks base method.
+func (m *MockStore) ChargeMaintenanceFeeTx(ctx context.Context, arg sqlc.ChargeMaintenanceFeeTxParams) (sqlc.ChargeMaintenanceFeeTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", ctx, id)
+	ret := m.ctrl.Call(m, "ChargeMaintenanceFeeTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ChargeMaintenanceFeeTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChargeMaintenanceFeeTx indicates an expected call of ChargeMaintenanceFeeTx.
+func (mr *MockStoreMockRecorder) ChargeMaintenanceFeeTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChargeMaintenanceFeeTx", reflect.TypeOf((*MockStore)(nil).ChargeMaintenanceFeeTx), ctx, arg)
+}
+
+// CloseAccount mocks base method.
+func (m *MockStore) CloseAccount(ctx context.Context, id int64) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAccount", ctx, id)
 	ret0, _ := ret[0].(sqlc.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockStoreMockRecorder) GetAccountForUpdate(ctx, id interface{}) *gomock.Call {
+// CloseAccount indicates an expected call of CloseAccount.
+func (mr *MockStoreMockRecorder) CloseAccount(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAccount", reflect.TypeOf((*MockStore)(nil).CloseAccount), ctx, id)
 }
 
-// GetEntry mocks base method.
-func (m *MockStore) GetEntry(ctx context.Context, id int64) (sqlc.Entry, error) {
+// CloseAccountTx mocks base method.
+func (m *MockStore) CloseAccountTx(ctx context.Context, arg sqlc.CloseAccountTxParams) (sqlc.CloseAccountTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", ctx, id)
-	ret0, _ := ret[0].(sqlc.Entry)
+	ret := m.ctrl.Call(m, "CloseAccountTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.CloseAccountTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockStoreMockRecorder) GetEntry(ctx, id interface{}) *gomock.Call {
+// CloseAccountTx indicates an expected call of CloseAccountTx.
+func (mr *MockStoreMockRecorder) CloseAccountTx(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAccountTx", reflect.TypeOf((*MockStore)(nil).CloseAccountTx), ctx, arg)
 }
 
-// GetTransfer mocks base method.
-func (m *MockStore) GetTransfer(ctx context.Context, id int64) (sqlc.Transfer, error) {
+// CompleteTransferBatch mocks base method.
+func (m *MockStore) CompleteTransferBatch(ctx context.Context, arg sqlc.CompleteTransferBatchParams) (sqlc.TransferBatch, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransfer", ctx, id)
-	ret0, _ := ret[0].(sqlc.Transfer)
+	ret := m.ctrl.Call(m, "CompleteTransferBatch", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferBatch)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransfer indicates an expected call of GetTransfer.
-func (mr *MockStoreMockRecorder) GetTransfer(ctx, id interface{}) *gomock.Call {
+// CompleteTransferBatch indicates an expected call of CompleteTransferBatch.
+func (mr *MockStoreMockRecorder) CompleteTransferBatch(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTransferBatch", reflect.TypeOf((*MockStore)(nil).CompleteTransferBatch), ctx, arg)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(ctx context.Context, username string) (sqlc.User, error) {
+// ConvertTx mocks base method.
+func (m *MockStore) ConvertTx(ctx context.Context, arg sqlc.ConvertTxParams) (sqlc.ConvertTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", ctx, username)
-	ret0, _ := ret[0].(sqlc.User)
+	ret := m.ctrl.Call(m, "ConvertTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ConvertTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(ctx, username interface{}) *gomock.Call {
+// ConvertTx indicates an expected call of ConvertTx.
+func (mr *MockStoreMockRecorder) ConvertTx(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), ctx, username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertTx", reflect.TypeOf((*MockStore)(nil).ConvertTx), ctx, arg)
 }
 
-// ListAccounts mocks base method.
-func (m *MockStore) ListAccounts(ctx context.Context, arg sqlc.ListAccountsParams) ([]sqlc.Account, error) {
+// CountOpenAccountsByProduct mocks base method.
+func (m *MockStore) CountOpenAccountsByProduct(ctx context.Context, arg sqlc.CountOpenAccountsByProductParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", ctx, arg)
-	ret0, _ := ret[0].([]sqlc.Account)
+	ret := m.ctrl.Call(m, "CountOpenAccountsByProduct", ctx, arg)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockStoreMockRecorder) ListAccounts(ctx, arg interface{}) *gomock.Call {
+// CountOpenAccountsByProduct indicates an expected call of CountOpenAccountsByProduct.
+func (mr *MockStoreMockRecorder) CountOpenAccountsByProduct(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOpenAccountsByProduct", reflect.TypeOf((*MockStore)(nil).CountOpenAccountsByProduct), ctx, arg)
 }
 
-// ListEntries mocks base method.
-func (m *MockStore) ListEntries(ctx context.Context, arg sqlc.ListEntriesParams) ([]sqlc.Entry, error) {
+// CountOpenTransfers mocks base method.
+func (m *MockStore) CountOpenTransfers(ctx context.Context, accountID int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", ctx, arg)
-	ret0, _ := ret[0].([]sqlc.Entry)
+	ret := m.ctrl.Call(m, "CountOpenTransfers", ctx, accountID)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListEntries indicates an expected call of ListEntries.
-func (mr *MockStoreMockRecorder) ListEntries(ctx, arg interface{}) *gomock.Call {
+// CountOpenTransfers indicates an expected call of CountOpenTransfers.
+func (mr *MockStoreMockRecorder) CountOpenTransfers(ctx, accountID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOpenTransfers", reflect.TypeOf((*MockStore)(nil).CountOpenTransfers), ctx, accountID)
 }
 
-// ListTransfers mocks base method.
-func (m *MockStore) ListTransfers(ctx context.Context, arg sqlc.ListTransfersParams) ([]sqlc.Transfer, error) {
+// CountOtherUsersWithEmail mocks base method.
+func (m *MockStore) CountOtherUsersWithEmail(ctx context.Context, arg sqlc.CountOtherUsersWithEmailParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTransfers", ctx, arg)
-	ret0, _ := ret[0].([]sqlc.Transfer)
+	ret := m.ctrl.Call(m, "CountOtherUsersWithEmail", ctx, arg)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTransfers indicates an expected call of ListTransfers.
-func (mr *MockStoreMockRecorder) ListTransfers(ctx, arg interface{}) *gomock.Call {
+// CountOtherUsersWithEmail indicates an expected call of CountOtherUsersWithEmail.
+func (mr *MockStoreMockRecorder) CountOtherUsersWithEmail(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOtherUsersWithEmail", reflect.TypeOf((*MockStore)(nil).CountOtherUsersWithEmail), ctx, arg)
 }
 
-// TransferTx mocks base method.
-func (m *MockStore) TransferTx(ctx context.Context, arg sqlc.TransferTxParams) (sqlc.TransferTxResult, error) {
+// CountOwnerTransfersInRange mocks base method.
+func (m *MockStore) CountOwnerTransfersInRange(ctx context.Context, arg sqlc.CountOwnerTransfersInRangeParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TransferTx", ctx, arg)
-	ret0, _ := ret[0].(sqlc.TransferTxResult)
+	ret := m.ctrl.Call(m, "CountOwnerTransfersInRange", ctx, arg)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// TransferTx indicates an expected call of TransferTx.
-func (mr *MockStoreMockRecorder) TransferTx(ctx, arg interface{}) *gomock.Call {
+// CountOwnerTransfersInRange indicates an expected call of CountOwnerTransfersInRange.
+func (mr *MockStoreMockRecorder) CountOwnerTransfersInRange(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockStore)(nil).TransferTx), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOwnerTransfersInRange", reflect.TypeOf((*MockStore)(nil).CountOwnerTransfersInRange), ctx, arg)
 }
 
-// UpdateAccount mocks base method.
-func (m *MockStore) UpdateAccount(ctx context.Context, arg sqlc.UpdateAccountParams) (sqlc.Account, error) {
+// CountRecentLoginFailures mocks base method.
+func (m *MockStore) CountRecentLoginFailures(ctx context.Context, arg sqlc.CountRecentLoginFailuresParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccount", ctx, arg)
+	ret := m.ctrl.Call(m, "CountRecentLoginFailures", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRecentLoginFailures indicates an expected call of CountRecentLoginFailures.
+func (mr *MockStoreMockRecorder) CountRecentLoginFailures(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRecentLoginFailures", reflect.TypeOf((*MockStore)(nil).CountRecentLoginFailures), ctx, arg)
+}
+
+// CountRecentLoginFailuresByIP mocks base method.
+func (m *MockStore) CountRecentLoginFailuresByIP(ctx context.Context, arg sqlc.CountRecentLoginFailuresByIPParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRecentLoginFailuresByIP", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRecentLoginFailuresByIP indicates an expected call of CountRecentLoginFailuresByIP.
+func (mr *MockStoreMockRecorder) CountRecentLoginFailuresByIP(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRecentLoginFailuresByIP", reflect.TypeOf((*MockStore)(nil).CountRecentLoginFailuresByIP), ctx, arg)
+}
+
+// CountUnusedTOTPRecoveryCodes mocks base method.
+func (m *MockStore) CountUnusedTOTPRecoveryCodes(ctx context.Context, username string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnusedTOTPRecoveryCodes", ctx, username)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnusedTOTPRecoveryCodes indicates an expected call of CountUnusedTOTPRecoveryCodes.
+func (mr *MockStoreMockRecorder) CountUnusedTOTPRecoveryCodes(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnusedTOTPRecoveryCodes", reflect.TypeOf((*MockStore)(nil).CountUnusedTOTPRecoveryCodes), ctx, username)
+}
+
+// CountUsersByRole mocks base method.
+func (m *MockStore) CountUsersByRole(ctx context.Context, role string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsersByRole", ctx, role)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsersByRole indicates an expected call of CountUsersByRole.
+func (mr *MockStoreMockRecorder) CountUsersByRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsersByRole", reflect.TypeOf((*MockStore)(nil).CountUsersByRole), ctx, role)
+}
+
+// CreateAMLAlert mocks base method.
+func (m *MockStore) CreateAMLAlert(ctx context.Context, arg sqlc.CreateAMLAlertParams) (sqlc.AmlAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAMLAlert", ctx, arg)
+	ret0, _ := ret[0].(sqlc.AmlAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAMLAlert indicates an expected call of CreateAMLAlert.
+func (mr *MockStoreMockRecorder) CreateAMLAlert(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAMLAlert", reflect.TypeOf((*MockStore)(nil).CreateAMLAlert), ctx, arg)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockStore) CreateAPIKey(ctx context.Context, arg sqlc.CreateAPIKeyParams) (sqlc.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockStoreMockRecorder) CreateAPIKey(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockStore)(nil).CreateAPIKey), ctx, arg)
+}
+
+// CreateAccount mocks base method.
+func (m *MockStore) CreateAccount(ctx context.Context, arg sqlc.CreateAccountParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, arg)
 	ret0, _ := ret[0].(sqlc.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAccount indicates an expected call of UpdateAccount.
-func (mr *MockStoreMockRecorder) UpdateAccount(ctx, arg interface{}) *gomock.Call {
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockStoreMockRecorder) CreateAccount(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), ctx, arg)
+}
+
+// CreateAccountTx mocks base method.
+func (m *MockStore) CreateAccountTx(ctx context.Context, arg sqlc.CreateAccountTxParams) (sqlc.CreateAccountTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.CreateAccountTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountTx indicates an expected call of CreateAccountTx.
+func (mr *MockStoreMockRecorder) CreateAccountTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTx", reflect.TypeOf((*MockStore)(nil).CreateAccountTx), ctx, arg)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockStore) CreateAuditEvent(ctx context.Context, arg sqlc.CreateAuditEventParams) (sqlc.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", ctx, arg)
+	ret0, _ := ret[0].(sqlc.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockStoreMockRecorder) CreateAuditEvent(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockStore)(nil).CreateAuditEvent), ctx, arg)
+}
+
+// CreateConversion mocks base method.
+func (m *MockStore) CreateConversion(ctx context.Context, arg sqlc.CreateConversionParams) (sqlc.Conversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateConversion", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Conversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateConversion indicates an expected call of CreateConversion.
+func (mr *MockStoreMockRecorder) CreateConversion(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConversion", reflect.TypeOf((*MockStore)(nil).CreateConversion), ctx, arg)
+}
+
+// CreateDailyBalance mocks base method.
+func (m *MockStore) CreateDailyBalance(ctx context.Context, arg sqlc.CreateDailyBalanceParams) (sqlc.DailyBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDailyBalance", ctx, arg)
+	ret0, _ := ret[0].(sqlc.DailyBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDailyBalance indicates an expected call of CreateDailyBalance.
+func (mr *MockStoreMockRecorder) CreateDailyBalance(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDailyBalance", reflect.TypeOf((*MockStore)(nil).CreateDailyBalance), ctx, arg)
+}
+
+// CreateDataKey mocks base method.
+func (m *MockStore) CreateDataKey(ctx context.Context, arg sqlc.CreateDataKeyParams) (sqlc.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataKey", ctx, arg)
+	ret0, _ := ret[0].(sqlc.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDataKey indicates an expected call of CreateDataKey.
+func (mr *MockStoreMockRecorder) CreateDataKey(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataKey", reflect.TypeOf((*MockStore)(nil).CreateDataKey), ctx, arg)
+}
+
+// CreateEntry mocks base method.
+func (m *MockStore) CreateEntry(ctx context.Context, arg sqlc.CreateEntryParams) (sqlc.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEntry", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockStoreMockRecorder) CreateEntry(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), ctx, arg)
+}
+
+// CreateFee mocks base method.
+func (m *MockStore) CreateFee(ctx context.Context, arg sqlc.CreateFeeParams) (sqlc.Fee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFee", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Fee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFee indicates an expected call of CreateFee.
+func (mr *MockStoreMockRecorder) CreateFee(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFee", reflect.TypeOf((*MockStore)(nil).CreateFee), ctx, arg)
+}
+
+// CreateHold mocks base method.
+func (m *MockStore) CreateHold(ctx context.Context, arg sqlc.CreateHoldParams) (sqlc.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockStoreMockRecorder) CreateHold(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockStore)(nil).CreateHold), ctx, arg)
+}
+
+// CreateHoldTx mocks base method.
+func (m *MockStore) CreateHoldTx(ctx context.Context, arg sqlc.CreateHoldTxParams) (sqlc.HoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHoldTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.HoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHoldTx indicates an expected call of CreateHoldTx.
+func (mr *MockStoreMockRecorder) CreateHoldTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHoldTx", reflect.TypeOf((*MockStore)(nil).CreateHoldTx), ctx, arg)
+}
+
+// CreateInterestAccrual mocks base method.
+func (m *MockStore) CreateInterestAccrual(ctx context.Context, arg sqlc.CreateInterestAccrualParams) (sqlc.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInterestAccrual", ctx, arg)
+	ret0, _ := ret[0].(sqlc.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInterestAccrual indicates an expected call of CreateInterestAccrual.
+func (mr *MockStoreMockRecorder) CreateInterestAccrual(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestAccrual", reflect.TypeOf((*MockStore)(nil).CreateInterestAccrual), ctx, arg)
+}
+
+// CreateInterestPosting mocks base method.
+func (m *MockStore) CreateInterestPosting(ctx context.Context, arg sqlc.CreateInterestPostingParams) (sqlc.InterestPosting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInterestPosting", ctx, arg)
+	ret0, _ := ret[0].(sqlc.InterestPosting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInterestPosting indicates an expected call of CreateInterestPosting.
+func (mr *MockStoreMockRecorder) CreateInterestPosting(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestPosting", reflect.TypeOf((*MockStore)(nil).CreateInterestPosting), ctx, arg)
+}
+
+// CreateKYCDocument mocks base method.
+func (m *MockStore) CreateKYCDocument(ctx context.Context, arg sqlc.CreateKYCDocumentParams) (sqlc.KycDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKYCDocument", ctx, arg)
+	ret0, _ := ret[0].(sqlc.KycDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKYCDocument indicates an expected call of CreateKYCDocument.
+func (mr *MockStoreMockRecorder) CreateKYCDocument(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKYCDocument", reflect.TypeOf((*MockStore)(nil).CreateKYCDocument), ctx, arg)
+}
+
+// CreateLoginAttempt mocks base method.
+func (m *MockStore) CreateLoginAttempt(ctx context.Context, arg sqlc.CreateLoginAttemptParams) (sqlc.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginAttempt", ctx, arg)
+	ret0, _ := ret[0].(sqlc.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoginAttempt indicates an expected call of CreateLoginAttempt.
+func (mr *MockStoreMockRecorder) CreateLoginAttempt(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginAttempt", reflect.TypeOf((*MockStore)(nil).CreateLoginAttempt), ctx, arg)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockStore) CreatePasswordResetToken(ctx context.Context, arg sqlc.CreatePasswordResetTokenParams) (sqlc.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", ctx, arg)
+	ret0, _ := ret[0].(sqlc.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockStoreMockRecorder) CreatePasswordResetToken(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockStore)(nil).CreatePasswordResetToken), ctx, arg)
+}
+
+// CreateScheduledTransfer mocks base method.
+func (m *MockStore) CreateScheduledTransfer(ctx context.Context, arg sqlc.CreateScheduledTransferParams) (sqlc.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransfer", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransfer indicates an expected call of CreateScheduledTransfer.
+func (mr *MockStoreMockRecorder) CreateScheduledTransfer(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransfer), ctx, arg)
+}
+
+// CreateScheduledTransferRun mocks base method.
+func (m *MockStore) CreateScheduledTransferRun(ctx context.Context, arg sqlc.CreateScheduledTransferRunParams) (sqlc.ScheduledTransferRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransferRun", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ScheduledTransferRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransferRun indicates an expected call of CreateScheduledTransferRun.
+func (mr *MockStoreMockRecorder) CreateScheduledTransferRun(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransferRun", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransferRun), ctx, arg)
+}
+
+// CreateSystemAccount mocks base method.
+func (m *MockStore) CreateSystemAccount(ctx context.Context, arg sqlc.CreateSystemAccountParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSystemAccount", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSystemAccount indicates an expected call of CreateSystemAccount.
+func (mr *MockStoreMockRecorder) CreateSystemAccount(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSystemAccount", reflect.TypeOf((*MockStore)(nil).CreateSystemAccount), ctx, arg)
+}
+
+// CreateTOTPRecoveryCode mocks base method.
+func (m *MockStore) CreateTOTPRecoveryCode(ctx context.Context, arg sqlc.CreateTOTPRecoveryCodeParams) (sqlc.TotpRecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTOTPRecoveryCode", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TotpRecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTOTPRecoveryCode indicates an expected call of CreateTOTPRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateTOTPRecoveryCode(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTOTPRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateTOTPRecoveryCode), ctx, arg)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(ctx context.Context, arg sqlc.CreateTransferParams) (sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), ctx, arg)
+}
+
+// CreateTransferApproval mocks base method.
+func (m *MockStore) CreateTransferApproval(ctx context.Context, arg sqlc.CreateTransferApprovalParams) (sqlc.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferApproval", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferApproval indicates an expected call of CreateTransferApproval.
+func (mr *MockStoreMockRecorder) CreateTransferApproval(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferApproval", reflect.TypeOf((*MockStore)(nil).CreateTransferApproval), ctx, arg)
+}
+
+// CreateTransferBatch mocks base method.
+func (m *MockStore) CreateTransferBatch(ctx context.Context, arg sqlc.CreateTransferBatchParams) (sqlc.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferBatch", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferBatch indicates an expected call of CreateTransferBatch.
+func (mr *MockStoreMockRecorder) CreateTransferBatch(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferBatch", reflect.TypeOf((*MockStore)(nil).CreateTransferBatch), ctx, arg)
+}
+
+// CreateTransferBatchItem mocks base method.
+func (m *MockStore) CreateTransferBatchItem(ctx context.Context, arg sqlc.CreateTransferBatchItemParams) (sqlc.TransferBatchItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferBatchItem", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferBatchItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferBatchItem indicates an expected call of CreateTransferBatchItem.
+func (mr *MockStoreMockRecorder) CreateTransferBatchItem(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferBatchItem", reflect.TypeOf((*MockStore)(nil).CreateTransferBatchItem), ctx, arg)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), ctx, arg)
+}
+
+// CreateVerifyEmail mocks base method.
+func (m *MockStore) CreateVerifyEmail(ctx context.Context, arg sqlc.CreateVerifyEmailParams) (sqlc.VerifyEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVerifyEmail", ctx, arg)
+	ret0, _ := ret[0].(sqlc.VerifyEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVerifyEmail indicates an expected call of CreateVerifyEmail.
+func (mr *MockStoreMockRecorder) CreateVerifyEmail(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerifyEmail", reflect.TypeOf((*MockStore)(nil).CreateVerifyEmail), ctx, arg)
+}
+
+// DeleteAMLScreening mocks base method.
+func (m *MockStore) DeleteAMLScreening(ctx context.Context, transferID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAMLScreening", ctx, transferID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAMLScreening indicates an expected call of DeleteAMLScreening.
+func (mr *MockStoreMockRecorder) DeleteAMLScreening(ctx, transferID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAMLScreening", reflect.TypeOf((*MockStore)(nil).DeleteAMLScreening), ctx, transferID)
+}
+
+// DeleteExchangeRate mocks base method.
+func (m *MockStore) DeleteExchangeRate(ctx context.Context, arg sqlc.DeleteExchangeRateParams) (sqlc.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExchangeRate", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExchangeRate indicates an expected call of DeleteExchangeRate.
+func (mr *MockStoreMockRecorder) DeleteExchangeRate(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExchangeRate", reflect.TypeOf((*MockStore)(nil).DeleteExchangeRate), ctx, arg)
+}
+
+// DeleteFeeSchedule mocks base method.
+func (m *MockStore) DeleteFeeSchedule(ctx context.Context, arg sqlc.DeleteFeeScheduleParams) (sqlc.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeeSchedule", ctx, arg)
+	ret0, _ := ret[0].(sqlc.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFeeSchedule indicates an expected call of DeleteFeeSchedule.
+func (mr *MockStoreMockRecorder) DeleteFeeSchedule(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeeSchedule", reflect.TypeOf((*MockStore)(nil).DeleteFeeSchedule), ctx, arg)
+}
+
+// DeleteLoginAttempts mocks base method.
+func (m *MockStore) DeleteLoginAttempts(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginAttempts", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginAttempts indicates an expected call of DeleteLoginAttempts.
+func (mr *MockStoreMockRecorder) DeleteLoginAttempts(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttempts", reflect.TypeOf((*MockStore)(nil).DeleteLoginAttempts), ctx, username)
+}
+
+// DeletePasswordResetTokens mocks base method.
+func (m *MockStore) DeletePasswordResetTokens(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResetTokens", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResetTokens indicates an expected call of DeletePasswordResetTokens.
+func (mr *MockStoreMockRecorder) DeletePasswordResetTokens(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetTokens", reflect.TypeOf((*MockStore)(nil).DeletePasswordResetTokens), ctx, username)
+}
+
+// DeleteTOTPRecoveryCodes mocks base method.
+func (m *MockStore) DeleteTOTPRecoveryCodes(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTPRecoveryCodes", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTPRecoveryCodes indicates an expected call of DeleteTOTPRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteTOTPRecoveryCodes(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTPRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteTOTPRecoveryCodes), ctx, username)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(ctx context.Context, arg sqlc.DeleteUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), ctx, arg)
+}
+
+// DeleteUserTOTP mocks base method.
+func (m *MockStore) DeleteUserTOTP(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTOTP", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTOTP indicates an expected call of DeleteUserTOTP.
+func (mr *MockStoreMockRecorder) DeleteUserTOTP(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTOTP", reflect.TypeOf((*MockStore)(nil).DeleteUserTOTP), ctx, username)
+}
+
+// DeleteUserTx mocks base method.
+func (m *MockStore) DeleteUserTx(ctx context.Context, arg sqlc.DeleteUserTxParams) (sqlc.DeleteUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.DeleteUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserTx indicates an expected call of DeleteUserTx.
+func (mr *MockStoreMockRecorder) DeleteUserTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTx", reflect.TypeOf((*MockStore)(nil).DeleteUserTx), ctx, arg)
+}
+
+// DeleteVerifyEmails mocks base method.
+func (m *MockStore) DeleteVerifyEmails(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVerifyEmails", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVerifyEmails indicates an expected call of DeleteVerifyEmails.
+func (mr *MockStoreMockRecorder) DeleteVerifyEmails(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVerifyEmails", reflect.TypeOf((*MockStore)(nil).DeleteVerifyEmails), ctx, username)
+}
+
+// DepositTx mocks base method.
+func (m *MockStore) DepositTx(ctx context.Context, arg sqlc.DepositTxParams) (sqlc.DepositTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DepositTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.DepositTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DepositTx indicates an expected call of DepositTx.
+func (mr *MockStoreMockRecorder) DepositTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositTx", reflect.TypeOf((*MockStore)(nil).DepositTx), ctx, arg)
+}
+
+// DisableTOTPTx mocks base method.
+func (m *MockStore) DisableTOTPTx(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTPTx", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTPTx indicates an expected call of DisableTOTPTx.
+func (mr *MockStoreMockRecorder) DisableTOTPTx(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTPTx", reflect.TypeOf((*MockStore)(nil).DisableTOTPTx), ctx, username)
+}
+
+// EnableTOTPTx mocks base method.
+func (m *MockStore) EnableTOTPTx(ctx context.Context, arg sqlc.EnableTOTPTxParams) (sqlc.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTPTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTOTPTx indicates an expected call of EnableTOTPTx.
+func (mr *MockStoreMockRecorder) EnableTOTPTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTPTx", reflect.TypeOf((*MockStore)(nil).EnableTOTPTx), ctx, arg)
+}
+
+// EnableUserTOTP mocks base method.
+func (m *MockStore) EnableUserTOTP(ctx context.Context, arg sqlc.EnableUserTOTPParams) (sqlc.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTOTP", ctx, arg)
+	ret0, _ := ret[0].(sqlc.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUserTOTP indicates an expected call of EnableUserTOTP.
+func (mr *MockStoreMockRecorder) EnableUserTOTP(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTOTP", reflect.TypeOf((*MockStore)(nil).EnableUserTOTP), ctx, arg)
+}
+
+// EraseUser mocks base method.
+func (m *MockStore) EraseUser(ctx context.Context, arg sqlc.EraseUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EraseUser indicates an expected call of EraseUser.
+func (mr *MockStoreMockRecorder) EraseUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseUser", reflect.TypeOf((*MockStore)(nil).EraseUser), ctx, arg)
+}
+
+// EraseUserTx mocks base method.
+func (m *MockStore) EraseUserTx(ctx context.Context, arg sqlc.EraseUserTxParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseUserTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EraseUserTx indicates an expected call of EraseUserTx.
+func (mr *MockStoreMockRecorder) EraseUserTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseUserTx", reflect.TypeOf((*MockStore)(nil).EraseUserTx), ctx, arg)
+}
+
+// ExecuteScheduledTransferTx mocks base method.
+func (m *MockStore) ExecuteScheduledTransferTx(ctx context.Context, arg sqlc.ExecuteScheduledTransferTxParams) (sqlc.ExecuteScheduledTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteScheduledTransferTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ExecuteScheduledTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteScheduledTransferTx indicates an expected call of ExecuteScheduledTransferTx.
+func (mr *MockStoreMockRecorder) ExecuteScheduledTransferTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteScheduledTransferTx", reflect.TypeOf((*MockStore)(nil).ExecuteScheduledTransferTx), ctx, arg)
+}
+
+// ExpireHoldTx mocks base method.
+func (m *MockStore) ExpireHoldTx(ctx context.Context, now time.Time) (sqlc.HoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHoldTx", ctx, now)
+	ret0, _ := ret[0].(sqlc.HoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHoldTx indicates an expected call of ExpireHoldTx.
+func (mr *MockStoreMockRecorder) ExpireHoldTx(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHoldTx", reflect.TypeOf((*MockStore)(nil).ExpireHoldTx), ctx, now)
+}
+
+// ExpireTransferApprovalTx mocks base method.
+func (m *MockStore) ExpireTransferApprovalTx(ctx context.Context, now time.Time) (sqlc.TransferApprovalTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireTransferApprovalTx", ctx, now)
+	ret0, _ := ret[0].(sqlc.TransferApprovalTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireTransferApprovalTx indicates an expected call of ExpireTransferApprovalTx.
+func (mr *MockStoreMockRecorder) ExpireTransferApprovalTx(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireTransferApprovalTx", reflect.TypeOf((*MockStore)(nil).ExpireTransferApprovalTx), ctx, now)
+}
+
+// ExportUserDataTx mocks base method.
+func (m *MockStore) ExportUserDataTx(ctx context.Context, username string) (sqlc.UserDataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUserDataTx", ctx, username)
+	ret0, _ := ret[0].(sqlc.UserDataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportUserDataTx indicates an expected call of ExportUserDataTx.
+func (mr *MockStoreMockRecorder) ExportUserDataTx(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUserDataTx", reflect.TypeOf((*MockStore)(nil).ExportUserDataTx), ctx, username)
+}
+
+// GetAMLAccount mocks base method.
+func (m *MockStore) GetAMLAccount(ctx context.Context, id int64) (sqlc.GetAMLAccountRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLAccount", ctx, id)
+	ret0, _ := ret[0].(sqlc.GetAMLAccountRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLAccount indicates an expected call of GetAMLAccount.
+func (mr *MockStoreMockRecorder) GetAMLAccount(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLAccount", reflect.TypeOf((*MockStore)(nil).GetAMLAccount), ctx, id)
+}
+
+// GetAMLAlert mocks base method.
+func (m *MockStore) GetAMLAlert(ctx context.Context, id int64) (sqlc.AmlAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLAlert", ctx, id)
+	ret0, _ := ret[0].(sqlc.AmlAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLAlert indicates an expected call of GetAMLAlert.
+func (mr *MockStoreMockRecorder) GetAMLAlert(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLAlert", reflect.TypeOf((*MockStore)(nil).GetAMLAlert), ctx, id)
+}
+
+// GetAMLAlertForUpdate mocks base method.
+func (m *MockStore) GetAMLAlertForUpdate(ctx context.Context, id int64) (sqlc.AmlAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLAlertForUpdate", ctx, id)
+	ret0, _ := ret[0].(sqlc.AmlAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLAlertForUpdate indicates an expected call of GetAMLAlertForUpdate.
+func (mr *MockStoreMockRecorder) GetAMLAlertForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLAlertForUpdate", reflect.TypeOf((*MockStore)(nil).GetAMLAlertForUpdate), ctx, id)
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (sqlc.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(sqlc.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockStoreMockRecorder) GetAPIKeyByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockStore)(nil).GetAPIKeyByPrefix), ctx, prefix)
+}
+
+// GetAPIKeyForUpdate mocks base method.
+func (m *MockStore) GetAPIKeyForUpdate(ctx context.Context, id int64) (sqlc.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyForUpdate", ctx, id)
+	ret0, _ := ret[0].(sqlc.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyForUpdate indicates an expected call of GetAPIKeyForUpdate.
+func (mr *MockStoreMockRecorder) GetAPIKeyForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyForUpdate", reflect.TypeOf((*MockStore)(nil).GetAPIKeyForUpdate), ctx, id)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(ctx context.Context, id int64) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", ctx, id)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), ctx, id)
+}
+
+// GetAccountBalanceAt mocks base method.
+func (m *MockStore) GetAccountBalanceAt(ctx context.Context, arg sqlc.GetAccountBalanceAtParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalanceAt", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalanceAt indicates an expected call of GetAccountBalanceAt.
+func (mr *MockStoreMockRecorder) GetAccountBalanceAt(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalanceAt", reflect.TypeOf((*MockStore)(nil).GetAccountBalanceAt), ctx, arg)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(ctx context.Context, id int64) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", ctx, id)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), ctx, id)
+}
+
+// GetAccountProduct mocks base method.
+func (m *MockStore) GetAccountProduct(ctx context.Context, id int64) (sqlc.AccountProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountProduct", ctx, id)
+	ret0, _ := ret[0].(sqlc.AccountProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountProduct indicates an expected call of GetAccountProduct.
+func (mr *MockStoreMockRecorder) GetAccountProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountProduct", reflect.TypeOf((*MockStore)(nil).GetAccountProduct), ctx, id)
+}
+
+// GetAccountProductByCode mocks base method.
+func (m *MockStore) GetAccountProductByCode(ctx context.Context, code string) (sqlc.AccountProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountProductByCode", ctx, code)
+	ret0, _ := ret[0].(sqlc.AccountProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountProductByCode indicates an expected call of GetAccountProductByCode.
+func (mr *MockStoreMockRecorder) GetAccountProductByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountProductByCode", reflect.TypeOf((*MockStore)(nil).GetAccountProductByCode), ctx, code)
+}
+
+// GetDataKey mocks base method.
+func (m *MockStore) GetDataKey(ctx context.Context, id int64) (sqlc.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataKey", ctx, id)
+	ret0, _ := ret[0].(sqlc.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataKey indicates an expected call of GetDataKey.
+func (mr *MockStoreMockRecorder) GetDataKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataKey", reflect.TypeOf((*MockStore)(nil).GetDataKey), ctx, id)
+}
+
+// GetDueScheduledTransferForUpdate mocks base method.
+func (m *MockStore) GetDueScheduledTransferForUpdate(ctx context.Context, now time.Time) (sqlc.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueScheduledTransferForUpdate", ctx, now)
+	ret0, _ := ret[0].(sqlc.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueScheduledTransferForUpdate indicates an expected call of GetDueScheduledTransferForUpdate.
+func (mr *MockStoreMockRecorder) GetDueScheduledTransferForUpdate(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueScheduledTransferForUpdate", reflect.TypeOf((*MockStore)(nil).GetDueScheduledTransferForUpdate), ctx, now)
+}
+
+// GetEndOfDayReport mocks base method.
+func (m *MockStore) GetEndOfDayReport(ctx context.Context, balanceDate time.Time) ([]sqlc.GetEndOfDayReportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEndOfDayReport", ctx, balanceDate)
+	ret0, _ := ret[0].([]sqlc.GetEndOfDayReportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEndOfDayReport indicates an expected call of GetEndOfDayReport.
+func (mr *MockStoreMockRecorder) GetEndOfDayReport(ctx, balanceDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEndOfDayReport", reflect.TypeOf((*MockStore)(nil).GetEndOfDayReport), ctx, balanceDate)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(ctx context.Context, id int64) (sqlc.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", ctx, id)
+	ret0, _ := ret[0].(sqlc.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), ctx, id)
+}
+
+// GetExchangeRate mocks base method.
+func (m *MockStore) GetExchangeRate(ctx context.Context, arg sqlc.GetExchangeRateParams) (sqlc.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExchangeRate", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExchangeRate indicates an expected call of GetExchangeRate.
+func (mr *MockStoreMockRecorder) GetExchangeRate(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExchangeRate", reflect.TypeOf((*MockStore)(nil).GetExchangeRate), ctx, arg)
+}
+
+// GetExpiredHoldForUpdate mocks base method.
+func (m *MockStore) GetExpiredHoldForUpdate(ctx context.Context, now time.Time) (sqlc.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredHoldForUpdate", ctx, now)
+	ret0, _ := ret[0].(sqlc.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredHoldForUpdate indicates an expected call of GetExpiredHoldForUpdate.
+func (mr *MockStoreMockRecorder) GetExpiredHoldForUpdate(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredHoldForUpdate", reflect.TypeOf((*MockStore)(nil).GetExpiredHoldForUpdate), ctx, now)
+}
+
+// GetExpiredTransferApprovalForUpdate mocks base method.
+func (m *MockStore) GetExpiredTransferApprovalForUpdate(ctx context.Context, now time.Time) (sqlc.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredTransferApprovalForUpdate", ctx, now)
+	ret0, _ := ret[0].(sqlc.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredTransferApprovalForUpdate indicates an expected call of GetExpiredTransferApprovalForUpdate.
+func (mr *MockStoreMockRecorder) GetExpiredTransferApprovalForUpdate(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredTransferApprovalForUpdate", reflect.TypeOf((*MockStore)(nil).GetExpiredTransferApprovalForUpdate), ctx, now)
+}
+
+// GetFeeForPeriod mocks base method.
+func (m *MockStore) GetFeeForPeriod(ctx context.Context, arg sqlc.GetFeeForPeriodParams) (sqlc.Fee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeForPeriod", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Fee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeForPeriod indicates an expected call of GetFeeForPeriod.
+func (mr *MockStoreMockRecorder) GetFeeForPeriod(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeForPeriod", reflect.TypeOf((*MockStore)(nil).GetFeeForPeriod), ctx, arg)
+}
+
+// GetFeeSchedule mocks base method.
+func (m *MockStore) GetFeeSchedule(ctx context.Context, arg sqlc.GetFeeScheduleParams) (sqlc.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeSchedule", ctx, arg)
+	ret0, _ := ret[0].(sqlc.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeSchedule indicates an expected call of GetFeeSchedule.
+func (mr *MockStoreMockRecorder) GetFeeSchedule(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeSchedule", reflect.TypeOf((*MockStore)(nil).GetFeeSchedule), ctx, arg)
+}
+
+// GetHold mocks base method.
+func (m *MockStore) GetHold(ctx context.Context, id int64) (sqlc.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, id)
+	ret0, _ := ret[0].(sqlc.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockStoreMockRecorder) GetHold(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockStore)(nil).GetHold), ctx, id)
+}
+
+// GetHoldForUpdate mocks base method.
+func (m *MockStore) GetHoldForUpdate(ctx context.Context, id int64) (sqlc.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldForUpdate", ctx, id)
+	ret0, _ := ret[0].(sqlc.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldForUpdate indicates an expected call of GetHoldForUpdate.
+func (mr *MockStoreMockRecorder) GetHoldForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldForUpdate", reflect.TypeOf((*MockStore)(nil).GetHoldForUpdate), ctx, id)
+}
+
+// GetKYCDocument mocks base method.
+func (m *MockStore) GetKYCDocument(ctx context.Context, id int64) (sqlc.KycDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCDocument", ctx, id)
+	ret0, _ := ret[0].(sqlc.KycDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCDocument indicates an expected call of GetKYCDocument.
+func (mr *MockStoreMockRecorder) GetKYCDocument(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCDocument", reflect.TypeOf((*MockStore)(nil).GetKYCDocument), ctx, id)
+}
+
+// GetKYCProfile mocks base method.
+func (m *MockStore) GetKYCProfile(ctx context.Context, username string) (sqlc.KycProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCProfile", ctx, username)
+	ret0, _ := ret[0].(sqlc.KycProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCProfile indicates an expected call of GetKYCProfile.
+func (mr *MockStoreMockRecorder) GetKYCProfile(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCProfile", reflect.TypeOf((*MockStore)(nil).GetKYCProfile), ctx, username)
+}
+
+// GetKYCTier mocks base method.
+func (m *MockStore) GetKYCTier(ctx context.Context, code string) (sqlc.KycTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCTier", ctx, code)
+	ret0, _ := ret[0].(sqlc.KycTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCTier indicates an expected call of GetKYCTier.
+func (mr *MockStoreMockRecorder) GetKYCTier(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCTier", reflect.TypeOf((*MockStore)(nil).GetKYCTier), ctx, code)
+}
+
+// GetKYCTierByAccount mocks base method.
+func (m *MockStore) GetKYCTierByAccount(ctx context.Context, id int64) (sqlc.KycTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCTierByAccount", ctx, id)
+	ret0, _ := ret[0].(sqlc.KycTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCTierByAccount indicates an expected call of GetKYCTierByAccount.
+func (mr *MockStoreMockRecorder) GetKYCTierByAccount(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCTierByAccount", reflect.TypeOf((*MockStore)(nil).GetKYCTierByAccount), ctx, id)
+}
+
+// GetLastInterestPosting mocks base method.
+func (m *MockStore) GetLastInterestPosting(ctx context.Context, accountID int64) (sqlc.InterestPosting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastInterestPosting", ctx, accountID)
+	ret0, _ := ret[0].(sqlc.InterestPosting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastInterestPosting indicates an expected call of GetLastInterestPosting.
+func (mr *MockStoreMockRecorder) GetLastInterestPosting(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastInterestPosting", reflect.TypeOf((*MockStore)(nil).GetLastInterestPosting), ctx, accountID)
+}
+
+// GetLatestDailyBalance mocks base method.
+func (m *MockStore) GetLatestDailyBalance(ctx context.Context, arg sqlc.GetLatestDailyBalanceParams) (sqlc.DailyBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestDailyBalance", ctx, arg)
+	ret0, _ := ret[0].(sqlc.DailyBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestDailyBalance indicates an expected call of GetLatestDailyBalance.
+func (mr *MockStoreMockRecorder) GetLatestDailyBalance(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestDailyBalance", reflect.TypeOf((*MockStore)(nil).GetLatestDailyBalance), ctx, arg)
+}
+
+// GetLatestDataKey mocks base method.
+func (m *MockStore) GetLatestDataKey(ctx context.Context) (sqlc.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestDataKey", ctx)
+	ret0, _ := ret[0].(sqlc.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestDataKey indicates an expected call of GetLatestDataKey.
+func (mr *MockStoreMockRecorder) GetLatestDataKey(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestDataKey", reflect.TypeOf((*MockStore)(nil).GetLatestDataKey), ctx)
+}
+
+// GetLatestVerifyEmail mocks base method.
+func (m *MockStore) GetLatestVerifyEmail(ctx context.Context, username string) (sqlc.VerifyEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestVerifyEmail", ctx, username)
+	ret0, _ := ret[0].(sqlc.VerifyEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestVerifyEmail indicates an expected call of GetLatestVerifyEmail.
+func (mr *MockStoreMockRecorder) GetLatestVerifyEmail(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestVerifyEmail", reflect.TypeOf((*MockStore)(nil).GetLatestVerifyEmail), ctx, username)
+}
+
+// GetPasswordResetTokenForUpdate mocks base method.
+func (m *MockStore) GetPasswordResetTokenForUpdate(ctx context.Context, tokenHash string) (sqlc.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetTokenForUpdate", ctx, tokenHash)
+	ret0, _ := ret[0].(sqlc.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetTokenForUpdate indicates an expected call of GetPasswordResetTokenForUpdate.
+func (mr *MockStoreMockRecorder) GetPasswordResetTokenForUpdate(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetTokenForUpdate", reflect.TypeOf((*MockStore)(nil).GetPasswordResetTokenForUpdate), ctx, tokenHash)
+}
+
+// GetRole mocks base method.
+func (m *MockStore) GetRole(ctx context.Context, name string) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRole", ctx, name)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRole indicates an expected call of GetRole.
+func (mr *MockStoreMockRecorder) GetRole(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRole", reflect.TypeOf((*MockStore)(nil).GetRole), ctx, name)
+}
+
+// GetRoleForUpdate mocks base method.
+func (m *MockStore) GetRoleForUpdate(ctx context.Context, name string) (sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleForUpdate", ctx, name)
+	ret0, _ := ret[0].(sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleForUpdate indicates an expected call of GetRoleForUpdate.
+func (mr *MockStoreMockRecorder) GetRoleForUpdate(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleForUpdate", reflect.TypeOf((*MockStore)(nil).GetRoleForUpdate), ctx, name)
+}
+
+// GetScheduledTransfer mocks base method.
+func (m *MockStore) GetScheduledTransfer(ctx context.Context, id int64) (sqlc.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransfer", ctx, id)
+	ret0, _ := ret[0].(sqlc.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransfer indicates an expected call of GetScheduledTransfer.
+func (mr *MockStoreMockRecorder) GetScheduledTransfer(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransfer", reflect.TypeOf((*MockStore)(nil).GetScheduledTransfer), ctx, id)
+}
+
+// GetScheduledTransferForUpdate mocks base method.
+func (m *MockStore) GetScheduledTransferForUpdate(ctx context.Context, id int64) (sqlc.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransferForUpdate", ctx, id)
+	ret0, _ := ret[0].(sqlc.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransferForUpdate indicates an expected call of GetScheduledTransferForUpdate.
+func (mr *MockStoreMockRecorder) GetScheduledTransferForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransferForUpdate", reflect.TypeOf((*MockStore)(nil).GetScheduledTransferForUpdate), ctx, id)
+}
+
+// GetSystemAccount mocks base method.
+func (m *MockStore) GetSystemAccount(ctx context.Context, arg sqlc.GetSystemAccountParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemAccount", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemAccount indicates an expected call of GetSystemAccount.
+func (mr *MockStoreMockRecorder) GetSystemAccount(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemAccount", reflect.TypeOf((*MockStore)(nil).GetSystemAccount), ctx, arg)
+}
+
+// GetTransfer mocks base method.
+func (m *MockStore) GetTransfer(ctx context.Context, id int64) (sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", ctx, id)
+	ret0, _ := ret[0].(sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockStoreMockRecorder) GetTransfer(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), ctx, id)
+}
+
+// GetTransferApproval mocks base method.
+func (m *MockStore) GetTransferApproval(ctx context.Context, transferID int64) (sqlc.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferApproval", ctx, transferID)
+	ret0, _ := ret[0].(sqlc.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferApproval indicates an expected call of GetTransferApproval.
+func (mr *MockStoreMockRecorder) GetTransferApproval(ctx, transferID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferApproval", reflect.TypeOf((*MockStore)(nil).GetTransferApproval), ctx, transferID)
+}
+
+// GetTransferApprovalForUpdate mocks base method.
+func (m *MockStore) GetTransferApprovalForUpdate(ctx context.Context, transferID int64) (sqlc.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferApprovalForUpdate", ctx, transferID)
+	ret0, _ := ret[0].(sqlc.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferApprovalForUpdate indicates an expected call of GetTransferApprovalForUpdate.
+func (mr *MockStoreMockRecorder) GetTransferApprovalForUpdate(ctx, transferID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferApprovalForUpdate", reflect.TypeOf((*MockStore)(nil).GetTransferApprovalForUpdate), ctx, transferID)
+}
+
+// GetTransferBatch mocks base method.
+func (m *MockStore) GetTransferBatch(ctx context.Context, id int64) (sqlc.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferBatch", ctx, id)
+	ret0, _ := ret[0].(sqlc.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferBatch indicates an expected call of GetTransferBatch.
+func (mr *MockStoreMockRecorder) GetTransferBatch(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferBatch", reflect.TypeOf((*MockStore)(nil).GetTransferBatch), ctx, id)
+}
+
+// GetTrialBalance mocks base method.
+func (m *MockStore) GetTrialBalance(ctx context.Context) ([]sqlc.GetTrialBalanceRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrialBalance", ctx)
+	ret0, _ := ret[0].([]sqlc.GetTrialBalanceRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrialBalance indicates an expected call of GetTrialBalance.
+func (mr *MockStoreMockRecorder) GetTrialBalance(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrialBalance", reflect.TypeOf((*MockStore)(nil).GetTrialBalance), ctx)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(ctx context.Context, username string) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, username)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), ctx, username)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStore) GetUserByEmail(ctx context.Context, email string) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStoreMockRecorder) GetUserByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), ctx, email)
+}
+
+// GetUserByEmailIndex mocks base method.
+func (m *MockStore) GetUserByEmailIndex(ctx context.Context, arg sqlc.GetUserByEmailIndexParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmailIndex", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmailIndex indicates an expected call of GetUserByEmailIndex.
+func (mr *MockStoreMockRecorder) GetUserByEmailIndex(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmailIndex", reflect.TypeOf((*MockStore)(nil).GetUserByEmailIndex), ctx, arg)
+}
+
+// GetUserForUpdate mocks base method.
+func (m *MockStore) GetUserForUpdate(ctx context.Context, username string) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserForUpdate", ctx, username)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserForUpdate indicates an expected call of GetUserForUpdate.
+func (mr *MockStoreMockRecorder) GetUserForUpdate(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserForUpdate", reflect.TypeOf((*MockStore)(nil).GetUserForUpdate), ctx, username)
+}
+
+// GetUserTOTP mocks base method.
+func (m *MockStore) GetUserTOTP(ctx context.Context, username string) (sqlc.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTP", ctx, username)
+	ret0, _ := ret[0].(sqlc.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTP indicates an expected call of GetUserTOTP.
+func (mr *MockStoreMockRecorder) GetUserTOTP(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTP", reflect.TypeOf((*MockStore)(nil).GetUserTOTP), ctx, username)
+}
+
+// GetUserTOTPForUpdate mocks base method.
+func (m *MockStore) GetUserTOTPForUpdate(ctx context.Context, username string) (sqlc.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTPForUpdate", ctx, username)
+	ret0, _ := ret[0].(sqlc.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTPForUpdate indicates an expected call of GetUserTOTPForUpdate.
+func (mr *MockStoreMockRecorder) GetUserTOTPForUpdate(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTPForUpdate", reflect.TypeOf((*MockStore)(nil).GetUserTOTPForUpdate), ctx, username)
+}
+
+// GetVerifyEmailForUpdate mocks base method.
+func (m *MockStore) GetVerifyEmailForUpdate(ctx context.Context, id int64) (sqlc.VerifyEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVerifyEmailForUpdate", ctx, id)
+	ret0, _ := ret[0].(sqlc.VerifyEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVerifyEmailForUpdate indicates an expected call of GetVerifyEmailForUpdate.
+func (mr *MockStoreMockRecorder) GetVerifyEmailForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVerifyEmailForUpdate", reflect.TypeOf((*MockStore)(nil).GetVerifyEmailForUpdate), ctx, id)
+}
+
+// InsertUser mocks base method.
+func (m *MockStore) InsertUser(ctx context.Context, arg sqlc.InsertUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertUser indicates an expected call of InsertUser.
+func (mr *MockStoreMockRecorder) InsertUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUser", reflect.TypeOf((*MockStore)(nil).InsertUser), ctx, arg)
+}
+
+// ListAMLAlerts mocks base method.
+func (m *MockStore) ListAMLAlerts(ctx context.Context, arg sqlc.ListAMLAlertsParams) ([]sqlc.AmlAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAMLAlerts", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.AmlAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAMLAlerts indicates an expected call of ListAMLAlerts.
+func (mr *MockStoreMockRecorder) ListAMLAlerts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAMLAlerts", reflect.TypeOf((*MockStore)(nil).ListAMLAlerts), ctx, arg)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockStore) ListAPIKeys(ctx context.Context, owner string) ([]sqlc.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, owner)
+	ret0, _ := ret[0].([]sqlc.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockStoreMockRecorder) ListAPIKeys(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockStore)(nil).ListAPIKeys), ctx, owner)
+}
+
+// ListAccountIDs mocks base method.
+func (m *MockStore) ListAccountIDs(ctx context.Context, arg sqlc.ListAccountIDsParams) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountIDs", ctx, arg)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountIDs indicates an expected call of ListAccountIDs.
+func (mr *MockStoreMockRecorder) ListAccountIDs(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountIDs", reflect.TypeOf((*MockStore)(nil).ListAccountIDs), ctx, arg)
+}
+
+// ListAccountIDsOpenedBefore mocks base method.
+func (m *MockStore) ListAccountIDsOpenedBefore(ctx context.Context, arg sqlc.ListAccountIDsOpenedBeforeParams) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountIDsOpenedBefore", ctx, arg)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountIDsOpenedBefore indicates an expected call of ListAccountIDsOpenedBefore.
+func (mr *MockStoreMockRecorder) ListAccountIDsOpenedBefore(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountIDsOpenedBefore", reflect.TypeOf((*MockStore)(nil).ListAccountIDsOpenedBefore), ctx, arg)
+}
+
+// ListAccountProducts mocks base method.
+func (m *MockStore) ListAccountProducts(ctx context.Context) ([]sqlc.AccountProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountProducts", ctx)
+	ret0, _ := ret[0].([]sqlc.AccountProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountProducts indicates an expected call of ListAccountProducts.
+func (mr *MockStoreMockRecorder) ListAccountProducts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountProducts", reflect.TypeOf((*MockStore)(nil).ListAccountProducts), ctx)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(ctx context.Context, arg sqlc.ListAccountsParams) ([]sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), ctx, arg)
+}
+
+// ListActiveHoldsByOwnerForUpdate mocks base method.
+func (m *MockStore) ListActiveHoldsByOwnerForUpdate(ctx context.Context, owner string) ([]sqlc.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveHoldsByOwnerForUpdate", ctx, owner)
+	ret0, _ := ret[0].([]sqlc.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveHoldsByOwnerForUpdate indicates an expected call of ListActiveHoldsByOwnerForUpdate.
+func (mr *MockStoreMockRecorder) ListActiveHoldsByOwnerForUpdate(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveHoldsByOwnerForUpdate", reflect.TypeOf((*MockStore)(nil).ListActiveHoldsByOwnerForUpdate), ctx, owner)
+}
+
+// ListActiveScheduledTransfersByOwnerForUpdate mocks base method.
+func (m *MockStore) ListActiveScheduledTransfersByOwnerForUpdate(ctx context.Context, owner string) ([]sqlc.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveScheduledTransfersByOwnerForUpdate", ctx, owner)
+	ret0, _ := ret[0].([]sqlc.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveScheduledTransfersByOwnerForUpdate indicates an expected call of ListActiveScheduledTransfersByOwnerForUpdate.
+func (mr *MockStoreMockRecorder) ListActiveScheduledTransfersByOwnerForUpdate(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveScheduledTransfersByOwnerForUpdate", reflect.TypeOf((*MockStore)(nil).ListActiveScheduledTransfersByOwnerForUpdate), ctx, owner)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockStore) ListAuditEvents(ctx context.Context, arg sqlc.ListAuditEventsParams) ([]sqlc.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockStoreMockRecorder) ListAuditEvents(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockStore)(nil).ListAuditEvents), ctx, arg)
+}
+
+// ListChartOfAccounts mocks base method.
+func (m *MockStore) ListChartOfAccounts(ctx context.Context) ([]sqlc.AccountProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChartOfAccounts", ctx)
+	ret0, _ := ret[0].([]sqlc.AccountProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChartOfAccounts indicates an expected call of ListChartOfAccounts.
+func (mr *MockStoreMockRecorder) ListChartOfAccounts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChartOfAccounts", reflect.TypeOf((*MockStore)(nil).ListChartOfAccounts), ctx)
+}
+
+// ListDataKeys mocks base method.
+func (m *MockStore) ListDataKeys(ctx context.Context) ([]sqlc.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDataKeys", ctx)
+	ret0, _ := ret[0].([]sqlc.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDataKeys indicates an expected call of ListDataKeys.
+func (mr *MockStoreMockRecorder) ListDataKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDataKeys", reflect.TypeOf((*MockStore)(nil).ListDataKeys), ctx)
+}
+
+// ListEntries mocks base method.
+func (m *MockStore) ListEntries(ctx context.Context, arg sqlc.ListEntriesParams) ([]sqlc.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockStoreMockRecorder) ListEntries(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), ctx, arg)
+}
+
+// ListEntryChain mocks base method.
+func (m *MockStore) ListEntryChain(ctx context.Context, arg sqlc.ListEntryChainParams) ([]sqlc.ListEntryChainRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntryChain", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.ListEntryChainRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntryChain indicates an expected call of ListEntryChain.
+func (mr *MockStoreMockRecorder) ListEntryChain(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntryChain", reflect.TypeOf((*MockStore)(nil).ListEntryChain), ctx, arg)
+}
+
+// ListExchangeRates mocks base method.
+func (m *MockStore) ListExchangeRates(ctx context.Context) ([]sqlc.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExchangeRates", ctx)
+	ret0, _ := ret[0].([]sqlc.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExchangeRates indicates an expected call of ListExchangeRates.
+func (mr *MockStoreMockRecorder) ListExchangeRates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExchangeRates", reflect.TypeOf((*MockStore)(nil).ListExchangeRates), ctx)
+}
+
+// ListFeeSchedules mocks base method.
+func (m *MockStore) ListFeeSchedules(ctx context.Context) ([]sqlc.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeeSchedules", ctx)
+	ret0, _ := ret[0].([]sqlc.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeeSchedules indicates an expected call of ListFeeSchedules.
+func (mr *MockStoreMockRecorder) ListFeeSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeeSchedules", reflect.TypeOf((*MockStore)(nil).ListFeeSchedules), ctx)
+}
+
+// ListHolds mocks base method.
+func (m *MockStore) ListHolds(ctx context.Context, arg sqlc.ListHoldsParams) ([]sqlc.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHolds", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHolds indicates an expected call of ListHolds.
+func (mr *MockStoreMockRecorder) ListHolds(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHolds", reflect.TypeOf((*MockStore)(nil).ListHolds), ctx, arg)
+}
+
+// ListInterestAccruals mocks base method.
+func (m *MockStore) ListInterestAccruals(ctx context.Context, arg sqlc.ListInterestAccrualsParams) ([]sqlc.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestAccruals", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestAccruals indicates an expected call of ListInterestAccruals.
+func (mr *MockStoreMockRecorder) ListInterestAccruals(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestAccruals", reflect.TypeOf((*MockStore)(nil).ListInterestAccruals), ctx, arg)
+}
+
+// ListInterestBearingAccounts mocks base method.
+func (m *MockStore) ListInterestBearingAccounts(ctx context.Context, arg sqlc.ListInterestBearingAccountsParams) ([]sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestBearingAccounts", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestBearingAccounts indicates an expected call of ListInterestBearingAccounts.
+func (mr *MockStoreMockRecorder) ListInterestBearingAccounts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestBearingAccounts", reflect.TypeOf((*MockStore)(nil).ListInterestBearingAccounts), ctx, arg)
+}
+
+// ListKYCDocuments mocks base method.
+func (m *MockStore) ListKYCDocuments(ctx context.Context, username string) ([]sqlc.KycDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKYCDocuments", ctx, username)
+	ret0, _ := ret[0].([]sqlc.KycDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKYCDocuments indicates an expected call of ListKYCDocuments.
+func (mr *MockStoreMockRecorder) ListKYCDocuments(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKYCDocuments", reflect.TypeOf((*MockStore)(nil).ListKYCDocuments), ctx, username)
+}
+
+// ListKYCTiers mocks base method.
+func (m *MockStore) ListKYCTiers(ctx context.Context) ([]sqlc.KycTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKYCTiers", ctx)
+	ret0, _ := ret[0].([]sqlc.KycTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKYCTiers indicates an expected call of ListKYCTiers.
+func (mr *MockStoreMockRecorder) ListKYCTiers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKYCTiers", reflect.TypeOf((*MockStore)(nil).ListKYCTiers), ctx)
+}
+
+// ListLoginAttempts mocks base method.
+func (m *MockStore) ListLoginAttempts(ctx context.Context, arg sqlc.ListLoginAttemptsParams) ([]sqlc.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoginAttempts", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoginAttempts indicates an expected call of ListLoginAttempts.
+func (mr *MockStoreMockRecorder) ListLoginAttempts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginAttempts", reflect.TypeOf((*MockStore)(nil).ListLoginAttempts), ctx, arg)
+}
+
+// ListMaintenanceFeeAccounts mocks base method.
+func (m *MockStore) ListMaintenanceFeeAccounts(ctx context.Context, arg sqlc.ListMaintenanceFeeAccountsParams) ([]sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMaintenanceFeeAccounts", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMaintenanceFeeAccounts indicates an expected call of ListMaintenanceFeeAccounts.
+func (mr *MockStoreMockRecorder) ListMaintenanceFeeAccounts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMaintenanceFeeAccounts", reflect.TypeOf((*MockStore)(nil).ListMaintenanceFeeAccounts), ctx, arg)
+}
+
+// ListNewCounterparties mocks base method.
+func (m *MockStore) ListNewCounterparties(ctx context.Context, arg sqlc.ListNewCounterpartiesParams) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNewCounterparties", ctx, arg)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNewCounterparties indicates an expected call of ListNewCounterparties.
+func (mr *MockStoreMockRecorder) ListNewCounterparties(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNewCounterparties", reflect.TypeOf((*MockStore)(nil).ListNewCounterparties), ctx, arg)
+}
+
+// ListOpenAccountIDsByOwner mocks base method.
+func (m *MockStore) ListOpenAccountIDsByOwner(ctx context.Context, owner string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenAccountIDsByOwner", ctx, owner)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenAccountIDsByOwner indicates an expected call of ListOpenAccountIDsByOwner.
+func (mr *MockStoreMockRecorder) ListOpenAccountIDsByOwner(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenAccountIDsByOwner", reflect.TypeOf((*MockStore)(nil).ListOpenAccountIDsByOwner), ctx, owner)
+}
+
+// ListPendingTransferApprovals mocks base method.
+func (m *MockStore) ListPendingTransferApprovals(ctx context.Context, arg sqlc.ListPendingTransferApprovalsParams) ([]sqlc.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingTransferApprovals", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingTransferApprovals indicates an expected call of ListPendingTransferApprovals.
+func (mr *MockStoreMockRecorder) ListPendingTransferApprovals(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingTransferApprovals", reflect.TypeOf((*MockStore)(nil).ListPendingTransferApprovals), ctx, arg)
+}
+
+// ListPendingTransferApprovalsByOwnerForUpdate mocks base method.
+func (m *MockStore) ListPendingTransferApprovalsByOwnerForUpdate(ctx context.Context, owner string) ([]sqlc.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingTransferApprovalsByOwnerForUpdate", ctx, owner)
+	ret0, _ := ret[0].([]sqlc.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingTransferApprovalsByOwnerForUpdate indicates an expected call of ListPendingTransferApprovalsByOwnerForUpdate.
+func (mr *MockStoreMockRecorder) ListPendingTransferApprovalsByOwnerForUpdate(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingTransferApprovalsByOwnerForUpdate", reflect.TypeOf((*MockStore)(nil).ListPendingTransferApprovalsByOwnerForUpdate), ctx, owner)
+}
+
+// ListPendingTransfersForUpdate mocks base method.
+func (m *MockStore) ListPendingTransfersForUpdate(ctx context.Context, limit int32) ([]sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingTransfersForUpdate", ctx, limit)
+	ret0, _ := ret[0].([]sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingTransfersForUpdate indicates an expected call of ListPendingTransfersForUpdate.
+func (mr *MockStoreMockRecorder) ListPendingTransfersForUpdate(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingTransfersForUpdate", reflect.TypeOf((*MockStore)(nil).ListPendingTransfersForUpdate), ctx, limit)
+}
+
+// ListPermissions mocks base method.
+func (m *MockStore) ListPermissions(ctx context.Context) ([]sqlc.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPermissions", ctx)
+	ret0, _ := ret[0].([]sqlc.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPermissions indicates an expected call of ListPermissions.
+func (mr *MockStoreMockRecorder) ListPermissions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPermissions", reflect.TypeOf((*MockStore)(nil).ListPermissions), ctx)
+}
+
+// ListQueuedAMLScreenings mocks base method.
+func (m *MockStore) ListQueuedAMLScreenings(ctx context.Context, arg sqlc.ListQueuedAMLScreeningsParams) ([]sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQueuedAMLScreenings", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListQueuedAMLScreenings indicates an expected call of ListQueuedAMLScreenings.
+func (mr *MockStoreMockRecorder) ListQueuedAMLScreenings(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQueuedAMLScreenings", reflect.TypeOf((*MockStore)(nil).ListQueuedAMLScreenings), ctx, arg)
+}
+
+// ListRolePermissions mocks base method.
+func (m *MockStore) ListRolePermissions(ctx context.Context) ([]sqlc.RolePermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRolePermissions", ctx)
+	ret0, _ := ret[0].([]sqlc.RolePermission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRolePermissions indicates an expected call of ListRolePermissions.
+func (mr *MockStoreMockRecorder) ListRolePermissions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRolePermissions", reflect.TypeOf((*MockStore)(nil).ListRolePermissions), ctx)
+}
+
+// ListRoles mocks base method.
+func (m *MockStore) ListRoles(ctx context.Context) ([]sqlc.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", ctx)
+	ret0, _ := ret[0].([]sqlc.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockStoreMockRecorder) ListRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockStore)(nil).ListRoles), ctx)
+}
+
+// ListScheduledTransferRuns mocks base method.
+func (m *MockStore) ListScheduledTransferRuns(ctx context.Context, arg sqlc.ListScheduledTransferRunsParams) ([]sqlc.ScheduledTransferRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledTransferRuns", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.ScheduledTransferRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledTransferRuns indicates an expected call of ListScheduledTransferRuns.
+func (mr *MockStoreMockRecorder) ListScheduledTransferRuns(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransferRuns", reflect.TypeOf((*MockStore)(nil).ListScheduledTransferRuns), ctx, arg)
+}
+
+// ListScheduledTransfers mocks base method.
+func (m *MockStore) ListScheduledTransfers(ctx context.Context, arg sqlc.ListScheduledTransfersParams) ([]sqlc.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledTransfers", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledTransfers indicates an expected call of ListScheduledTransfers.
+func (mr *MockStoreMockRecorder) ListScheduledTransfers(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransfers", reflect.TypeOf((*MockStore)(nil).ListScheduledTransfers), ctx, arg)
+}
+
+// ListTransferBatchItems mocks base method.
+func (m *MockStore) ListTransferBatchItems(ctx context.Context, batchID int64) ([]sqlc.TransferBatchItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransferBatchItems", ctx, batchID)
+	ret0, _ := ret[0].([]sqlc.TransferBatchItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransferBatchItems indicates an expected call of ListTransferBatchItems.
+func (mr *MockStoreMockRecorder) ListTransferBatchItems(ctx, batchID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferBatchItems", reflect.TypeOf((*MockStore)(nil).ListTransferBatchItems), ctx, batchID)
+}
+
+// ListTransferEntries mocks base method.
+func (m *MockStore) ListTransferEntries(ctx context.Context, transferID sql.NullInt64) ([]sqlc.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransferEntries", ctx, transferID)
+	ret0, _ := ret[0].([]sqlc.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransferEntries indicates an expected call of ListTransferEntries.
+func (mr *MockStoreMockRecorder) ListTransferEntries(ctx, transferID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferEntries", reflect.TypeOf((*MockStore)(nil).ListTransferEntries), ctx, transferID)
+}
+
+// ListTransferFees mocks base method.
+func (m *MockStore) ListTransferFees(ctx context.Context, chargedTransferID sql.NullInt64) ([]sqlc.Fee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransferFees", ctx, chargedTransferID)
+	ret0, _ := ret[0].([]sqlc.Fee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransferFees indicates an expected call of ListTransferFees.
+func (mr *MockStoreMockRecorder) ListTransferFees(ctx, chargedTransferID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferFees", reflect.TypeOf((*MockStore)(nil).ListTransferFees), ctx, chargedTransferID)
+}
+
+// ListTransfers mocks base method.
+func (m *MockStore) ListTransfers(ctx context.Context, arg sqlc.ListTransfersParams) ([]sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockStoreMockRecorder) ListTransfers(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), ctx, arg)
+}
+
+// ListUsersToReencrypt mocks base method.
+func (m *MockStore) ListUsersToReencrypt(ctx context.Context, arg sqlc.ListUsersToReencryptParams) ([]sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersToReencrypt", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersToReencrypt indicates an expected call of ListUsersToReencrypt.
+func (mr *MockStoreMockRecorder) ListUsersToReencrypt(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersToReencrypt", reflect.TypeOf((*MockStore)(nil).ListUsersToReencrypt), ctx, arg)
+}
+
+// MarkInterestAccrualsPosted mocks base method.
+func (m *MockStore) MarkInterestAccrualsPosted(ctx context.Context, arg sqlc.MarkInterestAccrualsPostedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkInterestAccrualsPosted", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkInterestAccrualsPosted indicates an expected call of MarkInterestAccrualsPosted.
+func (mr *MockStoreMockRecorder) MarkInterestAccrualsPosted(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkInterestAccrualsPosted", reflect.TypeOf((*MockStore)(nil).MarkInterestAccrualsPosted), ctx, arg)
+}
+
+// PendingTransferTx mocks base method.
+func (m *MockStore) PendingTransferTx(ctx context.Context, arg sqlc.TransferTxParams) (sqlc.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingTransferTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingTransferTx indicates an expected call of PendingTransferTx.
+func (mr *MockStoreMockRecorder) PendingTransferTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingTransferTx", reflect.TypeOf((*MockStore)(nil).PendingTransferTx), ctx, arg)
+}
+
+// PostInterestTx mocks base method.
+func (m *MockStore) PostInterestTx(ctx context.Context, arg sqlc.PostInterestTxParams) (sqlc.PostInterestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostInterestTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.PostInterestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostInterestTx indicates an expected call of PostInterestTx.
+func (mr *MockStoreMockRecorder) PostInterestTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostInterestTx", reflect.TypeOf((*MockStore)(nil).PostInterestTx), ctx, arg)
+}
+
+// RecordAMLScreeningFailure mocks base method.
+func (m *MockStore) RecordAMLScreeningFailure(ctx context.Context, arg sqlc.RecordAMLScreeningFailureParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAMLScreeningFailure", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAMLScreeningFailure indicates an expected call of RecordAMLScreeningFailure.
+func (mr *MockStoreMockRecorder) RecordAMLScreeningFailure(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAMLScreeningFailure", reflect.TypeOf((*MockStore)(nil).RecordAMLScreeningFailure), ctx, arg)
+}
+
+// RecordAMLScreeningTx mocks base method.
+func (m *MockStore) RecordAMLScreeningTx(ctx context.Context, arg sqlc.RecordAMLScreeningTxParams) ([]sqlc.AmlAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAMLScreeningTx", ctx, arg)
+	ret0, _ := ret[0].([]sqlc.AmlAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordAMLScreeningTx indicates an expected call of RecordAMLScreeningTx.
+func (mr *MockStoreMockRecorder) RecordAMLScreeningTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAMLScreeningTx", reflect.TypeOf((*MockStore)(nil).RecordAMLScreeningTx), ctx, arg)
+}
+
+// RedactUserAuditEvents mocks base method.
+func (m *MockStore) RedactUserAuditEvents(ctx context.Context, arg sqlc.RedactUserAuditEventsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedactUserAuditEvents", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedactUserAuditEvents indicates an expected call of RedactUserAuditEvents.
+func (mr *MockStoreMockRecorder) RedactUserAuditEvents(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedactUserAuditEvents", reflect.TypeOf((*MockStore)(nil).RedactUserAuditEvents), ctx, arg)
+}
+
+// ReencryptUsersTx mocks base method.
+func (m *MockStore) ReencryptUsersTx(ctx context.Context, batchSize int32) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReencryptUsersTx", ctx, batchSize)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReencryptUsersTx indicates an expected call of ReencryptUsersTx.
+func (mr *MockStoreMockRecorder) ReencryptUsersTx(ctx, batchSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReencryptUsersTx", reflect.TypeOf((*MockStore)(nil).ReencryptUsersTx), ctx, batchSize)
+}
+
+// RehashUserPassword mocks base method.
+func (m *MockStore) RehashUserPassword(ctx context.Context, arg sqlc.RehashUserPasswordParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashUserPassword", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RehashUserPassword indicates an expected call of RehashUserPassword.
+func (mr *MockStoreMockRecorder) RehashUserPassword(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashUserPassword", reflect.TypeOf((*MockStore)(nil).RehashUserPassword), ctx, arg)
+}
+
+// RejectTransferTx mocks base method.
+func (m *MockStore) RejectTransferTx(ctx context.Context, arg sqlc.ReviewTransferTxParams) (sqlc.TransferApprovalTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectTransferTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferApprovalTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectTransferTx indicates an expected call of RejectTransferTx.
+func (mr *MockStoreMockRecorder) RejectTransferTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectTransferTx", reflect.TypeOf((*MockStore)(nil).RejectTransferTx), ctx, arg)
+}
+
+// ReleaseHoldTx mocks base method.
+func (m *MockStore) ReleaseHoldTx(ctx context.Context, holdID int64) (sqlc.HoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHoldTx", ctx, holdID)
+	ret0, _ := ret[0].(sqlc.HoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHoldTx indicates an expected call of ReleaseHoldTx.
+func (mr *MockStoreMockRecorder) ReleaseHoldTx(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHoldTx", reflect.TypeOf((*MockStore)(nil).ReleaseHoldTx), ctx, holdID)
+}
+
+// RequestTransferApprovalTx mocks base method.
+func (m *MockStore) RequestTransferApprovalTx(ctx context.Context, arg sqlc.RequestTransferApprovalTxParams) (sqlc.TransferApprovalTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestTransferApprovalTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferApprovalTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestTransferApprovalTx indicates an expected call of RequestTransferApprovalTx.
+func (mr *MockStoreMockRecorder) RequestTransferApprovalTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestTransferApprovalTx", reflect.TypeOf((*MockStore)(nil).RequestTransferApprovalTx), ctx, arg)
+}
+
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(ctx context.Context, arg sqlc.ResetPasswordTxParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPasswordTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), ctx, arg)
+}
+
+// ReverseTransferTx mocks base method.
+func (m *MockStore) ReverseTransferTx(ctx context.Context, transferID int64) (sqlc.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransferTx", ctx, transferID)
+	ret0, _ := ret[0].(sqlc.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransferTx indicates an expected call of ReverseTransferTx.
+func (mr *MockStoreMockRecorder) ReverseTransferTx(ctx, transferID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransferTx", reflect.TypeOf((*MockStore)(nil).ReverseTransferTx), ctx, transferID)
+}
+
+// ReviewKYCProfile mocks base method.
+func (m *MockStore) ReviewKYCProfile(ctx context.Context, arg sqlc.ReviewKYCProfileParams) (sqlc.KycProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewKYCProfile", ctx, arg)
+	ret0, _ := ret[0].(sqlc.KycProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewKYCProfile indicates an expected call of ReviewKYCProfile.
+func (mr *MockStoreMockRecorder) ReviewKYCProfile(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewKYCProfile", reflect.TypeOf((*MockStore)(nil).ReviewKYCProfile), ctx, arg)
+}
+
+// ReviewKYCTx mocks base method.
+func (m *MockStore) ReviewKYCTx(ctx context.Context, arg sqlc.ReviewKYCTxParams) (sqlc.KYCProfileTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewKYCTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.KYCProfileTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewKYCTx indicates an expected call of ReviewKYCTx.
+func (mr *MockStoreMockRecorder) ReviewKYCTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewKYCTx", reflect.TypeOf((*MockStore)(nil).ReviewKYCTx), ctx, arg)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockStore) RevokeAPIKey(ctx context.Context, arg sqlc.RevokeAPIKeyParams) (sqlc.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockStoreMockRecorder) RevokeAPIKey(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockStore)(nil).RevokeAPIKey), ctx, arg)
+}
+
+// RevokeAPIKeysByOwner mocks base method.
+func (m *MockStore) RevokeAPIKeysByOwner(ctx context.Context, arg sqlc.RevokeAPIKeysByOwnerParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKeysByOwner", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKeysByOwner indicates an expected call of RevokeAPIKeysByOwner.
+func (mr *MockStoreMockRecorder) RevokeAPIKeysByOwner(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKeysByOwner", reflect.TypeOf((*MockStore)(nil).RevokeAPIKeysByOwner), ctx, arg)
+}
+
+// RewrapDataKey mocks base method.
+func (m *MockStore) RewrapDataKey(ctx context.Context, arg sqlc.RewrapDataKeyParams) (sqlc.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewrapDataKey", ctx, arg)
+	ret0, _ := ret[0].(sqlc.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RewrapDataKey indicates an expected call of RewrapDataKey.
+func (mr *MockStoreMockRecorder) RewrapDataKey(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewrapDataKey", reflect.TypeOf((*MockStore)(nil).RewrapDataKey), ctx, arg)
+}
+
+// RewrapDataKeys mocks base method.
+func (m *MockStore) RewrapDataKeys(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewrapDataKeys", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RewrapDataKeys indicates an expected call of RewrapDataKeys.
+func (mr *MockStoreMockRecorder) RewrapDataKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewrapDataKeys", reflect.TypeOf((*MockStore)(nil).RewrapDataKeys), ctx)
+}
+
+// RotateDataKey mocks base method.
+func (m *MockStore) RotateDataKey(ctx context.Context) (sqlc.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateDataKey", ctx)
+	ret0, _ := ret[0].(sqlc.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateDataKey indicates an expected call of RotateDataKey.
+func (mr *MockStoreMockRecorder) RotateDataKey(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateDataKey", reflect.TypeOf((*MockStore)(nil).RotateDataKey), ctx)
+}
+
+// SealVerifyEmails mocks base method.
+func (m *MockStore) SealVerifyEmails(ctx context.Context, arg sqlc.SealVerifyEmailsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SealVerifyEmails", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SealVerifyEmails indicates an expected call of SealVerifyEmails.
+func (mr *MockStoreMockRecorder) SealVerifyEmails(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SealVerifyEmails", reflect.TypeOf((*MockStore)(nil).SealVerifyEmails), ctx, arg)
+}
+
+// SetInterestPostingTransfer mocks base method.
+func (m *MockStore) SetInterestPostingTransfer(ctx context.Context, arg sqlc.SetInterestPostingTransferParams) (sqlc.InterestPosting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInterestPostingTransfer", ctx, arg)
+	ret0, _ := ret[0].(sqlc.InterestPosting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetInterestPostingTransfer indicates an expected call of SetInterestPostingTransfer.
+func (mr *MockStoreMockRecorder) SetInterestPostingTransfer(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInterestPostingTransfer", reflect.TypeOf((*MockStore)(nil).SetInterestPostingTransfer), ctx, arg)
+}
+
+// SetUserEmailVerified mocks base method.
+func (m *MockStore) SetUserEmailVerified(ctx context.Context, arg sqlc.SetUserEmailVerifiedParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserEmailVerified", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserEmailVerified indicates an expected call of SetUserEmailVerified.
+func (mr *MockStoreMockRecorder) SetUserEmailVerified(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserEmailVerified", reflect.TypeOf((*MockStore)(nil).SetUserEmailVerified), ctx, arg)
+}
+
+// SetUserKYC mocks base method.
+func (m *MockStore) SetUserKYC(ctx context.Context, arg sqlc.SetUserKYCParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserKYC", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserKYC indicates an expected call of SetUserKYC.
+func (mr *MockStoreMockRecorder) SetUserKYC(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserKYC", reflect.TypeOf((*MockStore)(nil).SetUserKYC), ctx, arg)
+}
+
+// SettleTransfersTx mocks base method.
+func (m *MockStore) SettleTransfersTx(ctx context.Context, arg sqlc.SettleTransfersTxParams) (sqlc.SettleTransfersTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SettleTransfersTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.SettleTransfersTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SettleTransfersTx indicates an expected call of SettleTransfersTx.
+func (mr *MockStoreMockRecorder) SettleTransfersTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettleTransfersTx", reflect.TypeOf((*MockStore)(nil).SettleTransfersTx), ctx, arg)
+}
+
+// StartUserTOTPEnrolment mocks base method.
+func (m *MockStore) StartUserTOTPEnrolment(ctx context.Context, arg sqlc.StartUserTOTPEnrolmentParams) (sqlc.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartUserTOTPEnrolment", ctx, arg)
+	ret0, _ := ret[0].(sqlc.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartUserTOTPEnrolment indicates an expected call of StartUserTOTPEnrolment.
+func (mr *MockStoreMockRecorder) StartUserTOTPEnrolment(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartUserTOTPEnrolment", reflect.TypeOf((*MockStore)(nil).StartUserTOTPEnrolment), ctx, arg)
+}
+
+// SubmitKYCProfileTx mocks base method.
+func (m *MockStore) SubmitKYCProfileTx(ctx context.Context, arg sqlc.SubmitKYCProfileTxParams) (sqlc.KYCProfileTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitKYCProfileTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.KYCProfileTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitKYCProfileTx indicates an expected call of SubmitKYCProfileTx.
+func (mr *MockStoreMockRecorder) SubmitKYCProfileTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitKYCProfileTx", reflect.TypeOf((*MockStore)(nil).SubmitKYCProfileTx), ctx, arg)
+}
+
+// SumAccountTransfersInWindow mocks base method.
+func (m *MockStore) SumAccountTransfersInWindow(ctx context.Context, arg sqlc.SumAccountTransfersInWindowParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumAccountTransfersInWindow", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumAccountTransfersInWindow indicates an expected call of SumAccountTransfersInWindow.
+func (mr *MockStoreMockRecorder) SumAccountTransfersInWindow(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumAccountTransfersInWindow", reflect.TypeOf((*MockStore)(nil).SumAccountTransfersInWindow), ctx, arg)
+}
+
+// SumEntriesBetween mocks base method.
+func (m *MockStore) SumEntriesBetween(ctx context.Context, arg sqlc.SumEntriesBetweenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumEntriesBetween", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumEntriesBetween indicates an expected call of SumEntriesBetween.
+func (mr *MockStoreMockRecorder) SumEntriesBetween(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesBetween", reflect.TypeOf((*MockStore)(nil).SumEntriesBetween), ctx, arg)
+}
+
+// SumTransfersSentSince mocks base method.
+func (m *MockStore) SumTransfersSentSince(ctx context.Context, arg sqlc.SumTransfersSentSinceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumTransfersSentSince", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumTransfersSentSince indicates an expected call of SumTransfersSentSince.
+func (mr *MockStoreMockRecorder) SumTransfersSentSince(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumTransfersSentSince", reflect.TypeOf((*MockStore)(nil).SumTransfersSentSince), ctx, arg)
+}
+
+// SumUnpostedInterest mocks base method.
+func (m *MockStore) SumUnpostedInterest(ctx context.Context, arg sqlc.SumUnpostedInterestParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumUnpostedInterest", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumUnpostedInterest indicates an expected call of SumUnpostedInterest.
+func (mr *MockStoreMockRecorder) SumUnpostedInterest(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumUnpostedInterest", reflect.TypeOf((*MockStore)(nil).SumUnpostedInterest), ctx, arg)
+}
+
+// TransferTx mocks base method.
+func (m *MockStore) TransferTx(ctx context.Context, arg sqlc.TransferTxParams) (sqlc.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferTx indicates an expected call of TransferTx.
+func (mr *MockStoreMockRecorder) TransferTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockStore)(nil).TransferTx), ctx, arg)
+}
+
+// UnlockUserLogin mocks base method.
+func (m *MockStore) UnlockUserLogin(ctx context.Context, arg sqlc.UnlockUserLoginParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUserLogin", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnlockUserLogin indicates an expected call of UnlockUserLogin.
+func (mr *MockStoreMockRecorder) UnlockUserLogin(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUserLogin", reflect.TypeOf((*MockStore)(nil).UnlockUserLogin), ctx, arg)
+}
+
+// UpdateAMLAlert mocks base method.
+func (m *MockStore) UpdateAMLAlert(ctx context.Context, arg sqlc.UpdateAMLAlertParams) (sqlc.AmlAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAMLAlert", ctx, arg)
+	ret0, _ := ret[0].(sqlc.AmlAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAMLAlert indicates an expected call of UpdateAMLAlert.
+func (mr *MockStoreMockRecorder) UpdateAMLAlert(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAMLAlert", reflect.TypeOf((*MockStore)(nil).UpdateAMLAlert), ctx, arg)
+}
+
+// UpdateAMLAlertTx mocks base method.
+func (m *MockStore) UpdateAMLAlertTx(ctx context.Context, arg sqlc.UpdateAMLAlertTxParams) (sqlc.AmlAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAMLAlertTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.AmlAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAMLAlertTx indicates an expected call of UpdateAMLAlertTx.
+func (mr *MockStoreMockRecorder) UpdateAMLAlertTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAMLAlertTx", reflect.TypeOf((*MockStore)(nil).UpdateAMLAlertTx), ctx, arg)
+}
+
+// UpdateAPIKeyLastUsed mocks base method.
+func (m *MockStore) UpdateAPIKeyLastUsed(ctx context.Context, arg sqlc.UpdateAPIKeyLastUsedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyLastUsed", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyLastUsed indicates an expected call of UpdateAPIKeyLastUsed.
+func (mr *MockStoreMockRecorder) UpdateAPIKeyLastUsed(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyLastUsed", reflect.TypeOf((*MockStore)(nil).UpdateAPIKeyLastUsed), ctx, arg)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockStore) UpdateAccount(ctx context.Context, arg sqlc.UpdateAccountParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockStoreMockRecorder) UpdateAccount(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), ctx, arg)
+}
+
+// UpdateAccountStatus mocks base method.
+func (m *MockStore) UpdateAccountStatus(ctx context.Context, arg sqlc.UpdateAccountStatusParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountStatus", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountStatus indicates an expected call of UpdateAccountStatus.
+func (mr *MockStoreMockRecorder) UpdateAccountStatus(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountStatus", reflect.TypeOf((*MockStore)(nil).UpdateAccountStatus), ctx, arg)
+}
+
+// UpdateHold mocks base method.
+func (m *MockStore) UpdateHold(ctx context.Context, arg sqlc.UpdateHoldParams) (sqlc.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHold", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHold indicates an expected call of UpdateHold.
+func (mr *MockStoreMockRecorder) UpdateHold(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHold", reflect.TypeOf((*MockStore)(nil).UpdateHold), ctx, arg)
+}
+
+// UpdateScheduledTransfer mocks base method.
+func (m *MockStore) UpdateScheduledTransfer(ctx context.Context, arg sqlc.UpdateScheduledTransferParams) (sqlc.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledTransfer", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduledTransfer indicates an expected call of UpdateScheduledTransfer.
+func (mr *MockStoreMockRecorder) UpdateScheduledTransfer(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledTransfer", reflect.TypeOf((*MockStore)(nil).UpdateScheduledTransfer), ctx, arg)
+}
+
+// UpdateScheduledTransferAfterRun mocks base method.
+func (m *MockStore) UpdateScheduledTransferAfterRun(ctx context.Context, arg sqlc.UpdateScheduledTransferAfterRunParams) (sqlc.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledTransferAfterRun", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduledTransferAfterRun indicates an expected call of UpdateScheduledTransferAfterRun.
+func (mr *MockStoreMockRecorder) UpdateScheduledTransferAfterRun(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledTransferAfterRun", reflect.TypeOf((*MockStore)(nil).UpdateScheduledTransferAfterRun), ctx, arg)
+}
+
+// UpdateTransferApproval mocks base method.
+func (m *MockStore) UpdateTransferApproval(ctx context.Context, arg sqlc.UpdateTransferApprovalParams) (sqlc.TransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTransferApproval", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTransferApproval indicates an expected call of UpdateTransferApproval.
+func (mr *MockStoreMockRecorder) UpdateTransferApproval(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTransferApproval", reflect.TypeOf((*MockStore)(nil).UpdateTransferApproval), ctx, arg)
+}
+
+// UpdateTransferStatus mocks base method.
+func (m *MockStore) UpdateTransferStatus(ctx context.Context, arg sqlc.UpdateTransferStatusParams) (sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTransferStatus", ctx, arg)
+	ret0, _ := ret[0].(sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTransferStatus indicates an expected call of UpdateTransferStatus.
+func (mr *MockStoreMockRecorder) UpdateTransferStatus(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTransferStatus", reflect.TypeOf((*MockStore)(nil).UpdateTransferStatus), ctx, arg)
+}
+
+// UpdateUser mocks base method.
+func (m *MockStore) UpdateUser(ctx context.Context, arg sqlc.UpdateUserParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockStoreMockRecorder) UpdateUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), ctx, arg)
+}
+
+// UpdateUserPII mocks base method.
+func (m *MockStore) UpdateUserPII(ctx context.Context, arg sqlc.UpdateUserPIIParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPII", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserPII indicates an expected call of UpdateUserPII.
+func (mr *MockStoreMockRecorder) UpdateUserPII(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPII", reflect.TypeOf((*MockStore)(nil).UpdateUserPII), ctx, arg)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockStore) UpdateUserPassword(ctx context.Context, arg sqlc.UpdateUserPasswordParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockStoreMockRecorder) UpdateUserPassword(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), ctx, arg)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockStore) UpdateUserRole(ctx context.Context, arg sqlc.UpdateUserRoleParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStoreMockRecorder) UpdateUserRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStore)(nil).UpdateUserRole), ctx, arg)
+}
+
+// UpsertExchangeRate mocks base method.
+func (m *MockStore) UpsertExchangeRate(ctx context.Context, arg sqlc.UpsertExchangeRateParams) (sqlc.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertExchangeRate", ctx, arg)
+	ret0, _ := ret[0].(sqlc.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertExchangeRate indicates an expected call of UpsertExchangeRate.
+func (mr *MockStoreMockRecorder) UpsertExchangeRate(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertExchangeRate", reflect.TypeOf((*MockStore)(nil).UpsertExchangeRate), ctx, arg)
+}
+
+// UpsertFeeSchedule mocks base method.
+func (m *MockStore) UpsertFeeSchedule(ctx context.Context, arg sqlc.UpsertFeeScheduleParams) (sqlc.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertFeeSchedule", ctx, arg)
+	ret0, _ := ret[0].(sqlc.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertFeeSchedule indicates an expected call of UpsertFeeSchedule.
+func (mr *MockStoreMockRecorder) UpsertFeeSchedule(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertFeeSchedule", reflect.TypeOf((*MockStore)(nil).UpsertFeeSchedule), ctx, arg)
+}
+
+// UpsertKYCProfile mocks base method.
+func (m *MockStore) UpsertKYCProfile(ctx context.Context, arg sqlc.UpsertKYCProfileParams) (sqlc.KycProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertKYCProfile", ctx, arg)
+	ret0, _ := ret[0].(sqlc.KycProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertKYCProfile indicates an expected call of UpsertKYCProfile.
+func (mr *MockStoreMockRecorder) UpsertKYCProfile(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertKYCProfile", reflect.TypeOf((*MockStore)(nil).UpsertKYCProfile), ctx, arg)
+}
+
+// UsePasswordResetTokens mocks base method.
+func (m *MockStore) UsePasswordResetTokens(ctx context.Context, arg sqlc.UsePasswordResetTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordResetTokens", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UsePasswordResetTokens indicates an expected call of UsePasswordResetTokens.
+func (mr *MockStoreMockRecorder) UsePasswordResetTokens(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordResetTokens", reflect.TypeOf((*MockStore)(nil).UsePasswordResetTokens), ctx, arg)
+}
+
+// UseTOTPRecoveryCode mocks base method.
+func (m *MockStore) UseTOTPRecoveryCode(ctx context.Context, arg sqlc.UseTOTPRecoveryCodeParams) (sqlc.TotpRecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPRecoveryCode", ctx, arg)
+	ret0, _ := ret[0].(sqlc.TotpRecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPRecoveryCode indicates an expected call of UseTOTPRecoveryCode.
+func (mr *MockStoreMockRecorder) UseTOTPRecoveryCode(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseTOTPRecoveryCode), ctx, arg)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockStore) UseTOTPStep(ctx context.Context, arg sqlc.UseTOTPStepParams) (sqlc.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, arg)
+	ret0, _ := ret[0].(sqlc.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockStoreMockRecorder) UseTOTPStep(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockStore)(nil).UseTOTPStep), ctx, arg)
+}
+
+// UseVerifyEmail mocks base method.
+func (m *MockStore) UseVerifyEmail(ctx context.Context, arg sqlc.UseVerifyEmailParams) (sqlc.VerifyEmail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseVerifyEmail", ctx, arg)
+	ret0, _ := ret[0].(sqlc.VerifyEmail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseVerifyEmail indicates an expected call of UseVerifyEmail.
+func (mr *MockStoreMockRecorder) UseVerifyEmail(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseVerifyEmail", reflect.TypeOf((*MockStore)(nil).UseVerifyEmail), ctx, arg)
+}
+
+// VerifyEmailTx mocks base method.
+func (m *MockStore) VerifyEmailTx(ctx context.Context, arg sqlc.VerifyEmailTxParams) (sqlc.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmailTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmailTx indicates an expected call of VerifyEmailTx.
+func (mr *MockStoreMockRecorder) VerifyEmailTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailTx", reflect.TypeOf((*MockStore)(nil).VerifyEmailTx), ctx, arg)
+}
+
+// WithdrawTx mocks base method.
+func (m *MockStore) WithdrawTx(ctx context.Context, arg sqlc.WithdrawTxParams) (sqlc.WithdrawTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawTx", ctx, arg)
+	ret0, _ := ret[0].(sqlc.WithdrawTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawTx indicates an expected call of WithdrawTx.
+func (mr *MockStoreMockRecorder) WithdrawTx(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawTx", reflect.TypeOf((*MockStore)(nil).WithdrawTx), ctx, arg)
 }
@@ -2,8 +2,10 @@
 INSERT INTO accounts (
     owner,
     balance,
-    currency
-) VALUES ($1, $2, $3) RETURNING *;
+    currency,
+    product_id,
+    overdraft_limit
+) VALUES ($1, $2, $3, $4, $5) RETURNING *;
 
 -- name: GetAccount :one
 SELECT * FROM accounts
@@ -16,9 +18,10 @@ FOR NO KEY UPDATE;
 
 -- name: ListAccounts :many
 SELECT * FROM accounts
+WHERE sqlc.narg(owner)::varchar IS NULL OR owner = sqlc.narg(owner)
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -32,6 +35,60 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: CloseAccount :one
+UPDATE accounts
+SET status = 'closed', closed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CountOpenAccountsByProduct :one
+SELECT count(*) FROM accounts
+WHERE owner = $1 AND product_id = $2 AND status <> 'closed';
+
+-- name: GetSystemAccount :one
+SELECT a.* FROM accounts a
+JOIN account_products p ON p.id = a.product_id
+WHERE a.owner = 'system' AND p.code = sqlc.arg(product_code) AND a.currency = sqlc.arg(currency)
+LIMIT 1;
+
+-- name: CreateSystemAccount :one
+INSERT INTO accounts (
+    owner,
+    balance,
+    currency,
+    product_id
+) VALUES ('system', 0, $1, $2)
+ON CONFLICT ON CONSTRAINT owner_product_currency_key DO NOTHING
+RETURNING *;
+
+-- name: GetTrialBalance :many
+SELECT a.currency, p.ledger_class, p.code AS product_code,
+    COUNT(a.id)::bigint AS accounts,
+    SUM(a.balance)::bigint AS balance
+FROM accounts a
+JOIN account_products p ON p.id = a.product_id
+GROUP BY a.currency, p.ledger_class, p.code
+ORDER BY a.currency, p.ledger_class, p.code;
+
+-- name: ListAccountIDs :many
+SELECT id FROM accounts
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: ListOpenAccountIDsByOwner :many
+SELECT id FROM accounts
+WHERE owner = $1 AND status <> 'closed'
+ORDER BY id;
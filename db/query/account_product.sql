@@ -0,0 +1,16 @@
+-- name: GetAccountProduct :one
+SELECT * FROM account_products
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAccountProductByCode :one
+SELECT * FROM account_products
+WHERE code = $1 LIMIT 1;
+
+-- name: ListAccountProducts :many
+SELECT * FROM account_products
+WHERE internal = false
+ORDER BY id;
+
+-- name: ListChartOfAccounts :many
+SELECT * FROM account_products
+ORDER BY ledger_class, code;
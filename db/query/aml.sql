@@ -0,0 +1,108 @@
+-- name: ListQueuedAMLScreenings :many
+-- Posted transfers waiting to be screened by the AML monitor, oldest first, after after_id.
+-- Transfers whose screening failed max_attempts times are left out.
+SELECT t.* FROM aml_screening_queue q
+JOIN transfers t ON t.id = q.transfer_id
+WHERE q.transfer_id > sqlc.arg(after_id)::bigint
+  AND q.attempts < sqlc.arg(max_attempts)::integer
+ORDER BY q.transfer_id
+LIMIT sqlc.arg('limit');
+
+-- name: RecordAMLScreeningFailure :exec
+-- Keeps a transfer queued after its screening failed, counting the attempt.
+UPDATE aml_screening_queue
+SET attempts = attempts + 1,
+    last_error = sqlc.arg(last_error)
+WHERE transfer_id = sqlc.arg(transfer_id);
+
+-- name: DeleteAMLScreening :execrows
+DELETE FROM aml_screening_queue
+WHERE transfer_id = $1;
+
+-- name: GetAMLAccount :one
+-- An account with whether it is one of the bank's own ledger accounts, which are not monitored.
+SELECT a.id, a.owner, a.currency, p.internal FROM accounts a
+JOIN account_products p ON p.id = a.product_id
+WHERE a.id = $1 LIMIT 1;
+
+-- name: CountOwnerTransfersInRange :one
+-- Posted transfers sent (or received) by the accounts of a user in a currency in a time window, with an amount
+-- in [min_amount, max_amount). Amounts are only comparable within a currency.
+SELECT count(*) FROM transfers t
+JOIN accounts a ON a.id = CASE WHEN sqlc.arg(sent)::boolean THEN t.from_account_id ELSE t.to_account_id END
+WHERE a.owner = sqlc.arg(owner)
+  AND a.currency = sqlc.arg(currency)
+  AND t.status = 'posted'
+  AND t.created_at > sqlc.arg(since)
+  AND t.created_at <= sqlc.arg(until)
+  AND t.amount >= sqlc.arg(min_amount)
+  AND t.amount < sqlc.arg(max_amount);
+
+-- name: SumAccountTransfersInWindow :one
+-- The total of the posted transfers sent (or received) by an account in a currency in a time window.
+SELECT COALESCE(SUM(t.amount), 0)::bigint AS total FROM transfers t
+JOIN accounts a ON a.id = CASE WHEN sqlc.arg(sent)::boolean THEN t.from_account_id ELSE t.to_account_id END
+WHERE a.id = sqlc.arg(account_id)::bigint
+  AND a.currency = sqlc.arg(currency)
+  AND t.status = 'posted'
+  AND t.created_at > sqlc.arg(since)
+  AND t.created_at <= sqlc.arg(until);
+
+-- name: ListNewCounterparties :many
+-- Customer accounts of other users that a user first sent money to in a time window.
+SELECT t.to_account_id FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+JOIN accounts r ON r.id = t.to_account_id
+JOIN account_products p ON p.id = r.product_id
+WHERE a.owner = sqlc.arg(owner)
+  AND r.owner <> sqlc.arg(owner)
+  AND NOT p.internal
+  AND t.status IN ('posted', 'reversed')
+  AND t.created_at <= sqlc.arg(until)
+GROUP BY t.to_account_id
+HAVING min(t.created_at) > sqlc.arg(since)::timestamptz
+ORDER BY t.to_account_id;
+
+-- name: CreateAMLAlert :one
+-- A rule raises at most one alert per transfer, so screening a transfer again adds nothing.
+INSERT INTO aml_alerts (
+    transfer_id,
+    account_id,
+    owner,
+    rule,
+    severity,
+    details
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (transfer_id, rule) DO NOTHING
+RETURNING *;
+
+-- name: GetAMLAlert :one
+SELECT * FROM aml_alerts
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAMLAlertForUpdate :one
+SELECT * FROM aml_alerts
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListAMLAlerts :many
+SELECT * FROM aml_alerts
+WHERE id > sqlc.arg(after_id)
+AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+AND (sqlc.narg(rule)::varchar IS NULL OR rule = sqlc.narg(rule))
+AND (sqlc.narg(owner)::varchar IS NULL OR owner = sqlc.narg(owner))
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateAMLAlert :one
+UPDATE aml_alerts
+SET status = sqlc.arg(status),
+    assigned_to = sqlc.narg(assigned_to),
+    resolution = sqlc.arg(resolution),
+    notes = sqlc.arg(notes),
+    closed_at = sqlc.narg(closed_at),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,44 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+  owner,
+  name,
+  prefix,
+  secret_hash,
+  scopes,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT * FROM api_keys
+WHERE prefix = $1 LIMIT 1;
+
+-- name: GetAPIKeyForUpdate :one
+SELECT * FROM api_keys
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE owner = $1
+ORDER BY id;
+
+-- name: RevokeAPIKey :one
+-- Revokes a key of the owner, which has no rows when the key is not theirs or was already revoked.
+UPDATE api_keys
+SET revoked_at = sqlc.arg(revoked_at)
+WHERE id = sqlc.arg(id)
+  AND owner = sqlc.arg(owner)
+  AND revoked_at IS NULL
+RETURNING *;
+
+-- name: UpdateAPIKeyLastUsed :exec
+UPDATE api_keys
+SET last_used_at = sqlc.arg(last_used_at)
+WHERE id = sqlc.arg(id);
+
+-- name: RevokeAPIKeysByOwner :exec
+UPDATE api_keys
+SET revoked_at = sqlc.arg(revoked_at)
+WHERE owner = sqlc.arg(owner) AND revoked_at IS NULL;
@@ -0,0 +1,30 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+    actor,
+    actor_role,
+    action,
+    resource_type,
+    resource_id,
+    before,
+    after,
+    request_id,
+    remote_addr
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE id > sqlc.arg(after_id)
+AND (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+AND (sqlc.narg(resource_type)::varchar IS NULL OR resource_type = sqlc.narg(resource_type))
+AND (sqlc.narg(resource_id)::varchar IS NULL OR resource_id = sqlc.narg(resource_id))
+AND (sqlc.narg(request_id)::varchar IS NULL OR request_id = sqlc.narg(request_id))
+AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: RedactUserAuditEvents :exec
+SELECT redact_user_audit_events(sqlc.arg(username)::varchar, sqlc.arg(full_name)::varchar, sqlc.arg(email)::varchar);
@@ -0,0 +1,44 @@
+-- name: ListAccountIDsOpenedBefore :many
+SELECT id FROM accounts
+WHERE created_at < sqlc.arg(opened_before)
+AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CreateDailyBalance :one
+INSERT INTO daily_balances (
+    account_id,
+    balance_date,
+    balance
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (account_id, balance_date) DO NOTHING
+RETURNING *;
+
+-- name: GetLatestDailyBalance :one
+SELECT * FROM daily_balances
+WHERE account_id = sqlc.arg(account_id)
+AND balance_date <= sqlc.arg(through_date)
+ORDER BY balance_date DESC
+LIMIT 1;
+
+-- name: SumEntriesBetween :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS amount
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+AND created_at >= sqlc.arg(from_time)
+AND created_at < sqlc.arg(to_time);
+
+-- name: GetEndOfDayReport :many
+SELECT
+    a.currency,
+    COUNT(*)::bigint AS accounts,
+    COALESCE(SUM(GREATEST(d.balance, 0)), 0)::bigint AS credit,
+    COALESCE(SUM(LEAST(d.balance, 0)), 0)::bigint AS debit,
+    COALESCE(SUM(d.balance), 0)::bigint AS balance
+FROM daily_balances d
+JOIN accounts a ON a.id = d.account_id
+WHERE d.balance_date = sqlc.arg(balance_date)
+GROUP BY a.currency
+ORDER BY a.currency;
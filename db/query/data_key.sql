@@ -0,0 +1,28 @@
+-- name: CreateDataKey :one
+INSERT INTO data_keys (
+  wrapped_key,
+  master_key_id
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: GetDataKey :one
+SELECT * FROM data_keys
+WHERE id = $1 LIMIT 1;
+
+-- name: GetLatestDataKey :one
+-- The newest data key is the active one, which seals new data.
+SELECT * FROM data_keys
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListDataKeys :many
+SELECT * FROM data_keys
+ORDER BY id;
+
+-- name: RewrapDataKey :one
+UPDATE data_keys
+SET wrapped_key = sqlc.arg(wrapped_key),
+  master_key_id = sqlc.arg(master_key_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -1,9 +1,10 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +16,16 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+-- name: ListTransferEntries :many
+SELECT * FROM entries
+WHERE transfer_id = $1
+ORDER BY id;
+
+-- name: ListEntryChain :many
+SELECT id, account_id, prev_hash, hash,
+    entry_hash(id, account_id, amount, transfer_id, created_at, prev_hash)::bytea AS expected_hash
+FROM entries
+WHERE account_id = sqlc.arg(account_id) AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,34 @@
+-- name: GetExchangeRate :one
+SELECT * FROM exchange_rates
+WHERE base_currency = $1 AND quote_currency = $2 LIMIT 1;
+
+-- name: ListExchangeRates :many
+SELECT * FROM exchange_rates
+ORDER BY base_currency, quote_currency;
+
+-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (
+    base_currency,
+    quote_currency,
+    rate
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (base_currency, quote_currency) DO UPDATE
+SET rate = EXCLUDED.rate,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteExchangeRate :one
+DELETE FROM exchange_rates
+WHERE base_currency = $1 AND quote_currency = $2
+RETURNING *;
+
+-- name: CreateConversion :one
+INSERT INTO conversions (
+    from_transfer_id,
+    to_transfer_id,
+    rate
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
@@ -0,0 +1,65 @@
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE fee_type = $1 AND currency = $2 LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY fee_type, currency;
+
+-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (
+    fee_type,
+    currency,
+    method,
+    flat_amount,
+    rate_bps,
+    min_fee,
+    max_fee
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (fee_type, currency) DO UPDATE
+SET method = EXCLUDED.method,
+    flat_amount = EXCLUDED.flat_amount,
+    rate_bps = EXCLUDED.rate_bps,
+    min_fee = EXCLUDED.min_fee,
+    max_fee = EXCLUDED.max_fee,
+    updated_at = now()
+RETURNING *;
+
+-- name: CreateFee :one
+INSERT INTO fees (
+    account_id,
+    fee_type,
+    amount,
+    charged_transfer_id,
+    period_end,
+    transfer_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetFeeForPeriod :one
+SELECT * FROM fees
+WHERE account_id = $1 AND fee_type = $2 AND period_end = $3
+LIMIT 1;
+
+-- name: ListTransferFees :many
+SELECT * FROM fees
+WHERE charged_transfer_id = $1
+ORDER BY id;
+
+-- name: ListMaintenanceFeeAccounts :many
+SELECT a.* FROM accounts a
+JOIN account_products p ON p.id = a.product_id
+WHERE p.monthly_fee > 0
+AND a.status = 'active'
+AND a.created_at < sqlc.arg(opened_before)
+AND a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteFeeSchedule :one
+DELETE FROM fee_schedules
+WHERE fee_type = $1 AND currency = $2
+RETURNING *;
@@ -0,0 +1,48 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    account_id,
+    amount,
+    description,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetExpiredHoldForUpdate :one
+SELECT * FROM holds
+WHERE status = 'active' AND expires_at <= sqlc.arg(now)
+ORDER BY expires_at
+LIMIT 1
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: ListHolds :many
+SELECT * FROM holds
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateHold :one
+UPDATE holds
+SET
+    status = sqlc.arg(status),
+    captured_amount = sqlc.arg(captured_amount),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListActiveHoldsByOwnerForUpdate :many
+SELECT * FROM holds
+WHERE status = 'active'
+  AND account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY id
+FOR NO KEY UPDATE;
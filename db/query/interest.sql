@@ -0,0 +1,76 @@
+-- name: ListInterestBearingAccounts :many
+SELECT a.* FROM accounts a
+JOIN account_products p ON p.id = a.product_id
+WHERE p.interest_rate_bps > 0
+AND a.status <> 'closed'
+AND a.created_at < sqlc.arg(opened_before)
+AND a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg('limit');
+
+-- name: GetAccountBalanceAt :one
+SELECT (a.balance - COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= sqlc.arg(at)
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id;
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    principal_micros,
+    rate_bps,
+    interest_method,
+    day_count,
+    amount_micros
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (account_id, accrual_date) DO NOTHING
+RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+AND accrual_date >= sqlc.arg(from_date)
+AND accrual_date <= sqlc.arg(to_date)
+ORDER BY accrual_date;
+
+-- name: SumUnpostedInterest :one
+SELECT COALESCE(SUM(amount_micros), 0)::bigint AS amount_micros FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+AND posting_id IS NULL
+AND accrual_date <= sqlc.arg(through);
+
+-- name: MarkInterestAccrualsPosted :exec
+UPDATE interest_accruals
+SET posting_id = sqlc.arg(posting_id)
+WHERE account_id = sqlc.arg(account_id)
+AND posting_id IS NULL
+AND accrual_date <= sqlc.arg(through);
+
+-- name: GetLastInterestPosting :one
+SELECT * FROM interest_postings
+WHERE account_id = $1
+ORDER BY period_end DESC
+LIMIT 1;
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+    account_id,
+    period_end,
+    accrued_micros,
+    amount,
+    remainder_micros
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, period_end) DO NOTHING
+RETURNING *;
+
+-- name: SetInterestPostingTransfer :one
+UPDATE interest_postings
+SET transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,82 @@
+-- name: GetKYCTier :one
+SELECT * FROM kyc_tiers
+WHERE code = $1 LIMIT 1;
+
+-- name: ListKYCTiers :many
+SELECT * FROM kyc_tiers
+ORDER BY code;
+
+-- name: GetKYCTierByAccount :one
+-- The tier of the owner of an account, whose limits apply to it.
+SELECT t.* FROM kyc_tiers t
+JOIN users u ON u.kyc_tier = t.code
+JOIN accounts a ON a.owner = u.username
+WHERE a.id = $1 LIMIT 1;
+
+-- name: SumTransfersSentSince :one
+-- The total a user sent from their accounts since a time, leaving out transfers that gave the money back.
+-- Transfers to the bank's own accounts, such as withdrawals and fees, count too.
+SELECT COALESCE(SUM(t.amount), 0)::bigint AS total FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND t.created_at >= sqlc.arg(since)
+  AND t.status NOT IN ('failed', 'reversed', 'cancelled');
+
+-- name: UpsertKYCProfile :one
+-- Submitting the profile again replaces it and clears the previous review.
+INSERT INTO kyc_profiles (
+  username,
+  date_of_birth,
+  address_line1,
+  address_line2,
+  city,
+  postal_code,
+  country
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (username) DO UPDATE
+SET date_of_birth = EXCLUDED.date_of_birth,
+  address_line1 = EXCLUDED.address_line1,
+  address_line2 = EXCLUDED.address_line2,
+  city = EXCLUDED.city,
+  postal_code = EXCLUDED.postal_code,
+  country = EXCLUDED.country,
+  submitted_at = now(),
+  reviewed_by = NULL,
+  reviewed_at = NULL,
+  rejection_reason = ''
+RETURNING *;
+
+-- name: GetKYCProfile :one
+SELECT * FROM kyc_profiles
+WHERE username = $1 LIMIT 1;
+
+-- name: ReviewKYCProfile :one
+UPDATE kyc_profiles
+SET reviewed_by = sqlc.arg(reviewed_by),
+  reviewed_at = sqlc.arg(reviewed_at),
+  rejection_reason = sqlc.arg(rejection_reason)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: CreateKYCDocument :one
+INSERT INTO kyc_documents (
+  username,
+  kind,
+  blob_key,
+  content_type,
+  size,
+  sha256
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetKYCDocument :one
+SELECT * FROM kyc_documents
+WHERE id = $1 LIMIT 1;
+
+-- name: ListKYCDocuments :many
+SELECT * FROM kyc_documents
+WHERE username = $1
+ORDER BY id;
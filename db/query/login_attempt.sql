@@ -0,0 +1,37 @@
+-- name: CreateLoginAttempt :one
+INSERT INTO login_attempts (
+  username,
+  client_ip,
+  user_agent,
+  success
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: CountRecentLoginFailures :one
+-- Failed logins of a username since a time, not counting those before its last successful login.
+SELECT count(*) FROM login_attempts
+WHERE username = sqlc.arg(username)
+  AND NOT success
+  AND created_at > sqlc.arg(since)
+  AND created_at > COALESCE((
+    SELECT max(created_at) FROM login_attempts
+    WHERE username = sqlc.arg(username) AND success
+  ), '-infinity');
+
+-- name: CountRecentLoginFailuresByIP :one
+SELECT count(*) FROM login_attempts
+WHERE client_ip = sqlc.arg(client_ip)
+  AND NOT success
+  AND created_at > sqlc.arg(since);
+
+-- name: ListLoginAttempts :many
+SELECT * FROM login_attempts
+WHERE username = sqlc.arg(username)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: DeleteLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE username = $1;
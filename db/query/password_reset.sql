@@ -0,0 +1,22 @@
+-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens (
+  username,
+  token_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetPasswordResetTokenForUpdate :one
+SELECT * FROM password_reset_tokens
+WHERE token_hash = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UsePasswordResetTokens :exec
+UPDATE password_reset_tokens
+SET used_at = sqlc.arg(used_at)
+WHERE username = sqlc.arg(username) AND used_at IS NULL;
+
+-- name: DeletePasswordResetTokens :exec
+DELETE FROM password_reset_tokens
+WHERE username = $1;
@@ -0,0 +1,30 @@
+-- name: ListRoles :many
+SELECT * FROM roles
+ORDER BY name;
+
+-- name: GetRole :one
+SELECT * FROM roles
+WHERE name = $1 LIMIT 1;
+
+-- name: GetRoleForUpdate :one
+SELECT * FROM roles
+WHERE name = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListRolePermissions :many
+SELECT * FROM role_permissions
+ORDER BY role, permission;
+
+-- name: ListPermissions :many
+SELECT * FROM permissions
+ORDER BY name;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = sqlc.arg(role)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: CountUsersByRole :one
+SELECT count(*) FROM users
+WHERE role = $1;
@@ -0,0 +1,84 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    currency,
+    schedule_type,
+    cron_expression,
+    next_run_at,
+    end_at,
+    max_runs
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE from_account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: GetDueScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE status = 'active' AND next_run_at <= sqlc.arg(now)
+ORDER BY next_run_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+    amount = COALESCE(sqlc.narg(amount), amount),
+    status = COALESCE(sqlc.narg(status), status),
+    failure_count = COALESCE(sqlc.narg(failure_count), failure_count),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateScheduledTransferAfterRun :one
+UPDATE scheduled_transfers
+SET
+    next_run_at = $2,
+    run_count = $3,
+    failure_count = $4,
+    status = $5,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+    scheduled_transfer_id,
+    scheduled_for,
+    status,
+    transfer_id,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListActiveScheduledTransfersByOwnerForUpdate :many
+-- Active and paused scheduled transfers from the owner's accounts.
+SELECT * FROM scheduled_transfers
+WHERE status IN ('active', 'paused')
+  AND from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+ORDER BY id
+FOR NO KEY UPDATE;
@@ -0,0 +1,65 @@
+-- name: StartUserTOTPEnrolment :one
+INSERT INTO user_totp (
+  username,
+  secret_encrypted
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (username) DO UPDATE
+SET secret_encrypted = EXCLUDED.secret_encrypted,
+    last_used_step = 0,
+    created_at = now()
+WHERE user_totp.enabled_at IS NULL
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT * FROM user_totp
+WHERE username = $1 LIMIT 1;
+
+-- name: GetUserTOTPForUpdate :one
+SELECT * FROM user_totp
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: EnableUserTOTP :one
+UPDATE user_totp
+SET enabled_at = sqlc.arg(enabled_at),
+    last_used_step = sqlc.arg(last_used_step)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: UseTOTPStep :one
+UPDATE user_totp
+SET last_used_step = sqlc.arg(last_used_step)
+WHERE username = sqlc.arg(username)
+  AND enabled_at IS NOT NULL
+  AND last_used_step < sqlc.arg(last_used_step)
+RETURNING *;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE username = $1;
+
+-- name: CreateTOTPRecoveryCode :one
+INSERT INTO totp_recovery_codes (
+  username,
+  code_hash
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: UseTOTPRecoveryCode :one
+UPDATE totp_recovery_codes
+SET used_at = sqlc.arg(used_at)
+WHERE username = sqlc.arg(username)
+  AND code_hash = sqlc.arg(code_hash)
+  AND used_at IS NULL
+RETURNING *;
+
+-- name: CountUnusedTOTPRecoveryCodes :one
+SELECT count(*) FROM totp_recovery_codes
+WHERE username = $1 AND used_at IS NULL;
+
+-- name: DeleteTOTPRecoveryCodes :exec
+DELETE FROM totp_recovery_codes
+WHERE username = $1;
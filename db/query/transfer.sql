@@ -2,20 +2,40 @@
 INSERT INTO transfers (
     from_account_id, 
     to_account_id, 
-    amount
+    amount,
+    status
 )  VALUES(
-    $1,$2,$3
+    $1,$2,$3,$4
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: ListPendingTransfersForUpdate :many
+SELECT * FROM transfers
+WHERE status = 'pending'
+ORDER BY to_account_id, id
+LIMIT $1
+FOR NO KEY UPDATE SKIP LOCKED;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+    (from_account_id = sqlc.arg(from_account_id) OR
+    to_account_id = sqlc.arg(to_account_id)) AND
+    (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
 ORDER BY id
-LIMIT $3
-OFFSET $4;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: CountOpenTransfers :one
+SELECT count(*) FROM transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+AND status IN ('pending_approval', 'pending');
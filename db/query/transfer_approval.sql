@@ -0,0 +1,52 @@
+-- name: CreateTransferApproval :one
+INSERT INTO transfer_approvals (
+    transfer_id,
+    requested_by,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetTransferApproval :one
+SELECT * FROM transfer_approvals
+WHERE transfer_id = $1 LIMIT 1;
+
+-- name: GetTransferApprovalForUpdate :one
+SELECT * FROM transfer_approvals
+WHERE transfer_id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: GetExpiredTransferApprovalForUpdate :one
+SELECT * FROM transfer_approvals
+WHERE status = 'pending' AND expires_at <= sqlc.arg(now)
+ORDER BY expires_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: ListPendingTransferApprovals :many
+SELECT * FROM transfer_approvals
+WHERE status = 'pending'
+ORDER BY created_at
+LIMIT $1
+OFFSET $2;
+
+-- name: UpdateTransferApproval :one
+UPDATE transfer_approvals
+SET
+    status = sqlc.arg(status),
+    reviewed_by = sqlc.narg(reviewed_by),
+    reviewed_at = now()
+WHERE transfer_id = sqlc.arg(transfer_id)
+RETURNING *;
+
+-- name: ListPendingTransferApprovalsByOwnerForUpdate :many
+-- Locks the pending approval requests of transfers from a user's accounts, or requested by the user.
+SELECT * FROM transfer_approvals
+WHERE status = 'pending'
+  AND (requested_by = sqlc.arg(owner) OR transfer_id IN (
+    SELECT t.id FROM transfers t
+    JOIN accounts a ON a.id = t.from_account_id
+    WHERE a.owner = sqlc.arg(owner)
+  ))
+ORDER BY transfer_id
+FOR UPDATE;
@@ -0,0 +1,41 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+    requested_by,
+    mode,
+    item_count
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: CompleteTransferBatch :one
+UPDATE transfer_batches
+SET
+    status = sqlc.arg(status),
+    succeeded_count = sqlc.arg(succeeded_count),
+    failed_count = sqlc.arg(failed_count),
+    completed_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items (
+    batch_id,
+    position,
+    from_account_id,
+    to_account_id,
+    amount,
+    status,
+    transfer_id,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListTransferBatchItems :many
+SELECT * FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY position;
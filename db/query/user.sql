@@ -1,15 +1,111 @@
--- name: CreateUser :one
+-- name: InsertUser :one
+-- Creates a user whose full name and email are already sealed by the store, see SQLStore.CreateUser.
 INSERT INTO users (
   username,
   hashed_password,
   full_name,
-  email
+  email,
+  email_index,
+  data_key_id
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetUser :one
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- name: GetUserForUpdate :one
+SELECT * FROM users
+WHERE username = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: CountOtherUsersWithEmail :one
+-- Counts the other users with an email, by its blind index or by the email itself while it is in plaintext.
+-- Both are checked, as users are sealed one by one while the bank is running.
+SELECT count(*) FROM users
+WHERE username <> sqlc.arg(username)
+  AND (email_index = sqlc.narg(email_index) OR (email_index IS NULL AND email = sqlc.arg(email)));
+
+-- name: GetUserByEmailIndex :one
+-- Finds a user by the blind index of their email, or by the email itself while it is in plaintext.
+SELECT * FROM users
+WHERE email_index = sqlc.narg(email_index)
+  OR (email_index IS NULL AND email = sqlc.arg(email))
+LIMIT 1;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = sqlc.arg(hashed_password),
+  password_changed_at = sqlc.arg(password_changed_at)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: RehashUserPassword :one
+-- Replaces a password hash with a new hash of the same password, unless the password changed in the meantime.
+UPDATE users
+SET hashed_password = sqlc.arg(new_hashed_password)
+WHERE username = sqlc.arg(username)
+  AND hashed_password = sqlc.arg(old_hashed_password)
+RETURNING *;
+
+-- name: SetUserEmailVerified :one
+UPDATE users
+SET is_email_verified = true
+WHERE username = sqlc.arg(username)
+  AND (email_index = sqlc.narg(email_index) OR (email_index IS NULL AND email = sqlc.arg(email)))
+RETURNING *;
+
+-- name: UnlockUserLogin :one
+UPDATE users
+SET login_unlocked_at = sqlc.arg(login_unlocked_at)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: UpdateUserPII :one
+-- Replaces the full name and email of a user, sealed together by the store with one data key.
+UPDATE users
+SET full_name = sqlc.arg(full_name),
+  email = sqlc.arg(email),
+  email_index = sqlc.narg(email_index),
+  data_key_id = sqlc.narg(data_key_id),
+  is_email_verified = is_email_verified AND NOT sqlc.arg(reset_email_verified)::boolean
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: ListUsersToReencrypt :many
+-- Locks a batch of users that are in plaintext or sealed with another data key than the active one.
+-- Users locked by other transactions are skipped, and picked up by a later batch.
+SELECT * FROM users
+WHERE data_key_id IS DISTINCT FROM sqlc.arg(active_data_key_id)::bigint
+ORDER BY username
+LIMIT sqlc.arg('limit')
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: DeleteUser :one
+-- Soft-deletes a user, who keeps their row for retention.
+UPDATE users
+SET deleted_at = sqlc.arg(deleted_at)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: EraseUser :one
+-- Pseudonymizes the personal data of a user. The username stays, as the key of their financial records.
+UPDATE users
+SET full_name = sqlc.arg(full_name),
+  email = sqlc.arg(email),
+  email_index = sqlc.narg(email_index),
+  data_key_id = sqlc.narg(data_key_id),
+  hashed_password = '',
+  is_email_verified = false,
+  erased_at = sqlc.arg(erased_at)
+WHERE username = sqlc.arg(username)
+RETURNING *;
 
+-- name: SetUserKYC :one
+-- Sets the KYC status of a user and, when given, their tier.
+UPDATE users
+SET kyc_status = sqlc.arg(kyc_status),
+  kyc_tier = COALESCE(sqlc.narg(kyc_tier)::varchar, kyc_tier)
+WHERE username = sqlc.arg(username)
+RETURNING *;
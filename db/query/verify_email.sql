@@ -0,0 +1,37 @@
+-- name: CreateVerifyEmail :one
+INSERT INTO verify_emails (
+  username,
+  email,
+  email_index,
+  secret_code_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetVerifyEmailForUpdate :one
+SELECT * FROM verify_emails
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetLatestVerifyEmail :one
+SELECT * FROM verify_emails
+WHERE username = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: UseVerifyEmail :one
+UPDATE verify_emails
+SET used_at = sqlc.arg(used_at)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteVerifyEmails :exec
+DELETE FROM verify_emails
+WHERE username = $1;
+
+-- name: SealVerifyEmails :exec
+UPDATE verify_emails
+SET email_index = CASE WHEN email = sqlc.arg(email)::varchar THEN sqlc.arg(email_index)::varchar END,
+    email = ''
+WHERE username = sqlc.arg(username) AND email <> '';
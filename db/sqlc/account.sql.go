@@ -7,13 +7,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit
 `
 
 type AddAccountBalanceParams struct {
@@ -30,26 +31,116 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
 	)
 	return i, err
 }
 
+const addAccountHeldBalance = `-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit
+`
+
+type AddAccountHeldBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
+	)
+	return i, err
+}
+
+const closeAccount = `-- name: CloseAccount :one
+UPDATE accounts
+SET status = 'closed', closed_at = now()
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit
+`
+
+func (q *Queries) CloseAccount(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, closeAccount, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
+	)
+	return i, err
+}
+
+const countOpenAccountsByProduct = `-- name: CountOpenAccountsByProduct :one
+SELECT count(*) FROM accounts
+WHERE owner = $1 AND product_id = $2 AND status <> 'closed'
+`
+
+type CountOpenAccountsByProductParams struct {
+	Owner     string `json:"owner"`
+	ProductID int64  `json:"product_id"`
+}
+
+func (q *Queries) CountOpenAccountsByProduct(ctx context.Context, arg CountOpenAccountsByProductParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOpenAccountsByProduct, arg.Owner, arg.ProductID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
     owner,
     balance,
-    currency
-) VALUES ($1, $2, $3) RETURNING id, owner, balance, currency, created_at
+    currency,
+    product_id,
+    overdraft_limit
+) VALUES ($1, $2, $3, $4, $5) RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner          string `json:"owner"`
+	Balance        int64  `json:"balance"`
+	Currency       string `json:"currency"`
+	ProductID      int64  `json:"product_id"`
+	OverdraftLimit int64  `json:"overdraft_limit"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.ProductID,
+		arg.OverdraftLimit,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -57,22 +148,53 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
 	)
 	return i, err
 }
 
-const deleteAccount = `-- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1
+const createSystemAccount = `-- name: CreateSystemAccount :one
+INSERT INTO accounts (
+    owner,
+    balance,
+    currency,
+    product_id
+) VALUES ('system', 0, $1, $2)
+ON CONFLICT ON CONSTRAINT owner_product_currency_key DO NOTHING
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit
 `
 
-func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteAccount, id)
-	return err
+type CreateSystemAccountParams struct {
+	Currency  string `json:"currency"`
+	ProductID int64  `json:"product_id"`
+}
+
+func (q *Queries) CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, createSystemAccount, arg.Currency, arg.ProductID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
+	)
+	return i, err
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -85,12 +207,18 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -104,24 +232,145 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
 	)
 	return i, err
 }
 
+const getSystemAccount = `-- name: GetSystemAccount :one
+SELECT a.id, a.owner, a.balance, a.currency, a.created_at, a.held_balance, a.available_balance, a.status, a.closed_at, a.product_id, a.overdraft_limit FROM accounts a
+JOIN account_products p ON p.id = a.product_id
+WHERE a.owner = 'system' AND p.code = $1 AND a.currency = $2
+LIMIT 1
+`
+
+type GetSystemAccountParams struct {
+	ProductCode string `json:"product_code"`
+	Currency    string `json:"currency"`
+}
+
+func (q *Queries) GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getSystemAccount, arg.ProductCode, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
+	)
+	return i, err
+}
+
+const getTrialBalance = `-- name: GetTrialBalance :many
+SELECT a.currency, p.ledger_class, p.code AS product_code,
+    COUNT(a.id)::bigint AS accounts,
+    SUM(a.balance)::bigint AS balance
+FROM accounts a
+JOIN account_products p ON p.id = a.product_id
+GROUP BY a.currency, p.ledger_class, p.code
+ORDER BY a.currency, p.ledger_class, p.code
+`
+
+type GetTrialBalanceRow struct {
+	Currency    string `json:"currency"`
+	LedgerClass string `json:"ledger_class"`
+	ProductCode string `json:"product_code"`
+	Accounts    int64  `json:"accounts"`
+	Balance     int64  `json:"balance"`
+}
+
+func (q *Queries) GetTrialBalance(ctx context.Context) ([]GetTrialBalanceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTrialBalance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTrialBalanceRow{}
+	for rows.Next() {
+		var i GetTrialBalanceRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.LedgerClass,
+			&i.ProductCode,
+			&i.Accounts,
+			&i.Balance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountIDs = `-- name: ListAccountIDs :many
+SELECT id FROM accounts
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListAccountIDsParams struct {
+	AfterID int64 `json:"after_id"`
+	Limit   int32 `json:"limit"`
+}
+
+func (q *Queries) ListAccountIDs(ctx context.Context, arg ListAccountIDsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountIDs, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit FROM accounts
+WHERE $1::varchar IS NULL OR owner = $1
 ORDER BY id
-LIMIT $1
-OFFSET $2
+LIMIT $2
+OFFSET $3
 `
 
 type ListAccountsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Owner  sql.NullString `json:"owner"`
+	Limit  int32          `json:"limit"`
+	Offset int32          `json:"offset"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +384,12 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.AvailableBalance,
+			&i.Status,
+			&i.ClosedAt,
+			&i.ProductID,
+			&i.OverdraftLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -149,11 +404,40 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const listOpenAccountIDsByOwner = `-- name: ListOpenAccountIDsByOwner :many
+SELECT id FROM accounts
+WHERE owner = $1 AND status <> 'closed'
+ORDER BY id
+`
+
+func (q *Queries) ListOpenAccountIDsByOwner(ctx context.Context, owner string) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenAccountIDsByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit
 `
 
 type UpdateAccountParams struct {
@@ -170,6 +454,44 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $1
+WHERE id = $2 AND status = $3
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status, closed_at, product_id, overdraft_limit
+`
+
+type UpdateAccountStatusParams struct {
+	Status     string `json:"status"`
+	ID         int64  `json:"id"`
+	FromStatus string `json:"from_status"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.Status, arg.ID, arg.FromStatus)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+		&i.ClosedAt,
+		&i.ProductID,
+		&i.OverdraftLimit,
 	)
 	return i, err
 }
@@ -16,9 +16,10 @@ func createRandomAccount(t *testing.T) Account {
 
 	// Prepare the parameters to create an account
 	arg := CreateAccountParams{
-		Owner:    user.Username, // Use the created user's username
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Owner:     user.Username, // Use the created user's username
+		Balance:   util.RandomMoney(),
+		Currency:  util.RandomCurrency(),
+		ProductID: fetchAccountProduct(t, ProductChecking).ID,
 	}
 
 	// Insert the account into the database
@@ -29,6 +30,23 @@ func createRandomAccount(t *testing.T) Account {
 	return account // Return the created account for use in tests
 }
 
+// fetchAccountProduct returns one of the products created by the migrations.
+func fetchAccountProduct(t *testing.T, code string) AccountProduct {
+	product, err := testQueries.GetAccountProductByCode(context.Background(), code)
+	require.NoError(t, err)
+	return product
+}
+
+// fundAccount credits an account so that the tests can spend from it, now that debits check the available balance.
+func fundAccount(t *testing.T, account Account, amount int64) Account {
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+	return account
+}
+
 // TestCreateAccount tests the account creation functionality.
 func TestCreateAccount(t *testing.T) {
 	// Use the helper to create a random account
@@ -111,19 +129,169 @@ func TestUpdateAccountWithInvalidID(t *testing.T) {
 	require.Equal(t, int64(0), account.ID) // Ensure update was not successful
 }
 
-// TestDeleteAccount tests deleting an account and verifying it no longer exists.
-func TestDeleteAccount(t *testing.T) {
-	// Create a random account to delete
-	account1 := createRandomAccount(t)
+// TestCreateAccountTx tests opening accounts of different products in the same currency.
+func TestCreateAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	setRandomUserKYCTier(t, user.Username, KYCTierStandard) // the basic tier allows a single account
+	business := fetchAccountProduct(t, ProductBusiness)
+
+	result, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.USD,
+		ProductCode: ProductBusiness,
+	})
+	require.NoError(t, err)
+	require.Equal(t, business.ID, result.Account.ProductID)
+	require.Equal(t, business.OverdraftLimit, result.Account.OverdraftLimit)
+	require.Zero(t, result.Account.Balance)
+
+	// The same currency is allowed again for another product, but not for the same one
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.USD,
+		ProductCode: ProductSavings,
+	})
+	require.NoError(t, err)
+
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.USD,
+		ProductCode: ProductBusiness,
+	})
+	require.Error(t, err)
+}
+
+// TestCreateAccountTxTooManyAccounts tests the per-owner limit of a product.
+func TestCreateAccountTxTooManyAccounts(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	setRandomUserKYCTier(t, user.Username, KYCTierStandard) // the basic tier allows a single account
+	checking := fetchAccountProduct(t, ProductChecking)
+
+	// One account per currency, until the limit is reached
+	currencies := []string{util.USD, util.EUR, util.CAD, util.GBP}
+	require.Less(t, int(checking.MaxAccountsPerOwner), len(currencies))
+	for _, currency := range currencies[:checking.MaxAccountsPerOwner] {
+		_, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+			Owner:       user.Username,
+			Currency:    currency,
+			ProductCode: ProductChecking,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    currencies[checking.MaxAccountsPerOwner],
+		ProductCode: ProductChecking,
+	})
+	require.ErrorIs(t, err, ErrTooManyAccounts)
+}
+
+// TestTransferTxOverdraft tests that a debit can take the available balance down to minus the overdraft limit.
+func TestTransferTxOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	result, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.USD,
+		ProductCode: ProductBusiness,
+	})
+	require.NoError(t, err)
+	account1 := result.Account
+	require.Positive(t, account1.OverdraftLimit)
+	account2 := createRandomAccount(t)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.OverdraftLimit,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -account1.OverdraftLimit, transfer.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+// TestCloseAccountTx tests closing an account whose balance is swept to another account.
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account1.ID,
+		SweepToAccountID: account2.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountClosed, result.Account.Status)
+	require.True(t, result.Account.ClosedAt.Valid)
+	require.Zero(t, result.Account.Balance)
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, account1.Balance, result.Sweep.Transfer.Amount)
+	require.Equal(t, account2.Balance+account1.Balance, result.Sweep.ToAccount.Balance)
+
+	// The closed account stays queryable, but no money can move on it anymore
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountClosed, account.Status)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
+
+// TestCloseAccountTxNotEmpty tests that an account with money left cannot be closed without a sweep account.
+func TestCloseAccountTxNotEmpty(t *testing.T) {
+	store := NewStore(testDB)
+	account := fundAccount(t, createRandomAccount(t), 100)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	account, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountActive, account.Status)
+}
+
+// TestFrozenAccountTransferTx tests that a frozen account can be credited but not debited.
+func TestFrozenAccountTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := fundAccount(t, createRandomAccount(t), 100)
+
+	frozen, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status:     AccountFrozen,
+		ID:         account1.ID,
+		FromStatus: AccountActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountFrozen, frozen.Status)
 
-	// Delete the account by its ID
-	err := testQueries.DeleteAccount(context.Background(), account1.ID)
-	require.NoError(t, err) // Expect no error during deletion
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
 
-	// Attempt to retrieve the deleted account
-	account2, err2 := testQueries.GetAccount(context.Background(), account1.ID)
-	require.Error(t, err2)                  // Expect an error since the account should no longer exist
-	require.Equal(t, int64(0), account2.ID) // The account should not exist
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance+10, result.ToAccount.Balance)
 }
 
 // TestListAccounts tests listing accounts from the database.
@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// isAMLScreeningQueued tells whether a transfer waits on the AML screening queue.
+func isAMLScreeningQueued(t *testing.T, transferID int64) bool {
+	var queued bool
+	err := testDB.QueryRowContext(context.Background(),
+		"SELECT EXISTS (SELECT 1 FROM aml_screening_queue WHERE transfer_id = $1)", transferID).Scan(&queued)
+	require.NoError(t, err)
+	return queued
+}
+
+// createRandomPostedTransfer creates a posted transfer between two accounts, without moving money.
+func createRandomPostedTransfer(t *testing.T, account1, account2 Account) Transfer {
+	transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.RandomMoney(),
+		Status:        TransferStatusPosted,
+	})
+	require.NoError(t, err)
+	return transfer
+}
+
+// createRandomAMLAlert records an open alert on a new transfer.
+func createRandomAMLAlert(t *testing.T, store Store) AmlAlert {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	transfer := createRandomPostedTransfer(t, account1, account2)
+
+	alerts, err := store.RecordAMLScreeningTx(context.Background(), RecordAMLScreeningTxParams{
+		TransferID: transfer.ID,
+		Alerts: []CreateAMLAlertParams{{
+			TransferID: transfer.ID,
+			AccountID:  account1.ID,
+			Owner:      account1.Owner,
+			Rule:       "large_transfer",
+			Severity:   AMLSeverityHigh,
+			Details:    json.RawMessage(`{"amount": ` + strconv.FormatInt(transfer.Amount, 10) + `}`),
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	require.Equal(t, AMLAlertStatusOpen, alerts[0].Status)
+	return alerts[0]
+}
+
+func TestAMLScreeningQueue(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// Posted transfers are queued as they are created, pending ones once they are posted
+	posted := createRandomPostedTransfer(t, account1, account2)
+	require.True(t, isAMLScreeningQueued(t, posted.ID))
+
+	pending, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Status:        TransferStatusPending,
+	})
+	require.NoError(t, err)
+	require.False(t, isAMLScreeningQueued(t, pending.ID))
+	_, err = testQueries.UpdateTransferStatus(context.Background(), UpdateTransferStatusParams{
+		Status:     TransferStatusPosted,
+		ID:         pending.ID,
+		FromStatus: TransferStatusPending,
+	})
+	require.NoError(t, err)
+	require.True(t, isAMLScreeningQueued(t, pending.ID))
+
+	arg := ListQueuedAMLScreeningsParams{AfterID: posted.ID - 1, MaxAttempts: 1, Limit: 1000000}
+	queued, err := testQueries.ListQueuedAMLScreenings(context.Background(), arg)
+	require.NoError(t, err)
+	ids := make([]int64, len(queued))
+	for i, transfer := range queued {
+		ids[i] = transfer.ID
+	}
+	require.Contains(t, ids, posted.ID)
+	require.Contains(t, ids, pending.ID)
+
+	// A transfer whose screening failed too many times stays queued, but is no longer listed
+	err = testQueries.RecordAMLScreeningFailure(context.Background(), RecordAMLScreeningFailureParams{
+		LastError:  "cannot evaluate",
+		TransferID: pending.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, isAMLScreeningQueued(t, pending.ID))
+	queued, err = testQueries.ListQueuedAMLScreenings(context.Background(), arg)
+	require.NoError(t, err)
+	for _, transfer := range queued {
+		require.NotEqual(t, pending.ID, transfer.ID)
+	}
+
+	// Screening takes the transfer off the queue, and only the first screening records its alerts
+	alert := CreateAMLAlertParams{
+		TransferID: posted.ID,
+		AccountID:  account1.ID,
+		Owner:      account1.Owner,
+		Rule:       "round_amount",
+		Severity:   AMLSeverityLow,
+		Details:    json.RawMessage(`{}`),
+	}
+	alerts, err := store.RecordAMLScreeningTx(context.Background(), RecordAMLScreeningTxParams{TransferID: posted.ID, Alerts: []CreateAMLAlertParams{alert}})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	require.False(t, isAMLScreeningQueued(t, posted.ID))
+
+	alerts, err = store.RecordAMLScreeningTx(context.Background(), RecordAMLScreeningTxParams{TransferID: posted.ID, Alerts: []CreateAMLAlertParams{alert}})
+	require.NoError(t, err)
+	require.Empty(t, alerts)
+}
+
+// TestAMLWindowQueries tests the queries the monitoring rules look back with.
+func TestAMLWindowQueries(t *testing.T) {
+	ctx := context.Background()
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	sent1 := createRandomPostedTransfer(t, account1, account2)
+	sent2 := createRandomPostedTransfer(t, account1, account3)
+	received := createRandomPostedTransfer(t, account2, account1)
+	since, until := sent1.CreatedAt.Add(-time.Minute), received.CreatedAt
+
+	total, err := testQueries.SumAccountTransfersInWindow(ctx, SumAccountTransfersInWindowParams{Sent: true, AccountID: account1.ID, Currency: account1.Currency, Since: since, Until: until})
+	require.NoError(t, err)
+	require.Equal(t, sent1.Amount+sent2.Amount, total)
+	total, err = testQueries.SumAccountTransfersInWindow(ctx, SumAccountTransfersInWindowParams{Sent: false, AccountID: account1.ID, Currency: account1.Currency, Since: since, Until: until})
+	require.NoError(t, err)
+	require.Equal(t, received.Amount, total)
+
+	// The window ends at the transfer being screened
+	total, err = testQueries.SumAccountTransfersInWindow(ctx, SumAccountTransfersInWindowParams{Sent: false, AccountID: account1.ID, Currency: account1.Currency, Since: since, Until: sent1.CreatedAt})
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	arg := CountOwnerTransfersInRangeParams{
+		Sent:      true,
+		Owner:     account1.Owner,
+		Currency:  account1.Currency,
+		Since:     since,
+		Until:     until,
+		MinAmount: 0,
+		MaxAmount: 1 << 62,
+	}
+	count, err := testQueries.CountOwnerTransfersInRange(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// Amounts in other currencies are not comparable
+	arg.Currency = util.USD
+	if account1.Currency == util.USD {
+		arg.Currency = util.EUR
+	}
+	count, err = testQueries.CountOwnerTransfersInRange(ctx, arg)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	counterparties, err := testQueries.ListNewCounterparties(ctx, ListNewCounterpartiesParams{Owner: account1.Owner, Since: since, Until: until})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []int64{account2.ID, account3.ID}, counterparties)
+
+	// Accounts paid before the window are not new
+	counterparties, err = testQueries.ListNewCounterparties(ctx, ListNewCounterpartiesParams{Owner: account1.Owner, Since: sent1.CreatedAt, Until: until})
+	require.NoError(t, err)
+	require.NotContains(t, counterparties, account2.ID)
+}
+
+func TestUpdateAMLAlertTx(t *testing.T) {
+	store := NewStore(testDB)
+	alert := createRandomAMLAlert(t, store)
+	investigator := createRandomUser(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// Open alerts cannot be escalated before someone takes them
+	_, err := store.UpdateAMLAlertTx(context.Background(), UpdateAMLAlertTxParams{ID: alert.ID, Status: AMLAlertStatusEscalated, Investigator: investigator.Username, Now: now})
+	require.ErrorIs(t, err, ErrInvalidAMLAlertTransition)
+
+	taken, err := store.UpdateAMLAlertTx(context.Background(), UpdateAMLAlertTxParams{
+		ID:           alert.ID,
+		Status:       AMLAlertStatusInvestigating,
+		Investigator: investigator.Username,
+		Notes:        "asked for the source of funds",
+		Now:          now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AMLAlertStatusInvestigating, taken.Status)
+	require.Equal(t, investigator.Username, taken.AssignedTo.String)
+	require.Equal(t, "asked for the source of funds", taken.Notes)
+
+	_, err = store.UpdateAMLAlertTx(context.Background(), UpdateAMLAlertTxParams{ID: alert.ID, Status: AMLAlertStatusClosed, Investigator: investigator.Username, Now: now})
+	require.ErrorIs(t, err, ErrAMLResolutionRequired)
+
+	// Notes are kept when none are given
+	closed, err := store.UpdateAMLAlertTx(context.Background(), UpdateAMLAlertTxParams{
+		ID:           alert.ID,
+		Status:       AMLAlertStatusClosed,
+		Investigator: investigator.Username,
+		Resolution:   AMLResolutionFalsePositive,
+		Now:          now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AMLAlertStatusClosed, closed.Status)
+	require.Equal(t, AMLResolutionFalsePositive, closed.Resolution)
+	require.Equal(t, "asked for the source of funds", closed.Notes)
+	require.WithinDuration(t, now, closed.ClosedAt.Time, time.Second)
+
+	// Closed alerts are final
+	_, err = store.UpdateAMLAlertTx(context.Background(), UpdateAMLAlertTxParams{ID: alert.ID, Status: AMLAlertStatusInvestigating, Investigator: investigator.Username, Now: now})
+	require.ErrorIs(t, err, ErrInvalidAMLAlertTransition)
+
+	events := listAuditEventsOf(t, AuditAMLAlert, auditID(alert.ID))
+	require.Len(t, events, 3)
+	require.Equal(t, "aml_alert.create", events[0].Action)
+	require.Equal(t, "aml_alert.investigate", events[1].Action)
+	require.Equal(t, "aml_alert.close", events[2].Action)
+
+	alerts, err := testQueries.ListAMLAlerts(context.Background(), ListAMLAlertsParams{
+		Status: sql.NullString{String: AMLAlertStatusClosed, Valid: true},
+		Owner:  sql.NullString{String: closed.Owner, Valid: true},
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	require.Equal(t, alert.ID, alerts[0].ID)
+}
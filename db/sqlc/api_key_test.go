@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// createRandomAPIKey stores an API key of the user with the scopes
+func createRandomAPIKey(t *testing.T, user User, scopes ...string) ApiKey {
+	arg := CreateAPIKeyParams{
+		Owner:      user.Username,
+		Name:       util.RandomOwner(),
+		Prefix:     util.RandomString(12),
+		SecretHash: util.RandomString(64),
+		Scopes:     scopes,
+		ExpiresAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	key, err := NewStore(testDB).CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, key.Owner)
+	require.Equal(t, arg.Prefix, key.Prefix)
+	require.Equal(t, arg.Scopes, key.Scopes)
+	require.False(t, key.LastUsedAt.Valid)
+	require.False(t, key.RevokedAt.Valid)
+	return key
+}
+
+func TestAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	key := createRandomAPIKey(t, user, util.ScopeAccountsRead, util.ScopeTransfersWrite)
+	createRandomAPIKey(t, user, util.ScopeHoldsRead)
+
+	found, err := testQueries.GetAPIKeyByPrefix(context.Background(), key.Prefix)
+	require.NoError(t, err)
+	require.Equal(t, key.ID, found.ID)
+	require.Equal(t, key.Scopes, found.Scopes)
+
+	now := time.Now()
+	err = testQueries.UpdateAPIKeyLastUsed(context.Background(), UpdateAPIKeyLastUsedParams{LastUsedAt: sql.NullTime{Time: now, Valid: true}, ID: key.ID})
+	require.NoError(t, err)
+
+	keys, err := testQueries.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, key.ID, keys[0].ID)
+	require.WithinDuration(t, now, keys[0].LastUsedAt.Time, time.Second)
+
+	events := listAuditEventsOf(t, AuditAPIKey, auditID(key.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, "api_key.create", events[0].Action)
+	require.NotContains(t, string(events[0].After), key.SecretHash)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	key := createRandomAPIKey(t, user, util.ScopeAccountsRead)
+
+	arg := RevokeAPIKeyParams{RevokedAt: sql.NullTime{Time: time.Now(), Valid: true}, ID: key.ID, Owner: other.Username}
+	_, err := store.RevokeAPIKey(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg.Owner = user.Username
+	revoked, err := store.RevokeAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	_, err = store.RevokeAPIKey(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	events := listAuditEventsOf(t, AuditAPIKey, auditID(key.ID))
+	require.Len(t, events, 2)
+	require.Equal(t, "api_key.revoke", events[1].Action)
+}
@@ -0,0 +1,492 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Resource types of the audit events
+const (
+	AuditAccount           = "account"
+	AuditTransfer          = "transfer"
+	AuditTransferBatch     = "transfer_batch"
+	AuditTransferApproval  = "transfer_approval"
+	AuditHold              = "hold"
+	AuditUser              = "user"
+	AuditFeeSchedule       = "fee_schedule"
+	AuditExchangeRate      = "exchange_rate"
+	AuditScheduledTransfer = "scheduled_transfer"
+	AuditAPIKey            = "api_key"
+	AuditDataKey           = "data_key"
+	AuditKYCDocument       = "kyc_document"
+	AuditAMLAlert          = "aml_alert"
+)
+
+// AuditActor is who made a change and from where. The API puts it in the context of each request,
+// and the store records it with every change made with that context.
+type AuditActor struct {
+	Username   string
+	Role       string
+	RequestID  string
+	RemoteAddr string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a copy of ctx carrying the actor of the changes made with it.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFrom returns the actor carried by ctx, which is empty when there is none.
+func AuditActorFrom(ctx context.Context) AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor
+}
+
+// auditUser is the audited snapshot of a user, leaving out the password hash. The full name and email
+// are recorded as stored, sealed with the data key of DataKeyID when the personal data is encrypted.
+type auditUser struct {
+	Username          string        `json:"username"`
+	FullName          string        `json:"full_name"`
+	Email             string        `json:"email"`
+	Role              string        `json:"role"`
+	IsEmailVerified   bool          `json:"is_email_verified"`
+	PasswordChangedAt time.Time     `json:"password_changed_at"`
+	LoginUnlockedAt   time.Time     `json:"login_unlocked_at"`
+	DeletedAt         sql.NullTime  `json:"deleted_at"`
+	ErasedAt          sql.NullTime  `json:"erased_at"`
+	DataKeyID         sql.NullInt64 `json:"data_key_id"`
+	KYCStatus         string        `json:"kyc_status"`
+	KYCTier           string        `json:"kyc_tier"`
+	CreatedAt         time.Time     `json:"created_at"`
+}
+
+func newAuditUser(user User) auditUser {
+	return auditUser{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		PasswordChangedAt: user.PasswordChangedAt,
+		LoginUnlockedAt:   user.LoginUnlockedAt,
+		DeletedAt:         user.DeletedAt,
+		ErasedAt:          user.ErasedAt,
+		DataKeyID:         user.DataKeyID,
+		KYCStatus:         user.KycStatus,
+		KYCTier:           user.KycTier,
+		CreatedAt:         user.CreatedAt,
+	}
+}
+
+// auditAPIKey is the audited snapshot of an API key, leaving out the secret's hash.
+type auditAPIKey struct {
+	ID         int64        `json:"id"`
+	Owner      string       `json:"owner"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	Scopes     []string     `json:"scopes"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func newAuditAPIKey(key ApiKey) auditAPIKey {
+	return auditAPIKey{
+		ID:         key.ID,
+		Owner:      key.Owner,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// auditID formats the ID of a resource for the audit log, which also records resources keyed by name.
+func auditID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// recordAudit appends an audit event for a change made with ctx. It is called with the queries of the
+// transaction making the change, so the event is committed, or rolled back, together with it.
+// before is nil for a created resource and after is nil for a deleted one.
+func recordAudit(ctx context.Context, q *Queries, action, resourceType, resourceID string, before, after any) error {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return fmt.Errorf("audit %s: %w", action, err)
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return fmt.Errorf("audit %s: %w", action, err)
+	}
+
+	// Changes made without a user, such as those of the background workers, are the system's
+	actor := AuditActorFrom(ctx)
+	if actor.Username == "" {
+		actor.Username = SystemUsername
+	}
+	_, err = q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		Actor:        actor.Username,
+		ActorRole:    actor.Role,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       beforeJSON,
+		After:        afterJSON,
+		RequestID:    actor.RequestID,
+		RemoteAddr:   actor.RemoteAddr,
+	})
+	return err
+}
+
+// The API makes some changes with single queries. SQLStore wraps those in a transaction with their
+// audit event; the Querier methods of the same names remain unaudited for the transactions that use them.
+
+// CreateUserParams contains the input parameters of CreateUser, with the full name and email in plaintext.
+type CreateUserParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
+	FullName       string `json:"full_name"`
+	Email          string `json:"email"`
+}
+
+// createUser seals the full name and email of a new user and creates them.
+func (store *SQLStore) createUser(ctx context.Context, q *Queries, arg CreateUserParams) (User, error) {
+	sealed, err := store.sealUser(ctx, arg.Username, arg.FullName, arg.Email)
+	if err != nil {
+		return User{}, err
+	}
+	if err := checkEmailFree(ctx, q, arg.Username, arg.Email, sealed); err != nil {
+		return User{}, err
+	}
+	return q.InsertUser(ctx, InsertUserParams{
+		Username:       arg.Username,
+		HashedPassword: arg.HashedPassword,
+		FullName:       sealed.FullName,
+		Email:          sealed.Email,
+		EmailIndex:     sealed.EmailIndex,
+		DataKeyID:      sealed.DataKeyID,
+	})
+}
+
+// CreateUser creates a user and audits it. The audited user is sealed, like the stored one.
+func (store *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = store.createUser(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.create", AuditUser, user.Username, nil, newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// UpdateUserParams contains the input parameters of UpdateUser. Null fields are left unchanged.
+type UpdateUserParams struct {
+	FullName sql.NullString `json:"full_name"`
+	Email    sql.NullString `json:"email"`
+	Username string         `json:"username"`
+}
+
+// UpdateUser changes a user's full name or email and audits the change. A new email is not verified yet.
+// Both are sealed again together, with the active data key.
+func (store *SQLStore) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		current, err := store.openUser(ctx, before)
+		if err != nil {
+			return err
+		}
+
+		fullName, email := current.FullName, current.Email
+		if arg.FullName.Valid {
+			fullName = arg.FullName.String
+		}
+		if arg.Email.Valid {
+			email = arg.Email.String
+		}
+		sealed, err := store.sealUser(ctx, arg.Username, fullName, email)
+		if err != nil {
+			return err
+		}
+		if email != current.Email {
+			if err := checkEmailFree(ctx, q, arg.Username, email, sealed); err != nil {
+				return err
+			}
+		}
+
+		user, err = q.UpdateUserPII(ctx, UpdateUserPIIParams{
+			FullName:           sealed.FullName,
+			Email:              sealed.Email,
+			EmailIndex:         sealed.EmailIndex,
+			DataKeyID:          sealed.DataKeyID,
+			ResetEmailVerified: email != current.Email,
+			Username:           arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.update", AuditUser, user.Username, newAuditUser(before), newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// UpdateUserRole gives a user another role and audits the change.
+func (store *SQLStore) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.UpdateUserRole(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.update_role", AuditUser, user.Username, newAuditUser(before), newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// UpdateUserPassword changes a user's password and audits it, without the hashes.
+func (store *SQLStore) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.UpdateUserPassword(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.change_password", AuditUser, user.Username, newAuditUser(before), newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// RehashUserPassword upgrades a user's password hash and audits it, without the hashes.
+func (store *SQLStore) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.RehashUserPassword(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.rehash_password", AuditUser, user.Username, newAuditUser(before), newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// UnlockUserLogin lets a user who was locked out after failed logins try again, and audits it.
+func (store *SQLStore) UnlockUserLogin(ctx context.Context, arg UnlockUserLoginParams) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.UnlockUserLogin(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.unlock_login", AuditUser, user.Username, newAuditUser(before), newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// CreateAPIKey creates an API key and audits it, without the secret's hash.
+func (store *SQLStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	var key ApiKey
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		key, err = q.CreateAPIKey(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "api_key.create", AuditAPIKey, auditID(key.ID), nil, newAuditAPIKey(key))
+	})
+	return key, err
+}
+
+// RevokeAPIKey revokes an API key of its owner and audits it.
+func (store *SQLStore) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error) {
+	var key ApiKey
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAPIKeyForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		key, err = q.RevokeAPIKey(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "api_key.revoke", AuditAPIKey, auditID(key.ID), newAuditAPIKey(before), newAuditAPIKey(key))
+	})
+	return key, err
+}
+
+// UpdateAccountStatus freezes or unfreezes an account and audits the change.
+func (store *SQLStore) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	var account Account
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		account, err = q.UpdateAccountStatus(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "account.update_status", AuditAccount, auditID(account.ID), before, account)
+	})
+	return account, err
+}
+
+// UpsertFeeSchedule sets the fee schedule of a fee type and currency and audits the change.
+func (store *SQLStore) UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error) {
+	var schedule FeeSchedule
+	err := store.execTx(ctx, func(q *Queries) error {
+		var before *FeeSchedule
+		old, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{FeeType: arg.FeeType, Currency: arg.Currency})
+		if err == nil {
+			before = &old
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		schedule, err = q.UpsertFeeSchedule(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "fee_schedule.set", AuditFeeSchedule, feeScheduleID(schedule.FeeType, schedule.Currency), before, schedule)
+	})
+	return schedule, err
+}
+
+// DeleteFeeSchedule removes the fee schedule of a fee type and currency and audits it.
+func (store *SQLStore) DeleteFeeSchedule(ctx context.Context, arg DeleteFeeScheduleParams) (FeeSchedule, error) {
+	var schedule FeeSchedule
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		schedule, err = q.DeleteFeeSchedule(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "fee_schedule.delete", AuditFeeSchedule, feeScheduleID(schedule.FeeType, schedule.Currency), schedule, nil)
+	})
+	return schedule, err
+}
+
+// feeScheduleID identifies a fee schedule, which is keyed by fee type and currency, in the audit log.
+func feeScheduleID(feeType, currency string) string {
+	return feeType + "/" + currency
+}
+
+// UpsertExchangeRate sets the exchange rate between two currencies and audits the change.
+func (store *SQLStore) UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error) {
+	var rate ExchangeRate
+	err := store.execTx(ctx, func(q *Queries) error {
+		var before *ExchangeRate
+		old, err := q.GetExchangeRate(ctx, GetExchangeRateParams{BaseCurrency: arg.BaseCurrency, QuoteCurrency: arg.QuoteCurrency})
+		if err == nil {
+			before = &old
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		rate, err = q.UpsertExchangeRate(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "exchange_rate.set", AuditExchangeRate, exchangeRateID(rate.BaseCurrency, rate.QuoteCurrency), before, rate)
+	})
+	return rate, err
+}
+
+// DeleteExchangeRate removes the exchange rate between two currencies and audits it.
+func (store *SQLStore) DeleteExchangeRate(ctx context.Context, arg DeleteExchangeRateParams) (ExchangeRate, error) {
+	var rate ExchangeRate
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		rate, err = q.DeleteExchangeRate(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "exchange_rate.delete", AuditExchangeRate, exchangeRateID(rate.BaseCurrency, rate.QuoteCurrency), rate, nil)
+	})
+	return rate, err
+}
+
+// exchangeRateID identifies an exchange rate, which is keyed by its currency pair, in the audit log.
+func exchangeRateID(baseCurrency, quoteCurrency string) string {
+	return baseCurrency + "/" + quoteCurrency
+}
+
+// CreateScheduledTransfer creates a scheduled transfer and audits it.
+func (store *SQLStore) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		scheduled, err = q.CreateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "scheduled_transfer.create", AuditScheduledTransfer, auditID(scheduled.ID), nil, scheduled)
+	})
+	return scheduled, err
+}
+
+// UpdateScheduledTransfer changes, pauses or cancels a scheduled transfer and audits the change.
+func (store *SQLStore) UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		scheduled, err = q.UpdateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "scheduled_transfer.update", AuditScheduledTransfer, auditID(scheduled.ID), before, scheduled)
+	})
+	return scheduled, err
+}
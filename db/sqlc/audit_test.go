@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// listAuditEventsOf returns the audit events of a resource, oldest first.
+func listAuditEventsOf(t *testing.T, resourceType, resourceID string) []AuditEvent {
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		ResourceType: sql.NullString{String: resourceType, Valid: true},
+		ResourceID:   sql.NullString{String: resourceID, Valid: true},
+		Limit:        10,
+	})
+	require.NoError(t, err)
+	return events
+}
+
+func TestTransferTxAudit(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	actor := AuditActor{
+		Username:   account1.Owner,
+		Role:       util.CustomerRole,
+		RequestID:  util.RandomString(32),
+		RemoteAddr: "203.0.113.7",
+	}
+	ctx := WithAuditActor(context.Background(), actor)
+
+	result, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events := listAuditEventsOf(t, AuditTransfer, auditID(result.Transfer.ID))
+	require.Len(t, events, 1)
+	event := events[0]
+	require.Equal(t, "transfer.create", event.Action)
+	require.Equal(t, actor.Username, event.Actor)
+	require.Equal(t, actor.Role, event.ActorRole)
+	require.Equal(t, actor.RequestID, event.RequestID)
+	require.Equal(t, actor.RemoteAddr, event.RemoteAddr)
+	require.JSONEq(t, "null", string(event.Before))
+
+	var after Transfer
+	require.NoError(t, json.Unmarshal(event.After, &after))
+	require.Equal(t, result.Transfer.ID, after.ID)
+
+	// the audit log is append-only, like the ledger
+	_, err = testDB.Exec("UPDATE audit_events SET actor = 'someone else' WHERE id = $1", event.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = testDB.Exec("DELETE FROM audit_events WHERE id = $1", event.ID)
+	require.ErrorContains(t, err, "append-only")
+}
+
+func TestUpdateAccountStatusAudit(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	// changes made without an actor, such as the workers', are the system's
+	frozen, err := store.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status:     AccountFrozen,
+		ID:         account.ID,
+		FromStatus: AccountActive,
+	})
+	require.NoError(t, err)
+
+	events := listAuditEventsOf(t, AuditAccount, auditID(account.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, "account.update_status", events[0].Action)
+	require.Equal(t, SystemUsername, events[0].Actor)
+
+	var before, after Account
+	require.NoError(t, json.Unmarshal(events[0].Before, &before))
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, AccountActive, before.Status)
+	require.Equal(t, frozen.Status, after.Status)
+
+	// a failed change is rolled back together with its audit event
+	_, err = store.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status:     AccountFrozen,
+		ID:         account.ID,
+		FromStatus: AccountActive,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.Len(t, listAuditEventsOf(t, AuditAccount, auditID(account.ID)), 1)
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// setExchangeRate sets the rate of a currency pair for the duration of a test.
+func setExchangeRate(t *testing.T, baseCurrency, quoteCurrency, rate string) ExchangeRate {
+	exchangeRate, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		BaseCurrency:  baseCurrency,
+		QuoteCurrency: quoteCurrency,
+		Rate:          rate,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, err := testQueries.DeleteExchangeRate(context.Background(), DeleteExchangeRateParams{
+			BaseCurrency:  baseCurrency,
+			QuoteCurrency: quoteCurrency,
+		})
+		require.NoError(t, err)
+	})
+	return exchangeRate
+}
+
+// createConversionAccounts opens a funded account and an empty one in another currency for the same user.
+func createConversionAccounts(t *testing.T, fromCurrency, toCurrency string, funds int64) (Account, Account) {
+	user := createRandomUser(t)
+	product := fetchAccountProduct(t, ProductChecking)
+
+	from, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     user.Username,
+		Balance:   funds,
+		Currency:  fromCurrency,
+		ProductID: product.ID,
+	})
+	require.NoError(t, err)
+	to, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     user.Username,
+		Currency:  toCurrency,
+		ProductID: product.ID,
+	})
+	require.NoError(t, err)
+	return from, to
+}
+
+func TestConvertAmount(t *testing.T) {
+	converted, err := ConvertAmount("1.5", 150)
+	require.NoError(t, err)
+	require.Equal(t, int64(225), converted)
+
+	// rounded down to the minor unit
+	converted, err = ConvertAmount("0.9199", 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(91), converted)
+
+	_, err = ConvertAmount("0", 100)
+	require.Error(t, err)
+	_, err = ConvertAmount("abc", 100)
+	require.Error(t, err)
+}
+
+// TestConvertTx tests that a conversion moves the amount through the FX position accounts of both currencies
+// and charges the FX spread into the FX position of the source currency.
+func TestConvertTx(t *testing.T) {
+	store := NewStore(testDB)
+	from, to := createConversionAccounts(t, util.CHF, util.SEK, 1_000)
+	setExchangeRate(t, util.CHF, util.SEK, "11.5")
+	_, err := testQueries.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		FeeType:  FeeFXSpread,
+		Currency: util.CHF,
+		Method:   FeePercentage,
+		RateBps:  100,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := testQueries.DeleteFeeSchedule(context.Background(), DeleteFeeScheduleParams{FeeType: FeeFXSpread, Currency: util.CHF})
+		require.NoError(t, err)
+	})
+
+	fromPosition, err := systemAccount(context.Background(), testQueries, ProductFXPosition, util.CHF)
+	require.NoError(t, err)
+	toPosition, err := systemAccount(context.Background(), testQueries, ProductFXPosition, util.SEK)
+	require.NoError(t, err)
+
+	result, err := store.ConvertTx(context.Background(), ConvertTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        500,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "11.5000000000", result.Conversion.Rate)
+	require.Equal(t, result.FromTransfer.Transfer.ID, result.Conversion.FromTransferID)
+	require.Equal(t, result.ToTransfer.Transfer.ID, result.Conversion.ToTransferID)
+
+	require.Equal(t, fromPosition.ID, result.FromTransfer.Transfer.ToAccountID)
+	require.Equal(t, toPosition.ID, result.ToTransfer.Transfer.FromAccountID)
+	require.Equal(t, toPosition.Balance-5_750, result.ToTransfer.FromAccount.Balance)
+	require.Equal(t, int64(5_750), result.ToTransfer.ToAccount.Balance)
+
+	// the spread is a separate fee, paid into the FX position rather than the fee income account
+	require.NotNil(t, result.Spread)
+	require.Equal(t, FeeFXSpread, result.Spread.Fee.FeeType)
+	require.Equal(t, int64(5), result.Spread.Fee.Amount)
+	require.Equal(t, fromPosition.ID, result.Spread.Transfer.ToAccountID)
+	require.Equal(t, int64(495), result.FromTransfer.FromAccount.Balance)
+
+	position, err := testQueries.GetAccount(context.Background(), fromPosition.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromPosition.Balance+505, position.Balance)
+
+	fees, err := testQueries.ListTransferFees(context.Background(), sql.NullInt64{Int64: result.FromTransfer.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, fees, 1)
+
+	// The spread counts against the balance: the whole conversion is rolled back when it cannot be paid
+	_, err = store.ConvertTx(context.Background(), ConvertTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        495,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestConvertTxNoExchangeRate(t *testing.T) {
+	store := NewStore(testDB)
+	from, to := createConversionAccounts(t, util.NOK, util.ZAR, 1_000)
+
+	_, err := store.ConvertTx(context.Background(), ConvertTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+	})
+	require.ErrorIs(t, err, ErrNoExchangeRate)
+
+	// only the pair in the given direction is used
+	setExchangeRate(t, util.ZAR, util.NOK, "0.58")
+	_, err = store.ConvertTx(context.Background(), ConvertTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+	})
+	require.ErrorIs(t, err, ErrNoExchangeRate)
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SnapshotDailyBalance records the balance of an account at the end of a day, midnight UTC.
+// The balance is the current one minus the entries made since, so a past day can be snapshotted late.
+// A day is snapshotted once; taken is false when the snapshot already existed.
+func SnapshotDailyBalance(ctx context.Context, q Querier, accountID int64, date time.Time) (snapshot DailyBalance, taken bool, err error) {
+	date = InterestDate(date)
+
+	balance, err := q.GetAccountBalanceAt(ctx, GetAccountBalanceAtParams{
+		At:        date.AddDate(0, 0, 1),
+		AccountID: accountID,
+	})
+	if err != nil {
+		return snapshot, false, err
+	}
+
+	snapshot, err = q.CreateDailyBalance(ctx, CreateDailyBalanceParams{
+		AccountID:   accountID,
+		BalanceDate: date,
+		Balance:     balance,
+	})
+	if err == sql.ErrNoRows {
+		return snapshot, false, nil
+	}
+	return snapshot, err == nil, err
+}
+
+// PointInTimeBalance is the balance of an account at a point in time.
+// SnapshotDate is the end-of-day snapshot it was computed from, if there was one.
+type PointInTimeBalance struct {
+	AccountID    int64      `json:"account_id"`
+	At           time.Time  `json:"at"`
+	Balance      int64      `json:"balance"`
+	SnapshotDate *time.Time `json:"snapshot_date,omitempty"`
+}
+
+// BalanceAt returns the balance of an account at a point in time: the latest end-of-day snapshot
+// taken no later than that time plus the entries made since. Without such a snapshot it falls back
+// to the current balance minus the entries made after that time.
+func BalanceAt(ctx context.Context, q Querier, accountID int64, at time.Time) (PointInTimeBalance, error) {
+	result := PointInTimeBalance{AccountID: accountID, At: at}
+
+	// The snapshot of a day holds the balance at midnight after it, so it must be of an earlier day
+	snapshot, err := q.GetLatestDailyBalance(ctx, GetLatestDailyBalanceParams{
+		AccountID:   accountID,
+		ThroughDate: InterestDate(at).AddDate(0, 0, -1),
+	})
+	if err == sql.ErrNoRows {
+		result.Balance, err = q.GetAccountBalanceAt(ctx, GetAccountBalanceAtParams{
+			At:        at,
+			AccountID: accountID,
+		})
+		return result, err
+	}
+	if err != nil {
+		return result, err
+	}
+
+	since, err := q.SumEntriesBetween(ctx, SumEntriesBetweenParams{
+		AccountID: accountID,
+		FromTime:  InterestDate(snapshot.BalanceDate).AddDate(0, 0, 1),
+		ToTime:    at,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Balance = snapshot.Balance + since
+	result.SnapshotDate = &snapshot.BalanceDate
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyBalanceSnapshot(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1 = fundAccount(t, account1, 100)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// yesterday's snapshot does not include today's transfer
+	today := InterestDate(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+	snapshot, taken, err := SnapshotDailyBalance(context.Background(), testQueries, account1.ID, yesterday)
+	require.NoError(t, err)
+	require.True(t, taken)
+	require.Equal(t, account1.Balance, snapshot.Balance)
+
+	_, taken, err = SnapshotDailyBalance(context.Background(), testQueries, account1.ID, yesterday)
+	require.NoError(t, err)
+	require.False(t, taken)
+
+	// at midnight the balance is the snapshot, afterwards the snapshot plus today's entries
+	balance, err := BalanceAt(context.Background(), testQueries, account1.ID, today)
+	require.NoError(t, err)
+	require.NotNil(t, balance.SnapshotDate)
+	require.True(t, balance.SnapshotDate.Equal(yesterday))
+	require.Equal(t, account1.Balance, balance.Balance)
+
+	balance, err = BalanceAt(context.Background(), testQueries, account1.ID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, balance.Balance)
+
+	// without a snapshot the balance is worked back from the current one
+	balance, err = BalanceAt(context.Background(), testQueries, account2.ID, today)
+	require.NoError(t, err)
+	require.Nil(t, balance.SnapshotDate)
+	require.Equal(t, account2.Balance, balance.Balance)
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"bytes"
+	"context"
+)
+
+// DefaultChainPageSize is the number of accounts or entries read at a time when verifying hash chains.
+const DefaultChainPageSize = 1000
+
+// BrokenLink is the first entry of an account's hash chain that does not match.
+type BrokenLink struct {
+	AccountID int64  `json:"account_id"`
+	EntryID   int64  `json:"entry_id"`
+	Reason    string `json:"reason"`
+}
+
+// LedgerVerification is the outcome of walking the hash chains of one or all accounts.
+// It is valid when BrokenLink is nil.
+type LedgerVerification struct {
+	Accounts   int64       `json:"accounts"`
+	Entries    int64       `json:"entries"`
+	Valid      bool        `json:"valid"`
+	BrokenLink *BrokenLink `json:"broken_link,omitempty"`
+}
+
+// VerifyLedger walks the hash chain of every account, in ID order, and stops at the first broken link.
+func VerifyLedger(ctx context.Context, q Querier, pageSize int32) (LedgerVerification, error) {
+	result := LedgerVerification{Valid: true}
+	arg := ListAccountIDsParams{Limit: pageSize}
+
+	for {
+		ids, err := q.ListAccountIDs(ctx, arg)
+		if err != nil {
+			return result, err
+		}
+
+		for _, id := range ids {
+			account, err := VerifyAccountChain(ctx, q, id, pageSize)
+			result.Accounts++
+			result.Entries += account.Entries
+			if err != nil || !account.Valid {
+				result.Valid = account.Valid
+				result.BrokenLink = account.BrokenLink
+				return result, err
+			}
+		}
+
+		if len(ids) < int(pageSize) {
+			return result, nil
+		}
+		arg.AfterID = ids[len(ids)-1]
+	}
+}
+
+// VerifyAccountChain walks the hash chain of one account: every entry's hash must match its contents,
+// and every entry must point to the hash of the account's previous entry.
+func VerifyAccountChain(ctx context.Context, q Querier, accountID int64, pageSize int32) (LedgerVerification, error) {
+	result := LedgerVerification{Accounts: 1, Valid: true}
+	arg := ListEntryChainParams{AccountID: accountID, Limit: pageSize}
+	var prevHash []byte
+
+	for {
+		entries, err := q.ListEntryChain(ctx, arg)
+		if err != nil {
+			return result, err
+		}
+
+		for _, entry := range entries {
+			result.Entries++
+
+			reason := ""
+			switch {
+			case !bytes.Equal(entry.Hash, entry.ExpectedHash):
+				reason = "hash does not match the entry's contents"
+			case !bytes.Equal(entry.PrevHash, prevHash):
+				reason = "prev_hash does not match the hash of the account's previous entry"
+			}
+			if reason != "" {
+				result.Valid = false
+				result.BrokenLink = &BrokenLink{AccountID: accountID, EntryID: entry.ID, Reason: reason}
+				return result, nil
+			}
+			prevHash = entry.Hash
+		}
+
+		if len(entries) < int(pageSize) {
+			return result, nil
+		}
+		arg.AfterID = entries[len(entries)-1].ID
+	}
+}
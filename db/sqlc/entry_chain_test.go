@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEntryHashChain tests that the entries of an account are chained as they are written.
+func TestEntryHashChain(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	var results []TransferTxResult
+	for i := 0; i < 3; i++ {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		require.Len(t, result.FromEntry.Hash, 32)
+		results = append(results, result)
+	}
+
+	require.Nil(t, results[0].FromEntry.PrevHash)
+	require.Equal(t, results[0].FromEntry.Hash, results[1].FromEntry.PrevHash)
+	require.Equal(t, results[1].FromEntry.Hash, results[2].FromEntry.PrevHash)
+	require.Equal(t, results[1].ToEntry.Hash, results[2].ToEntry.PrevHash)
+
+	verification, err := VerifyAccountChain(context.Background(), testQueries, account1.ID, 2)
+	require.NoError(t, err)
+	require.True(t, verification.Valid)
+	require.Equal(t, int64(3), verification.Entries)
+}
+
+// TestLedgerAppendOnly tests that entries and transfers cannot be changed or removed once written.
+func TestLedgerAppendOnly(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = testDB.Exec("UPDATE entries SET amount = 1000 WHERE id = $1", result.ToEntry.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = testDB.Exec("DELETE FROM entries WHERE id = $1", result.ToEntry.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = testDB.Exec("UPDATE transfers SET amount = 1000 WHERE id = $1", result.Transfer.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = testDB.Exec("DELETE FROM transfers WHERE id = $1", result.Transfer.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	// Status changes are how transfers move through their lifecycle, so they are still allowed
+	reversed, err := store.ReverseTransferTx(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusReversed, reversed.Transfer.Status)
+}
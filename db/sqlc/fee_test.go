@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// setTransferFee prices transfers in a currency for the duration of a test.
+func setTransferFee(t *testing.T, arg UpsertFeeScheduleParams) FeeSchedule {
+	arg.FeeType = FeeTransfer
+	schedule, err := testQueries.UpsertFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, err := testQueries.DeleteFeeSchedule(context.Background(), DeleteFeeScheduleParams{
+			FeeType:  FeeTransfer,
+			Currency: arg.Currency,
+		})
+		require.NoError(t, err)
+	})
+	return schedule
+}
+
+func TestCalculateFee(t *testing.T) {
+	flat := FeeSchedule{Method: FeeFlat, FlatAmount: 30}
+	require.Equal(t, int64(30), CalculateFee(flat, 1))
+	require.Equal(t, int64(30), CalculateFee(flat, 1_000_000))
+
+	// 0.5%, at least 25 and at most 500
+	percentage := FeeSchedule{Method: FeePercentage, RateBps: 50, MinFee: 25, MaxFee: 500}
+	require.Equal(t, int64(25), CalculateFee(percentage, 1_000))
+	require.Equal(t, int64(51), CalculateFee(percentage, 10_001)) // rounded up
+	require.Equal(t, int64(500), CalculateFee(percentage, 1_000_000))
+
+	uncapped := FeeSchedule{Method: FeePercentage, RateBps: 50}
+	require.Equal(t, int64(5_000), CalculateFee(uncapped, 1_000_000))
+}
+
+// TestTransferTxFee tests that the transfer fee is paid by the sender into the fee income account.
+func TestTransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1 = fundAccount(t, account1, 1_000)
+	setTransferFee(t, UpsertFeeScheduleParams{Currency: account1.Currency, Method: FeeFlat, FlatAmount: 30})
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Fee)
+	require.Equal(t, int64(30), result.Fee.Fee.Amount)
+	require.Equal(t, result.Transfer.ID, result.Fee.Fee.ChargedTransferID.Int64)
+	require.Equal(t, int64(-30), result.Fee.FromEntry.Amount)
+	require.Equal(t, account1.Balance-130, result.FromAccount.Balance)
+
+	income, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		ProductCode: ProductFeeIncome,
+		Currency:    account1.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, income.ID, result.Fee.Transfer.ToAccountID)
+
+	fees, err := testQueries.ListTransferFees(context.Background(), sql.NullInt64{Int64: result.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, fees, 1)
+
+	// The fee counts against the balance: the whole transfer is rolled back when it cannot be paid
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        result.FromAccount.Balance,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	account1, err = testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, account1.Balance)
+}
+
+// TestChargeMaintenanceFeeTx tests that the monthly fee of a product is charged once per month.
+func TestChargeMaintenanceFeeTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	business := fetchAccountProduct(t, ProductBusiness)
+	require.Positive(t, business.MonthlyFee)
+
+	created, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.EUR,
+		ProductCode: ProductBusiness,
+	})
+	require.NoError(t, err)
+	account := created.Account
+
+	periodEnd := time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC)
+	result, err := store.ChargeMaintenanceFeeTx(context.Background(), ChargeMaintenanceFeeTxParams{
+		AccountID: account.ID,
+		PeriodEnd: periodEnd,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Charged)
+	require.Equal(t, business.MonthlyFee, result.Fee.Fee.Amount)
+	require.Equal(t, FeeMaintenance, result.Fee.Fee.FeeType)
+	require.Equal(t, -business.MonthlyFee, result.Account.Balance)
+
+	again, err := store.ChargeMaintenanceFeeTx(context.Background(), ChargeMaintenanceFeeTxParams{
+		AccountID: account.ID,
+		PeriodEnd: periodEnd,
+	})
+	require.NoError(t, err)
+	require.False(t, again.Charged)
+	require.Nil(t, again.Fee)
+	require.Equal(t, -business.MonthlyFee, again.Account.Balance)
+
+	// A checking account has no monthly fee
+	checking := createRandomAccount(t)
+	free, err := store.ChargeMaintenanceFeeTx(context.Background(), ChargeMaintenanceFeeTxParams{
+		AccountID: checking.ID,
+		PeriodEnd: periodEnd,
+	})
+	require.NoError(t, err)
+	require.False(t, free.Charged)
+}
@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createRandomHold places a hold on a fresh account funded with exactly the held amount.
+func createRandomHold(t *testing.T, amount int64, expiresAt time.Time) HoldTxResult {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	account = fundAccount(t, account, amount)
+
+	result, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		AccountID:   account.ID,
+		Amount:      amount,
+		Description: "card authorization",
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, HoldActive, result.Hold.Status)
+	require.Equal(t, amount, result.Hold.Amount)
+	require.Zero(t, result.Hold.CapturedAmount)
+
+	// the hold only lowers the available balance
+	require.Equal(t, account.Balance, result.Account.Balance)
+	require.Equal(t, account.HeldBalance+amount, result.Account.HeldBalance)
+	require.Equal(t, account.AvailableBalance-amount, result.Account.AvailableBalance)
+
+	return result
+}
+
+func TestCreateHoldTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	held := createRandomHold(t, 10, time.Now().Add(time.Hour))
+
+	_, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		AccountID: held.Account.ID,
+		Amount:    held.Account.AvailableBalance + 1,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxChecksAvailableBalance(t *testing.T) {
+	store := NewStore(testDB)
+	held := createRandomHold(t, 10, time.Now().Add(time.Hour))
+	account2 := createRandomAccount(t)
+
+	// the ledger balance would cover the transfer, the available balance does not
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: held.Account.ID,
+		ToAccountID:   account2.ID,
+		Amount:        held.Account.AvailableBalance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: held.Account.ID,
+		Amount:    held.Account.AvailableBalance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// nothing was debited
+	account1, err := testQueries.GetAccount(context.Background(), held.Account.ID)
+	require.NoError(t, err)
+	require.Equal(t, held.Account.Balance, account1.Balance)
+}
+
+func TestCaptureHoldTxPartialWithdrawal(t *testing.T) {
+	store := NewStore(testDB)
+	held := createRandomHold(t, 100, time.Now().Add(time.Hour))
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: held.Hold.ID,
+		Amount: 60,
+		Now:    time.Now(),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.Equal(t, int64(60), result.Hold.CapturedAmount)
+	require.Nil(t, result.Transfer)
+	require.NotNil(t, result.Withdrawal)
+	require.Equal(t, int64(-60), result.Withdrawal.Entry.Amount)
+
+	// 60 was taken and the remaining 40 is available again
+	account := result.Withdrawal.Account
+	require.Equal(t, held.Account.Balance-60, account.Balance)
+	require.Equal(t, held.Account.HeldBalance-100, account.HeldBalance)
+	require.Equal(t, held.Account.AvailableBalance+40, account.AvailableBalance)
+
+	// a captured hold cannot be captured or released again
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: held.Hold.ID, Now: time.Now()})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+	_, err = store.ReleaseHoldTx(context.Background(), held.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestCaptureHoldTxTransfer(t *testing.T) {
+	store := NewStore(testDB)
+	held := createRandomHold(t, 100, time.Now().Add(time.Hour))
+	account2 := createRandomAccount(t)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID:      held.Hold.ID,
+		ToAccountID: account2.ID,
+		Now:         time.Now(),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(100), result.Hold.CapturedAmount)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, TransferStatusPosted, result.Transfer.Transfer.Status)
+	require.Equal(t, int64(100), result.Transfer.Transfer.Amount)
+	require.Equal(t, held.Account.Balance-100, result.Transfer.FromAccount.Balance)
+	require.Equal(t, held.Account.HeldBalance-100, result.Transfer.FromAccount.HeldBalance)
+	require.Equal(t, account2.Balance+100, result.Transfer.ToAccount.Balance)
+}
+
+// TestCaptureHoldTxTransferFee tests that a capture into another account pays the transfer fee like other transfers.
+func TestCaptureHoldTxTransferFee(t *testing.T) {
+	store := NewStore(testDB)
+	held := createRandomHold(t, 100, time.Now().Add(time.Hour))
+	account2 := createRandomAccount(t)
+	setTransferFee(t, UpsertFeeScheduleParams{Currency: held.Account.Currency, Method: FeeFlat, FlatAmount: 30})
+
+	// the account was funded with exactly the held amount, which leaves nothing for the fee
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID:      held.Hold.ID,
+		ToAccountID: account2.ID,
+		Now:         time.Now(),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID:      held.Hold.ID,
+		Amount:      70,
+		ToAccountID: account2.ID,
+		Now:         time.Now(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer.Fee)
+	require.Equal(t, int64(30), result.Transfer.Fee.Fee.Amount)
+	require.Equal(t, held.Account.Balance-100, result.Transfer.FromAccount.Balance)
+}
+
+// TestCaptureHoldTxApproval tests that a capture awaiting approval keeps the captured amount on hold
+// until the transfer is reviewed.
+func TestCaptureHoldTxApproval(t *testing.T) {
+	store := NewStore(testDB)
+	held := createRandomHold(t, 100, time.Now().Add(time.Hour))
+	account2 := createRandomAccount(t)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID:            held.Hold.ID,
+		Amount:            60,
+		ToAccountID:       account2.ID,
+		Now:               time.Now(),
+		RequestedBy:       held.Account.Owner,
+		ApprovalExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.Nil(t, result.Transfer)
+	require.NotNil(t, result.Approval)
+	require.Equal(t, TransferStatusPendingApproval, result.Approval.Transfer.Status)
+	require.Equal(t, TransferApprovalPending, result.Approval.Approval.Status)
+	// the rest of the hold is released, the captured amount stays held for the review
+	require.Equal(t, held.Account.Balance, result.Approval.FromAccount.Balance)
+	require.Equal(t, held.Account.HeldBalance-40, result.Approval.FromAccount.HeldBalance)
+
+	approved, err := store.ApproveTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: result.Approval.Transfer.ID,
+		ReviewedBy: createRandomUser(t).Username,
+		Now:        time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+60, approved.ToAccount.Balance)
+}
+
+func TestReleaseHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	held := createRandomHold(t, 10, time.Now().Add(time.Hour))
+
+	result, err := store.ReleaseHoldTx(context.Background(), held.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldReleased, result.Hold.Status)
+	require.Equal(t, held.Account.Balance, result.Account.Balance)
+	require.Equal(t, held.Account.AvailableBalance+10, result.Account.AvailableBalance)
+}
+
+func TestExpireHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	held := createRandomHold(t, 10, time.Now().Add(-time.Minute))
+
+	// an expired hold can no longer be captured
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: held.Hold.ID, Now: time.Now()})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	// Other expired holds may exist in the test database, so keep expiring until ours has been picked
+	var result HoldTxResult
+	for result.Hold.ID != held.Hold.ID {
+		result, err = store.ExpireHoldTx(context.Background(), time.Now())
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, HoldExpired, result.Hold.Status)
+	require.Equal(t, held.Account.AvailableBalance+10, result.Account.AvailableBalance)
+}
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	result, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    50,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance+50, result.Account.Balance)
+	require.Equal(t, int64(50), result.Entry.Amount)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+	require.Equal(t, TransferStatusPosted, result.Transfer.Status)
+
+	// the money came from the cash clearing account of the currency
+	cash, err := testQueries.GetAccount(context.Background(), result.Transfer.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, SystemUsername, cash.Owner)
+	require.Equal(t, account.Currency, cash.Currency)
+}
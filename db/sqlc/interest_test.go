@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// createSavingsAccount opens a funded savings account, which earns interest.
+func createSavingsAccount(t *testing.T, balance int64) Account {
+	user := createRandomUser(t)
+	result, err := NewStore(testDB).CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.USD,
+		ProductCode: ProductSavings,
+	})
+	require.NoError(t, err)
+	return fundAccount(t, result.Account, balance)
+}
+
+func TestDailyInterest(t *testing.T) {
+	// 1,000.00 at 1.50% is 15.00 a year
+	principal := int64(100_000 * MicrosPerUnit)
+	require.Equal(t, int64(4_109_589), DailyInterest(principal, 150, DayCountACT365))
+	require.Equal(t, int64(4_166_666), DailyInterest(principal, 150, DayCountACT360))
+
+	require.Zero(t, DailyInterest(-principal, 150, DayCountACT365))
+	require.Zero(t, DailyInterest(principal, 0, DayCountACT365))
+}
+
+// TestAccrueInterestTx tests that a day is accrued once, and that compound interest accrues on unposted interest.
+func TestAccrueInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createSavingsAccount(t, 100_000)
+	savings := fetchAccountProduct(t, ProductSavings)
+	require.Equal(t, InterestCompound, savings.InterestMethod)
+
+	today := InterestDate(time.Now())
+	result, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, Date: today})
+	require.NoError(t, err)
+	require.True(t, result.Accrued)
+	require.Equal(t, int64(100_000*MicrosPerUnit), result.Accrual.PrincipalMicros)
+	require.Equal(t, DailyInterest(result.Accrual.PrincipalMicros, savings.InterestRateBps, savings.DayCount), result.Accrual.AmountMicros)
+
+	// Running the same day again records nothing
+	again, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, Date: today.Add(time.Hour)})
+	require.NoError(t, err)
+	require.False(t, again.Accrued)
+
+	tomorrow, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, Date: today.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	require.True(t, tomorrow.Accrued)
+	require.Equal(t, result.Accrual.PrincipalMicros+result.Accrual.AmountMicros, tomorrow.Accrual.PrincipalMicros)
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), ListInterestAccrualsParams{
+		AccountID: account.ID,
+		FromDate:  today,
+		ToDate:    today.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 2)
+}
+
+// TestPostInterestTx tests that the accrued interest is paid once from the interest expense account.
+func TestPostInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createSavingsAccount(t, 1_000_000)
+
+	today := InterestDate(time.Now())
+	accrual, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{AccountID: account.ID, Date: today})
+	require.NoError(t, err)
+	require.True(t, accrual.Accrued)
+
+	result, err := store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, PeriodEnd: today})
+	require.NoError(t, err)
+	require.True(t, result.Posted)
+	require.Equal(t, accrual.Accrual.AmountMicros/MicrosPerUnit, result.Posting.Amount)
+	require.Equal(t, accrual.Accrual.AmountMicros%MicrosPerUnit, result.Posting.RemainderMicros)
+	require.Positive(t, result.Posting.Amount)
+
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Posting.TransferID.Int64)
+	require.Equal(t, account.Balance+result.Posting.Amount, result.Transfer.ToAccount.Balance)
+
+	expense, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		ProductCode: ProductInterestExpense,
+		Currency:    util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, expense.ID, result.Transfer.Transfer.FromAccountID)
+
+	// The period is paid once, and its accruals are not paid again by the next posting
+	again, err := store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, PeriodEnd: today})
+	require.NoError(t, err)
+	require.False(t, again.Posted)
+
+	next, err := store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, PeriodEnd: today.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	require.True(t, next.Posted)
+	require.Equal(t, result.Posting.RemainderMicros, next.Posting.AccruedMicros)
+	require.Nil(t, next.Transfer)
+}
+
+// TestCreateAccountTxInternalProduct tests that users cannot open accounts of the bank's own products.
+func TestCreateAccountTxInternalProduct(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := NewStore(testDB).CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.USD,
+		ProductCode: ProductInterestExpense,
+	})
+	require.ErrorIs(t, err, ErrUnknownProduct)
+}
@@ -0,0 +1,322 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// setRandomUserKYCTier verifies a user with the given tier, without a profile.
+func setRandomUserKYCTier(t *testing.T, username, tier string) User {
+	user, err := testQueries.SetUserKYC(context.Background(), SetUserKYCParams{
+		KycStatus: KYCStatusVerified,
+		KycTier:   sql.NullString{String: tier, Valid: true},
+		Username:  username,
+	})
+	require.NoError(t, err)
+	return user
+}
+
+// submitRandomKYCProfile submits a KYC profile with one document for a new user.
+func submitRandomKYCProfile(t *testing.T, store Store) User {
+	user := createRandomUser(t)
+	require.Equal(t, KYCStatusNone, user.KycStatus)
+	require.Equal(t, KYCTierBasic, user.KycTier)
+
+	result, err := store.SubmitKYCProfileTx(context.Background(), SubmitKYCProfileTxParams{
+		Username:     user.Username,
+		DateOfBirth:  time.Date(1990, time.March, 14, 0, 0, 0, 0, time.UTC),
+		AddressLine1: "1 High Street",
+		City:         "Leeds",
+		PostalCode:   "LS1 1AA",
+		Country:      "GB",
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusPending, result.User.KycStatus)
+	require.Equal(t, KYCTierBasic, result.User.KycTier)
+	require.Equal(t, "Leeds", result.Profile.City)
+	require.False(t, result.Profile.ReviewedBy.Valid)
+
+	document, err := store.AddKYCDocumentTx(context.Background(), CreateKYCDocumentParams{
+		Username:    user.Username,
+		Kind:        KYCDocumentPassport,
+		BlobKey:     "kyc/" + user.Username + "/" + util.RandomString(16),
+		ContentType: "application/pdf",
+		Size:        100,
+		Sha256:      util.RandomString(64),
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, document.Username)
+
+	return result.User
+}
+
+func TestReviewKYCTxApprove(t *testing.T) {
+	store := NewStore(testDB)
+	user := submitRandomKYCProfile(t, store)
+	reviewer := createRandomUser(t)
+
+	// Users cannot review themselves
+	_, err := store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		Username: user.Username,
+		Reviewer: user.Username,
+		Approve:  true,
+		Now:      time.Now(),
+	})
+	require.ErrorIs(t, err, ErrKYCSelfReview)
+
+	_, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		Username: user.Username,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Tier:     "platinum",
+		Now:      time.Now(),
+	})
+	require.ErrorIs(t, err, ErrUnknownKYCTier)
+
+	result, err := store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		Username: user.Username,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Now:      time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusVerified, result.User.KycStatus)
+	require.Equal(t, KYCTierStandard, result.User.KycTier)
+	require.Equal(t, reviewer.Username, result.Profile.ReviewedBy.String)
+	require.True(t, result.Profile.ReviewedAt.Valid)
+
+	// Verified profiles cannot be changed or reviewed again
+	_, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		Username: user.Username,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Now:      time.Now(),
+	})
+	require.ErrorIs(t, err, ErrKYCNotPending)
+	_, err = store.SubmitKYCProfileTx(context.Background(), SubmitKYCProfileTxParams{Username: user.Username, DateOfBirth: time.Now()})
+	require.ErrorIs(t, err, ErrKYCVerified)
+
+	events := listAuditEventsOf(t, AuditUser, user.Username)
+	require.Equal(t, "user.kyc_submit", events[0].Action)
+	require.Equal(t, "user.kyc_approve", events[len(events)-1].Action)
+}
+
+func TestReviewKYCTxReject(t *testing.T) {
+	store := NewStore(testDB)
+	user := submitRandomKYCProfile(t, store)
+	reviewer := createRandomUser(t)
+
+	result, err := store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		Username: user.Username,
+		Reviewer: reviewer.Username,
+		Reason:   "document is expired",
+		Now:      time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusRejected, result.User.KycStatus)
+	require.Equal(t, KYCTierBasic, result.User.KycTier)
+	require.Equal(t, "document is expired", result.Profile.RejectionReason)
+
+	// A rejected user can submit their profile again, which clears the review
+	again, err := store.SubmitKYCProfileTx(context.Background(), SubmitKYCProfileTxParams{
+		Username:     user.Username,
+		DateOfBirth:  time.Date(1990, time.March, 14, 0, 0, 0, 0, time.UTC),
+		AddressLine1: "2 High Street",
+		City:         "Leeds",
+		PostalCode:   "LS1 1AA",
+		Country:      "GB",
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusPending, again.User.KycStatus)
+	require.Empty(t, again.Profile.RejectionReason)
+	require.False(t, again.Profile.ReviewedBy.Valid)
+}
+
+func TestReviewKYCTxNoDocuments(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	reviewer := createRandomUser(t)
+
+	_, err := store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		Username: user.Username,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Now:      time.Now(),
+	})
+	require.ErrorIs(t, err, ErrKYCNotPending)
+
+	_, err = store.SubmitKYCProfileTx(context.Background(), SubmitKYCProfileTxParams{
+		Username:     user.Username,
+		DateOfBirth:  time.Date(1990, time.March, 14, 0, 0, 0, 0, time.UTC),
+		AddressLine1: "1 High Street",
+		City:         "Leeds",
+		PostalCode:   "LS1 1AA",
+		Country:      "GB",
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		Username: user.Username,
+		Reviewer: reviewer.Username,
+		Approve:  true,
+		Now:      time.Now(),
+	})
+	require.ErrorIs(t, err, ErrKYCNoDocuments)
+}
+
+// TestCreateAccountTxKYCLimit tests that users on the basic tier can open a single account.
+func TestCreateAccountTxKYCLimit(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.USD,
+		ProductCode: ProductChecking,
+	})
+	require.NoError(t, err)
+
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.EUR,
+		ProductCode: ProductChecking,
+	})
+	require.ErrorIs(t, err, ErrKYCAccountLimit)
+
+	setRandomUserKYCTier(t, user.Username, KYCTierStandard)
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:       user.Username,
+		Currency:    util.EUR,
+		ProductCode: ProductChecking,
+	})
+	require.NoError(t, err)
+}
+
+// TestTransferTxKYCDailyLimit tests that the transfers of a day count toward the daily limit of the sender's tier.
+func TestTransferTxKYCDailyLimit(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     createRandomUser(t).Username,
+		Currency:  account1.Currency,
+		ProductID: account1.ProductID,
+	})
+	require.NoError(t, err)
+	_, err = store.DepositTx(context.Background(), DepositTxParams{AccountID: account1.ID, Amount: 150000})
+	require.NoError(t, err)
+
+	basic, err := testQueries.GetKYCTier(context.Background(), KYCTierBasic)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        basic.DailyTransferLimit - 10,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// Nothing moved with the refused transfer
+	refused, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance+150000-(basic.DailyTransferLimit-10), refused.Balance)
+
+	// Verified users have higher limits
+	setRandomUserKYCTier(t, account1.Owner, KYCTierStandard)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+	})
+	require.NoError(t, err)
+}
+
+// TestWithdrawTxKYCDailyLimit tests that withdrawals count toward the daily limit like transfers to customers.
+func TestWithdrawTxKYCDailyLimit(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     createRandomUser(t).Username,
+		Currency:  account1.Currency,
+		ProductID: account1.ProductID,
+	})
+	require.NoError(t, err)
+	_, err = store.DepositTx(context.Background(), DepositTxParams{AccountID: account1.ID, Amount: 150000})
+	require.NoError(t, err)
+
+	basic, err := testQueries.GetKYCTier(context.Background(), KYCTierBasic)
+	require.NoError(t, err)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account1.ID,
+		Amount:    basic.DailyTransferLimit - 10,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+}
+
+// TestWithdrawTxKYCDailyLimitRejected tests that a withdrawal over the daily limit is itself rejected.
+func TestWithdrawTxKYCDailyLimitRejected(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	_, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account.ID, Amount: 150000})
+	require.NoError(t, err)
+
+	basic, err := testQueries.GetKYCTier(context.Background(), KYCTierBasic)
+	require.NoError(t, err)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    basic.DailyTransferLimit + 1,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    basic.DailyTransferLimit,
+	})
+	require.NoError(t, err)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{AccountID: account.ID, Amount: 1})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+}
+
+// TestDepositTxKYCBalanceCap tests that deposits cannot take an account over the balance cap of its owner's tier.
+func TestDepositTxKYCBalanceCap(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	basic, err := testQueries.GetKYCTier(context.Background(), KYCTierBasic)
+	require.NoError(t, err)
+
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    basic.MaxBalance - account.Balance,
+	})
+	require.NoError(t, err)
+
+	_, err = store.DepositTx(context.Background(), DepositTxParams{AccountID: account.ID, Amount: 1})
+	require.ErrorIs(t, err, ErrBalanceCapExceeded)
+
+	setRandomUserKYCTier(t, account.Owner, KYCTierEnhanced)
+	result, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account.ID, Amount: 1})
+	require.NoError(t, err)
+	require.Equal(t, basic.MaxBalance+1, result.Account.Balance)
+}
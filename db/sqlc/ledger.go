@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Classes of the chart of accounts. Account balances are signed so that they sum to zero across the ledger:
+// credit balances (liabilities and income) are positive, debit balances (assets and expenses) are negative.
+const (
+	LedgerAsset     = "asset"
+	LedgerLiability = "liability" // customer accounts: money the bank owes its customers
+	LedgerIncome    = "income"
+	LedgerExpense   = "expense"
+)
+
+// SystemUsername owns the bank's own ledger accounts, the system accounts.
+const SystemUsername = "system"
+
+// Internal products of the system accounts, of which there is one per currency, opened on first use
+const (
+	ProductCashClearing    = "cash_clearing"    // asset: money that entered or left the bank, e.g. cash withdrawals
+	ProductTransit         = "transit"          // liability: pending transfers debited from the sender but not credited yet
+	ProductFXPosition      = "fx_position"      // asset: the bank's position in a currency after conversions
+	ProductFeeIncome       = "fee_income"       // income: fees charged to customers
+	ProductInterestExpense = "interest_expense" // expense: interest paid to customers
+)
+
+// systemAccount returns the system account of an internal product in a currency, opening it on first use.
+// System accounts must be updated after the customer accounts of the same transaction, so that they are
+// always locked last and cannot deadlock with transactions that lock customer accounts in ID order.
+func systemAccount(ctx context.Context, q *Queries, productCode, currency string) (Account, error) {
+	arg := GetSystemAccountParams{ProductCode: productCode, Currency: currency}
+
+	account, err := q.GetSystemAccount(ctx, arg)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return account, err
+	}
+
+	product, err := q.GetAccountProductByCode(ctx, productCode)
+	if err != nil {
+		return account, err
+	}
+	// Another transaction may open the same account first, in which case the insert does nothing
+	account, err = q.CreateSystemAccount(ctx, CreateSystemAccountParams{
+		Currency:  currency,
+		ProductID: product.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return q.GetSystemAccount(ctx, arg)
+	}
+	return account, err
+}
+
+// entryTransfer is the TransferID of the entries posted for a transfer. The entries of a transfer
+// must sum to zero, which the database checks when the transaction commits.
+func entryTransfer(transferID int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: transferID, Valid: true}
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requireBalancedEntries checks that the entries of a transfer sum to zero and returns them.
+func requireBalancedEntries(t *testing.T, transferID int64) []Entry {
+	entries, err := testQueries.ListTransferEntries(context.Background(), sql.NullInt64{Int64: transferID, Valid: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	var sum int64
+	for _, entry := range entries {
+		sum += entry.Amount
+	}
+	require.Zero(t, sum)
+	return entries
+}
+
+// TestEntriesMustBalance tests that a transaction leaving the entries of a transfer unbalanced cannot commit.
+func TestEntriesMustBalance(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		transfer, err := q.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+			Status:        TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID:  account1.ID,
+			Amount:     -10,
+			TransferID: entryTransfer(transfer.ID),
+		})
+		return err
+	})
+	require.ErrorContains(t, err, "do not balance")
+}
+
+// TestPendingTransferTxTransit tests that a pending transfer parks its amount in the transit account.
+func TestPendingTransferTxTransit(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account1 = fundAccount(t, account1, 100)
+
+	result, err := store.PendingTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	transit, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		ProductCode: ProductTransit,
+		Currency:    account1.Currency,
+	})
+	require.NoError(t, err)
+
+	entries := requireBalancedEntries(t, result.Transfer.ID)
+	require.Len(t, entries, 2)
+	require.Equal(t, transit.ID, entries[1].AccountID)
+	require.Equal(t, int64(100), entries[1].Amount)
+}
+
+// TestWithdrawTxCashClearing tests that a withdrawal is a balanced transfer to the cash clearing account.
+func TestWithdrawTxCashClearing(t *testing.T) {
+	store := NewStore(testDB)
+	account := fundAccount(t, createRandomAccount(t), 100)
+
+	result, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    40,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-40, result.Account.Balance)
+	require.Equal(t, result.Transfer.ID, result.Entry.TransferID.Int64)
+
+	cash, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		ProductCode: ProductCashClearing,
+		Currency:    account.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, cash.ID, result.Transfer.ToAccountID)
+	requireBalancedEntries(t, result.Transfer.ID)
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// createRandomLoginAttempt records a login attempt for username from clientIP
+func createRandomLoginAttempt(t *testing.T, username, clientIP string, success bool) LoginAttempt {
+	attempt, err := testQueries.CreateLoginAttempt(context.Background(), CreateLoginAttemptParams{
+		Username:  username,
+		ClientIp:  clientIP,
+		UserAgent: "test",
+		Success:   success,
+	})
+	require.NoError(t, err)
+	require.Equal(t, username, attempt.Username)
+	require.Equal(t, success, attempt.Success)
+	return attempt
+}
+
+func TestCountRecentLoginFailures(t *testing.T) {
+	// Attempts for unknown usernames are counted too
+	username := util.RandomOwner()
+	clientIP := "192.0.2." + util.RandomString(3)
+	since := time.Now().Add(-time.Minute)
+
+	createRandomLoginAttempt(t, username, clientIP, false)
+	createRandomLoginAttempt(t, username, clientIP, false)
+
+	arg := CountRecentLoginFailuresParams{Username: username, Since: since}
+	failures, err := testQueries.CountRecentLoginFailures(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), failures)
+
+	byIP, err := testQueries.CountRecentLoginFailuresByIP(context.Background(), CountRecentLoginFailuresByIPParams{ClientIp: clientIP, Since: since})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), byIP)
+
+	// A successful login starts the count again
+	createRandomLoginAttempt(t, username, clientIP, true)
+	createRandomLoginAttempt(t, username, clientIP, false)
+	failures, err = testQueries.CountRecentLoginFailures(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), failures)
+
+	arg.Since = time.Now().Add(time.Minute)
+	failures, err = testQueries.CountRecentLoginFailures(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, failures)
+
+	attempts, err := testQueries.ListLoginAttempts(context.Background(), ListLoginAttemptsParams{Username: username, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, attempts, 4)
+	require.False(t, attempts[0].Success)
+	require.True(t, attempts[1].Success)
+}
+
+func TestUnlockUserLogin(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.True(t, user.LoginUnlockedAt.IsZero())
+
+	now := time.Now()
+	unlocked, err := store.UnlockUserLogin(context.Background(), UnlockUserLoginParams{LoginUnlockedAt: now, Username: user.Username})
+	require.NoError(t, err)
+	require.WithinDuration(t, now, unlocked.LoginUnlockedAt, time.Second)
+
+	events := listAuditEventsOf(t, AuditUser, user.Username)
+	require.NotEmpty(t, events)
+	require.Equal(t, "user.unlock_login", events[len(events)-1].Action)
+}
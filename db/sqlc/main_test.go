@@ -13,6 +13,10 @@ import (
 var testQueries *Queries
 var testDB *sql.DB
 
+// testPII encrypts personal data with the master keys of the config, which every run shares as data keys are kept
+var testPII *PIICipher
+var testMasterKeys []string
+
 // Test main is the entry point for all unit tests in your package by default
 func TestMain(m *testing.M) {
 	config, err := util.LoadConfig("../..")
@@ -25,6 +29,15 @@ func TestMain(m *testing.M) {
 	}
 	// If there are no errors, initialize testQueries with the connection object
 	testQueries = New(testDB)
+
+	testMasterKeys, err = config.PIIMasterKeys()
+	if err != nil {
+		log.Fatal("cannot load the master keys:", err)
+	}
+	testPII, err = NewPIICipher(testMasterKeys, config.PIIBlindIndexKey)
+	if err != nil {
+		log.Fatal("cannot create the personal data cipher:", err)
+	}
 	os.Exit(m.Run())
 
 }
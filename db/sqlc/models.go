@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -14,16 +16,334 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// funds reserved by holds and transfers awaiting approval, still part of balance
+	HeldBalance      int64 `json:"held_balance"`
+	AvailableBalance int64 `json:"available_balance"`
+	// frozen accounts can still be credited, closed accounts cannot move money at all
+	Status    string       `json:"status"`
+	ClosedAt  sql.NullTime `json:"closed_at"`
+	ProductID int64        `json:"product_id"`
+	// how far the available balance may go below zero, copied from the product when the account is opened
+	OverdraftLimit int64 `json:"overdraft_limit"`
+}
+
+type AccountProduct struct {
+	ID             int64  `json:"id"`
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+	OverdraftLimit int64  `json:"overdraft_limit"`
+	// yearly interest rate in basis points (1/100 of a percent)
+	InterestRateBps int32 `json:"interest_rate_bps"`
+	MonthlyFee      int64 `json:"monthly_fee"`
+	// open accounts of this product a user can hold, across currencies
+	MaxAccountsPerOwner int32     `json:"max_accounts_per_owner"`
+	CreatedAt           time.Time `json:"created_at"`
+	// internal products are the bank's own ledger accounts and cannot be opened by users
+	Internal bool `json:"internal"`
+	// compound interest also accrues on interest accrued but not posted yet
+	InterestMethod string `json:"interest_method"`
+	DayCount       string `json:"day_count"`
+	// chart of accounts class; customer accounts are liabilities of the bank
+	LedgerClass string `json:"ledger_class"`
+}
+
+type AmlAlert struct {
+	ID         int64 `json:"id"`
+	TransferID int64 `json:"transfer_id"`
+	// customer account the alert is about, the sender of the transfer unless it came from the bank
+	AccountID int64 `json:"account_id"`
+	// owner of the account, the subject of the case
+	Owner string `json:"owner"`
+	// name of the rule that raised the alert
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	// figures the rule matched on
+	Details json.RawMessage `json:"details"`
+	// open, investigating, escalated or closed
+	Status string `json:"status"`
+	// investigator working the case
+	AssignedTo sql.NullString `json:"assigned_to"`
+	// false_positive or reported once closed
+	Resolution string       `json:"resolution"`
+	Notes      string       `json:"notes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	ClosedAt   sql.NullTime `json:"closed_at"`
+}
+
+type AmlScreeningQueue struct {
+	TransferID int64     `json:"transfer_id"`
+	QueuedAt   time.Time `json:"queued_at"`
+	// screenings that failed, the monitor gives up on the transfer after too many
+	Attempts int32 `json:"attempts"`
+	// why the last screening failed, for whoever looks into a transfer left queued
+	LastError string `json:"last_error"`
+}
+
+type ApiKey struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	// public part of the key, which finds it without the secret
+	Prefix string `json:"prefix"`
+	// SHA-256 of the secret part of the key, the key itself is never stored
+	SecretHash string   `json:"secret_hash"`
+	Scopes     []string `json:"scopes"`
+	// the key does not expire when null
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// username that made the change, system for background jobs
+	Actor     string `json:"actor"`
+	ActorRole string `json:"actor_role"`
+	// resource type and verb, such as account.create
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	// resource before the change, null when it was created
+	Before json.RawMessage `json:"before"`
+	// resource after the change, null when it was deleted
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	RemoteAddr string          `json:"remote_addr"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type Conversion struct {
+	ID             int64     `json:"id"`
+	FromTransferID int64     `json:"from_transfer_id"`
+	ToTransferID   int64     `json:"to_transfer_id"`
+	Rate           string    `json:"rate"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type DailyBalance struct {
+	AccountID   int64     `json:"account_id"`
+	BalanceDate time.Time `json:"balance_date"`
+	// balance at the end of the day, midnight UTC
+	Balance   int64     `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DataKey struct {
+	ID int64 `json:"id"`
+	// AES-256-GCM data key sealed with the master key, which is only in the config
+	WrappedKey []byte `json:"wrapped_key"`
+	// fingerprint of the master key that sealed the data key
+	MasterKeyID string    `json:"master_key_id"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// can be negative or positive
-	Amount    int64     `json:"amount"`
+	Amount     int64         `json:"amount"`
+	CreatedAt  time.Time     `json:"created_at"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// hash of the previous entry of the same account, null for the first one
+	PrevHash []byte `json:"prev_hash"`
+	// entry_hash() of the entry, chained to prev_hash
+	Hash []byte `json:"hash"`
+}
+
+type ExchangeRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// minor units of the quote currency per minor unit of the base currency
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Fee struct {
+	ID        int64  `json:"id"`
+	AccountID int64  `json:"account_id"`
+	FeeType   string `json:"fee_type"`
+	Amount    int64  `json:"amount"`
+	// transfer the fee was charged on, for transfer fees
+	ChargedTransferID sql.NullInt64 `json:"charged_transfer_id"`
+	// last day of the month a maintenance fee was charged for
+	PeriodEnd sql.NullTime `json:"period_end"`
+	// transfer that moved the fee to the fee income account, or to the FX position account for FX spreads
+	TransferID int64     `json:"transfer_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FeeSchedule struct {
+	ID         int64  `json:"id"`
+	FeeType    string `json:"fee_type"`
+	Currency   string `json:"currency"`
+	Method     string `json:"method"`
+	FlatAmount int64  `json:"flat_amount"`
+	// percentage fees in basis points (1/100 of a percent) of the amount
+	RateBps int32 `json:"rate_bps"`
+	MinFee  int64 `json:"min_fee"`
+	// cap on percentage fees, 0 for no cap
+	MaxFee    int64     `json:"max_fee"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Hold struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// amount actually taken on capture, the rest is released
+	CapturedAmount int64     `json:"captured_amount"`
+	Description    string    `json:"description"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type InterestAccrual struct {
+	ID              int64     `json:"id"`
+	AccountID       int64     `json:"account_id"`
+	AccrualDate     time.Time `json:"accrual_date"`
+	PrincipalMicros int64     `json:"principal_micros"`
+	RateBps         int32     `json:"rate_bps"`
+	InterestMethod  string    `json:"interest_method"`
+	DayCount        string    `json:"day_count"`
+	// interest for the day in millionths of the minor unit
+	AmountMicros int64         `json:"amount_micros"`
+	PostingID    sql.NullInt64 `json:"posting_id"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type InterestPosting struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	PeriodEnd time.Time `json:"period_end"`
+	// interest accrued over the period plus the remainder of the previous posting
+	AccruedMicros int64 `json:"accrued_micros"`
+	Amount        int64 `json:"amount"`
+	// fraction of a minor unit left unpaid, carried over to the next posting
+	RemainderMicros int64         `json:"remainder_micros"`
+	TransferID      sql.NullInt64 `json:"transfer_id"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+type KycDocument struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// passport, id_card, driving_licence or proof_of_address
+	Kind string `json:"kind"`
+	// key of the uploaded file in the blob store
+	BlobKey     string    `json:"blob_key"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Sha256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type KycProfile struct {
+	Username     string    `json:"username"`
+	DateOfBirth  time.Time `json:"date_of_birth"`
+	AddressLine1 string    `json:"address_line1"`
+	AddressLine2 string    `json:"address_line2"`
+	City         string    `json:"city"`
+	PostalCode   string    `json:"postal_code"`
+	// ISO 3166-1 alpha-2 code
+	Country         string         `json:"country"`
+	SubmittedAt     time.Time      `json:"submitted_at"`
+	ReviewedBy      sql.NullString `json:"reviewed_by"`
+	ReviewedAt      sql.NullTime   `json:"reviewed_at"`
+	RejectionReason string         `json:"rejection_reason"`
+}
+
+type KycTier struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// open accounts a user can hold across products, 0 for no limit
+	MaxOpenAccounts int32 `json:"max_open_accounts"`
+	// total a user can send per UTC day across their accounts, 0 for no limit
+	DailyTransferLimit int64 `json:"daily_transfer_limit"`
+	// highest balance an account of the user can reach through transfers and deposits, 0 for no limit
+	MaxBalance int64     `json:"max_balance"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	ID int64 `json:"id"`
+	// username that was tried, which may not exist, so failures are counted the same for every username
+	Username  string    `json:"username"`
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type PasswordResetToken struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// SHA-256 of the token sent to the user, the token itself is never stored
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Role struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// whether admins can give the role to users
+	Assignable bool `json:"assignable"`
+}
+
+type RolePermission struct {
+	Role       string `json:"role"`
+	Permission string `json:"permission"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	ScheduleType  string `json:"schedule_type"`
+	// five-field cron expression, empty for one-off transfers
+	CronExpression string        `json:"cron_expression"`
+	NextRunAt      time.Time     `json:"next_run_at"`
+	EndAt          sql.NullTime  `json:"end_at"`
+	MaxRuns        sql.NullInt32 `json:"max_runs"`
+	RunCount       int32         `json:"run_count"`
+	// consecutive failed executions, reset on success
+	FailureCount int32     `json:"failure_count"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64         `json:"id"`
+	ScheduledTransferID int64         `json:"scheduled_transfer_id"`
+	ScheduledFor        time.Time     `json:"scheduled_for"`
+	Status              string        `json:"status"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	Error               string        `json:"error"`
+	CreatedAt           time.Time     `json:"created_at"`
+}
+
+type TotpRecoveryCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// SHA-256 of the normalized recovery code
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
@@ -31,6 +351,43 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	Status    string    `json:"status"`
+}
+
+type TransferApproval struct {
+	TransferID  int64          `json:"transfer_id"`
+	RequestedBy string         `json:"requested_by"`
+	Status      string         `json:"status"`
+	ReviewedBy  sql.NullString `json:"reviewed_by"`
+	ReviewedAt  sql.NullTime   `json:"reviewed_at"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type TransferBatch struct {
+	ID             int64        `json:"id"`
+	RequestedBy    string       `json:"requested_by"`
+	Mode           string       `json:"mode"`
+	Status         string       `json:"status"`
+	ItemCount      int32        `json:"item_count"`
+	SucceededCount int32        `json:"succeeded_count"`
+	FailedCount    int32        `json:"failed_count"`
+	CreatedAt      time.Time    `json:"created_at"`
+	CompletedAt    sql.NullTime `json:"completed_at"`
+}
+
+type TransferBatchItem struct {
+	ID      int64 `json:"id"`
+	BatchID int64 `json:"batch_id"`
+	// index of the item in the submitted batch, starting at 0
+	Position      int32         `json:"position"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	Status        string        `json:"status"`
+	TransferID    sql.NullInt64 `json:"transfer_id"`
+	Error         string        `json:"error"`
+	CreatedAt     time.Time     `json:"created_at"`
 }
 
 type User struct {
@@ -40,4 +397,45 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	// last unlock by an admin, failed logins before it no longer count
+	LoginUnlockedAt time.Time `json:"login_unlocked_at"`
+	// when the user deactivated themselves, the user and their records are kept for retention but cannot log in
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// when the personal data of the deactivated user was pseudonymized, their financial records are kept
+	ErasedAt sql.NullTime `json:"erased_at"`
+	// keyed hash of the email, which finds users by email once it is encrypted
+	EmailIndex sql.NullString `json:"email_index"`
+	// data key that encrypted full_name and email, NULL while they are in plaintext
+	DataKeyID sql.NullInt64 `json:"data_key_id"`
+	// none, pending, verified or rejected
+	KycStatus string `json:"kyc_status"`
+	// limits of the user, raised when their KYC profile is verified
+	KycTier string `json:"kyc_tier"`
+}
+
+type UserTotp struct {
+	Username string `json:"username"`
+	// AES-256-GCM sealed TOTP secret, the key is in the config
+	SecretEncrypted []byte `json:"secret_encrypted"`
+	// NULL until the user confirms enrolment with a first code
+	EnabledAt sql.NullTime `json:"enabled_at"`
+	// time step of the last accepted code, a code is only accepted once
+	LastUsedStep int64     `json:"last_used_step"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type VerifyEmail struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// address the code was sent to while emails are in plaintext, empty once they are encrypted
+	Email string `json:"email"`
+	// SHA-256 of the secret code in the verification link
+	SecretCodeHash string       `json:"secret_code_hash"`
+	ExpiresAt      time.Time    `json:"expires_at"`
+	UsedAt         sql.NullTime `json:"used_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	// blind index of the address the code was sent to, NULL while emails are in plaintext
+	EmailIndex sql.NullString `json:"email_index"`
 }
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// createRandomResetToken stores a reset token for the user that expires after ttl
+func createRandomResetToken(t *testing.T, username string, ttl time.Duration) PasswordResetToken {
+	token, err := testQueries.CreatePasswordResetToken(context.Background(), CreatePasswordResetTokenParams{
+		Username:  username,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	require.NoError(t, err)
+	require.False(t, token.UsedAt.Valid)
+	return token
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	token := createRandomResetToken(t, user.Username, time.Hour)
+	other := createRandomResetToken(t, user.Username, time.Hour)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+	now := time.Now().Truncate(time.Microsecond)
+
+	arg := ResetPasswordTxParams{TokenHash: token.TokenHash, HashedPassword: hashedPassword, Now: now}
+	updated, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, updated.HashedPassword)
+	require.WithinDuration(t, now, updated.PasswordChangedAt, time.Microsecond)
+
+	// The token is single-use, and the user's other outstanding tokens are used up with it
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrResetTokenInvalid)
+
+	arg.TokenHash = other.TokenHash
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrResetTokenInvalid)
+}
+
+func TestResetPasswordTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	token := createRandomResetToken(t, user.Username, -time.Minute)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	arg := ResetPasswordTxParams{TokenHash: token.TokenHash, HashedPassword: hashedPassword, Now: time.Now()}
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrResetTokenExpired)
+
+	unchanged, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.HashedPassword, unchanged.HashedPassword)
+
+	// An expired token cannot be tried again
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrResetTokenInvalid)
+}
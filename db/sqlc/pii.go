@@ -0,0 +1,349 @@
+package db
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+const (
+	// dataKeySize is the size of the AES-256 data keys
+	dataKeySize = 32
+	// dataKeyCacheTTL is how long the active data key is used before the newest one is read again,
+	// so a key rotated by another process is picked up
+	dataKeyCacheTTL = time.Minute
+)
+
+// Errors returned when personal data cannot be sealed or opened
+var (
+	ErrPIIKeyMissing     = errors.New("personal data is encrypted but no master key is configured")
+	ErrUnknownMasterKey  = errors.New("data key is sealed with a master key that is not configured")
+	ErrBlindIndexKeySize = errors.New("blind index key must be exactly 32 characters")
+)
+
+// PIICipher encrypts the full names and emails of users with envelope encryption. They are sealed
+// with AES-256-GCM data keys, which are stored in the data_keys table sealed with a master key
+// that is only in the config. Emails also get a blind index, a keyed hash that finds users by email.
+//
+// Users are stored with the data key that sealed them, or none while they are in plaintext, so the
+// data can be encrypted, and keys rotated, while the bank is running.
+type PIICipher struct {
+	masterKeys      map[string]*util.Encrypter
+	currentMasterID string
+	blindIndexKey   []byte
+
+	mu          sync.Mutex
+	dataKeys    map[int64]*util.Encrypter
+	activeKeyID int64
+	activeAt    time.Time
+}
+
+// NewPIICipher creates a cipher from master keys of exactly 32 characters, the current one first,
+// followed by older ones whose data keys are still to be rewrapped.
+func NewPIICipher(masterKeys []string, blindIndexKey string) (*PIICipher, error) {
+	if len(masterKeys) == 0 {
+		return nil, errors.New("at least one master key is required")
+	}
+	if len(blindIndexKey) != dataKeySize {
+		return nil, ErrBlindIndexKeySize
+	}
+
+	cipher := &PIICipher{
+		masterKeys:      make(map[string]*util.Encrypter),
+		currentMasterID: masterKeyID(masterKeys[0]),
+		blindIndexKey:   []byte(blindIndexKey),
+		dataKeys:        make(map[int64]*util.Encrypter),
+	}
+	for _, key := range masterKeys {
+		encrypter, err := util.NewEncrypter(key)
+		if err != nil {
+			return nil, fmt.Errorf("master key: %w", err)
+		}
+		cipher.masterKeys[masterKeyID(key)] = encrypter
+	}
+	return cipher, nil
+}
+
+// masterKeyID is the fingerprint of a master key, which tells which one sealed a data key without revealing it.
+func masterKeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// blindIndex returns the keyed hash of an email. It is not valid without a cipher, as emails are then in plaintext.
+func (cipher *PIICipher) blindIndex(email string) sql.NullString {
+	if cipher == nil {
+		return sql.NullString{}
+	}
+	mac := hmac.New(sha256.New, cipher.blindIndexKey)
+	mac.Write([]byte(email))
+	return sql.NullString{String: hex.EncodeToString(mac.Sum(nil)), Valid: true}
+}
+
+// openDataKey decrypts a data key with the master key that sealed it.
+func (cipher *PIICipher) openDataKey(key DataKey) ([]byte, error) {
+	master, ok := cipher.masterKeys[key.MasterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("data key %d: %w", key.ID, ErrUnknownMasterKey)
+	}
+	plaintext, err := master.Decrypt(key.WrappedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("data key %d: %w", key.ID, err)
+	}
+	return plaintext, nil
+}
+
+// unwrap returns the encrypter of a data key.
+func (cipher *PIICipher) unwrap(key DataKey) (*util.Encrypter, error) {
+	plaintext, err := cipher.openDataKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewEncrypter(string(plaintext))
+}
+
+// rewrap seals a data key again with the current master key.
+func (cipher *PIICipher) rewrap(key DataKey) ([]byte, error) {
+	plaintext, err := cipher.openDataKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.masterKeys[cipher.currentMasterID].Encrypt(plaintext, nil)
+}
+
+// newDataKey creates a random data key sealed with the current master key. It becomes the active key.
+func (cipher *PIICipher) newDataKey(ctx context.Context, q Querier) (DataKey, error) {
+	plaintext := make([]byte, dataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return DataKey{}, err
+	}
+	wrapped, err := cipher.masterKeys[cipher.currentMasterID].Encrypt(plaintext, nil)
+	if err != nil {
+		return DataKey{}, err
+	}
+	key, err := q.CreateDataKey(ctx, CreateDataKeyParams{WrappedKey: wrapped, MasterKeyID: cipher.currentMasterID})
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	encrypter, err := util.NewEncrypter(string(plaintext))
+	if err != nil {
+		return DataKey{}, err
+	}
+	cipher.mu.Lock()
+	defer cipher.mu.Unlock()
+	cipher.dataKeys[key.ID] = encrypter
+	cipher.activeKeyID = key.ID
+	cipher.activeAt = time.Now()
+	return key, nil
+}
+
+// dataKey returns a data key by ID. Data keys never change, rewrapping them only changes how they are sealed.
+func (cipher *PIICipher) dataKey(ctx context.Context, q Querier, id int64) (*util.Encrypter, error) {
+	cipher.mu.Lock()
+	encrypter, ok := cipher.dataKeys[id]
+	cipher.mu.Unlock()
+	if ok {
+		return encrypter, nil
+	}
+
+	key, err := q.GetDataKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	encrypter, err = cipher.unwrap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	cipher.mu.Lock()
+	defer cipher.mu.Unlock()
+	cipher.dataKeys[id] = encrypter
+	return encrypter, nil
+}
+
+// activeDataKey returns the newest data key, which seals new data, creating the first one on first use.
+func (cipher *PIICipher) activeDataKey(ctx context.Context, q Querier) (int64, *util.Encrypter, error) {
+	cipher.mu.Lock()
+	id, encrypter := cipher.activeKeyID, cipher.dataKeys[cipher.activeKeyID]
+	fresh := encrypter != nil && time.Since(cipher.activeAt) < dataKeyCacheTTL
+	cipher.mu.Unlock()
+	if fresh {
+		return id, encrypter, nil
+	}
+
+	key, err := q.GetLatestDataKey(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		key, err = cipher.newDataKey(ctx, q)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	encrypter, err = cipher.dataKey(ctx, q, key.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	cipher.mu.Lock()
+	defer cipher.mu.Unlock()
+	cipher.activeKeyID = key.ID
+	cipher.activeAt = time.Now()
+	return key.ID, encrypter, nil
+}
+
+// piiAssociatedData ties a sealed field to its column and user, so it cannot be moved to another one.
+func piiAssociatedData(column, username string) []byte {
+	return []byte("users." + column + ":" + username)
+}
+
+// sealedUser is the full name and email of a user as stored.
+type sealedUser struct {
+	FullName   string
+	Email      string
+	EmailIndex sql.NullString
+	DataKeyID  sql.NullInt64
+}
+
+// sealUser encrypts the full name and email of a user with the active data key. Without a cipher they stay in plaintext.
+// Data keys are read and created outside of the caller's transaction, so a rollback cannot lose a key in use.
+func (store *SQLStore) sealUser(ctx context.Context, username, fullName, email string) (sealedUser, error) {
+	if store.pii == nil {
+		return sealedUser{FullName: fullName, Email: email}, nil
+	}
+
+	id, key, err := store.pii.activeDataKey(ctx, store.Queries)
+	if err != nil {
+		return sealedUser{}, err
+	}
+	sealedName, err := key.Encrypt([]byte(fullName), piiAssociatedData("full_name", username))
+	if err != nil {
+		return sealedUser{}, err
+	}
+	sealedEmail, err := key.Encrypt([]byte(email), piiAssociatedData("email", username))
+	if err != nil {
+		return sealedUser{}, err
+	}
+	return sealedUser{
+		FullName:   base64.StdEncoding.EncodeToString(sealedName),
+		Email:      base64.StdEncoding.EncodeToString(sealedEmail),
+		EmailIndex: store.pii.blindIndex(email),
+		DataKeyID:  sql.NullInt64{Int64: id, Valid: true},
+	}, nil
+}
+
+// openUser decrypts the full name and email of a user read from the database.
+func (store *SQLStore) openUser(ctx context.Context, user User) (User, error) {
+	if !user.DataKeyID.Valid {
+		return user, nil
+	}
+	if store.pii == nil {
+		return User{}, ErrPIIKeyMissing
+	}
+
+	key, err := store.pii.dataKey(ctx, store.Queries, user.DataKeyID.Int64)
+	if err != nil {
+		return User{}, err
+	}
+	fullName, err := openPIIField(key, user.FullName, piiAssociatedData("full_name", user.Username))
+	if err != nil {
+		return User{}, fmt.Errorf("full name of %s: %w", user.Username, err)
+	}
+	email, err := openPIIField(key, user.Email, piiAssociatedData("email", user.Username))
+	if err != nil {
+		return User{}, fmt.Errorf("email of %s: %w", user.Username, err)
+	}
+	user.FullName, user.Email = fullName, email
+	return user, nil
+}
+
+func openPIIField(key *util.Encrypter, sealed string, associatedData []byte) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", util.ErrDecrypt
+	}
+	plaintext, err := key.Decrypt(ciphertext, associatedData)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// The queries reading users return them sealed, SQLStore opens them. The queries writing full names and
+// emails, such as InsertUser, take them sealed and are only used by the store, through CreateUser and UpdateUser.
+
+// GetUser returns a user with their full name and email decrypted.
+func (store *SQLStore) GetUser(ctx context.Context, username string) (User, error) {
+	user, err := store.Queries.GetUser(ctx, username)
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// GetUserForUpdate locks a user and returns them with their full name and email decrypted.
+func (store *SQLStore) GetUserForUpdate(ctx context.Context, username string) (User, error) {
+	user, err := store.Queries.GetUserForUpdate(ctx, username)
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// GetUserByEmail finds a user by email through its blind index.
+func (store *SQLStore) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	user, err := store.GetUserByEmailIndex(ctx, GetUserByEmailIndexParams{
+		EmailIndex: store.pii.blindIndex(email),
+		Email:      email,
+	})
+	if err != nil {
+		return user, err
+	}
+	return store.openUser(ctx, user)
+}
+
+// CreateVerifyEmail stores a verification code. Once emails are encrypted, it keeps the blind index of the
+// email the code is sent to rather than the email.
+func (store *SQLStore) CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error) {
+	arg.EmailIndex = store.pii.blindIndex(arg.Email)
+	if arg.EmailIndex.Valid {
+		arg.Email = ""
+	}
+	return store.Queries.CreateVerifyEmail(ctx, arg)
+}
+
+// verifyEmailMatches tells whether a verification code was sent to email, by the blind index it keeps or,
+// when it was created while emails were in plaintext, by the email itself.
+func verifyEmailMatches(cipher *PIICipher, verifyEmail VerifyEmail, email string) bool {
+	if verifyEmail.EmailIndex.Valid {
+		index := cipher.blindIndex(email)
+		return index.Valid && hmac.Equal([]byte(index.String), []byte(verifyEmail.EmailIndex.String))
+	}
+	return verifyEmail.Email != "" && verifyEmail.Email == email
+}
+
+// checkEmailFree returns ErrEmailTaken if another user has the email. The unique indexes on emails and their
+// blind indexes cannot tell a sealed user from a plaintext one with the same email while users are being sealed.
+func checkEmailFree(ctx context.Context, q *Queries, username string, email string, sealed sealedUser) error {
+	count, err := q.CountOtherUsersWithEmail(ctx, CountOtherUsersWithEmailParams{
+		Username:   username,
+		EmailIndex: sealed.EmailIndex,
+		Email:      email,
+	})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrEmailTaken
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// createRandomEncryptedUser creates a user through an encrypted store.
+func createRandomEncryptedUser(t *testing.T, store Store) (User, CreateUserParams) {
+	arg := CreateUserParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: util.RandomString(20),
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	}
+	user, err := store.CreateUser(context.Background(), arg)
+	require.NoError(t, err)
+	return user, arg
+}
+
+func TestPIICipher(t *testing.T) {
+	_, err := NewPIICipher(nil, util.RandomString(32))
+	require.Error(t, err)
+	_, err = NewPIICipher([]string{util.RandomString(16)}, util.RandomString(32))
+	require.Error(t, err)
+	_, err = NewPIICipher([]string{util.RandomString(32)}, util.RandomString(16))
+	require.ErrorIs(t, err, ErrBlindIndexKeySize)
+
+	cipher, err := NewPIICipher([]string{util.RandomString(32)}, util.RandomString(32))
+	require.NoError(t, err)
+
+	// The blind index is deterministic, so it finds emails, and keyed, so it cannot be computed without the key
+	email := util.RandomEmail()
+	index := cipher.blindIndex(email)
+	require.True(t, index.Valid)
+	require.NotContains(t, index.String, email)
+	require.Equal(t, index, cipher.blindIndex(email))
+	require.NotEqual(t, index, cipher.blindIndex(util.RandomEmail()))
+
+	other, err := NewPIICipher([]string{util.RandomString(32)}, util.RandomString(32))
+	require.NoError(t, err)
+	require.NotEqual(t, index, other.blindIndex(email))
+
+	var plaintext *PIICipher
+	require.False(t, plaintext.blindIndex(email).Valid)
+}
+
+func TestEncryptedStoreUser(t *testing.T) {
+	store := NewEncryptedStore(testDB, testPII)
+	user, arg := createRandomEncryptedUser(t, store)
+	require.Equal(t, arg.FullName, user.FullName)
+	require.Equal(t, arg.Email, user.Email)
+
+	// The row holds ciphertext and the blind index of the email
+	raw, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, raw.DataKeyID.Valid)
+	require.NotContains(t, raw.FullName, arg.FullName)
+	require.NotContains(t, raw.Email, arg.Email)
+	require.Equal(t, testPII.blindIndex(arg.Email), raw.EmailIndex)
+
+	// The audit log keeps the sealed user too
+	events := listAuditEventsOf(t, AuditUser, user.Username)
+	require.NotContains(t, string(events[0].After), arg.Email)
+
+	got, err := store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user, got)
+
+	got, err = store.GetUserByEmail(context.Background(), arg.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, got.Username)
+
+	// A store without the keys cannot read the user
+	_, err = NewStore(testDB).GetUser(context.Background(), user.Username)
+	require.ErrorIs(t, err, ErrPIIKeyMissing)
+
+	newEmail := util.RandomEmail()
+	updated, err := store.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: newEmail, Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newEmail, updated.Email)
+	require.Equal(t, arg.FullName, updated.FullName)
+
+	got, err = store.GetUserByEmail(context.Background(), newEmail)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, got.Username)
+	_, err = store.GetUserByEmail(context.Background(), arg.Email)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestEncryptedStorePlaintextUser(t *testing.T) {
+	store := NewEncryptedStore(testDB, testPII)
+	user := createRandomUser(t)
+
+	// Users stored before the encryption was set up are read as they are, until they are sealed
+	got, err := store.GetUserByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user, got)
+
+	for {
+		n, err := store.ReencryptUsersTx(context.Background(), 100)
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+	}
+
+	raw, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, raw.DataKeyID.Valid)
+	require.NotEqual(t, user.Email, raw.Email)
+
+	got, err = store.GetUserByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.FullName, got.FullName)
+	require.Equal(t, user.Email, got.Email)
+}
+
+func TestEncryptedStoreEmailTaken(t *testing.T) {
+	store := NewEncryptedStore(testDB, testPII)
+	plaintext := createRandomUser(t)
+
+	// The email of a user who is not sealed yet has no blind index to collide with
+	arg := CreateUserParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: util.RandomString(20),
+		FullName:       util.RandomOwner(),
+		Email:          plaintext.Email,
+	}
+	_, err := store.CreateUser(context.Background(), arg)
+	require.ErrorIs(t, err, ErrEmailTaken)
+
+	sealed, _ := createRandomEncryptedUser(t, store)
+	_, err = store.UpdateUser(context.Background(), UpdateUserParams{
+		Username: sealed.Username,
+		Email:    sql.NullString{String: plaintext.Email, Valid: true},
+	})
+	require.ErrorIs(t, err, ErrEmailTaken)
+
+	// A user keeps their own email
+	_, err = store.UpdateUser(context.Background(), UpdateUserParams{
+		Username: sealed.Username,
+		Email:    sql.NullString{String: sealed.Email, Valid: true},
+	})
+	require.NoError(t, err)
+}
+
+func TestEncryptedStoreVerifyEmail(t *testing.T) {
+	store := NewEncryptedStore(testDB, testPII)
+	user, arg := createRandomEncryptedUser(t, store)
+
+	// The code keeps the blind index of the email it was sent to, not the email
+	verifyEmail, err := store.CreateVerifyEmail(context.Background(), CreateVerifyEmailParams{
+		Username:       user.Username,
+		Email:          arg.Email,
+		SecretCodeHash: util.RandomString(64),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Empty(t, verifyEmail.Email)
+	require.Equal(t, testPII.blindIndex(arg.Email), verifyEmail.EmailIndex)
+
+	verified, err := store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		ID:             verifyEmail.ID,
+		SecretCodeHash: verifyEmail.SecretCodeHash,
+		Now:            time.Now(),
+	})
+	require.NoError(t, err)
+	require.True(t, verified.IsEmailVerified)
+	require.Equal(t, arg.Email, verified.Email)
+
+	// A code sent while the user was in plaintext still verifies them once they are sealed
+	plaintext := createRandomUser(t)
+	verifyEmail = createRandomVerifyEmail(t, plaintext, time.Hour)
+	require.Equal(t, plaintext.Email, verifyEmail.Email)
+	for {
+		n, err := store.ReencryptUsersTx(context.Background(), 100)
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+	}
+	sealed, err := testQueries.GetLatestVerifyEmail(context.Background(), plaintext.Username)
+	require.NoError(t, err)
+	require.Empty(t, sealed.Email)
+	require.Equal(t, testPII.blindIndex(plaintext.Email), sealed.EmailIndex)
+	verified, err = store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		ID:             verifyEmail.ID,
+		SecretCodeHash: verifyEmail.SecretCodeHash,
+		Now:            time.Now(),
+	})
+	require.NoError(t, err)
+	require.True(t, verified.IsEmailVerified)
+}
+
+func TestRotatePIIKeys(t *testing.T) {
+	store := NewEncryptedStore(testDB, testPII)
+	user, arg := createRandomEncryptedUser(t, store)
+
+	key, err := store.RotateDataKey(context.Background())
+	require.NoError(t, err)
+	events := listAuditEventsOf(t, AuditDataKey, auditID(key.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, "data_key.create", events[0].Action)
+
+	for {
+		n, err := store.ReencryptUsersTx(context.Background(), 100)
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+	}
+	raw, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, key.ID, raw.DataKeyID.Int64)
+
+	// A new master key rewraps every data key, and the older one can still open them until then
+	oldMaster, newMaster := testMasterKeys[0], util.RandomString(32)
+	rotated, err := NewPIICipher([]string{newMaster, oldMaster}, string(testPII.blindIndexKey))
+	require.NoError(t, err)
+	// Rewrap the data keys back with the master key of the config, which the other tests and runs use
+	defer func() {
+		restored, err := NewPIICipher([]string{oldMaster, newMaster}, string(testPII.blindIndexKey))
+		require.NoError(t, err)
+		_, err = NewEncryptedStore(testDB, restored).RewrapDataKeys(context.Background())
+		require.NoError(t, err)
+	}()
+
+	rotatedStore := NewEncryptedStore(testDB, rotated)
+	rewrapped, err := rotatedStore.RewrapDataKeys(context.Background())
+	require.NoError(t, err)
+	require.Positive(t, rewrapped)
+
+	rewrappedKey, err := testQueries.GetDataKey(context.Background(), key.ID)
+	require.NoError(t, err)
+	require.Equal(t, masterKeyID(newMaster), rewrappedKey.MasterKeyID)
+	require.NotEqual(t, key.WrappedKey, rewrappedKey.WrappedKey)
+
+	got, err := rotatedStore.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, arg.FullName, got.FullName)
+	require.Equal(t, arg.Email, got.Email)
+}
@@ -6,24 +6,180 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error)
+	CloseAccount(ctx context.Context, id int64) (Account, error)
+	CompleteTransferBatch(ctx context.Context, arg CompleteTransferBatchParams) (TransferBatch, error)
+	CountOpenAccountsByProduct(ctx context.Context, arg CountOpenAccountsByProductParams) (int64, error)
+	CountOpenTransfers(ctx context.Context, accountID int64) (int64, error)
+	CountOtherUsersWithEmail(ctx context.Context, arg CountOtherUsersWithEmailParams) (int64, error)
+	CountOwnerTransfersInRange(ctx context.Context, arg CountOwnerTransfersInRangeParams) (int64, error)
+	CountRecentLoginFailures(ctx context.Context, arg CountRecentLoginFailuresParams) (int64, error)
+	CountRecentLoginFailuresByIP(ctx context.Context, arg CountRecentLoginFailuresByIPParams) (int64, error)
+	CountUnusedTOTPRecoveryCodes(ctx context.Context, username string) (int64, error)
+	CountUsersByRole(ctx context.Context, role string) (int64, error)
+	CreateAMLAlert(ctx context.Context, arg CreateAMLAlertParams) (AmlAlert, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateConversion(ctx context.Context, arg CreateConversionParams) (Conversion, error)
+	CreateDailyBalance(ctx context.Context, arg CreateDailyBalanceParams) (DailyBalance, error)
+	CreateDataKey(ctx context.Context, arg CreateDataKeyParams) (DataKey, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFee(ctx context.Context, arg CreateFeeParams) (Fee, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateKYCDocument(ctx context.Context, arg CreateKYCDocumentParams) (KycDocument, error)
+	CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) (LoginAttempt, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) (Account, error)
+	CreateTOTPRecoveryCode(ctx context.Context, arg CreateTOTPRecoveryCodeParams) (TotpRecoveryCode, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
-	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int64) error
+	CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (TransferApproval, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchItem(ctx context.Context, arg CreateTransferBatchItemParams) (TransferBatchItem, error)
+	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	DeleteAMLScreening(ctx context.Context, transferID int64) (int64, error)
+	DeleteExchangeRate(ctx context.Context, arg DeleteExchangeRateParams) (ExchangeRate, error)
+	DeleteFeeSchedule(ctx context.Context, arg DeleteFeeScheduleParams) (FeeSchedule, error)
+	DeleteLoginAttempts(ctx context.Context, username string) error
+	DeletePasswordResetTokens(ctx context.Context, username string) error
+	DeleteTOTPRecoveryCodes(ctx context.Context, username string) error
+	DeleteUser(ctx context.Context, arg DeleteUserParams) (User, error)
+	DeleteUserTOTP(ctx context.Context, username string) error
+	DeleteVerifyEmails(ctx context.Context, username string) error
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (UserTotp, error)
+	EraseUser(ctx context.Context, arg EraseUserParams) (User, error)
+	GetAMLAccount(ctx context.Context, id int64) (GetAMLAccountRow, error)
+	GetAMLAlert(ctx context.Context, id int64) (AmlAlert, error)
+	GetAMLAlertForUpdate(ctx context.Context, id int64) (AmlAlert, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetAPIKeyForUpdate(ctx context.Context, id int64) (ApiKey, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountProduct(ctx context.Context, id int64) (AccountProduct, error)
+	GetAccountProductByCode(ctx context.Context, code string) (AccountProduct, error)
+	GetDataKey(ctx context.Context, id int64) (DataKey, error)
+	GetDueScheduledTransferForUpdate(ctx context.Context, now time.Time) (ScheduledTransfer, error)
+	GetEndOfDayReport(ctx context.Context, balanceDate time.Time) ([]GetEndOfDayReportRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetExpiredHoldForUpdate(ctx context.Context, now time.Time) (Hold, error)
+	GetExpiredTransferApprovalForUpdate(ctx context.Context, now time.Time) (TransferApproval, error)
+	GetFeeForPeriod(ctx context.Context, arg GetFeeForPeriodParams) (Fee, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetKYCDocument(ctx context.Context, id int64) (KycDocument, error)
+	GetKYCProfile(ctx context.Context, username string) (KycProfile, error)
+	GetKYCTier(ctx context.Context, code string) (KycTier, error)
+	GetKYCTierByAccount(ctx context.Context, id int64) (KycTier, error)
+	GetLastInterestPosting(ctx context.Context, accountID int64) (InterestPosting, error)
+	GetLatestDailyBalance(ctx context.Context, arg GetLatestDailyBalanceParams) (DailyBalance, error)
+	GetLatestDataKey(ctx context.Context) (DataKey, error)
+	GetLatestVerifyEmail(ctx context.Context, username string) (VerifyEmail, error)
+	GetPasswordResetTokenForUpdate(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	GetRole(ctx context.Context, name string) (Role, error)
+	GetRoleForUpdate(ctx context.Context, name string) (Role, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferApproval(ctx context.Context, transferID int64) (TransferApproval, error)
+	GetTransferApprovalForUpdate(ctx context.Context, transferID int64) (TransferApproval, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTrialBalance(ctx context.Context) ([]GetTrialBalanceRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmailIndex(ctx context.Context, arg GetUserByEmailIndexParams) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetUserTOTP(ctx context.Context, username string) (UserTotp, error)
+	GetUserTOTPForUpdate(ctx context.Context, username string) (UserTotp, error)
+	GetVerifyEmailForUpdate(ctx context.Context, id int64) (VerifyEmail, error)
+	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
+	ListAMLAlerts(ctx context.Context, arg ListAMLAlertsParams) ([]AmlAlert, error)
+	ListAPIKeys(ctx context.Context, owner string) ([]ApiKey, error)
+	ListAccountIDs(ctx context.Context, arg ListAccountIDsParams) ([]int64, error)
+	ListAccountIDsOpenedBefore(ctx context.Context, arg ListAccountIDsOpenedBeforeParams) ([]int64, error)
+	ListAccountProducts(ctx context.Context) ([]AccountProduct, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListActiveHoldsByOwnerForUpdate(ctx context.Context, owner string) ([]Hold, error)
+	ListActiveScheduledTransfersByOwnerForUpdate(ctx context.Context, owner string) ([]ScheduledTransfer, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListChartOfAccounts(ctx context.Context) ([]AccountProduct, error)
+	ListDataKeys(ctx context.Context) ([]DataKey, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntryChain(ctx context.Context, arg ListEntryChainParams) ([]ListEntryChainRow, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListHolds(ctx context.Context, arg ListHoldsParams) ([]Hold, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]Account, error)
+	ListKYCDocuments(ctx context.Context, username string) ([]KycDocument, error)
+	ListKYCTiers(ctx context.Context) ([]KycTier, error)
+	ListLoginAttempts(ctx context.Context, arg ListLoginAttemptsParams) ([]LoginAttempt, error)
+	ListMaintenanceFeeAccounts(ctx context.Context, arg ListMaintenanceFeeAccountsParams) ([]Account, error)
+	ListNewCounterparties(ctx context.Context, arg ListNewCounterpartiesParams) ([]int64, error)
+	ListOpenAccountIDsByOwner(ctx context.Context, owner string) ([]int64, error)
+	ListPendingTransferApprovals(ctx context.Context, arg ListPendingTransferApprovalsParams) ([]TransferApproval, error)
+	ListPendingTransferApprovalsByOwnerForUpdate(ctx context.Context, owner string) ([]TransferApproval, error)
+	ListPendingTransfersForUpdate(ctx context.Context, limit int32) ([]Transfer, error)
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	ListQueuedAMLScreenings(ctx context.Context, arg ListQueuedAMLScreeningsParams) ([]Transfer, error)
+	ListRolePermissions(ctx context.Context) ([]RolePermission, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListTransferBatchItems(ctx context.Context, batchID int64) ([]TransferBatchItem, error)
+	ListTransferEntries(ctx context.Context, transferID sql.NullInt64) ([]Entry, error)
+	ListTransferFees(ctx context.Context, chargedTransferID sql.NullInt64) ([]Fee, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUsersToReencrypt(ctx context.Context, arg ListUsersToReencryptParams) ([]User, error)
+	MarkInterestAccrualsPosted(ctx context.Context, arg MarkInterestAccrualsPostedParams) error
+	RecordAMLScreeningFailure(ctx context.Context, arg RecordAMLScreeningFailureParams) error
+	RedactUserAuditEvents(ctx context.Context, arg RedactUserAuditEventsParams) error
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (User, error)
+	ReviewKYCProfile(ctx context.Context, arg ReviewKYCProfileParams) (KycProfile, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error)
+	RevokeAPIKeysByOwner(ctx context.Context, arg RevokeAPIKeysByOwnerParams) error
+	RewrapDataKey(ctx context.Context, arg RewrapDataKeyParams) (DataKey, error)
+	SealVerifyEmails(ctx context.Context, arg SealVerifyEmailsParams) error
+	SetInterestPostingTransfer(ctx context.Context, arg SetInterestPostingTransferParams) (InterestPosting, error)
+	SetUserEmailVerified(ctx context.Context, arg SetUserEmailVerifiedParams) (User, error)
+	SetUserKYC(ctx context.Context, arg SetUserKYCParams) (User, error)
+	StartUserTOTPEnrolment(ctx context.Context, arg StartUserTOTPEnrolmentParams) (UserTotp, error)
+	SumAccountTransfersInWindow(ctx context.Context, arg SumAccountTransfersInWindowParams) (int64, error)
+	SumEntriesBetween(ctx context.Context, arg SumEntriesBetweenParams) (int64, error)
+	SumTransfersSentSince(ctx context.Context, arg SumTransfersSentSinceParams) (int64, error)
+	SumUnpostedInterest(ctx context.Context, arg SumUnpostedInterestParams) (int64, error)
+	UnlockUserLogin(ctx context.Context, arg UnlockUserLoginParams) (User, error)
+	UpdateAMLAlert(ctx context.Context, arg UpdateAMLAlertParams) (AmlAlert, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, arg UpdateAPIKeyLastUsedParams) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferAfterRun(ctx context.Context, arg UpdateScheduledTransferAfterRunParams) (ScheduledTransfer, error)
+	UpdateTransferApproval(ctx context.Context, arg UpdateTransferApprovalParams) (TransferApproval, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
+	UpdateUserPII(ctx context.Context, arg UpdateUserPIIParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error)
+	UpsertKYCProfile(ctx context.Context, arg UpsertKYCProfileParams) (KycProfile, error)
+	UsePasswordResetTokens(ctx context.Context, arg UsePasswordResetTokensParams) error
+	UseTOTPRecoveryCode(ctx context.Context, arg UseTOTPRecoveryCodeParams) (TotpRecoveryCode, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserTotp, error)
+	UseVerifyEmail(ctx context.Context, arg UseVerifyEmailParams) (VerifyEmail, error)
 }
 
 var _ Querier = (*Queries)(nil)
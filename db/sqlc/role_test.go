@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestRolePermissionsSeeded(t *testing.T) {
+	rows, err := testQueries.ListRolePermissions(context.Background())
+	require.NoError(t, err)
+
+	granted := make(map[string]bool)
+	for _, row := range rows {
+		granted[row.Role+" "+row.Permission] = true
+	}
+	require.True(t, granted[util.TellerRole+" "+util.PermDepositCash])
+	require.True(t, granted[util.AdminRole+" "+util.PermAssignRoles])
+	require.False(t, granted[util.TellerRole+" "+util.PermAssignRoles])
+
+	role, err := testQueries.GetRole(context.Background(), util.SystemRole)
+	require.NoError(t, err)
+	require.False(t, role.Assignable)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	teller, err := store.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Role:     util.TellerRole,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.TellerRole, teller.Role)
+
+	// Only the roles of the roles table can be given
+	_, err = store.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Role:     "janitor",
+		Username: user.Username,
+	})
+	require.Error(t, err)
+}
+
+func TestBootstrapAdminTxOnce(t *testing.T) {
+	store := NewStore(testDB)
+
+	// Make sure there is an admin, whichever test ran first
+	admin := createRandomUser(t)
+	_, err := store.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Role:     util.AdminRole,
+		Username: admin.Username,
+	})
+	require.NoError(t, err)
+
+	user := createRandomUser(t)
+	_, err = store.BootstrapAdminTx(context.Background(), CreateUserParams{Username: user.Username})
+	require.ErrorIs(t, err, ErrAdminExists)
+
+	unchanged, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, util.CustomerRole, unchanged.Role)
+}
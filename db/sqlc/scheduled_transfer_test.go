@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// createRandomScheduledTransfer inserts a scheduled transfer between two fresh accounts that is already due.
+func createRandomScheduledTransfer(t *testing.T, amount int64, scheduleType string) ScheduledTransfer {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	arg := CreateScheduledTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Currency:      account1.Currency,
+		ScheduleType:  scheduleType,
+		NextRunAt:     time.Now().Add(-time.Minute),
+	}
+	if scheduleType == ScheduleTypeCron {
+		arg.CronExpression = "0 9 * * *"
+		arg.MaxRuns = sql.NullInt32{Int32: 12, Valid: true}
+	}
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduledTransfer.ID)
+	require.Equal(t, arg.FromAccountID, scheduledTransfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduledTransfer.ToAccountID)
+	require.Equal(t, arg.Amount, scheduledTransfer.Amount)
+	require.Equal(t, arg.ScheduleType, scheduledTransfer.ScheduleType)
+	require.Equal(t, arg.CronExpression, scheduledTransfer.CronExpression)
+	require.Equal(t, arg.MaxRuns, scheduledTransfer.MaxRuns)
+	require.Equal(t, ScheduledTransferActive, scheduledTransfer.Status)
+	require.Zero(t, scheduledTransfer.RunCount)
+	require.Zero(t, scheduledTransfer.FailureCount)
+
+	return scheduledTransfer
+}
+
+func TestGetScheduledTransfer(t *testing.T) {
+	scheduledTransfer1 := createRandomScheduledTransfer(t, util.RandomMoney()+1, ScheduleTypeOnce)
+
+	scheduledTransfer2, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer1.ID)
+	require.NoError(t, err)
+	require.Equal(t, scheduledTransfer1.ID, scheduledTransfer2.ID)
+	require.Equal(t, scheduledTransfer1.Amount, scheduledTransfer2.Amount)
+	require.WithinDuration(t, scheduledTransfer1.NextRunAt, scheduledTransfer2.NextRunAt, time.Second)
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	scheduledTransfer := createRandomScheduledTransfer(t, 10, ScheduleTypeCron)
+
+	// fund the source account so the transfer can go through
+	_, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     scheduledTransfer.FromAccountID,
+		Amount: 100,
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	arg := ExecuteScheduledTransferTxParams{Now: now, MaxFailures: 3, RetryDelay: time.Hour}
+
+	// Other due rows may exist in the test database, so keep executing until ours has been picked
+	var result ExecuteScheduledTransferTxResult
+	for result.ScheduledTransfer.ID != scheduledTransfer.ID {
+		result, err = store.ExecuteScheduledTransferTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, ScheduledTransferRunSucceeded, result.Run.Status)
+	require.True(t, result.Run.TransferID.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Run.TransferID.Int64)
+	require.Equal(t, scheduledTransfer.Amount, result.Transfer.Transfer.Amount)
+
+	updated := result.ScheduledTransfer
+	require.Equal(t, int32(1), updated.RunCount)
+	require.Zero(t, updated.FailureCount)
+	require.Equal(t, ScheduledTransferActive, updated.Status)
+	require.True(t, updated.NextRunAt.After(now))
+
+	// exactly one run has been recorded for the occurrence
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+}
+
+// TestExecuteScheduledTransferTxApprovalThreshold tests that a scheduled transfer above the approval threshold
+// is not executed but paused, as nobody reviews it.
+func TestExecuteScheduledTransferTxApprovalThreshold(t *testing.T) {
+	store := NewStore(testDB)
+	scheduledTransfer := createRandomScheduledTransfer(t, 10, ScheduleTypeCron)
+	funded, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     scheduledTransfer.FromAccountID,
+		Amount: 100,
+	})
+	require.NoError(t, err)
+
+	arg := ExecuteScheduledTransferTxParams{Now: time.Now(), MaxFailures: 3, RetryDelay: time.Hour, ApprovalThreshold: 5}
+
+	var result ExecuteScheduledTransferTxResult
+	for result.ScheduledTransfer.ID != scheduledTransfer.ID {
+		result, err = store.ExecuteScheduledTransferTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, ScheduledTransferRunFailed, result.Run.Status)
+	require.Equal(t, ErrScheduledTransferNeedsApproval.Error(), result.Run.Error)
+	require.False(t, result.Run.TransferID.Valid)
+	require.Equal(t, ScheduledTransferPaused, result.ScheduledTransfer.Status)
+
+	fromAccount, err := testQueries.GetAccount(context.Background(), scheduledTransfer.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, funded.Balance, fromAccount.Balance)
+}
+
+func TestExecuteScheduledTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	// No account created by the tests holds more than this, so the transfer always fails
+	scheduledTransfer := createRandomScheduledTransfer(t, 1_000_000_000, ScheduleTypeOnce)
+
+	now := time.Now()
+	arg := ExecuteScheduledTransferTxParams{Now: now, MaxFailures: 2, RetryDelay: -2 * time.Minute}
+
+	for i := 1; i <= 2; i++ {
+		var result ExecuteScheduledTransferTxResult
+		var err error
+		for result.ScheduledTransfer.ID != scheduledTransfer.ID {
+			result, err = store.ExecuteScheduledTransferTx(context.Background(), arg)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, ScheduledTransferRunFailed, result.Run.Status)
+		require.Equal(t, ErrInsufficientFunds.Error(), result.Run.Error)
+		require.False(t, result.Run.TransferID.Valid)
+		require.Equal(t, int32(i), result.ScheduledTransfer.FailureCount)
+		require.Zero(t, result.ScheduledTransfer.RunCount)
+
+		if i < 2 {
+			require.Equal(t, ScheduledTransferActive, result.ScheduledTransfer.Status)
+		} else {
+			require.Equal(t, ScheduledTransferPaused, result.ScheduledTransfer.Status)
+		}
+	}
+
+	// the failed attempts did not move any money
+	fromAccount, err := testQueries.GetAccount(context.Background(), scheduledTransfer.FromAccountID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, fromAccount.Balance, int64(0))
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	amount := int64(10)
+
+	// fund the sender so the pending transfer can be debited
+	account1, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account1.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+
+	pending, err := store.PendingTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	// the sender is debited straight away, the recipient is not credited yet
+	require.Equal(t, TransferStatusPending, pending.Transfer.Status)
+	require.Equal(t, -amount, pending.FromEntry.Amount)
+	require.Equal(t, account1.Balance-amount, pending.FromAccount.Balance)
+	require.Zero(t, pending.ToEntry.ID)
+
+	// Other pending transfers may exist in the test database, so keep settling until ours has been posted
+	var posted TransferTxResult
+	for posted.Transfer.ID != pending.Transfer.ID {
+		result, err := store.SettleTransfersTx(context.Background(), SettleTransfersTxParams{BatchSize: 10})
+		require.NoError(t, err)
+		require.NotZero(t, len(result.Posted)+len(result.Failed))
+
+		for _, settled := range result.Posted {
+			if settled.Transfer.ID == pending.Transfer.ID {
+				posted = settled
+			}
+		}
+	}
+
+	require.Equal(t, TransferStatusPosted, posted.Transfer.Status)
+	require.Equal(t, amount, posted.ToEntry.Amount)
+	require.Equal(t, account2.Balance+amount, posted.ToAccount.Balance)
+
+	// a settled transfer can be reversed once, and only once
+	reversed, err := store.ReverseTransferTx(context.Background(), pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusReversed, reversed.Transfer.Status)
+	require.Equal(t, account1.Balance, reversed.FromAccount.Balance)
+	require.Equal(t, account2.Balance, reversed.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), pending.Transfer.ID)
+	require.ErrorIs(t, err, ErrInvalidTransferTransition)
+}
+
+func TestPendingTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.PendingTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance - account1.HeldBalance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+// TestSettleTransfersTxDeadlock tests that refunding the senders of failed settlements does not deadlock
+// with live transfers going the other way.
+func TestSettleTransfersTxDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundAccount(t, createRandomAccount(t), 1000)
+	account2 := createRandomAccount(t)
+
+	// The recipient is at the balance cap of its tier, so the pending transfers fail and are refunded
+	basic, err := testQueries.GetKYCTier(context.Background(), KYCTierBasic)
+	require.NoError(t, err)
+	account2 = fundAccount(t, account2, basic.MaxBalance-account2.Balance)
+
+	n := 5
+	amount := int64(10)
+	for i := 0; i < n; i++ {
+		_, err := store.PendingTransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+	}
+
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.SettleTransfersTx(context.Background(), SettleTransfersTxParams{BatchSize: 10})
+			errs <- err
+		}()
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account2.ID,
+				ToAccountID:   account1.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < 2*n; i++ {
+		require.NoError(t, <-errs)
+	}
+}
+
+// TestReverseTransferTxInsufficientFunds tests that a transfer cannot be reversed once the recipient spent the money.
+func TestReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+
+	// The recipient spends everything, and its checking account has no overdraft
+	fundAccount(t, account2, -result.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), result.Transfer.ID)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPosted, transfer.Status)
+}
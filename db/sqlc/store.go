@@ -3,22 +3,69 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 )
 
+// ErrInsufficientFunds is returned when the available balance of an account, plus its overdraft limit, does not cover a debit.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
 // Store interface combines the SQLC-generated Querier interface and
 // custom transaction methods. This interface allows mocking of the store
 // for testing and separates the logic from specific implementations.
 type Store interface {
 	Querier
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	PendingTransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	SettleTransfersTx(ctx context.Context, arg SettleTransfersTxParams) (SettleTransfersTxResult, error)
+	ReverseTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error)
+	ConvertTx(ctx context.Context, arg ConvertTxParams) (ConvertTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error)
+	CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error)
+	AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (AccrueInterestTxResult, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	ChargeMaintenanceFeeTx(ctx context.Context, arg ChargeMaintenanceFeeTxParams) (ChargeMaintenanceFeeTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error)
+	BootstrapAdminTx(ctx context.Context, arg CreateUserParams) (BootstrapAdminTxResult, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error)
+	DeleteUserTx(ctx context.Context, arg DeleteUserTxParams) (DeleteUserTxResult, error)
+	ExportUserDataTx(ctx context.Context, username string) (UserDataExport, error)
+	EraseUserTx(ctx context.Context, arg EraseUserTxParams) (User, error)
+	RewrapDataKeys(ctx context.Context) (int, error)
+	RotateDataKey(ctx context.Context) (DataKey, error)
+	ReencryptUsersTx(ctx context.Context, batchSize int32) (int, error)
+	SubmitKYCProfileTx(ctx context.Context, arg SubmitKYCProfileTxParams) (KYCProfileTxResult, error)
+	AddKYCDocumentTx(ctx context.Context, arg CreateKYCDocumentParams) (KycDocument, error)
+	ReviewKYCTx(ctx context.Context, arg ReviewKYCTxParams) (KYCProfileTxResult, error)
+	RecordAMLScreeningTx(ctx context.Context, arg RecordAMLScreeningTxParams) ([]AmlAlert, error)
+	UpdateAMLAlertTx(ctx context.Context, arg UpdateAMLAlertTxParams) (AmlAlert, error)
+	EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (UserTotp, error)
+	DisableTOTPTx(ctx context.Context, username string) error
+	CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (HoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	ReleaseHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	ExpireHoldTx(ctx context.Context, now time.Time) (HoldTxResult, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	RequestTransferApprovalTx(ctx context.Context, arg RequestTransferApprovalTxParams) (TransferApprovalTxResult, error)
+	ApproveTransferTx(ctx context.Context, arg ReviewTransferTxParams) (ApproveTransferTxResult, error)
+	RejectTransferTx(ctx context.Context, arg ReviewTransferTxParams) (TransferApprovalTxResult, error)
+	ExpireTransferApprovalTx(ctx context.Context, now time.Time) (TransferApprovalTxResult, error)
 }
 
 // SQLStore implements the Store interface, providing methods to interact
 // with the database and handle transactions.
 type SQLStore struct {
 	*Queries
-	db *sql.DB
+	db  *sql.DB
+	pii *PIICipher // nil keeps the personal data of users in plaintext
 }
 
 // NewStore initializes a new SQLStore and returns it as a Store interface.
@@ -31,11 +78,25 @@ func NewStore(db *sql.DB) Store {
 	}
 }
 
+// NewEncryptedStore is NewStore for a bank that encrypts the full names and emails of its users with pii.
+func NewEncryptedStore(db *sql.DB, pii *PIICipher) Store {
+	return &SQLStore{
+		db:      db,
+		Queries: New(db),
+		pii:     pii,
+	}
+}
+
 // execTx executes a function within a database transaction.
 // It begins a transaction, passes a Queries object tied to the transaction
 // to the provided function, and commits or rolls back based on the function's success.
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil) // Start a new transaction
+	return store.execTxWithOptions(ctx, nil, fn)
+}
+
+// execTxWithOptions is execTx for a transaction with a different isolation level or access mode.
+func (store *SQLStore) execTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts) // Start a new transaction
 	if err != nil {
 		return err
 	}
@@ -63,12 +124,15 @@ type TransferTxParams struct {
 
 // TransferTxResult contains the result of a successful transfer transaction,
 // including the transfer record, the updated account balances, and entries for both accounts.
+// Transfer.Status tells how far the transfer got: a pending transfer has no entry or balance for the recipient yet.
 type TransferTxResult struct {
 	Transfer    Transfer `json:"transfer"`
 	FromAccount Account  `json:"from_account"`
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fee is the transfer fee charged to the sender on top of the amount, if its currency has one
+	Fee *ChargedFee `json:"fee,omitempty"`
 }
 
 // txKey is used to track the context of the current transaction.
@@ -76,61 +140,110 @@ type TransferTxResult struct {
 var txKey = struct{}{}
 
 // TransferTx performs a money transfer between two accounts, ensuring that the operation is atomic and safe.
-// It creates the necessary transfer and entry records and updates the accounts' balances.
+// It creates the necessary transfer and entry records, updates the accounts' balances and charges the transfer fee.
+// It returns ErrInsufficientFunds when the sender's available balance does not cover the amount and the fee,
+// and ErrTransferLimitExceeded or ErrBalanceCapExceeded when the transfer exceeds a KYC tier's limits.
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
-		txName := ctx.Value(txKey)
-
-		fmt.Println(txName, "Create transfer")
-		// Create the transfer record
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		result, err = feeTransferTx(ctx, q, arg)
 		if err != nil {
 			return err
 		}
+		return recordAudit(ctx, q, "transfer.create", AuditTransfer, auditID(result.Transfer.ID), nil, result.Transfer)
+	})
 
-		// Add entries for both accounts
-		fmt.Println(txName, "Create entry 1")
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+	return result, err
+}
 
-		fmt.Println(txName, "Create entry 2")
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+// feeTransferTx is transferTx for transfers made by customers, who pay the transfer fee of the currency.
+// Transfers the bank makes itself, such as sweeps, go through transferTx and are free.
+// They are also subject to the limits of the KYC tiers of the sender and the recipient.
+func feeTransferTx(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	if err := checkTransferLimit(ctx, q, arg.FromAccountID, arg.Amount); err != nil {
+		return TransferTxResult{}, err
+	}
+	result, err := transferTx(ctx, q, arg)
+	if err != nil {
+		return result, err
+	}
+	if err := checkBalanceCap(ctx, q, result.ToAccount); err != nil {
+		return result, err
+	}
+	return result, chargeTransferFee(ctx, q, &result)
+}
 
-		// Update the account balances, ensuring that the account with the smaller ID is updated first to avoid deadlocks.
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-			if err != nil {
-				return err
-			}
-		} else {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-			if err != nil {
-				return err
-			}
-		}
+// transferTx contains the body of TransferTx. It works on transaction-bound queries so that other
+// transactions (e.g. scheduled transfers) can move money as part of a larger unit of work.
+// It returns ErrInsufficientFunds, leaving the caller to roll back, when the transfer would spend
+// more than the sender's available balance, and ErrAccountFrozen or ErrAccountClosed when an account's
+// status does not allow the transfer.
+func transferTx(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	// Create the transfer record
+	transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Status:        TransferStatusPosted,
+	})
+	if err != nil {
+		return TransferTxResult{Transfer: transfer}, err
+	}
 
-		return nil
+	result, err := postTransfer(ctx, q, transfer)
+	if err != nil {
+		return result, err
+	}
+
+	// The balance is checked after the update, while the account row is locked, so concurrent transfers cannot overdraw it
+	if !canSpend(result.FromAccount, 0) {
+		return result, ErrInsufficientFunds
+	}
+	return result, nil
+}
+
+// postTransfer moves the money of an existing transfer between both accounts and writes its entries.
+// It is shared by TransferTx and the approval of transfers that were held for review.
+func postTransfer(ctx context.Context, q *Queries, transfer Transfer) (TransferTxResult, error) {
+	result := TransferTxResult{Transfer: transfer}
+	var err error
+
+	// Update the account balances, ensuring that the account with the smaller ID is updated first to avoid deadlocks.
+	// The entries are written afterwards, once both accounts are locked, as each account's entries form a hash chain.
+	if transfer.FromAccountID < transfer.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, transfer.FromAccountID, -transfer.Amount, transfer.ToAccountID, transfer.Amount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, transfer.ToAccountID, transfer.Amount, transfer.FromAccountID, -transfer.Amount)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// Add entries for both accounts
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.FromAccountID,
+		Amount:     -transfer.Amount,
+		TransferID: entryTransfer(transfer.ID),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.ToAccountID,
+		Amount:     transfer.Amount,
+		TransferID: entryTransfer(transfer.ID),
 	})
+	if err != nil {
+		return result, err
+	}
 
-	return result, err
+	if err = checkDebit(result.FromAccount); err != nil {
+		return result, err
+	}
+	return result, checkCredit(result.ToAccount)
 }
 
 // addMoney updates the balances of two accounts as part of the transfer transaction.
@@ -161,3 +274,20 @@ func addMoney(
 
 	return account1, account2, nil
 }
+
+// lockAccounts locks the given accounts in ascending ID order, the same order addMoney updates them in,
+// so that transactions touching several accounts cannot deadlock with each other.
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) error {
+	ids := append([]int64(nil), accountIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		if _, err := q.GetAccountForUpdate(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
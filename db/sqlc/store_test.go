@@ -15,6 +15,7 @@ func TestTransferTx(t *testing.T) {
 	// Create two random accounts
 	account1 := createRandomAccount(t) // Updated function name
 	account2 := createRandomAccount(t) // Updated function name
+	account1 = fundAccount(t, account1, 1000)
 
 	fmt.Println(">>before transactions: ", account1.Balance, account2.Balance)
 
@@ -129,6 +130,9 @@ func TestTransferTxDeadlock(t *testing.T) {
 	// Create two random accounts
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
+	// Both accounts send money, so both need enough to cover their side whatever the order
+	account1 = fundAccount(t, account1, 1000)
+	account2 = fundAccount(t, account2, 1000)
 
 	fmt.Println(">>before transactions: ", account1.Balance, account2.Balance)
 
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestTOTPEnrolment(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{Username: user.Username, Now: time.Now()})
+	require.ErrorIs(t, err, ErrTOTPNotPending)
+
+	// Enrolment can be restarted until it is confirmed
+	for i := 0; i < 2; i++ {
+		pending, err := testQueries.StartUserTOTPEnrolment(context.Background(), StartUserTOTPEnrolmentParams{
+			Username:        user.Username,
+			SecretEncrypted: []byte(util.RandomString(32)),
+		})
+		require.NoError(t, err)
+		require.False(t, pending.EnabledAt.Valid)
+	}
+
+	codeHashes := []string{util.RandomString(64), util.RandomString(64)}
+	enabled, err := store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{
+		Username:           user.Username,
+		Step:               100,
+		RecoveryCodeHashes: codeHashes,
+		Now:                time.Now(),
+	})
+	require.NoError(t, err)
+	require.True(t, enabled.EnabledAt.Valid)
+	require.Equal(t, int64(100), enabled.LastUsedStep)
+
+	_, err = testQueries.StartUserTOTPEnrolment(context.Background(), StartUserTOTPEnrolmentParams{
+		Username:        user.Username,
+		SecretEncrypted: []byte(util.RandomString(32)),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// A time step and a recovery code are only accepted once
+	_, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{LastUsedStep: 100, Username: user.Username})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{LastUsedStep: 101, Username: user.Username})
+	require.NoError(t, err)
+
+	useCode := UseTOTPRecoveryCodeParams{UsedAt: sql.NullTime{Time: time.Now(), Valid: true}, Username: user.Username, CodeHash: codeHashes[0]}
+	_, err = testQueries.UseTOTPRecoveryCode(context.Background(), useCode)
+	require.NoError(t, err)
+	_, err = testQueries.UseTOTPRecoveryCode(context.Background(), useCode)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	unused, err := testQueries.CountUnusedTOTPRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), unused)
+
+	require.NoError(t, store.DisableTOTPTx(context.Background(), user.Username))
+	_, err = testQueries.GetUserTOTP(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.ErrorIs(t, store.DisableTOTPTx(context.Background(), user.Username), sql.ErrNoRows)
+}
@@ -7,26 +7,47 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const countOpenTransfers = `-- name: CountOpenTransfers :one
+SELECT count(*) FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $1)
+AND status IN ('pending_approval', 'pending')
+`
+
+func (q *Queries) CountOpenTransfers(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOpenTransfers, accountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
     from_account_id, 
     to_account_id, 
-    amount
+    amount,
+    status
 )  VALUES(
-    $1,$2,$3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+    $1,$2,$3,$4
+) RETURNING id, from_account_id, to_account_id, amount, created_at, status
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Status        string `json:"status"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Status,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +55,13 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, status FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,31 +74,73 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
+const listPendingTransfersForUpdate = `-- name: ListPendingTransfersForUpdate :many
+SELECT id, from_account_id, to_account_id, amount, created_at, status FROM transfers
+WHERE status = 'pending'
+ORDER BY to_account_id, id
+LIMIT $1
+FOR NO KEY UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ListPendingTransfersForUpdate(ctx context.Context, limit int32) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingTransfersForUpdate, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, status FROM transfers
 WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+    (from_account_id = $1 OR
+    to_account_id = $2) AND
+    ($3::varchar IS NULL OR status = $3)
 ORDER BY id
-LIMIT $3
-OFFSET $4
+LIMIT $4
+OFFSET $5
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	FromAccountID int64          `json:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id"`
+	Status        sql.NullString `json:"status"`
+	Limit         int32          `json:"limit"`
+	Offset        int32          `json:"offset"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
 	rows, err := q.db.QueryContext(ctx, listTransfers,
 		arg.FromAccountID,
 		arg.ToAccountID,
+		arg.Status,
 		arg.Limit,
 		arg.Offset,
 	)
@@ -93,6 +157,7 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -106,3 +171,30 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $1
+WHERE id = $2 AND status = $3
+RETURNING id, from_account_id, to_account_id, amount, created_at, status
+`
+
+type UpdateTransferStatusParams struct {
+	Status     string `json:"status"`
+	ID         int64  `json:"id"`
+	FromStatus string `json:"from_status"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferStatus, arg.Status, arg.ID, arg.FromStatus)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createRandomTransferApproval holds the given amount on a fresh, funded account for approval by another user.
+func createRandomTransferApproval(t *testing.T, amount int64, expiresAt time.Time) TransferApprovalTxResult {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// fund the source account so the amount can be held
+	account1, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account1.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+
+	result, err := store.RequestTransferApprovalTx(context.Background(), RequestTransferApprovalTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		RequestedBy:   account1.Owner,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, TransferStatusPendingApproval, result.Transfer.Status)
+	require.Equal(t, TransferApprovalPending, result.Approval.Status)
+	require.Equal(t, account1.Owner, result.Approval.RequestedBy)
+	require.Equal(t, account1.HeldBalance+amount, result.FromAccount.HeldBalance)
+	// holding funds does not move any money
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+
+	return result
+}
+
+func TestRequestTransferApprovalTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	pending := createRandomTransferApproval(t, 10, time.Now().Add(time.Hour))
+
+	// The held amount cannot be held a second time
+	account, err := testQueries.GetAccount(context.Background(), pending.FromAccount.ID)
+	require.NoError(t, err)
+
+	_, err = store.RequestTransferApprovalTx(context.Background(), RequestTransferApprovalTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   pending.Transfer.ToAccountID,
+		Amount:        account.Balance - account.HeldBalance + 1,
+		RequestedBy:   account.Owner,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestApproveTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	pending := createRandomTransferApproval(t, 10, time.Now().Add(time.Hour))
+	approver := createRandomUser(t)
+
+	// the requester cannot approve their own transfer
+	_, err := store.ApproveTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		ReviewedBy: pending.Approval.RequestedBy,
+		Now:        time.Now(),
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	result, err := store.ApproveTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		ReviewedBy: approver.Username,
+		Now:        time.Now(),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, TransferStatusPosted, result.Transfer.Status)
+	require.Equal(t, TransferApprovalApproved, result.Approval.Status)
+	require.Equal(t, sql.NullString{String: approver.Username, Valid: true}, result.Approval.ReviewedBy)
+	require.True(t, result.Approval.ReviewedAt.Valid)
+
+	require.Equal(t, -pending.Transfer.Amount, result.FromEntry.Amount)
+	require.Equal(t, pending.Transfer.Amount, result.ToEntry.Amount)
+	require.Equal(t, pending.FromAccount.Balance-pending.Transfer.Amount, result.FromAccount.Balance)
+	require.Equal(t, pending.FromAccount.HeldBalance-pending.Transfer.Amount, result.FromAccount.HeldBalance)
+
+	// an approved transfer cannot be reviewed again
+	_, err = store.RejectTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		ReviewedBy: approver.Username,
+		Now:        time.Now(),
+	})
+	require.ErrorIs(t, err, ErrApprovalNotPending)
+}
+
+func TestRejectTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	pending := createRandomTransferApproval(t, 10, time.Now().Add(time.Hour))
+	approver := createRandomUser(t)
+
+	result, err := store.RejectTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		ReviewedBy: approver.Username,
+		Now:        time.Now(),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, TransferStatusCancelled, result.Transfer.Status)
+	require.Equal(t, TransferApprovalRejected, result.Approval.Status)
+	require.Equal(t, pending.FromAccount.Balance, result.FromAccount.Balance)
+	require.Equal(t, pending.FromAccount.HeldBalance-pending.Transfer.Amount, result.FromAccount.HeldBalance)
+}
+
+func TestExpireTransferApprovalTx(t *testing.T) {
+	store := NewStore(testDB)
+	pending := createRandomTransferApproval(t, 10, time.Now().Add(-time.Minute))
+	approver := createRandomUser(t)
+
+	// an expired transfer can no longer be approved
+	_, err := store.ApproveTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		ReviewedBy: approver.Username,
+		Now:        time.Now(),
+	})
+	require.ErrorIs(t, err, ErrApprovalExpired)
+
+	// Other expired approvals may exist in the test database, so keep expiring until ours has been picked
+	var result TransferApprovalTxResult
+	for result.Transfer.ID != pending.Transfer.ID {
+		result, err = store.ExpireTransferApprovalTx(context.Background(), time.Now())
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, TransferStatusCancelled, result.Transfer.Status)
+	require.Equal(t, TransferApprovalExpired, result.Approval.Status)
+	require.False(t, result.Approval.ReviewedBy.Valid)
+	require.Equal(t, pending.FromAccount.HeldBalance-pending.Transfer.Amount, result.FromAccount.HeldBalance)
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	arg := BatchTransferTxParams{
+		RequestedBy: user.Username,
+		Mode:        TransferBatchAtomic,
+		Items: []TransferTxParams{
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60},
+			{FromAccountID: account2.ID, ToAccountID: account3.ID, Amount: 50},
+		},
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchCompleted, result.Batch.Status)
+	require.Equal(t, int32(2), result.Batch.SucceededCount)
+	require.True(t, result.Batch.CompletedAt.Valid)
+	require.Len(t, result.Items, 2)
+	for _, item := range result.Items {
+		require.Equal(t, TransferBatchItemSucceeded, item.Status)
+		require.True(t, item.TransferID.Valid)
+	}
+
+	// the second transfer could only go through because the first one had already credited account2
+	updated3, err := testQueries.GetAccount(context.Background(), account3.ID)
+	require.NoError(t, err)
+	require.Equal(t, account3.Balance+50, updated3.Balance)
+}
+
+func TestAtomicBatchTransferTxRollback(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	arg := BatchTransferTxParams{
+		RequestedBy: user.Username,
+		Mode:        TransferBatchAtomic,
+		Items: []TransferTxParams{
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60},
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60},
+		},
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchFailed, result.Batch.Status)
+	require.Equal(t, int32(2), result.Batch.FailedCount)
+	require.Equal(t, ErrBatchRolledBack.Error(), result.Items[0].Error)
+	require.Equal(t, ErrInsufficientFunds.Error(), result.Items[1].Error)
+
+	// the first transfer has been rolled back with the second
+	updated1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated1.Balance)
+
+	// the failed batch can still be queried
+	items, err := testQueries.ListTransferBatchItems(context.Background(), result.Batch.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+}
+
+// TestAtomicBatchTransferTxDeadlock tests that atomic batches and single transfers between the same accounts
+// do not deadlock: both lock the senders before their accounts.
+func TestAtomicBatchTransferTxDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	account1 := fundAccount(t, createRandomAccount(t), 1000)
+	account2 := fundAccount(t, createRandomAccount(t), 1000)
+
+	n := 10
+	amount := int64(10)
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		fromAccountID, toAccountID := account1.ID, account2.ID
+		if i%2 == 1 {
+			fromAccountID, toAccountID = account2.ID, account1.ID
+		}
+
+		go func(i int) {
+			if i%4 < 2 {
+				_, err := store.TransferTx(context.Background(), TransferTxParams{
+					FromAccountID: fromAccountID,
+					ToAccountID:   toAccountID,
+					Amount:        amount,
+				})
+				errs <- err
+				return
+			}
+			result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+				RequestedBy: user.Username,
+				Mode:        TransferBatchAtomic,
+				Items: []TransferTxParams{
+					{FromAccountID: fromAccountID, ToAccountID: toAccountID, Amount: amount},
+					{FromAccountID: toAccountID, ToAccountID: fromAccountID, Amount: amount},
+				},
+			})
+			if err == nil && result.Batch.Status != TransferBatchCompleted {
+				err = fmt.Errorf("batch %s: %s", result.Batch.Status, result.Items[0].Error)
+			}
+			errs <- err
+		}(i)
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+}
+
+func TestBestEffortBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	arg := BatchTransferTxParams{
+		RequestedBy: user.Username,
+		Mode:        TransferBatchBestEffort,
+		Items: []TransferTxParams{
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60},
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60},
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 40},
+		},
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchPartiallyCompleted, result.Batch.Status)
+	require.Equal(t, int32(2), result.Batch.SucceededCount)
+	require.Equal(t, int32(1), result.Batch.FailedCount)
+
+	require.Equal(t, TransferBatchItemSucceeded, result.Items[0].Status)
+	require.Equal(t, TransferBatchItemFailed, result.Items[1].Status)
+	require.Equal(t, ErrInsufficientFunds.Error(), result.Items[1].Error)
+	require.False(t, result.Items[1].TransferID.Valid)
+	require.Equal(t, TransferBatchItemSucceeded, result.Items[2].Status)
+
+	updated1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, updated1.Balance)
+}
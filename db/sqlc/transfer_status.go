@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Statuses of a transfer
+const (
+	TransferStatusPendingApproval = "pending_approval" // held until a second user approves it
+	TransferStatusPending         = "pending"          // debited from the sender, credited to the recipient on settlement
+	TransferStatusPosted          = "posted"           // both accounts have been updated
+	TransferStatusFailed          = "failed"           // settlement failed and the sender was refunded
+	TransferStatusReversed        = "reversed"         // a posted transfer that was undone
+	TransferStatusCancelled       = "cancelled"        // never posted, e.g. rejected or expired approvals
+)
+
+// transferTransitions lists, for every status, the statuses a transfer may move to.
+// Failed, reversed and cancelled transfers are final.
+var transferTransitions = map[string][]string{
+	TransferStatusPendingApproval: {TransferStatusPosted, TransferStatusCancelled},
+	TransferStatusPending:         {TransferStatusPosted, TransferStatusFailed},
+	TransferStatusPosted:          {TransferStatusReversed},
+}
+
+// ErrInvalidTransferTransition is returned when a transfer cannot move to the requested status.
+var ErrInvalidTransferTransition = errors.New("invalid transfer status transition")
+
+// CanTransitionTransfer reports whether a transfer in status from may move to status to.
+func CanTransitionTransfer(from, to string) bool {
+	for _, next := range transferTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionTransfer moves a transfer from one status to another.
+// The update only applies while the transfer is still in the from status, so a concurrent
+// change makes it fail with ErrInvalidTransferTransition instead of silently overwriting the status.
+func transitionTransfer(ctx context.Context, q *Queries, id int64, from, to string) (Transfer, error) {
+	if !CanTransitionTransfer(from, to) {
+		return Transfer{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransferTransition, from, to)
+	}
+
+	transfer, err := q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+		Status:     to,
+		ID:         id,
+		FromStatus: from,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Tell a missing transfer apart from one that is no longer in the expected status
+		current, getErr := q.GetTransfer(ctx, id)
+		if getErr != nil {
+			return transfer, getErr
+		}
+		return transfer, fmt.Errorf("%w: transfer %d is %s", ErrInvalidTransferTransition, id, current.Status)
+	}
+	return transfer, err
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionTransfer(t *testing.T) {
+	allowed := [][2]string{
+		{TransferStatusPendingApproval, TransferStatusPosted},
+		{TransferStatusPendingApproval, TransferStatusCancelled},
+		{TransferStatusPending, TransferStatusPosted},
+		{TransferStatusPending, TransferStatusFailed},
+		{TransferStatusPosted, TransferStatusReversed},
+	}
+	for _, transition := range allowed {
+		require.True(t, CanTransitionTransfer(transition[0], transition[1]), "%s to %s", transition[0], transition[1])
+	}
+
+	forbidden := [][2]string{
+		{TransferStatusPosted, TransferStatusPending},
+		{TransferStatusPosted, TransferStatusCancelled},
+		{TransferStatusPending, TransferStatusReversed},
+		{TransferStatusFailed, TransferStatusPosted},
+		{TransferStatusReversed, TransferStatusPosted},
+		{TransferStatusCancelled, TransferStatusPosted},
+	}
+	for _, transition := range forbidden {
+		require.False(t, CanTransitionTransfer(transition[0], transition[1]), "%s to %s", transition[0], transition[1])
+	}
+}
@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Statuses of an account
+const (
+	AccountActive = "active"
+	AccountFrozen = "frozen" // can be credited but not debited
+	AccountClosed = "closed" // cannot move money at all, kept for history
+)
+
+// Codes of the account products created by the migrations
+const (
+	ProductChecking = "checking"
+	ProductSavings  = "savings"
+	ProductBusiness = "business"
+)
+
+// Errors returned when an account's status does not allow a movement, or when it cannot be closed
+var (
+	ErrAccountFrozen   = errors.New("account is frozen")
+	ErrAccountClosed   = errors.New("account is closed")
+	ErrAccountNotEmpty = errors.New("account balance must be zero, or swept to another account")
+	ErrAccountInUse    = errors.New("account has active holds or open transfers")
+	ErrTooManyAccounts = errors.New("maximum number of accounts reached for this product")
+	ErrUnknownProduct  = errors.New("unknown account product")
+)
+
+// canSpend reports whether the account can be debited by amount, counting its overdraft limit.
+// An amount of 0 checks an account that has already been debited.
+func canSpend(account Account, amount int64) bool {
+	return account.AvailableBalance+account.OverdraftLimit >= amount
+}
+
+// checkDebit returns an error when money cannot be taken out of the account.
+// Callers check the account after updating it, while its row is locked, like the available balance.
+func checkDebit(account Account) error {
+	switch account.Status {
+	case AccountFrozen:
+		return fmt.Errorf("account %d: %w", account.ID, ErrAccountFrozen)
+	case AccountClosed:
+		return fmt.Errorf("account %d: %w", account.ID, ErrAccountClosed)
+	}
+	return nil
+}
+
+// checkCredit returns an error when money cannot be paid into the account.
+func checkCredit(account Account) error {
+	if account.Status == AccountClosed {
+		return fmt.Errorf("account %d: %w", account.ID, ErrAccountClosed)
+	}
+	return nil
+}
+
+// CreateAccountTxParams contains the input parameters of CreateAccountTx.
+type CreateAccountTxParams struct {
+	Owner       string `json:"owner"`
+	Currency    string `json:"currency"`
+	ProductCode string `json:"product_code"`
+}
+
+// CreateAccountTxResult contains the new account and the product it was opened with.
+type CreateAccountTxResult struct {
+	Account Account        `json:"account"`
+	Product AccountProduct `json:"product"`
+}
+
+// CreateAccountTx opens an account of the given product, which sets its overdraft limit.
+// It returns ErrUnknownProduct when the product does not exist, sql.ErrNoRows when the owner does not exist,
+// ErrTooManyAccounts when the owner already holds as many open accounts of the product as it allows,
+// and ErrKYCAccountLimit when they hold as many open accounts as their KYC tier allows.
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error) {
+	var result CreateAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		product, err := q.GetAccountProductByCode(ctx, arg.ProductCode)
+		if errors.Is(err, sql.ErrNoRows) || product.Internal {
+			return fmt.Errorf("%w %q", ErrUnknownProduct, arg.ProductCode)
+		}
+		if err != nil {
+			return err
+		}
+		result.Product = product
+
+		// Locking the owner serializes the accounts they open, so the counts below cannot go stale
+		owner, err := q.GetUserForUpdate(ctx, arg.Owner)
+		if err != nil {
+			return err
+		}
+		tier, err := q.GetKYCTier(ctx, owner.KycTier)
+		if err != nil {
+			return err
+		}
+		if tier.MaxOpenAccounts > 0 {
+			open, err := q.ListOpenAccountIDsByOwner(ctx, arg.Owner)
+			if err != nil {
+				return err
+			}
+			if len(open) >= int(tier.MaxOpenAccounts) {
+				return ErrKYCAccountLimit
+			}
+		}
+
+		count, err := q.CountOpenAccountsByProduct(ctx, CountOpenAccountsByProductParams{
+			Owner:     arg.Owner,
+			ProductID: product.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if count >= int64(product.MaxAccountsPerOwner) {
+			return ErrTooManyAccounts
+		}
+
+		result.Account, err = q.CreateAccount(ctx, CreateAccountParams{
+			Owner:          arg.Owner,
+			Balance:        0,
+			Currency:       arg.Currency,
+			ProductID:      product.ID,
+			OverdraftLimit: product.OverdraftLimit,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "account.create", AuditAccount, auditID(result.Account.ID), nil, result.Account)
+	})
+
+	return result, err
+}
+
+// CloseAccountTxParams contains the input parameters of CloseAccountTx.
+type CloseAccountTxParams struct {
+	AccountID        int64 `json:"account_id"`
+	SweepToAccountID int64 `json:"sweep_to_account_id"` // account receiving the remaining balance, 0 if there is none
+}
+
+// CloseAccountTxResult contains the closed account and the transfer that swept its balance, if any.
+type CloseAccountTxResult struct {
+	Account Account           `json:"account"`
+	Sweep   *TransferTxResult `json:"sweep,omitempty"`
+}
+
+// CloseAccountTx closes an account for good. A positive balance is first transferred to the sweep account;
+// without one, the balance must already be zero. Accounts with active holds or open transfers cannot be closed,
+// since releasing or settling them later would move money on the closed account.
+func (store *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error) {
+	var result CloseAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		accountIDs := []int64{arg.AccountID}
+		if arg.SweepToAccountID != 0 {
+			accountIDs = append(accountIDs, arg.SweepToAccountID)
+		}
+		if err := lockAccounts(ctx, q, accountIDs...); err != nil {
+			return err
+		}
+
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := checkDebit(account); err != nil {
+			return err
+		}
+		if account.HeldBalance != 0 {
+			return ErrAccountInUse
+		}
+		openTransfers, err := q.CountOpenTransfers(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		if openTransfers > 0 {
+			return ErrAccountInUse
+		}
+
+		if account.Balance != 0 {
+			if account.Balance < 0 || arg.SweepToAccountID == 0 {
+				return ErrAccountNotEmpty
+			}
+
+			sweep, err := transferTx(ctx, q, TransferTxParams{
+				FromAccountID: account.ID,
+				ToAccountID:   arg.SweepToAccountID,
+				Amount:        account.Balance,
+			})
+			if err != nil {
+				return err
+			}
+			result.Sweep = &sweep
+		}
+
+		result.Account, err = q.CloseAccount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "account.close", AuditAccount, auditID(account.ID), account, result)
+	})
+
+	return result, err
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Statuses of an AML alert, worked as a case by investigators
+const (
+	AMLAlertStatusOpen          = "open"          // raised by the monitor, nobody works on it yet
+	AMLAlertStatusInvestigating = "investigating" // an investigator took the case
+	AMLAlertStatusEscalated     = "escalated"     // handed over to the money laundering reporting officer
+	AMLAlertStatusClosed        = "closed"        // resolved, final
+)
+
+// Severities of an AML alert, set by the rule that raised it
+const (
+	AMLSeverityLow    = "low"
+	AMLSeverityMedium = "medium"
+	AMLSeverityHigh   = "high"
+)
+
+// Resolutions of a closed AML alert
+const (
+	AMLResolutionFalsePositive = "false_positive" // nothing suspicious after investigation
+	AMLResolutionReported      = "reported"       // a suspicious activity report was filed
+)
+
+// amlAlertActions names the audit action of moving an alert to each status
+var amlAlertActions = map[string]string{
+	AMLAlertStatusInvestigating: "aml_alert.investigate",
+	AMLAlertStatusEscalated:     "aml_alert.escalate",
+	AMLAlertStatusClosed:        "aml_alert.close",
+}
+
+// amlAlertTransitions lists, for every status, the statuses an alert may move to.
+var amlAlertTransitions = map[string][]string{
+	AMLAlertStatusOpen:          {AMLAlertStatusInvestigating, AMLAlertStatusClosed},
+	AMLAlertStatusInvestigating: {AMLAlertStatusEscalated, AMLAlertStatusClosed},
+	AMLAlertStatusEscalated:     {AMLAlertStatusClosed},
+}
+
+// Errors returned when working AML alerts
+var (
+	ErrInvalidAMLAlertTransition = errors.New("invalid AML alert status transition")
+	ErrAMLResolutionRequired     = errors.New("closing an AML alert needs a resolution")
+)
+
+// CanTransitionAMLAlert reports whether an alert in status from may move to status to.
+func CanTransitionAMLAlert(from, to string) bool {
+	for _, next := range amlAlertTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordAMLScreeningTxParams contains the input parameters of RecordAMLScreeningTx.
+type RecordAMLScreeningTxParams struct {
+	TransferID int64                  `json:"transfer_id"`
+	Alerts     []CreateAMLAlertParams `json:"alerts"`
+}
+
+// RecordAMLScreeningTx takes a screened transfer off the AML screening queue and records the alerts its rules raised.
+// When another monitor already took the transfer off the queue, nothing is recorded, so several monitors can run
+// at the same time. It returns the alerts that were created.
+func (store *SQLStore) RecordAMLScreeningTx(ctx context.Context, arg RecordAMLScreeningTxParams) ([]AmlAlert, error) {
+	alerts := []AmlAlert{}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		alerts = alerts[:0]
+
+		deleted, err := q.DeleteAMLScreening(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return nil
+		}
+
+		for _, params := range arg.Alerts {
+			alert, err := q.CreateAMLAlert(ctx, params)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue // raised by an earlier screening of the transfer
+			}
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, q, "aml_alert.create", AuditAMLAlert, auditID(alert.ID), nil, alert); err != nil {
+				return err
+			}
+			alerts = append(alerts, alert)
+		}
+		return nil
+	})
+
+	return alerts, err
+}
+
+// UpdateAMLAlertTxParams contains the input parameters of UpdateAMLAlertTx. Notes replace those of the alert
+// unless they are empty, and Resolution is only kept when the alert is closed.
+type UpdateAMLAlertTxParams struct {
+	ID           int64     `json:"id"`
+	Status       string    `json:"status"`
+	Investigator string    `json:"investigator"`
+	Resolution   string    `json:"resolution"`
+	Notes        string    `json:"notes"`
+	Now          time.Time `json:"now"`
+}
+
+// UpdateAMLAlertTx moves an AML alert along its case: investigators take open alerts, then escalate or close them.
+// The investigator who takes an alert is assigned to it. Closing needs a resolution. It returns
+// ErrInvalidAMLAlertTransition when the alert cannot move to the status, e.g. once it is closed.
+func (store *SQLStore) UpdateAMLAlertTx(ctx context.Context, arg UpdateAMLAlertTxParams) (AmlAlert, error) {
+	var alert AmlAlert
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAMLAlertForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if !CanTransitionAMLAlert(before.Status, arg.Status) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidAMLAlertTransition, before.Status, arg.Status)
+		}
+
+		update := UpdateAMLAlertParams{
+			ID:         arg.ID,
+			Status:     arg.Status,
+			AssignedTo: before.AssignedTo,
+			Notes:      before.Notes,
+		}
+		if arg.Notes != "" {
+			update.Notes = arg.Notes
+		}
+		switch arg.Status {
+		case AMLAlertStatusInvestigating:
+			update.AssignedTo = sql.NullString{String: arg.Investigator, Valid: true}
+		case AMLAlertStatusClosed:
+			if arg.Resolution == "" {
+				return ErrAMLResolutionRequired
+			}
+			update.Resolution = arg.Resolution
+			update.ClosedAt = sql.NullTime{Time: arg.Now, Valid: true}
+		}
+
+		alert, err = q.UpdateAMLAlert(ctx, update)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, amlAlertActions[arg.Status], AuditAMLAlert, auditID(alert.ID), before, alert)
+	})
+
+	return alert, err
+}
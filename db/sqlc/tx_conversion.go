@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Errors returned when an amount cannot be converted between currencies
+var (
+	ErrSameCurrency       = errors.New("a conversion needs accounts in different currencies")
+	ErrNoExchangeRate     = errors.New("no exchange rate between the currencies")
+	ErrConversionTooSmall = errors.New("amount is too small to convert")
+)
+
+// ConvertTxParams contains the input parameters of ConvertTx.
+type ConvertTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"` // in the currency of the source account
+}
+
+// ConversionQuote prices the conversion of an amount: what it converts to at the current rate,
+// and the FX spread charged on top of it in the currency converted from.
+type ConversionQuote struct {
+	Rate            string `json:"rate"`
+	ConvertedAmount int64  `json:"converted_amount"`
+	Spread          int64  `json:"spread"`
+}
+
+// ConvertTxResult contains a conversion and its two transfers. FromTransfer moved the amount into the
+// FX position account of the source currency, ToTransfer the converted amount out of the FX position
+// account of the target currency. Spread is the FX spread fee, when the source currency has one.
+type ConvertTxResult struct {
+	Conversion   Conversion       `json:"conversion"`
+	FromTransfer TransferTxResult `json:"from_transfer"`
+	ToTransfer   TransferTxResult `json:"to_transfer"`
+	Spread       *ChargedFee      `json:"spread,omitempty"`
+}
+
+// ConvertAmount converts an amount at an exchange rate, rounding down to the minor unit.
+func ConvertAmount(rate string, amount int64) (int64, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid exchange rate %q", rate)
+	}
+
+	converted := r.Mul(r, new(big.Rat).SetInt64(amount))
+	whole := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !whole.IsInt64() {
+		return 0, fmt.Errorf("converting %d at %s overflows", amount, rate)
+	}
+	return whole.Int64(), nil
+}
+
+// QuoteConversion prices the conversion of an amount between two currencies without moving any money.
+// It returns ErrNoExchangeRate when the bank has not set a rate between them.
+func QuoteConversion(ctx context.Context, q Querier, fromCurrency, toCurrency string, amount int64) (ConversionQuote, error) {
+	var quote ConversionQuote
+
+	if fromCurrency == toCurrency {
+		return quote, ErrSameCurrency
+	}
+	rate, err := q.GetExchangeRate(ctx, GetExchangeRateParams{
+		BaseCurrency:  fromCurrency,
+		QuoteCurrency: toCurrency,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return quote, fmt.Errorf("%s to %s: %w", fromCurrency, toCurrency, ErrNoExchangeRate)
+	}
+	if err != nil {
+		return quote, err
+	}
+	quote.Rate = rate.Rate
+
+	quote.ConvertedAmount, err = ConvertAmount(rate.Rate, amount)
+	if err != nil {
+		return quote, err
+	}
+	if quote.ConvertedAmount == 0 {
+		return quote, ErrConversionTooSmall
+	}
+
+	quote.Spread, err = QuoteFee(ctx, q, FeeFXSpread, fromCurrency, amount)
+	return quote, err
+}
+
+// ConvertTx moves money between two accounts in different currencies at the bank's exchange rate.
+// The bank takes the amount into its FX position in the source currency and pays the converted amount
+// out of its FX position in the target currency, and the FX spread of the source currency is charged
+// on top of the amount into the same FX position, as separate entries.
+// Like transfers, conversions count towards the daily limit of the sender and the balance cap of the recipient.
+// It returns ErrNoExchangeRate when no rate is set, and ErrInsufficientFunds when the source account
+// cannot pay the amount and the spread.
+func (store *SQLStore) ConvertTx(ctx context.Context, arg ConvertTxParams) (ConvertTxResult, error) {
+	var result ConvertTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if err := checkTransferLimit(ctx, q, arg.FromAccountID, arg.Amount); err != nil {
+			return err
+		}
+		if err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID); err != nil {
+			return err
+		}
+		from, err := q.GetAccount(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+		to, err := q.GetAccount(ctx, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		quote, err := QuoteConversion(ctx, q, from.Currency, to.Currency, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		// Lock both FX position accounts after the customer accounts, and in ID order,
+		// so that conversions in opposite directions cannot deadlock
+		fromPosition, err := systemAccount(ctx, q, ProductFXPosition, from.Currency)
+		if err != nil {
+			return err
+		}
+		toPosition, err := systemAccount(ctx, q, ProductFXPosition, to.Currency)
+		if err != nil {
+			return err
+		}
+		if err := lockAccounts(ctx, q, fromPosition.ID, toPosition.ID); err != nil {
+			return err
+		}
+
+		result.FromTransfer, err = transferTx(ctx, q, TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   fromPosition.ID,
+			Amount:        arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The FX position is an asset of the bank, which is not limited by an available balance
+		transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: toPosition.ID,
+			ToAccountID:   to.ID,
+			Amount:        quote.ConvertedAmount,
+			Status:        TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+		result.ToTransfer, err = postTransfer(ctx, q, transfer)
+		if err != nil {
+			return err
+		}
+		if err := checkBalanceCap(ctx, q, result.ToTransfer.ToAccount); err != nil {
+			return err
+		}
+
+		if quote.Spread > 0 {
+			spread, account, err := chargeFeeTo(ctx, q, result.FromTransfer.FromAccount, ProductFXPosition, CreateFeeParams{
+				FeeType:           FeeFXSpread,
+				Amount:            quote.Spread,
+				ChargedTransferID: sql.NullInt64{Int64: result.FromTransfer.Transfer.ID, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			result.Spread = &spread
+			result.FromTransfer.FromAccount = account
+
+			if !canSpend(account, 0) {
+				return ErrInsufficientFunds
+			}
+		}
+
+		result.Conversion, err = q.CreateConversion(ctx, CreateConversionParams{
+			FromTransferID: result.FromTransfer.Transfer.ID,
+			ToTransferID:   result.ToTransfer.Transfer.ID,
+			Rate:           quote.Rate,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer.convert", AuditTransfer, auditID(result.FromTransfer.Transfer.ID), nil, result.Conversion)
+	})
+
+	return result, err
+}
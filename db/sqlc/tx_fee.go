@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// Types of fees. Transfer and FX spread fees are priced by a fee schedule per currency,
+// maintenance fees by the monthly fee of the account's product.
+const (
+	FeeTransfer    = "transfer"
+	FeeMaintenance = "maintenance"
+	FeeFXSpread    = "fx_spread" // charged on conversions, in the currency converted from
+)
+
+// Pricing methods of a fee schedule
+const (
+	FeeFlat       = "flat"       // the same amount whatever the amount it is charged on
+	FeePercentage = "percentage" // a rate of the amount, kept between the schedule's min and max fees
+)
+
+// ChargedFee is a fee taken from an account. Transfer moves the fee to the fee income account
+// of the account's currency, or its FX position account for FX spreads, and FromEntry is the entry
+// that debited the account.
+type ChargedFee struct {
+	Fee       Fee      `json:"fee"`
+	Transfer  Transfer `json:"transfer"`
+	FromEntry Entry    `json:"from_entry"`
+}
+
+// CalculateFee prices a fee on an amount according to a fee schedule.
+// Percentage fees are rounded up to the minor unit before the min and max fees are applied.
+func CalculateFee(schedule FeeSchedule, amount int64) int64 {
+	if schedule.Method == FeeFlat {
+		return schedule.FlatAmount
+	}
+
+	// amount * rate / 10_000 rounded up, computed on big integers since the product can overflow int64
+	fee := new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(schedule.RateBps)))
+	fee.Add(fee, big.NewInt(9_999))
+	fee.Quo(fee, big.NewInt(10_000))
+
+	switch {
+	case fee.Cmp(big.NewInt(schedule.MinFee)) < 0:
+		return schedule.MinFee
+	case schedule.MaxFee > 0 && fee.Cmp(big.NewInt(schedule.MaxFee)) > 0:
+		return schedule.MaxFee
+	}
+	return fee.Int64()
+}
+
+// QuoteFee returns the fee of a type charged on an amount in a currency, zero when the currency has no schedule for it.
+func QuoteFee(ctx context.Context, q Querier, feeType, currency string, amount int64) (int64, error) {
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		FeeType:  feeType,
+		Currency: currency,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return CalculateFee(schedule, amount), nil
+}
+
+// chargeTransferFee charges the sender of a transfer the transfer fee of its currency, if there is one,
+// and records it on the result. It returns ErrInsufficientFunds when the sender cannot pay the fee on top of the transfer.
+func chargeTransferFee(ctx context.Context, q *Queries, result *TransferTxResult) error {
+	amount, err := QuoteFee(ctx, q, FeeTransfer, result.FromAccount.Currency, result.Transfer.Amount)
+	if err != nil || amount == 0 {
+		return err
+	}
+
+	fee, from, err := chargeFee(ctx, q, result.FromAccount, CreateFeeParams{
+		FeeType:           FeeTransfer,
+		Amount:            amount,
+		ChargedTransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	result.Fee = &fee
+	result.FromAccount = from
+
+	if !canSpend(result.FromAccount, 0) {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// chargeFee moves a fee from an account to the fee income account of its currency and records it.
+// It does not check the account's balance; it returns the account as updated by the charge.
+func chargeFee(ctx context.Context, q *Queries, account Account, arg CreateFeeParams) (ChargedFee, Account, error) {
+	return chargeFeeTo(ctx, q, account, ProductFeeIncome, arg)
+}
+
+// chargeFeeTo is chargeFee for a fee paid into the system account of another internal product.
+func chargeFeeTo(ctx context.Context, q *Queries, account Account, productCode string, arg CreateFeeParams) (ChargedFee, Account, error) {
+	var charged ChargedFee
+
+	income, err := systemAccount(ctx, q, productCode, account.Currency)
+	if err != nil {
+		return charged, account, err
+	}
+
+	transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   income.ID,
+		Amount:        arg.Amount,
+		Status:        TransferStatusPosted,
+	})
+	if err != nil {
+		return charged, account, err
+	}
+	posted, err := postTransfer(ctx, q, transfer)
+	if err != nil {
+		return charged, account, err
+	}
+	charged.Transfer = posted.Transfer
+	charged.FromEntry = posted.FromEntry
+
+	arg.AccountID = account.ID
+	arg.TransferID = transfer.ID
+	charged.Fee, err = q.CreateFee(ctx, arg)
+	return charged, posted.FromAccount, err
+}
+
+// ChargeMaintenanceFeeTxParams contains the input parameters of ChargeMaintenanceFeeTx.
+type ChargeMaintenanceFeeTxParams struct {
+	AccountID int64     `json:"account_id"`
+	PeriodEnd time.Time `json:"period_end"` // last day of the month the fee is for
+}
+
+// ChargeMaintenanceFeeTxResult contains the maintenance fee charged, if any.
+// Charged is false when the month was already charged or the account's product has no monthly fee.
+type ChargeMaintenanceFeeTxResult struct {
+	Fee     *ChargedFee `json:"fee,omitempty"`
+	Account Account     `json:"account"`
+	Charged bool        `json:"charged"`
+}
+
+// ChargeMaintenanceFeeTx charges an account the monthly fee of its product for a month, at most once per month.
+// The fee is charged even when it takes the account past its overdraft limit, as an unpaid fee is still owed.
+func (store *SQLStore) ChargeMaintenanceFeeTx(ctx context.Context, arg ChargeMaintenanceFeeTxParams) (ChargeMaintenanceFeeTxResult, error) {
+	var result ChargeMaintenanceFeeTxResult
+	periodEnd := sql.NullTime{Time: InterestDate(arg.PeriodEnd), Valid: true}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The account lock makes the check for an earlier charge safe against concurrent runs
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		result.Account = account
+
+		_, err = q.GetFeeForPeriod(ctx, GetFeeForPeriodParams{
+			AccountID: account.ID,
+			FeeType:   FeeMaintenance,
+			PeriodEnd: periodEnd,
+		})
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		product, err := q.GetAccountProduct(ctx, account.ProductID)
+		if err != nil {
+			return err
+		}
+		if product.MonthlyFee == 0 {
+			return nil
+		}
+
+		fee, from, err := chargeFee(ctx, q, account, CreateFeeParams{
+			FeeType:   FeeMaintenance,
+			Amount:    product.MonthlyFee,
+			PeriodEnd: periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+		result.Fee = &fee
+		result.Account = from
+		result.Charged = true
+		return nil
+	})
+
+	return result, err
+}
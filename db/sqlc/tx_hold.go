@@ -0,0 +1,418 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Statuses of a hold
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+	HoldExpired  = "expired"
+)
+
+// Errors returned when a hold cannot be captured or released
+var (
+	ErrHoldNotActive        = errors.New("hold is not active")
+	ErrHoldExpired          = errors.New("hold has expired")
+	ErrCaptureExceedsHold   = errors.New("capture amount exceeds the hold")
+	ErrCaptureToHoldAccount = errors.New("cannot capture a hold into the account it was placed on")
+)
+
+// CreateHoldTxParams contains the input parameters of CreateHoldTx.
+type CreateHoldTxParams struct {
+	AccountID   int64     `json:"account_id"`
+	Amount      int64     `json:"amount"`
+	Description string    `json:"description"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// HoldTxResult contains a hold and its account after the held balance was updated.
+type HoldTxResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+// CaptureHoldTxParams contains the input parameters of CaptureHoldTx.
+type CaptureHoldTxParams struct {
+	HoldID      int64     `json:"hold_id"`
+	Amount      int64     `json:"amount"`        // amount to capture, 0 captures the whole hold
+	ToAccountID int64     `json:"to_account_id"` // account to pay, 0 withdraws the money instead
+	Now         time.Time `json:"now"`           // holds that expired before this time can no longer be captured
+	// When ApprovalExpiresAt is set, the payment to ToAccountID awaits a second user's approval until then,
+	// like the transfers above the approval threshold, instead of being posted
+	RequestedBy       string    `json:"requested_by"`
+	ApprovalExpiresAt time.Time `json:"approval_expires_at"`
+}
+
+// CaptureHoldTxResult contains a captured hold and the transfer, transfer awaiting approval or withdrawal it turned into.
+type CaptureHoldTxResult struct {
+	Hold       Hold                      `json:"hold"`
+	Transfer   *TransferTxResult         `json:"transfer,omitempty"`
+	Approval   *TransferApprovalTxResult `json:"approval,omitempty"`
+	Withdrawal *WithdrawTxResult         `json:"withdrawal,omitempty"`
+}
+
+// WithdrawTxParams contains the input parameters of WithdrawTx.
+type WithdrawTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// WithdrawTxResult contains the updated account, the entry debiting it and the transfer
+// that moved the money to the cash clearing account.
+type WithdrawTxResult struct {
+	Account  Account  `json:"account"`
+	Entry    Entry    `json:"entry"`
+	Transfer Transfer `json:"transfer"`
+}
+
+// DepositTxParams contains the input parameters of DepositTx.
+type DepositTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// DepositTxResult contains the updated account, the entry crediting it and the transfer
+// that moved the money from the cash clearing account.
+type DepositTxResult struct {
+	Account  Account  `json:"account"`
+	Entry    Entry    `json:"entry"`
+	Transfer Transfer `json:"transfer"`
+}
+
+// CreateHoldTx reserves money on an account, e.g. for a card authorization. The money stays part of
+// the ledger balance but is no longer available until the hold is captured, released or expires.
+// It returns ErrInsufficientFunds when the available balance does not cover the amount.
+func (store *SQLStore) CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := checkDebit(account); err != nil {
+			return err
+		}
+		if !canSpend(account, arg.Amount) {
+			return ErrInsufficientFunds
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   arg.AccountID,
+			Amount:      arg.Amount,
+			Description: arg.Description,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "hold.create", AuditHold, auditID(result.Hold.ID), nil, result.Hold)
+	})
+
+	return result, err
+}
+
+// CaptureHoldTx takes the money reserved by an active hold, fully or partially, and turns it into
+// a transfer to another account or into a withdrawal. Whatever is not captured is released.
+// Like the transfers customers make, the capture counts towards the daily limit of the owner, and a
+// transfer pays the transfer fee and is subject to the balance cap of the recipient. A transfer that
+// awaits approval keeps the captured amount on hold on the account until it is reviewed.
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Lock the owner before the hold and its account, like transfers and withdrawals do for the limit check
+		if err := lockHoldOwner(ctx, q, arg.HoldID); err != nil {
+			return err
+		}
+		hold, err := activeHold(ctx, q, arg.HoldID, arg.Now)
+		if err != nil {
+			return err
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+		if arg.ToAccountID == hold.AccountID {
+			return ErrCaptureToHoldAccount
+		}
+
+		// Lock both accounts in ID order up front, like addMoney does, before the hold account is updated
+		if arg.ToAccountID != 0 {
+			if err := lockAccounts(ctx, q, hold.AccountID, arg.ToAccountID); err != nil {
+				return err
+			}
+		}
+
+		// Release the whole hold first, so the captured amount becomes available to the debit below
+		if _, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     hold.AccountID,
+			Amount: -hold.Amount,
+		}); err != nil {
+			return err
+		}
+
+		switch {
+		case arg.ToAccountID != 0 && !arg.ApprovalExpiresAt.IsZero():
+			approval, err := requestTransferApproval(ctx, q, RequestTransferApprovalTxParams{
+				FromAccountID: hold.AccountID,
+				ToAccountID:   arg.ToAccountID,
+				Amount:        amount,
+				RequestedBy:   arg.RequestedBy,
+				ExpiresAt:     arg.ApprovalExpiresAt,
+			})
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, q, "transfer_approval.create", AuditTransferApproval, auditID(approval.Transfer.ID), nil, approval.Approval); err != nil {
+				return err
+			}
+			result.Approval = &approval
+		case arg.ToAccountID != 0:
+			transfer, err := feeTransferTx(ctx, q, TransferTxParams{
+				FromAccountID: hold.AccountID,
+				ToAccountID:   arg.ToAccountID,
+				Amount:        amount,
+			})
+			if err != nil {
+				return err
+			}
+			result.Transfer = &transfer
+		default:
+			withdrawal, err := withdraw(ctx, q, WithdrawTxParams{
+				AccountID: hold.AccountID,
+				Amount:    amount,
+			})
+			if err != nil {
+				return err
+			}
+			result.Withdrawal = &withdrawal
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			Status:         HoldCaptured,
+			CapturedAmount: amount,
+			ID:             hold.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "hold.capture", AuditHold, auditID(hold.ID), hold, result)
+	})
+
+	return result, err
+}
+
+// lockHoldOwner locks the user who owns the account of a hold.
+func lockHoldOwner(ctx context.Context, q *Queries, holdID int64) error {
+	hold, err := q.GetHold(ctx, holdID)
+	if err != nil {
+		return err
+	}
+	account, err := q.GetAccount(ctx, hold.AccountID)
+	if err != nil {
+		return err
+	}
+	_, err = q.GetUserForUpdate(ctx, account.Owner)
+	return err
+}
+
+// ReleaseHoldTx cancels an active hold and makes its money available again.
+func (store *SQLStore) ReleaseHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		if hold.Status != HoldActive {
+			return ErrHoldNotActive
+		}
+
+		result, err = endHold(ctx, q, hold, HoldReleased)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "hold.release", AuditHold, auditID(hold.ID), hold, result.Hold)
+	})
+
+	return result, err
+}
+
+// ExpireHoldTx releases one active hold that expired at or before now.
+// The row is claimed with FOR NO KEY UPDATE SKIP LOCKED, so several expiry workers can run at the same time.
+// It returns sql.ErrNoRows when nothing has expired.
+func (store *SQLStore) ExpireHoldTx(ctx context.Context, now time.Time) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetExpiredHoldForUpdate(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		result, err = endHold(ctx, q, hold, HoldExpired)
+		return err
+	})
+
+	return result, err
+}
+
+// WithdrawTx takes money out of an account, e.g. a cash withdrawal.
+// It returns ErrInsufficientFunds when the available balance does not cover the amount, and
+// ErrTransferLimitExceeded when it would take the owner over the daily limit of their KYC tier.
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error) {
+	var result WithdrawTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = withdraw(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer.withdraw", AuditTransfer, auditID(result.Transfer.ID), nil, result.Transfer)
+	})
+
+	return result, err
+}
+
+// withdraw contains the body of WithdrawTx, so that captured holds can withdraw inside their own transaction.
+// The money goes to the cash clearing account of the currency, the counterparty of money leaving the bank.
+func withdraw(ctx context.Context, q *Queries, arg WithdrawTxParams) (WithdrawTxResult, error) {
+	var result WithdrawTxResult
+
+	// Cash leaving the bank counts towards the daily limit of the owner, who is locked before the account
+	if err := checkTransferLimit(ctx, q, arg.AccountID, arg.Amount); err != nil {
+		return result, err
+	}
+
+	// Lock the account before the cash clearing account, which postTransfer could otherwise lock first
+	account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+	if err != nil {
+		return result, err
+	}
+	cash, err := systemAccount(ctx, q, ProductCashClearing, account.Currency)
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   cash.ID,
+		Amount:        arg.Amount,
+		Status:        TransferStatusPosted,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	posted, err := postTransfer(ctx, q, result.Transfer)
+	result.Account = posted.FromAccount
+	result.Entry = posted.FromEntry
+	if err != nil {
+		return result, err
+	}
+
+	if !canSpend(result.Account, 0) {
+		return result, ErrInsufficientFunds
+	}
+	return result, nil
+}
+
+// DepositTx puts money into an account, e.g. cash paid in at the counter. The money comes from the
+// cash clearing account of the currency, the counterparty of money entering the bank.
+// It returns ErrBalanceCapExceeded when the account would hold more than its owner's KYC tier allows.
+func (store *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error) {
+	var result DepositTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Lock the account before the cash clearing account, like withdraw does
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		cash, err := systemAccount(ctx, q, ProductCashClearing, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: cash.ID,
+			ToAccountID:   account.ID,
+			Amount:        arg.Amount,
+			Status:        TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+
+		posted, err := postTransfer(ctx, q, result.Transfer)
+		result.Account = posted.ToAccount
+		result.Entry = posted.ToEntry
+		if err != nil {
+			return err
+		}
+		if err = checkBalanceCap(ctx, q, result.Account); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer.deposit", AuditTransfer, auditID(result.Transfer.ID), nil, result.Transfer)
+	})
+
+	return result, err
+}
+
+// activeHold locks a hold and checks that it can still be captured.
+func activeHold(ctx context.Context, q *Queries, holdID int64, now time.Time) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != HoldActive {
+		return hold, ErrHoldNotActive
+	}
+	if !now.Before(hold.ExpiresAt) {
+		return hold, ErrHoldExpired
+	}
+
+	return hold, nil
+}
+
+// endHold closes a locked hold with the given status without capturing anything, and releases its money.
+func endHold(ctx context.Context, q *Queries, hold Hold, status string) (HoldTxResult, error) {
+	var result HoldTxResult
+	var err error
+
+	result.Account, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     hold.AccountID,
+		Amount: -hold.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+		Status:         status,
+		CapturedAmount: 0,
+		ID:             hold.ID,
+	})
+	return result, err
+}
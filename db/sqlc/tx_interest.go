@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// Interest methods of an account product
+const (
+	InterestSimple   = "simple"   // interest accrues on the ledger balance only
+	InterestCompound = "compound" // interest also accrues on interest accrued but not posted yet
+)
+
+// Day-count conventions: the actual number of days, over a year of 365 or 360 days
+const (
+	DayCountACT365 = "ACT/365"
+	DayCountACT360 = "ACT/360"
+)
+
+// MicrosPerUnit is the number of accrual units (micros) in one minor unit of a currency, e.g. one cent.
+// Daily interest is usually a fraction of a cent, so accruals are kept in micros until they are posted.
+const MicrosPerUnit = 1_000_000
+
+// DailyInterest returns one day of interest on a principal, both in micros, rounded down.
+func DailyInterest(principalMicros int64, rateBps int32, dayCount string) int64 {
+	if principalMicros <= 0 || rateBps <= 0 {
+		return 0
+	}
+
+	daysInYear := int64(365)
+	if dayCount == DayCountACT360 {
+		daysInYear = 360
+	}
+
+	// principal * rate / 10_000 / days, computed on big integers since the product can overflow int64
+	interest := new(big.Int).Mul(big.NewInt(principalMicros), big.NewInt(int64(rateBps)))
+	interest.Quo(interest, big.NewInt(10_000*daysInYear))
+	return interest.Int64()
+}
+
+// InterestDate truncates a time to the UTC day it falls on. Accruals and postings are dated in UTC.
+func InterestDate(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// AccrueInterestTxParams contains the input parameters of AccrueInterestTx.
+type AccrueInterestTxParams struct {
+	AccountID int64     `json:"account_id"`
+	Date      time.Time `json:"date"`
+}
+
+// AccrueInterestTxResult contains the accrual recorded for the day. Accrued is false when the day
+// had already been accrued, in which case nothing was recorded.
+type AccrueInterestTxResult struct {
+	Accrual InterestAccrual `json:"accrual"`
+	Accrued bool            `json:"accrued"`
+}
+
+// AccrueInterestTx records one day of interest for an account, according to the rate, interest method
+// and day-count convention of its product. The principal is the ledger balance at the end of that day,
+// so past days can be accrued later (backfilled). Each account is accrued at most once per day.
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (AccrueInterestTxResult, error) {
+	var result AccrueInterestTxResult
+	date := InterestDate(arg.Date)
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		product, err := q.GetAccountProduct(ctx, account.ProductID)
+		if err != nil {
+			return err
+		}
+
+		balance, err := q.GetAccountBalanceAt(ctx, GetAccountBalanceAtParams{
+			At:        date.AddDate(0, 0, 1),
+			AccountID: account.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		principal := balance * MicrosPerUnit
+		if product.InterestMethod == InterestCompound {
+			unposted, err := q.SumUnpostedInterest(ctx, SumUnpostedInterestParams{
+				AccountID: account.ID,
+				Through:   date.AddDate(0, 0, -1),
+			})
+			if err != nil {
+				return err
+			}
+			principal += unposted
+		}
+
+		result.Accrual, err = q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+			AccountID:       account.ID,
+			AccrualDate:     date,
+			PrincipalMicros: principal,
+			RateBps:         product.InterestRateBps,
+			InterestMethod:  product.InterestMethod,
+			DayCount:        product.DayCount,
+			AmountMicros:    DailyInterest(principal, product.InterestRateBps, product.DayCount),
+		})
+		// The insert does nothing when the day was already accrued
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result.Accrued = true
+		return nil
+	})
+
+	return result, err
+}
+
+// PostInterestTxParams contains the input parameters of PostInterestTx.
+type PostInterestTxParams struct {
+	AccountID int64     `json:"account_id"`
+	PeriodEnd time.Time `json:"period_end"` // last day of the period, included
+}
+
+// PostInterestTxResult contains the posting of a period and the transfer that paid it, if any.
+// Posted is false when the period had already been posted, in which case nothing was recorded.
+type PostInterestTxResult struct {
+	Posting  InterestPosting   `json:"posting"`
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+	Posted   bool              `json:"posted"`
+}
+
+// PostInterestTx pays the interest accrued on an account up to the end of a period. The whole minor units
+// are transferred from the system interest expense account of the account's currency; the fraction of a
+// minor unit that is left is carried over to the next posting. Each period is posted at most once.
+func (store *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+	periodEnd := InterestDate(arg.PeriodEnd)
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Lock the account before the interest expense account, which postTransfer could otherwise lock first
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		accrued, err := q.SumUnpostedInterest(ctx, SumUnpostedInterestParams{
+			AccountID: account.ID,
+			Through:   periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+		last, err := q.GetLastInterestPosting(ctx, account.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		accrued += last.RemainderMicros
+
+		// Recording the posting first claims the period: a concurrent or repeated run gets no row back
+		result.Posting, err = q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID:       account.ID,
+			PeriodEnd:       periodEnd,
+			AccruedMicros:   accrued,
+			Amount:          accrued / MicrosPerUnit,
+			RemainderMicros: accrued % MicrosPerUnit,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result.Posted = true
+
+		err = q.MarkInterestAccrualsPosted(ctx, MarkInterestAccrualsPostedParams{
+			PostingID: sql.NullInt64{Int64: result.Posting.ID, Valid: true},
+			AccountID: account.ID,
+			Through:   periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		if result.Posting.Amount == 0 {
+			return nil
+		}
+
+		expenseAccount, err := systemAccount(ctx, q, ProductInterestExpense, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		// The expense account is not checked for funds: it goes negative by the interest the bank pays
+		transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: expenseAccount.ID,
+			ToAccountID:   account.ID,
+			Amount:        result.Posting.Amount,
+			Status:        TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+		posted, err := postTransfer(ctx, q, transfer)
+		if err != nil {
+			return err
+		}
+		result.Transfer = &posted
+
+		result.Posting, err = q.SetInterestPostingTransfer(ctx, SetInterestPostingTransferParams{
+			TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+			ID:         result.Posting.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,251 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// KYC statuses of a user
+const (
+	KYCStatusNone     = "none"     // no profile submitted yet
+	KYCStatusPending  = "pending"  // submitted, waiting for a review
+	KYCStatusVerified = "verified" // approved, the user has the tier it was approved with
+	KYCStatusRejected = "rejected" // rejected, the user can submit their profile again
+)
+
+// Codes of the KYC tiers created by the migrations
+const (
+	KYCTierBasic    = "basic"    // every user until their profile is verified
+	KYCTierStandard = "standard" // the tier profiles are approved with by default
+	KYCTierEnhanced = "enhanced" // after enhanced due diligence, without limits
+)
+
+// Kinds of KYC documents
+const (
+	KYCDocumentPassport       = "passport"
+	KYCDocumentIDCard         = "id_card"
+	KYCDocumentDrivingLicence = "driving_licence"
+	KYCDocumentProofOfAddress = "proof_of_address"
+)
+
+// Errors returned by the KYC transactions and when a KYC tier's limits are exceeded
+var (
+	ErrKYCVerified           = errors.New("KYC profile is already verified")
+	ErrKYCNotPending         = errors.New("KYC profile is not waiting for a review")
+	ErrKYCSelfReview         = errors.New("users cannot review their own KYC profile")
+	ErrKYCNoDocuments        = errors.New("KYC profile has no documents")
+	ErrUnknownKYCTier        = errors.New("unknown KYC tier")
+	ErrKYCAccountLimit       = errors.New("maximum number of open accounts reached for the KYC tier")
+	ErrTransferLimitExceeded = errors.New("daily transfer limit of the KYC tier exceeded")
+	ErrBalanceCapExceeded    = errors.New("balance cap of the KYC tier exceeded")
+)
+
+// startOfDay is midnight UTC of the day of t, when the daily transfer limits start again.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// checkTransferLimit returns ErrTransferLimitExceeded when sending amount from an account would take its owner
+// over the daily transfer limit of their KYC tier. It locks the owner, so their concurrent transfers are counted
+// one after the other, before any account is locked, like the transactions that lock a user and their accounts.
+func checkTransferLimit(ctx context.Context, q *Queries, fromAccountID, amount int64) error {
+	account, err := q.GetAccount(ctx, fromAccountID)
+	if err != nil {
+		return err
+	}
+	owner, err := q.GetUserForUpdate(ctx, account.Owner)
+	if err != nil {
+		return err
+	}
+	tier, err := q.GetKYCTier(ctx, owner.KycTier)
+	if err != nil {
+		return err
+	}
+	if tier.DailyTransferLimit == 0 {
+		return nil
+	}
+
+	sent, err := q.SumTransfersSentSince(ctx, SumTransfersSentSinceParams{
+		Owner: account.Owner,
+		Since: startOfDay(time.Now()),
+	})
+	if err != nil {
+		return err
+	}
+	if sent+amount > tier.DailyTransferLimit {
+		return fmt.Errorf("%w: %d already sent today, limit is %d", ErrTransferLimitExceeded, sent, tier.DailyTransferLimit)
+	}
+	return nil
+}
+
+// checkBalanceCap returns ErrBalanceCapExceeded when an account that was just credited holds more than
+// the KYC tier of its owner allows. Like the available balance, it is checked after the update.
+func checkBalanceCap(ctx context.Context, q *Queries, account Account) error {
+	tier, err := q.GetKYCTierByAccount(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+	if tier.MaxBalance > 0 && account.Balance > tier.MaxBalance {
+		return fmt.Errorf("account %d: %w", account.ID, ErrBalanceCapExceeded)
+	}
+	return nil
+}
+
+// SubmitKYCProfileTxParams contains the input parameters of SubmitKYCProfileTx.
+type SubmitKYCProfileTxParams struct {
+	Username     string    `json:"username"`
+	DateOfBirth  time.Time `json:"date_of_birth"`
+	AddressLine1 string    `json:"address_line1"`
+	AddressLine2 string    `json:"address_line2"`
+	City         string    `json:"city"`
+	PostalCode   string    `json:"postal_code"`
+	Country      string    `json:"country"`
+}
+
+// KYCProfileTxResult contains a KYC profile and its user after it was submitted or reviewed.
+type KYCProfileTxResult struct {
+	Profile KycProfile `json:"profile"`
+	User    User       `json:"user"`
+}
+
+// SubmitKYCProfileTx submits the KYC profile of a user for review, replacing a previous one. The user's tier
+// stays as it is until the profile is approved. It returns ErrKYCVerified once the profile was approved.
+// The audit log records the change of status, but not the profile itself.
+func (store *SQLStore) SubmitKYCProfileTx(ctx context.Context, arg SubmitKYCProfileTxParams) (KYCProfileTxResult, error) {
+	var result KYCProfileTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if before.KycStatus == KYCStatusVerified {
+			return ErrKYCVerified
+		}
+
+		result.Profile, err = q.UpsertKYCProfile(ctx, UpsertKYCProfileParams(arg))
+		if err != nil {
+			return err
+		}
+		result.User, err = q.SetUserKYC(ctx, SetUserKYCParams{KycStatus: KYCStatusPending, Username: arg.Username})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.kyc_submit", AuditUser, arg.Username, newAuditUser(before), newAuditUser(result.User))
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.User, err = store.openUser(ctx, result.User)
+	return result, err
+}
+
+// AddKYCDocumentTx records a document uploaded to the blob store for the KYC profile of a user.
+// It returns ErrKYCVerified once the profile was approved.
+func (store *SQLStore) AddKYCDocumentTx(ctx context.Context, arg CreateKYCDocumentParams) (KycDocument, error) {
+	var document KycDocument
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		user, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if user.KycStatus == KYCStatusVerified {
+			return ErrKYCVerified
+		}
+
+		document, err = q.CreateKYCDocument(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "kyc_document.create", AuditKYCDocument, auditID(document.ID), nil, document)
+	})
+
+	return document, err
+}
+
+// ReviewKYCTxParams contains the input parameters of ReviewKYCTx. Approved profiles get Tier,
+// the standard tier when it is empty, and rejected ones the Reason.
+type ReviewKYCTxParams struct {
+	Username string    `json:"username"`
+	Reviewer string    `json:"reviewer"`
+	Approve  bool      `json:"approve"`
+	Tier     string    `json:"tier"`
+	Reason   string    `json:"reason"`
+	Now      time.Time `json:"now"`
+}
+
+// ReviewKYCTx approves or rejects a pending KYC profile. An approved user gets the limits of the tier,
+// a rejected one goes back to the basic tier. A profile is reviewed by another user than its own,
+// and approved only with at least one document. It returns ErrKYCNotPending when no profile waits for a review.
+func (store *SQLStore) ReviewKYCTx(ctx context.Context, arg ReviewKYCTxParams) (KYCProfileTxResult, error) {
+	var result KYCProfileTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Reviewer == arg.Username {
+			return ErrKYCSelfReview
+		}
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if before.KycStatus != KYCStatusPending {
+			return ErrKYCNotPending
+		}
+
+		status, tier := KYCStatusRejected, KYCTierBasic
+		if arg.Approve {
+			status, tier = KYCStatusVerified, arg.Tier
+			if tier == "" {
+				tier = KYCTierStandard
+			}
+			if _, err := q.GetKYCTier(ctx, tier); errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w %q", ErrUnknownKYCTier, tier)
+			} else if err != nil {
+				return err
+			}
+			documents, err := q.ListKYCDocuments(ctx, arg.Username)
+			if err != nil {
+				return err
+			}
+			if len(documents) == 0 {
+				return ErrKYCNoDocuments
+			}
+			arg.Reason = ""
+		}
+
+		result.Profile, err = q.ReviewKYCProfile(ctx, ReviewKYCProfileParams{
+			ReviewedBy:      sql.NullString{String: arg.Reviewer, Valid: true},
+			ReviewedAt:      sql.NullTime{Time: arg.Now, Valid: true},
+			RejectionReason: arg.Reason,
+			Username:        arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+		result.User, err = q.SetUserKYC(ctx, SetUserKYCParams{
+			KycStatus: status,
+			KycTier:   sql.NullString{String: tier, Valid: true},
+			Username:  arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		action := "user.kyc_reject"
+		if arg.Approve {
+			action = "user.kyc_approve"
+		}
+		return recordAudit(ctx, q, action, AuditUser, arg.Username, newAuditUser(before), newAuditUser(result.User))
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.User, err = store.openUser(ctx, result.User)
+	return result, err
+}
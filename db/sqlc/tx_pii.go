@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// auditDataKey is the audited snapshot of a data key, leaving out the key itself even though it is sealed.
+type auditDataKey struct {
+	ID          int64     `json:"id"`
+	MasterKeyID string    `json:"master_key_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newAuditDataKey(key DataKey) auditDataKey {
+	return auditDataKey{ID: key.ID, MasterKeyID: key.MasterKeyID, CreatedAt: key.CreatedAt}
+}
+
+// RewrapDataKeys seals every data key still sealed with an older master key with the current one, so the
+// older master key can be retired. The data keys themselves do not change. It returns how many were rewrapped.
+func (store *SQLStore) RewrapDataKeys(ctx context.Context) (int, error) {
+	if store.pii == nil {
+		return 0, ErrPIIKeyMissing
+	}
+
+	var rewrapped int
+	err := store.execTx(ctx, func(q *Queries) error {
+		keys, err := q.ListDataKeys(ctx)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if key.MasterKeyID == store.pii.currentMasterID {
+				continue
+			}
+			wrapped, err := store.pii.rewrap(key)
+			if err != nil {
+				return err
+			}
+			after, err := q.RewrapDataKey(ctx, RewrapDataKeyParams{
+				WrappedKey:  wrapped,
+				MasterKeyID: store.pii.currentMasterID,
+				ID:          key.ID,
+			})
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, q, "data_key.rewrap", AuditDataKey, auditID(key.ID), newAuditDataKey(key), newAuditDataKey(after)); err != nil {
+				return err
+			}
+			rewrapped++
+		}
+		return nil
+	})
+
+	return rewrapped, err
+}
+
+// RotateDataKey creates a new data key, which seals new and updated users from then on.
+// Users sealed with older keys are moved to it by ReencryptUsersTx.
+func (store *SQLStore) RotateDataKey(ctx context.Context) (DataKey, error) {
+	if store.pii == nil {
+		return DataKey{}, ErrPIIKeyMissing
+	}
+
+	key, err := store.pii.newDataKey(ctx, store.Queries)
+	if err != nil {
+		return key, err
+	}
+	err = recordAudit(ctx, store.Queries, "data_key.create", AuditDataKey, auditID(key.ID), nil, newAuditDataKey(key))
+	return key, err
+}
+
+// ReencryptUsersTx seals a batch of up to batchSize users with the active data key, encrypting those still
+// in plaintext. Users locked by other transactions are left for a later batch, so it runs while the bank is
+// open. Pending verification codes sent to a user in plaintext are sealed with them, keeping only the blind
+// index of their email. It returns how many users were sealed again, and is called until that is zero.
+func (store *SQLStore) ReencryptUsersTx(ctx context.Context, batchSize int32) (int, error) {
+	if store.pii == nil {
+		return 0, ErrPIIKeyMissing
+	}
+	activeID, _, err := store.pii.activeDataKey(ctx, store.Queries)
+	if err != nil {
+		return 0, err
+	}
+
+	var reencrypted int
+	err = store.execTx(ctx, func(q *Queries) error {
+		users, err := q.ListUsersToReencrypt(ctx, ListUsersToReencryptParams{
+			ActiveDataKeyID: activeID,
+			Limit:           batchSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			current, err := store.openUser(ctx, user)
+			if err != nil {
+				return err
+			}
+			sealed, err := store.sealUser(ctx, user.Username, current.FullName, current.Email)
+			if err != nil {
+				return err
+			}
+			_, err = q.UpdateUserPII(ctx, UpdateUserPIIParams{
+				FullName:   sealed.FullName,
+				Email:      sealed.Email,
+				EmailIndex: sealed.EmailIndex,
+				DataKeyID:  sealed.DataKeyID,
+				Username:   user.Username,
+			})
+			if err != nil {
+				return err
+			}
+			err = q.SealVerifyEmails(ctx, SealVerifyEmailsParams{
+				Email:      current.Email,
+				EmailIndex: sealed.EmailIndex.String,
+				Username:   user.Username,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		reencrypted = len(users)
+		return nil
+	})
+
+	return reencrypted, err
+}
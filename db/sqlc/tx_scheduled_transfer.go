@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Schedule types of a scheduled transfer
+const (
+	ScheduleTypeOnce = "once"
+	ScheduleTypeCron = "cron"
+)
+
+// Statuses of a scheduled transfer
+const (
+	ScheduledTransferActive    = "active"
+	ScheduledTransferPaused    = "paused"
+	ScheduledTransferCompleted = "completed"
+	ScheduledTransferCancelled = "cancelled"
+)
+
+// Outcomes of a single scheduled transfer execution
+const (
+	ScheduledTransferRunSucceeded = "succeeded"
+	ScheduledTransferRunFailed    = "failed"
+)
+
+// ErrScheduledTransferNeedsApproval is recorded when a scheduled transfer is above the approval threshold.
+// Nobody reviews scheduled transfers as they run, so they are not executed.
+var ErrScheduledTransferNeedsApproval = errors.New("amount is above the transfer approval threshold")
+
+// ExecuteScheduledTransferTxParams contains the input parameters of ExecuteScheduledTransferTx.
+type ExecuteScheduledTransferTxParams struct {
+	Now         time.Time     `json:"now"`          // only scheduled transfers due at or before this time are picked
+	MaxFailures int32         `json:"max_failures"` // consecutive failures after which the scheduled transfer is paused
+	RetryDelay  time.Duration `json:"retry_delay"`  // how long to wait before retrying a failed execution
+	// ApprovalThreshold is the amount above which transfers need a second user's approval, 0 for no threshold
+	ApprovalThreshold int64 `json:"approval_threshold"`
+}
+
+// ExecuteScheduledTransferTxResult contains the result of one scheduled transfer execution.
+// Transfer is empty when the execution failed; the reason is recorded in Run.Error.
+type ExecuteScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer    `json:"scheduled_transfer"`
+	Run               ScheduledTransferRun `json:"run"`
+	Transfer          TransferTxResult     `json:"transfer"`
+}
+
+// ExecuteScheduledTransferTx picks one due scheduled transfer and executes it.
+// The row is claimed with FOR UPDATE SKIP LOCKED, so concurrent schedulers never pick the same run twice,
+// and the transfer, the run record and the next occurrence are all committed in the same transaction.
+// A scheduled transfer above the approval threshold fails with ErrScheduledTransferNeedsApproval and is paused
+// straight away, as retrying cannot help. It returns sql.ErrNoRows when nothing is due.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduled, err := q.GetDueScheduledTransferForUpdate(ctx, arg.Now)
+		if err != nil {
+			return err
+		}
+
+		// The transfer runs inside a savepoint: if it fails we undo only the transfer
+		// and still commit the failed run, instead of losing it with the whole transaction.
+		if _, err = q.db.ExecContext(ctx, "SAVEPOINT scheduled_transfer"); err != nil {
+			return err
+		}
+
+		var transfer TransferTxResult
+		var transferErr error
+		if arg.ApprovalThreshold > 0 && scheduled.Amount > arg.ApprovalThreshold {
+			transferErr = ErrScheduledTransferNeedsApproval
+		} else {
+			transfer, transferErr = feeTransferTx(ctx, q, TransferTxParams{
+				FromAccountID: scheduled.FromAccountID,
+				ToAccountID:   scheduled.ToAccountID,
+				Amount:        scheduled.Amount,
+			})
+		}
+		if transferErr != nil {
+			if _, err = q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT scheduled_transfer"); err != nil {
+				return err
+			}
+		} else {
+			result.Transfer = transfer
+		}
+
+		runArg := CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			ScheduledFor:        scheduled.NextRunAt,
+		}
+		updateArg := UpdateScheduledTransferAfterRunParams{
+			ID:           scheduled.ID,
+			NextRunAt:    scheduled.NextRunAt,
+			RunCount:     scheduled.RunCount,
+			FailureCount: scheduled.FailureCount,
+			Status:       scheduled.Status,
+		}
+
+		if transferErr != nil {
+			runArg.Status = ScheduledTransferRunFailed
+			runArg.Error = transferErr.Error()
+
+			// Retry later, and give up once the transfer has failed too many times in a row
+			updateArg.FailureCount++
+			updateArg.NextRunAt = arg.Now.Add(arg.RetryDelay)
+			if errors.Is(transferErr, ErrScheduledTransferNeedsApproval) ||
+				(arg.MaxFailures > 0 && updateArg.FailureCount >= arg.MaxFailures) {
+				updateArg.Status = ScheduledTransferPaused
+			}
+		} else {
+			runArg.Status = ScheduledTransferRunSucceeded
+			runArg.TransferID = sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}
+
+			updateArg.RunCount++
+			updateArg.FailureCount = 0
+
+			next, ok, err := nextScheduledRun(scheduled, updateArg.RunCount, arg.Now)
+			if err != nil {
+				return err
+			}
+			if ok {
+				updateArg.NextRunAt = next
+			} else {
+				updateArg.Status = ScheduledTransferCompleted
+			}
+		}
+
+		result.Run, err = q.CreateScheduledTransferRun(ctx, runArg)
+		if err != nil {
+			return err
+		}
+
+		result.ScheduledTransfer, err = q.UpdateScheduledTransferAfterRun(ctx, updateArg)
+		return err
+	})
+
+	return result, err
+}
+
+// nextScheduledRun works out when a scheduled transfer should run after a successful execution.
+// Occurrences missed while no scheduler was running are skipped rather than replayed.
+// It returns false once the scheduled transfer has reached one of its end conditions.
+func nextScheduledRun(scheduled ScheduledTransfer, runCount int32, now time.Time) (time.Time, bool, error) {
+	if scheduled.ScheduleType != ScheduleTypeCron {
+		return time.Time{}, false, nil
+	}
+	if scheduled.MaxRuns.Valid && runCount >= scheduled.MaxRuns.Int32 {
+		return time.Time{}, false, nil
+	}
+
+	schedule, err := util.ParseCronSchedule(scheduled.CronExpression)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	next := schedule.Next(now.UTC())
+	if next.IsZero() || (scheduled.EndAt.Valid && next.After(scheduled.EndAt.Time)) {
+		return time.Time{}, false, nil
+	}
+
+	return next, true, nil
+}
@@ -0,0 +1,320 @@
+package db
+
+import (
+	"context"
+	"sort"
+)
+
+// PendingTransferTx creates a transfer that settles asynchronously. The sender is debited straight away,
+// while the recipient is only credited once SettleTransfersTx posts the transfer. Meanwhile the money
+// sits in the transit account of the currency.
+// It returns ErrInsufficientFunds when the sender's available balance does not cover the amount and the fee,
+// and ErrTransferLimitExceeded when the sender has sent as much as their KYC tier allows today.
+func (store *SQLStore) PendingTransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// The recipient's balance cap is checked when the transfer settles, failing it when exceeded
+		if err := checkTransferLimit(ctx, q, arg.FromAccountID, arg.Amount); err != nil {
+			return err
+		}
+		account, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+		if err := checkDebit(account); err != nil {
+			return err
+		}
+		if !canSpend(account, arg.Amount) {
+			return ErrInsufficientFunds
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			Status:        TransferStatusPending,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     arg.FromAccountID,
+			Amount: -arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  arg.FromAccountID,
+			Amount:     -arg.Amount,
+			TransferID: entryTransfer(result.Transfer.ID),
+		})
+		if err != nil {
+			return err
+		}
+
+		legs := []transitLeg{{TransferID: result.Transfer.ID, Currency: account.Currency, Amount: arg.Amount}}
+		if err = postTransit(ctx, q, legs); err != nil {
+			return err
+		}
+
+		// The fee is charged when the sender is debited, and kept if the transfer later fails to settle
+		if err = chargeTransferFee(ctx, q, &result); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer.create", AuditTransfer, auditID(result.Transfer.ID), nil, result.Transfer)
+	})
+
+	return result, err
+}
+
+// transitLeg is the entry of a pending transfer on the transit account of its currency:
+// a credit when the sender is debited, a debit when the transfer settles or fails.
+type transitLeg struct {
+	TransferID int64
+	Currency   string
+	Amount     int64
+}
+
+// postTransit updates the transit accounts by the legs' amounts, one currency at a time, and writes the legs' entries.
+// It must run after every customer account of the transaction was updated, so that the transit accounts are locked last.
+func postTransit(ctx context.Context, q *Queries, legs []transitLeg) error {
+	totals := make(map[string]int64)
+	for _, leg := range legs {
+		totals[leg.Currency] += leg.Amount
+	}
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	for _, currency := range currencies {
+		transit, err := systemAccount(ctx, q, ProductTransit, currency)
+		if err != nil {
+			return err
+		}
+		if _, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     transit.ID,
+			Amount: totals[currency],
+		}); err != nil {
+			return err
+		}
+
+		for _, leg := range legs {
+			if leg.Currency != currency {
+				continue
+			}
+			if _, err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID:  transit.ID,
+				Amount:     leg.Amount,
+				TransferID: entryTransfer(leg.TransferID),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SettleTransfersTxParams contains the input parameters of SettleTransfersTx.
+type SettleTransfersTxParams struct {
+	BatchSize int32 `json:"batch_size"` // maximum number of pending transfers settled in one transaction
+}
+
+// FailedSettlement is a pending transfer that could not be posted. Refund holds the entry and
+// balance that gave the money back to the sender.
+type FailedSettlement struct {
+	Refund TransferTxResult `json:"refund"`
+	Error  string           `json:"error"`
+}
+
+// SettleTransfersTxResult contains the transfers settled by one SettleTransfersTx call.
+type SettleTransfersTxResult struct {
+	Posted []TransferTxResult `json:"posted"`
+	Failed []FailedSettlement `json:"failed"`
+}
+
+// SettleTransfersTx posts a batch of pending transfers by crediting their recipients.
+// Rows are claimed with FOR UPDATE SKIP LOCKED, so several settlement workers never settle the same transfer.
+// The senders and recipients of the batch are then locked up front in ascending ID order, like TransferTx does,
+// so that refunding a sender cannot deadlock with live transfers.
+// A transfer that cannot be posted is marked as failed and its amount is refunded to the sender,
+// without affecting the rest of the batch.
+func (store *SQLStore) SettleTransfersTx(ctx context.Context, arg SettleTransfersTxParams) (SettleTransfersTxResult, error) {
+	var result SettleTransfersTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		transfers, err := q.ListPendingTransfersForUpdate(ctx, arg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		accountIDs := make([]int64, 0, 2*len(transfers))
+		for _, transfer := range transfers {
+			accountIDs = append(accountIDs, transfer.FromAccountID, transfer.ToAccountID)
+		}
+		if err = lockAccounts(ctx, q, accountIDs...); err != nil {
+			return err
+		}
+
+		// Transit entries are written once every transfer is done
+		var legs []transitLeg
+
+		for _, transfer := range transfers {
+			// Each transfer is settled inside a savepoint, so a failure only undoes that transfer
+			if _, err = q.db.ExecContext(ctx, "SAVEPOINT settle_transfer"); err != nil {
+				return err
+			}
+
+			posted, settleErr := settleTransfer(ctx, q, transfer)
+			if settleErr != nil {
+				if _, err = q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT settle_transfer"); err != nil {
+					return err
+				}
+
+				refund, err := failTransfer(ctx, q, transfer)
+				if err != nil {
+					return err
+				}
+				legs = append(legs, transitLeg{TransferID: transfer.ID, Currency: refund.FromAccount.Currency, Amount: -transfer.Amount})
+				result.Failed = append(result.Failed, FailedSettlement{Refund: refund, Error: settleErr.Error()})
+			} else {
+				legs = append(legs, transitLeg{TransferID: transfer.ID, Currency: posted.ToAccount.Currency, Amount: -transfer.Amount})
+				result.Posted = append(result.Posted, posted)
+			}
+
+			if _, err = q.db.ExecContext(ctx, "RELEASE SAVEPOINT settle_transfer"); err != nil {
+				return err
+			}
+		}
+
+		return postTransit(ctx, q, legs)
+	})
+
+	return result, err
+}
+
+// settleTransfer posts a pending transfer by crediting its recipient. The caller debits the transit account.
+func settleTransfer(ctx context.Context, q *Queries, transfer Transfer) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = transitionTransfer(ctx, q, transfer.ID, TransferStatusPending, TransferStatusPosted)
+	if err != nil {
+		return result, err
+	}
+
+	result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     transfer.ToAccountID,
+		Amount: transfer.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.ToAccountID,
+		Amount:     transfer.Amount,
+		TransferID: entryTransfer(transfer.ID),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// A recipient closed since the transfer was created, or over their balance cap, makes it fail, refunding the sender
+	if err = checkCredit(result.ToAccount); err != nil {
+		return result, err
+	}
+	return result, checkBalanceCap(ctx, q, result.ToAccount)
+}
+
+// failTransfer marks a pending transfer as failed and gives its amount back to the sender. The caller debits the transit account.
+func failTransfer(ctx context.Context, q *Queries, transfer Transfer) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = transitionTransfer(ctx, q, transfer.ID, TransferStatusPending, TransferStatusFailed)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     transfer.FromAccountID,
+		Amount: transfer.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.FromAccountID,
+		Amount:     transfer.Amount,
+		TransferID: entryTransfer(transfer.ID),
+	})
+	return result, err
+}
+
+// ReverseTransferTx undoes a posted transfer: the money goes back from the recipient to the sender
+// through compensating entries, and the transfer is marked as reversed.
+// It returns ErrInvalidTransferTransition when the transfer is not posted, sql.ErrNoRows when it does not exist,
+// and ErrInsufficientFunds when the recipient already spent the money and their overdraft does not cover it.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		transfer, err := transitionTransfer(ctx, q, transferID, TransferStatusPosted, TransferStatusReversed)
+		if err != nil {
+			return err
+		}
+		result.Transfer = transfer
+
+		// Same locking order as TransferTx to avoid deadlocks, and entries only once both accounts are locked
+		if transfer.FromAccountID < transfer.ToAccountID {
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, transfer.FromAccountID, transfer.Amount, transfer.ToAccountID, -transfer.Amount)
+		} else {
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, transfer.ToAccountID, -transfer.Amount, transfer.FromAccountID, transfer.Amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  transfer.FromAccountID,
+			Amount:     transfer.Amount,
+			TransferID: entryTransfer(transfer.ID),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  transfer.ToAccountID,
+			Amount:     -transfer.Amount,
+			TransferID: entryTransfer(transfer.ID),
+		})
+		if err != nil {
+			return err
+		}
+
+		// The money goes the other way, so the recipient is the one debited
+		if err = checkDebit(result.ToAccount); err != nil {
+			return err
+		}
+		if !canSpend(result.ToAccount, 0) {
+			return ErrInsufficientFunds
+		}
+		if err = checkCredit(result.FromAccount); err != nil {
+			return err
+		}
+
+		before := transfer
+		before.Status = TransferStatusPosted
+		return recordAudit(ctx, q, "transfer.reverse", AuditTransfer, auditID(transfer.ID), before, transfer)
+	})
+
+	return result, err
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Errors returned by the TOTP transactions
+var (
+	ErrTOTPNotPending     = errors.New("no two-factor enrolment is pending")
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+)
+
+// auditTOTP is the audited state of a user's two-factor authentication, leaving out the secret.
+type auditTOTP struct {
+	Enabled       bool         `json:"enabled"`
+	EnabledAt     sql.NullTime `json:"enabled_at"`
+	RecoveryCodes int          `json:"recovery_codes"`
+}
+
+// EnableTOTPTxParams contains the input parameters of EnableTOTPTx.
+type EnableTOTPTxParams struct {
+	Username           string    `json:"username"`
+	Step               int64     `json:"step"` // time step of the code that confirmed the enrolment
+	RecoveryCodeHashes []string  `json:"-"`
+	Now                time.Time `json:"now"`
+}
+
+// EnableTOTPTx completes a pending enrolment once the user has proved they can generate codes,
+// and replaces their recovery codes. It returns ErrTOTPNotPending when enrolment was not started
+// and ErrTOTPAlreadyEnabled when it was already completed.
+func (store *SQLStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (UserTotp, error) {
+	var totp UserTotp
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pending, err := q.GetUserTOTPForUpdate(ctx, arg.Username)
+		if err == sql.ErrNoRows {
+			return ErrTOTPNotPending
+		}
+		if err != nil {
+			return err
+		}
+		if pending.EnabledAt.Valid {
+			return ErrTOTPAlreadyEnabled
+		}
+
+		totp, err = q.EnableUserTOTP(ctx, EnableUserTOTPParams{
+			EnabledAt:    sql.NullTime{Time: arg.Now, Valid: true},
+			LastUsedStep: arg.Step,
+			Username:     arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteTOTPRecoveryCodes(ctx, arg.Username); err != nil {
+			return err
+		}
+		for _, codeHash := range arg.RecoveryCodeHashes {
+			_, err := q.CreateTOTPRecoveryCode(ctx, CreateTOTPRecoveryCodeParams{Username: arg.Username, CodeHash: codeHash})
+			if err != nil {
+				return err
+			}
+		}
+
+		after := auditTOTP{Enabled: true, EnabledAt: totp.EnabledAt, RecoveryCodes: len(arg.RecoveryCodeHashes)}
+		return recordAudit(ctx, q, "user.enable_totp", AuditUser, arg.Username, auditTOTP{}, after)
+	})
+
+	return totp, err
+}
+
+// DisableTOTPTx removes a user's TOTP secret, pending or enabled, and their recovery codes.
+// It returns sql.ErrNoRows when the user has no secret.
+func (store *SQLStore) DisableTOTPTx(ctx context.Context, username string) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		totp, err := q.GetUserTOTPForUpdate(ctx, username)
+		if err != nil {
+			return err
+		}
+		unused, err := q.CountUnusedTOTPRecoveryCodes(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteTOTPRecoveryCodes(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserTOTP(ctx, username); err != nil {
+			return err
+		}
+
+		before := auditTOTP{Enabled: totp.EnabledAt.Valid, EnabledAt: totp.EnabledAt, RecoveryCodes: int(unused)}
+		return recordAudit(ctx, q, "user.disable_totp", AuditUser, username, before, auditTOTP{})
+	})
+}
@@ -0,0 +1,258 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Statuses of a transfer approval request
+const (
+	TransferApprovalPending  = "pending"
+	TransferApprovalApproved = "approved"
+	TransferApprovalRejected = "rejected"
+	TransferApprovalExpired  = "expired"
+	// TransferApprovalCancelled is set when the requester or the sender deactivates before the review
+	TransferApprovalCancelled = "cancelled"
+)
+
+// Errors returned when a transfer approval cannot be reviewed
+var (
+	ErrApprovalNotPending = errors.New("transfer is not awaiting approval")
+	ErrApprovalExpired    = errors.New("transfer approval has expired")
+	ErrSelfApproval       = errors.New("a transfer cannot be reviewed by the user who requested it")
+)
+
+// RequestTransferApprovalTxParams contains the input parameters of RequestTransferApprovalTx.
+type RequestTransferApprovalTxParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	RequestedBy   string    `json:"requested_by"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// TransferApprovalTxResult contains a transfer awaiting (or no longer awaiting) approval,
+// its approval request and the source account after its hold was updated.
+type TransferApprovalTxResult struct {
+	Transfer    Transfer         `json:"transfer"`
+	Approval    TransferApproval `json:"approval"`
+	FromAccount Account          `json:"from_account"`
+}
+
+// ReviewTransferTxParams contains the input parameters to approve or reject a pending transfer.
+type ReviewTransferTxParams struct {
+	TransferID int64     `json:"transfer_id"`
+	ReviewedBy string    `json:"reviewed_by"`
+	Now        time.Time `json:"now"` // approvals that expired before this time can no longer be reviewed
+}
+
+// ApproveTransferTxResult contains the posted transfer and its approval request.
+type ApproveTransferTxResult struct {
+	TransferTxResult
+	Approval TransferApproval `json:"approval"`
+}
+
+// RequestTransferApprovalTx records a transfer that needs a second user's approval before it is posted.
+// The amount is put on hold on the source account, so it cannot be spent while the transfer awaits review.
+// It returns ErrInsufficientFunds when the account's available balance does not cover the amount, and
+// ErrTransferLimitExceeded when the sender has sent as much as their KYC tier allows today, as the transfer counts when requested.
+func (store *SQLStore) RequestTransferApprovalTx(ctx context.Context, arg RequestTransferApprovalTxParams) (TransferApprovalTxResult, error) {
+	var result TransferApprovalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = requestTransferApproval(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer_approval.create", AuditTransferApproval, auditID(result.Transfer.ID), nil, result.Approval)
+	})
+
+	return result, err
+}
+
+// requestTransferApproval contains the body of RequestTransferApprovalTx, so that hold captures can request approval inside their own transaction.
+func requestTransferApproval(ctx context.Context, q *Queries, arg RequestTransferApprovalTxParams) (TransferApprovalTxResult, error) {
+	var result TransferApprovalTxResult
+
+	if err := checkTransferLimit(ctx, q, arg.FromAccountID, arg.Amount); err != nil {
+		return result, err
+	}
+	account, err := q.GetAccountForUpdate(ctx, arg.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+	if err := checkDebit(account); err != nil {
+		return result, err
+	}
+	if !canSpend(account, arg.Amount) {
+		return result, ErrInsufficientFunds
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Status:        TransferStatusPendingApproval,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Approval, err = q.CreateTransferApproval(ctx, CreateTransferApprovalParams{
+		TransferID:  result.Transfer.ID,
+		RequestedBy: arg.RequestedBy,
+		ExpiresAt:   arg.ExpiresAt,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     arg.FromAccountID,
+		Amount: arg.Amount,
+	})
+	return result, err
+}
+
+// ApproveTransferTx posts a pending transfer on behalf of a reviewer, releases the hold on its funds and charges the transfer fee.
+// It returns ErrInsufficientFunds when the sender can no longer pay the fee, and ErrBalanceCapExceeded
+// when the recipient's account would hold more than their KYC tier allows.
+func (store *SQLStore) ApproveTransferTx(ctx context.Context, arg ReviewTransferTxParams) (ApproveTransferTxResult, error) {
+	var result ApproveTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		approval, err := reviewableTransferApproval(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		transfer, err := transitionTransfer(ctx, q, approval.TransferID, TransferStatusPendingApproval, TransferStatusPosted)
+		if err != nil {
+			return err
+		}
+
+		// Move the money first: addMoney locks both accounts in ID order,
+		// and the source account is already locked by the time the hold is released.
+		result.TransferTxResult, err = postTransfer(ctx, q, transfer)
+		if err != nil {
+			return err
+		}
+		if err = checkBalanceCap(ctx, q, result.ToAccount); err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     transfer.FromAccountID,
+			Amount: -transfer.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The fee was not held with the amount, so the sender must still be able to pay it
+		if err = chargeTransferFee(ctx, q, &result.TransferTxResult); err != nil {
+			return err
+		}
+
+		result.Approval, err = q.UpdateTransferApproval(ctx, UpdateTransferApprovalParams{
+			Status:     TransferApprovalApproved,
+			ReviewedBy: sql.NullString{String: arg.ReviewedBy, Valid: true},
+			TransferID: approval.TransferID,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer_approval.approve", AuditTransferApproval, auditID(approval.TransferID), approval, result.Approval)
+	})
+
+	return result, err
+}
+
+// RejectTransferTx cancels a pending transfer on behalf of a reviewer and releases the hold on its funds.
+func (store *SQLStore) RejectTransferTx(ctx context.Context, arg ReviewTransferTxParams) (TransferApprovalTxResult, error) {
+	var result TransferApprovalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		approval, err := reviewableTransferApproval(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		result, err = cancelPendingTransfer(ctx, q, approval, TransferApprovalRejected, arg.ReviewedBy)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer_approval.reject", AuditTransferApproval, auditID(approval.TransferID), approval, result.Approval)
+	})
+
+	return result, err
+}
+
+// ExpireTransferApprovalTx cancels one pending transfer whose approval expired at or before now.
+// The row is claimed with FOR UPDATE SKIP LOCKED, so several expiry workers can run at the same time.
+// It returns sql.ErrNoRows when nothing has expired.
+func (store *SQLStore) ExpireTransferApprovalTx(ctx context.Context, now time.Time) (TransferApprovalTxResult, error) {
+	var result TransferApprovalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		approval, err := q.GetExpiredTransferApprovalForUpdate(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		result, err = cancelPendingTransfer(ctx, q, approval, TransferApprovalExpired, "")
+		return err
+	})
+
+	return result, err
+}
+
+// reviewableTransferApproval locks the approval request of a transfer and checks that the reviewer may act on it.
+func reviewableTransferApproval(ctx context.Context, q *Queries, arg ReviewTransferTxParams) (TransferApproval, error) {
+	approval, err := q.GetTransferApprovalForUpdate(ctx, arg.TransferID)
+	if err != nil {
+		return approval, err
+	}
+
+	if approval.Status != TransferApprovalPending {
+		return approval, ErrApprovalNotPending
+	}
+	if !arg.Now.Before(approval.ExpiresAt) {
+		return approval, ErrApprovalExpired
+	}
+	if approval.RequestedBy == arg.ReviewedBy {
+		return approval, ErrSelfApproval
+	}
+
+	return approval, nil
+}
+
+// cancelPendingTransfer cancels the transfer of a locked approval request, releases its hold
+// and closes the request with the given status. An empty reviewer is stored as NULL.
+func cancelPendingTransfer(ctx context.Context, q *Queries, approval TransferApproval, status string, reviewedBy string) (TransferApprovalTxResult, error) {
+	var result TransferApprovalTxResult
+	var err error
+
+	result.Transfer, err = transitionTransfer(ctx, q, approval.TransferID, TransferStatusPendingApproval, TransferStatusCancelled)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     result.Transfer.FromAccountID,
+		Amount: -result.Transfer.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Approval, err = q.UpdateTransferApproval(ctx, UpdateTransferApprovalParams{
+		Status:     status,
+		ReviewedBy: sql.NullString{String: reviewedBy, Valid: reviewedBy != ""},
+		TransferID: approval.TransferID,
+	})
+	return result, err
+}
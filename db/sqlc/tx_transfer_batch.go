@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+)
+
+// Execution modes of a transfer batch
+const (
+	TransferBatchAtomic     = "atomic"      // every transfer goes through, or none does
+	TransferBatchBestEffort = "best_effort" // every transfer is attempted on its own
+)
+
+// Statuses of a transfer batch
+const (
+	TransferBatchProcessing         = "processing"
+	TransferBatchCompleted          = "completed"
+	TransferBatchPartiallyCompleted = "partially_completed"
+	TransferBatchFailed             = "failed"
+)
+
+// Outcomes of a single transfer batch item
+const (
+	TransferBatchItemSucceeded = "succeeded"
+	TransferBatchItemFailed    = "failed"
+)
+
+// ErrBatchRolledBack is recorded on the items of an atomic batch that were undone because another item failed.
+var ErrBatchRolledBack = errors.New("batch rolled back")
+
+// BatchTransferTxParams contains the input parameters of BatchTransferTx.
+type BatchTransferTxParams struct {
+	RequestedBy string             `json:"requested_by"`
+	Mode        string             `json:"mode"`
+	Items       []TransferTxParams `json:"items"`
+}
+
+// BatchTransferTxResult contains the recorded batch and the outcome of each of its items, in submission order.
+type BatchTransferTxResult struct {
+	Batch TransferBatch       `json:"batch"`
+	Items []TransferBatchItem `json:"items"`
+}
+
+// BatchTransferTx executes a list of transfers and records them as a batch.
+//
+// In atomic mode every transfer runs in a single transaction, with the senders locked up front in username
+// order and then all the accounts in ascending ID order. If one transfer fails they are all rolled back, and the batch is recorded as failed.
+// In best-effort mode every transfer runs in its own transaction, so failures do not affect the others.
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	if arg.Mode == TransferBatchAtomic {
+		return store.atomicBatchTransferTx(ctx, arg)
+	}
+	return store.bestEffortBatchTransferTx(ctx, arg)
+}
+
+// atomicBatchTransferTx executes an all-or-nothing batch.
+func (store *SQLStore) atomicBatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		batch, err := q.CreateTransferBatch(ctx, CreateTransferBatchParams{
+			RequestedBy: arg.RequestedBy,
+			Mode:        arg.Mode,
+			ItemCount:   int32(len(arg.Items)),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := lockBatchSenders(ctx, q, arg.Items); err != nil {
+			return err
+		}
+		accountIDs := make([]int64, 0, 2*len(arg.Items))
+		for _, item := range arg.Items {
+			accountIDs = append(accountIDs, item.FromAccountID, item.ToAccountID)
+		}
+		if err := lockAccounts(ctx, q, accountIDs...); err != nil {
+			return err
+		}
+
+		// The transfers run inside a savepoint, so that a failed batch can still be recorded
+		if _, err = q.db.ExecContext(ctx, "SAVEPOINT transfer_batch"); err != nil {
+			return err
+		}
+
+		transfers := make([]TransferTxResult, len(arg.Items))
+		failedAt := -1
+		var transferErr error
+		for i, item := range arg.Items {
+			transfers[i], transferErr = feeTransferTx(ctx, q, item)
+			if transferErr == nil {
+				transferErr = recordAudit(ctx, q, "transfer.create", AuditTransfer, auditID(transfers[i].Transfer.ID), nil, transfers[i].Transfer)
+			}
+			if transferErr != nil {
+				failedAt = i
+				break
+			}
+		}
+
+		if transferErr != nil {
+			if _, err = q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT transfer_batch"); err != nil {
+				return err
+			}
+		}
+
+		for i, item := range arg.Items {
+			itemArg := CreateTransferBatchItemParams{
+				BatchID:       batch.ID,
+				Position:      int32(i),
+				FromAccountID: item.FromAccountID,
+				ToAccountID:   item.ToAccountID,
+				Amount:        item.Amount,
+				Status:        TransferBatchItemSucceeded,
+				TransferID:    sql.NullInt64{Int64: transfers[i].Transfer.ID, Valid: true},
+			}
+			if transferErr != nil {
+				itemArg.Status = TransferBatchItemFailed
+				itemArg.TransferID = sql.NullInt64{}
+				itemArg.Error = ErrBatchRolledBack.Error()
+				if i == failedAt {
+					itemArg.Error = transferErr.Error()
+				}
+			}
+
+			batchItem, err := q.CreateTransferBatchItem(ctx, itemArg)
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, batchItem)
+		}
+
+		completeArg := CompleteTransferBatchParams{
+			Status:         TransferBatchCompleted,
+			SucceededCount: int32(len(arg.Items)),
+			ID:             batch.ID,
+		}
+		if transferErr != nil {
+			completeArg.Status = TransferBatchFailed
+			completeArg.SucceededCount = 0
+			completeArg.FailedCount = int32(len(arg.Items))
+		}
+
+		result.Batch, err = q.CompleteTransferBatch(ctx, completeArg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer_batch.create", AuditTransferBatch, auditID(batch.ID), nil, result)
+	})
+
+	return result, err
+}
+
+// lockBatchSenders locks the owners of the accounts an atomic batch sends from, in username order. The daily
+// limit check of every item locks its sender, and like the other transactions that lock a user and their
+// accounts, the batch has to lock them before any account.
+func lockBatchSenders(ctx context.Context, q *Queries, items []TransferTxParams) error {
+	owners := make([]string, 0, len(items))
+	for _, item := range items {
+		account, err := q.GetAccount(ctx, item.FromAccountID)
+		if err != nil {
+			return err
+		}
+		owners = append(owners, account.Owner)
+	}
+	sort.Strings(owners)
+
+	for i, owner := range owners {
+		if i > 0 && owner == owners[i-1] {
+			continue
+		}
+		if _, err := q.GetUserForUpdate(ctx, owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bestEffortBatchTransferTx executes every item of a batch in its own transaction.
+// If the process stops half way, the batch stays in the processing status with the items executed so far.
+func (store *SQLStore) bestEffortBatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	batch, err := store.CreateTransferBatch(ctx, CreateTransferBatchParams{
+		RequestedBy: arg.RequestedBy,
+		Mode:        arg.Mode,
+		ItemCount:   int32(len(arg.Items)),
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Batch = batch
+
+	var succeeded, failed int32
+	for i, item := range arg.Items {
+		itemArg := CreateTransferBatchItemParams{
+			BatchID:       batch.ID,
+			Position:      int32(i),
+			FromAccountID: item.FromAccountID,
+			ToAccountID:   item.ToAccountID,
+			Amount:        item.Amount,
+			Status:        TransferBatchItemSucceeded,
+		}
+
+		var batchItem TransferBatchItem
+		transferErr := store.execTx(ctx, func(q *Queries) error {
+			transfer, err := feeTransferTx(ctx, q, item)
+			if err != nil {
+				return err
+			}
+			err = recordAudit(ctx, q, "transfer.create", AuditTransfer, auditID(transfer.Transfer.ID), nil, transfer.Transfer)
+			if err != nil {
+				return err
+			}
+
+			itemArg.TransferID = sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}
+			batchItem, err = q.CreateTransferBatchItem(ctx, itemArg)
+			return err
+		})
+
+		if transferErr != nil {
+			itemArg.Status = TransferBatchItemFailed
+			itemArg.TransferID = sql.NullInt64{}
+			itemArg.Error = transferErr.Error()
+
+			batchItem, err = store.CreateTransferBatchItem(ctx, itemArg)
+			if err != nil {
+				return result, err
+			}
+			failed++
+		} else {
+			succeeded++
+		}
+		result.Items = append(result.Items, batchItem)
+	}
+
+	completeArg := CompleteTransferBatchParams{
+		Status:         TransferBatchCompleted,
+		SucceededCount: succeeded,
+		FailedCount:    failed,
+		ID:             batch.ID,
+	}
+	switch {
+	case succeeded == 0 && failed > 0:
+		completeArg.Status = TransferBatchFailed
+	case failed > 0:
+		completeArg.Status = TransferBatchPartiallyCompleted
+	}
+
+	// Each transfer was audited with its own transaction; the batch is audited once it is complete
+	err = store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Batch, err = q.CompleteTransferBatch(ctx, completeArg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "transfer_batch.create", AuditTransferBatch, auditID(batch.ID), nil, result)
+	})
+	return result, err
+}
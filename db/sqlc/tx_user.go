@@ -0,0 +1,354 @@
+package db
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Errors returned by the user transactions
+var (
+	ErrAdminExists        = errors.New("an admin already exists") // the bank has an admin, who assigns roles from then on
+	ErrResetTokenInvalid  = errors.New("password reset token is invalid or was already used")
+	ErrResetTokenExpired  = errors.New("password reset token has expired")
+	ErrVerifyEmailInvalid = errors.New("email verification link is invalid or was already used")
+	ErrVerifyEmailExpired = errors.New("email verification link has expired")
+	ErrUserDeleted        = errors.New("user is deactivated")
+	ErrEmailTaken         = errors.New("email is already in use")
+)
+
+// BootstrapAdminTxResult contains the new admin and whether the user had to be created.
+type BootstrapAdminTxResult struct {
+	User    User `json:"user"`
+	Created bool `json:"created"`
+}
+
+// BootstrapAdminTx makes the first admin of a new installation. An existing user is promoted,
+// otherwise the user is created with arg. Without a hashed password in arg the user must exist,
+// or sql.ErrNoRows is returned. It returns ErrAdminExists when there is an admin already.
+// The admin role is locked while admins are counted, so concurrent runs cannot both make an admin.
+func (store *SQLStore) BootstrapAdminTx(ctx context.Context, arg CreateUserParams) (BootstrapAdminTxResult, error) {
+	var result BootstrapAdminTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if _, err := q.GetRoleForUpdate(ctx, util.AdminRole); err != nil {
+			return err
+		}
+		admins, err := q.CountUsersByRole(ctx, util.AdminRole)
+		if err != nil {
+			return err
+		}
+		if admins > 0 {
+			return ErrAdminExists
+		}
+
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		switch {
+		case err == sql.ErrNoRows && arg.HashedPassword != "":
+			before, err = store.createUser(ctx, q, arg)
+			if err != nil {
+				return err
+			}
+			result.Created = true
+			if err := recordAudit(ctx, q, "user.create", AuditUser, before.Username, nil, newAuditUser(before)); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		}
+
+		result.User, err = q.UpdateUserRole(ctx, UpdateUserRoleParams{Role: util.AdminRole, Username: arg.Username})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.update_role", AuditUser, result.User.Username, newAuditUser(before), newAuditUser(result.User))
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.User, err = store.openUser(ctx, result.User)
+	return result, err
+}
+
+// ResetPasswordTxParams contains the input parameters of ResetPasswordTx.
+type ResetPasswordTxParams struct {
+	TokenHash      string    `json:"token_hash"`
+	HashedPassword string    `json:"-"`
+	Now            time.Time `json:"now"`
+}
+
+// ResetPasswordTx sets a new password with a password reset token. The token is single-use: it and every
+// other outstanding token of the user are used up, whether the reset succeeds or the token has expired.
+// It returns ErrResetTokenInvalid for an unknown or used token and ErrResetTokenExpired for an expired one.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+	var expired bool
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		token, err := q.GetPasswordResetTokenForUpdate(ctx, arg.TokenHash)
+		if err == sql.ErrNoRows || (err == nil && token.UsedAt.Valid) {
+			return ErrResetTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		err = q.UsePasswordResetTokens(ctx, UsePasswordResetTokensParams{
+			UsedAt:   sql.NullTime{Time: arg.Now, Valid: true},
+			Username: token.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		// An expired token is used up all the same, so the transaction commits without changing the password
+		if !arg.Now.Before(token.ExpiresAt) {
+			expired = true
+			return nil
+		}
+
+		before, err := q.GetUserForUpdate(ctx, token.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangedAt: arg.Now,
+			Username:          token.Username,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.reset_password", AuditUser, user.Username, newAuditUser(before), newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+	if expired {
+		return user, ErrResetTokenExpired
+	}
+
+	return store.openUser(ctx, user)
+}
+
+// VerifyEmailTxParams contains the input parameters of VerifyEmailTx.
+type VerifyEmailTxParams struct {
+	ID             int64     `json:"id"`
+	SecretCodeHash string    `json:"-"`
+	Now            time.Time `json:"now"`
+}
+
+// VerifyEmailTx uses a verification link and marks the email it was sent to as verified, if the user
+// still has it. It returns ErrVerifyEmailInvalid for an unknown, used or mismatched link and
+// ErrVerifyEmailExpired for an expired one.
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		verifyEmail, err := q.GetVerifyEmailForUpdate(ctx, arg.ID)
+		if err == sql.ErrNoRows {
+			return ErrVerifyEmailInvalid
+		}
+		if err != nil {
+			return err
+		}
+		if verifyEmail.UsedAt.Valid || subtle.ConstantTimeCompare([]byte(verifyEmail.SecretCodeHash), []byte(arg.SecretCodeHash)) != 1 {
+			return ErrVerifyEmailInvalid
+		}
+		if !arg.Now.Before(verifyEmail.ExpiresAt) {
+			return ErrVerifyEmailExpired
+		}
+
+		_, err = q.UseVerifyEmail(ctx, UseVerifyEmailParams{
+			UsedAt: sql.NullTime{Time: arg.Now, Valid: true},
+			ID:     verifyEmail.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		before, err := q.GetUserForUpdate(ctx, verifyEmail.Username)
+		if err != nil {
+			return err
+		}
+		current, err := store.openUser(ctx, before)
+		if err != nil {
+			return err
+		}
+		if !verifyEmailMatches(store.pii, verifyEmail, current.Email) {
+			// The user has changed their email since the link was sent
+			return ErrVerifyEmailInvalid
+		}
+		user, err = q.SetUserEmailVerified(ctx, SetUserEmailVerifiedParams{
+			Username:   before.Username,
+			EmailIndex: before.EmailIndex,
+			Email:      before.Email,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.verify_email", AuditUser, user.Username, newAuditUser(before), newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return store.openUser(ctx, user)
+}
+
+// DeleteUserTxParams contains the input parameters of DeleteUserTx.
+type DeleteUserTxParams struct {
+	Username string    `json:"username"`
+	Now      time.Time `json:"now"`
+}
+
+// DeleteUserTxResult contains the deactivated user, the accounts that were closed, the accounts that were
+// left open, and the scheduled transfers, transfer approval requests and holds that were cancelled.
+type DeleteUserTxResult struct {
+	User           User      `json:"user"`
+	ClosedAccounts []Account `json:"closed_accounts"`
+	// OpenAccounts hold funds, or cannot be closed yet, and are left for the bank to pay out
+	OpenAccounts                []Account           `json:"open_accounts"`
+	CancelledScheduledTransfers []ScheduledTransfer `json:"cancelled_scheduled_transfers"`
+	CancelledTransferApprovals  []TransferApproval  `json:"cancelled_transfer_approvals"`
+	ReleasedHolds               []Hold              `json:"released_holds"`
+}
+
+// DeleteUserTx deactivates a user at their request. The user is soft-deleted: their row, accounts and
+// history stay for regulatory retention, but they can no longer log in. Their scheduled transfers, the
+// transfers from their accounts still awaiting approval and the holds on their accounts are cancelled, so
+// nothing they asked for is paid out later, and their API keys revoked. Their zero-balance accounts are
+// then closed. Accounts that hold funds, have open transfers, or are frozen stay open, so that no money
+// is lost with the user.
+//
+// Like the reviews of approvals and the captures of holds, it locks those before the accounts.
+func (store *SQLStore) DeleteUserTx(ctx context.Context, arg DeleteUserTxParams) (DeleteUserTxResult, error) {
+	var result DeleteUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt.Valid {
+			return ErrUserDeleted
+		}
+
+		approvals, err := q.ListPendingTransferApprovalsByOwnerForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		holds, err := q.ListActiveHoldsByOwnerForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		accountIDs, err := q.ListOpenAccountIDsByOwner(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if err := lockAccounts(ctx, q, accountIDs...); err != nil {
+			return err
+		}
+
+		result.CancelledTransferApprovals = make([]TransferApproval, 0, len(approvals))
+		for _, approval := range approvals {
+			cancelled, err := cancelPendingTransfer(ctx, q, approval, TransferApprovalCancelled, "")
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, q, "transfer_approval.cancel", AuditTransferApproval, auditID(approval.TransferID), approval, cancelled.Approval); err != nil {
+				return err
+			}
+			result.CancelledTransferApprovals = append(result.CancelledTransferApprovals, cancelled.Approval)
+		}
+
+		result.ReleasedHolds = make([]Hold, 0, len(holds))
+		for _, hold := range holds {
+			released, err := endHold(ctx, q, hold, HoldReleased)
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, q, "hold.release", AuditHold, auditID(hold.ID), hold, released.Hold); err != nil {
+				return err
+			}
+			result.ReleasedHolds = append(result.ReleasedHolds, released.Hold)
+		}
+
+		result.ClosedAccounts = make([]Account, 0, len(accountIDs))
+		result.OpenAccounts = []Account{}
+		for _, id := range accountIDs {
+			account, err := q.GetAccount(ctx, id)
+			if err != nil {
+				return err
+			}
+			if account.Balance != 0 || account.HeldBalance != 0 || checkDebit(account) != nil {
+				result.OpenAccounts = append(result.OpenAccounts, account)
+				continue
+			}
+			openTransfers, err := q.CountOpenTransfers(ctx, account.ID)
+			if err != nil {
+				return err
+			}
+			if openTransfers > 0 {
+				result.OpenAccounts = append(result.OpenAccounts, account)
+				continue
+			}
+
+			closed, err := q.CloseAccount(ctx, account.ID)
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, q, "account.close", AuditAccount, auditID(account.ID), account, closed); err != nil {
+				return err
+			}
+			result.ClosedAccounts = append(result.ClosedAccounts, closed)
+		}
+
+		scheduledTransfers, err := q.ListActiveScheduledTransfersByOwnerForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		result.CancelledScheduledTransfers = make([]ScheduledTransfer, 0, len(scheduledTransfers))
+		for _, scheduled := range scheduledTransfers {
+			cancelled, err := q.UpdateScheduledTransfer(ctx, UpdateScheduledTransferParams{
+				Status: sql.NullString{String: ScheduledTransferCancelled, Valid: true},
+				ID:     scheduled.ID,
+			})
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, q, "scheduled_transfer.update", AuditScheduledTransfer, auditID(scheduled.ID), scheduled, cancelled); err != nil {
+				return err
+			}
+			result.CancelledScheduledTransfers = append(result.CancelledScheduledTransfers, cancelled)
+		}
+
+		err = q.RevokeAPIKeysByOwner(ctx, RevokeAPIKeysByOwnerParams{
+			RevokedAt: sql.NullTime{Time: arg.Now, Valid: true},
+			Owner:     arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.DeleteUser(ctx, DeleteUserParams{
+			DeletedAt: sql.NullTime{Time: arg.Now, Valid: true},
+			Username:  arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, "user.delete", AuditUser, result.User.Username, newAuditUser(before), newAuditUser(result.User))
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.User, err = store.openUser(ctx, result.User)
+	return result, err
+}
@@ -0,0 +1,347 @@
+package db
+
+import (
+	"archive/zip"
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// userDataPageSize is the number of rows read at a time while exporting a user's data.
+const userDataPageSize = 1000
+
+// ErasedFullName replaces the full name of erased users.
+const ErasedFullName = "erased user"
+
+// Errors returned by the user data transactions
+var (
+	ErrUserNotDeleted = errors.New("user must be deactivated before their data is erased") // their accounts are still open
+	ErrUserErased     = errors.New("user data was already erased")
+)
+
+// UserProfile is the exported profile of a user, leaving out the password hash.
+type UserProfile struct {
+	Username          string       `json:"username"`
+	FullName          string       `json:"full_name"`
+	Email             string       `json:"email"`
+	Role              string       `json:"role"`
+	IsEmailVerified   bool         `json:"is_email_verified"`
+	KYCStatus         string       `json:"kyc_status"`
+	KYCTier           string       `json:"kyc_tier"`
+	PasswordChangedAt time.Time    `json:"password_changed_at"`
+	DeletedAt         sql.NullTime `json:"deleted_at"`
+	ErasedAt          sql.NullTime `json:"erased_at"`
+	CreatedAt         time.Time    `json:"created_at"`
+}
+
+func newUserProfile(user User) UserProfile {
+	return UserProfile{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		KYCStatus:         user.KycStatus,
+		KYCTier:           user.KycTier,
+		PasswordChangedAt: user.PasswordChangedAt,
+		DeletedAt:         user.DeletedAt,
+		ErasedAt:          user.ErasedAt,
+		CreatedAt:         user.CreatedAt,
+	}
+}
+
+// UserDataExport is everything the bank holds about a user, for a subject access request.
+// Sessions are the user's logins, as access tokens themselves are not stored. KYC documents are
+// listed without the uploaded files, which are in the blob store.
+type UserDataExport struct {
+	ExportedAt   time.Time      `json:"exported_at"`
+	Profile      UserProfile    `json:"profile"`
+	KYCProfile   *KycProfile    `json:"kyc_profile"`
+	KYCDocuments []KycDocument  `json:"kyc_documents"`
+	Accounts     []Account      `json:"accounts"`
+	Entries      []Entry        `json:"entries"`
+	Transfers    []Transfer     `json:"transfers"`
+	Sessions     []LoginAttempt `json:"sessions"`
+	AuditEvents  []AuditEvent   `json:"audit_events"`
+}
+
+// userDataExportSummary is the audited record of an export, which does not copy the data itself.
+type userDataExportSummary struct {
+	KYCDocuments int `json:"kyc_documents"`
+	Accounts     int `json:"accounts"`
+	Entries      int `json:"entries"`
+	Transfers    int `json:"transfers"`
+	Sessions     int `json:"sessions"`
+	AuditEvents  int `json:"audit_events"`
+}
+
+// WriteZip writes the export as a ZIP archive with one JSON file per part.
+func (export UserDataExport) WriteZip(w io.Writer) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"kyc.json", struct {
+			Profile   *KycProfile   `json:"profile"`
+			Documents []KycDocument `json:"documents"`
+		}{export.KYCProfile, export.KYCDocuments}},
+		{"accounts.json", export.Accounts},
+		{"entries.json", export.Entries},
+		{"transfers.json", export.Transfers},
+		{"sessions.json", export.Sessions},
+		{"audit_events.json", export.AuditEvents},
+	}
+
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return fmt.Errorf("%s: %w", file.name, err)
+		}
+	}
+	return archive.Close()
+}
+
+// listAll reads every page of a query paged by limit and offset.
+func listAll[T any](list func(limit, offset int32) ([]T, error)) ([]T, error) {
+	all := []T{}
+	for offset := int32(0); ; offset += userDataPageSize {
+		page, err := list(userDataPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < userDataPageSize {
+			return all, nil
+		}
+	}
+}
+
+// listAllAuditEvents reads every audit event matching arg, whose AfterID and Limit are set here.
+func listAllAuditEvents(ctx context.Context, q *Queries, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	all := []AuditEvent{}
+	arg.Limit = userDataPageSize
+	for {
+		events, err := q.ListAuditEvents(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, events...)
+		if len(events) < userDataPageSize {
+			return all, nil
+		}
+		arg.AfterID = events[len(events)-1].ID
+	}
+}
+
+// ExportUserDataTx collects a user's profile, KYC profile and documents, accounts, their entries and transfers, the user's logins
+// and the audit events made by the user or about them or their accounts. It reads from one repeatable read
+// snapshot, so the parts are consistent with each other, and records the export in the audit log.
+func (store *SQLStore) ExportUserDataTx(ctx context.Context, username string) (UserDataExport, error) {
+	export := UserDataExport{ExportedAt: time.Now()}
+
+	// Under read committed every query sees the transfers committed since the last one, so an export
+	// taken during a transfer could hold its entry without its transfer or the balance before it
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead}
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		user, err := q.GetUser(ctx, username)
+		if err != nil {
+			return err
+		}
+		user, err = store.openUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		export.Profile = newUserProfile(user)
+
+		profile, err := q.GetKYCProfile(ctx, username)
+		switch {
+		case err == nil:
+			export.KYCProfile = &profile
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+		export.KYCDocuments, err = q.ListKYCDocuments(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		export.Accounts, err = listAll(func(limit, offset int32) ([]Account, error) {
+			return q.ListAccounts(ctx, ListAccountsParams{
+				Owner:  sql.NullString{String: username, Valid: true},
+				Limit:  limit,
+				Offset: offset,
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		export.Entries = []Entry{}
+		export.Transfers = []Transfer{}
+		seenTransfers := make(map[int64]bool)
+		for _, account := range export.Accounts {
+			entries, err := listAll(func(limit, offset int32) ([]Entry, error) {
+				return q.ListEntries(ctx, ListEntriesParams{AccountID: account.ID, Limit: limit, Offset: offset})
+			})
+			if err != nil {
+				return err
+			}
+			export.Entries = append(export.Entries, entries...)
+
+			transfers, err := listAll(func(limit, offset int32) ([]Transfer, error) {
+				return q.ListTransfers(ctx, ListTransfersParams{
+					FromAccountID: account.ID,
+					ToAccountID:   account.ID,
+					Limit:         limit,
+					Offset:        offset,
+				})
+			})
+			if err != nil {
+				return err
+			}
+			// A transfer between two of the user's accounts is listed for both
+			for _, transfer := range transfers {
+				if !seenTransfers[transfer.ID] {
+					seenTransfers[transfer.ID] = true
+					export.Transfers = append(export.Transfers, transfer)
+				}
+			}
+		}
+		slices.SortFunc(export.Transfers, func(a, b Transfer) int { return cmp.Compare(a.ID, b.ID) })
+
+		export.Sessions, err = listAll(func(limit, offset int32) ([]LoginAttempt, error) {
+			return q.ListLoginAttempts(ctx, ListLoginAttemptsParams{Username: username, Limit: limit, Offset: offset})
+		})
+		if err != nil {
+			return err
+		}
+
+		filters := []ListAuditEventsParams{
+			{Actor: sql.NullString{String: username, Valid: true}},
+			{
+				ResourceType: sql.NullString{String: AuditUser, Valid: true},
+				ResourceID:   sql.NullString{String: username, Valid: true},
+			},
+		}
+		for _, account := range export.Accounts {
+			filters = append(filters, ListAuditEventsParams{
+				ResourceType: sql.NullString{String: AuditAccount, Valid: true},
+				ResourceID:   sql.NullString{String: auditID(account.ID), Valid: true},
+			})
+		}
+		export.AuditEvents = []AuditEvent{}
+		seenEvents := make(map[int64]bool)
+		for _, filter := range filters {
+			events, err := listAllAuditEvents(ctx, q, filter)
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				if !seenEvents[event.ID] {
+					seenEvents[event.ID] = true
+					export.AuditEvents = append(export.AuditEvents, event)
+				}
+			}
+		}
+		slices.SortFunc(export.AuditEvents, func(a, b AuditEvent) int { return cmp.Compare(a.ID, b.ID) })
+
+		return recordAudit(ctx, q, "user.export", AuditUser, username, nil, userDataExportSummary{
+			KYCDocuments: len(export.KYCDocuments),
+			Accounts:     len(export.Accounts),
+			Entries:      len(export.Entries),
+			Transfers:    len(export.Transfers),
+			Sessions:     len(export.Sessions),
+			AuditEvents:  len(export.AuditEvents),
+		})
+	})
+
+	return export, err
+}
+
+// EraseUserTxParams contains the input parameters of EraseUserTx.
+type EraseUserTxParams struct {
+	Username string    `json:"username"`
+	Now      time.Time `json:"now"`
+}
+
+// EraseUserTx pseudonymizes the personal data of a deactivated user: their full name and email are
+// replaced, their password, two-factor secrets, verification and reset tokens and login history removed.
+// The username stays as the key of their accounts, entries and transfers, which are legally retained.
+// The audit log keeps its events, but their snapshots of the user get the same placeholders, as they may
+// hold the name and email in plaintext or sealed with a key that is not destroyed.
+// The user's KYC profile and documents stay as well, as anti-money laundering rules require the bank to keep them.
+func (store *SQLStore) EraseUserTx(ctx context.Context, arg EraseUserTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		if !before.DeletedAt.Valid {
+			return ErrUserNotDeleted
+		}
+		if before.ErasedAt.Valid {
+			return ErrUserErased
+		}
+
+		for _, deleteRows := range []func(context.Context, string) error{
+			q.DeleteUserTOTP,
+			q.DeleteTOTPRecoveryCodes,
+			q.DeleteVerifyEmails,
+			q.DeletePasswordResetTokens,
+			q.DeleteLoginAttempts,
+		} {
+			if err := deleteRows(ctx, arg.Username); err != nil {
+				return err
+			}
+		}
+
+		// Emails are unique, and .invalid addresses can never receive mail
+		erasedEmail := arg.Username + "@erased.invalid"
+		err = q.RedactUserAuditEvents(ctx, RedactUserAuditEventsParams{
+			FullName: ErasedFullName,
+			Email:    erasedEmail,
+			Username: arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		sealed, err := store.sealUser(ctx, arg.Username, ErasedFullName, erasedEmail)
+		if err != nil {
+			return err
+		}
+		user, err = q.EraseUser(ctx, EraseUserParams{
+			FullName:   sealed.FullName,
+			Email:      sealed.Email,
+			EmailIndex: sealed.EmailIndex,
+			DataKeyID:  sealed.DataKeyID,
+			ErasedAt:   sql.NullTime{Time: arg.Now, Valid: true},
+			Username:   arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+		// A before snapshot would copy the personal data being erased into the audit log
+		return recordAudit(ctx, q, "user.erase", AuditUser, user.Username, nil, newAuditUser(user))
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return store.openUser(ctx, user)
+}
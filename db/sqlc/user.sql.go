@@ -7,32 +7,244 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
-const createUser = `-- name: CreateUser :one
+const countOtherUsersWithEmail = `-- name: CountOtherUsersWithEmail :one
+SELECT count(*) FROM users
+WHERE username <> $1
+  AND (email_index = $2 OR (email_index IS NULL AND email = $3))
+`
+
+type CountOtherUsersWithEmailParams struct {
+	Username   string         `json:"username"`
+	EmailIndex sql.NullString `json:"email_index"`
+	Email      string         `json:"email"`
+}
+
+// Counts the other users with an email, by its blind index or by the email itself while it is in plaintext.
+// Both are checked, as users are sealed one by one while the bank is running.
+func (q *Queries) CountOtherUsersWithEmail(ctx context.Context, arg CountOtherUsersWithEmailParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOtherUsersWithEmail, arg.Username, arg.EmailIndex, arg.Email)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteUser = `-- name: DeleteUser :one
+UPDATE users
+SET deleted_at = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
+`
+
+type DeleteUserParams struct {
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	Username  string       `json:"username"`
+}
+
+// Soft-deletes a user, who keeps their row for retention.
+func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, deleteUser, arg.DeletedAt, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const eraseUser = `-- name: EraseUser :one
+UPDATE users
+SET full_name = $1,
+  email = $2,
+  email_index = $3,
+  data_key_id = $4,
+  hashed_password = '',
+  is_email_verified = false,
+  erased_at = $5
+WHERE username = $6
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
+`
+
+type EraseUserParams struct {
+	FullName   string         `json:"full_name"`
+	Email      string         `json:"email"`
+	EmailIndex sql.NullString `json:"email_index"`
+	DataKeyID  sql.NullInt64  `json:"data_key_id"`
+	ErasedAt   sql.NullTime   `json:"erased_at"`
+	Username   string         `json:"username"`
+}
+
+// Pseudonymizes the personal data of a user. The username stays, as the key of their financial records.
+func (q *Queries) EraseUser(ctx context.Context, arg EraseUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, eraseUser,
+		arg.FullName,
+		arg.Email,
+		arg.EmailIndex,
+		arg.DataKeyID,
+		arg.ErasedAt,
+		arg.Username,
+	)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier FROM users
+WHERE username = $1 LIMIT 1
+`
+
+func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const getUserByEmailIndex = `-- name: GetUserByEmailIndex :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier FROM users
+WHERE email_index = $1
+  OR (email_index IS NULL AND email = $2)
+LIMIT 1
+`
+
+type GetUserByEmailIndexParams struct {
+	EmailIndex sql.NullString `json:"email_index"`
+	Email      string         `json:"email"`
+}
+
+// Finds a user by the blind index of their email, or by the email itself while it is in plaintext.
+func (q *Queries) GetUserByEmailIndex(ctx context.Context, arg GetUserByEmailIndexParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmailIndex, arg.EmailIndex, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier FROM users
+WHERE username = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserForUpdate, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const insertUser = `-- name: InsertUser :one
 INSERT INTO users (
   username,
   hashed_password,
   full_name,
-  email
+  email,
+  email_index,
+  data_key_id
 ) VALUES (
-  $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+  $1, $2, $3, $4, $5, $6
+) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
 `
 
-type CreateUserParams struct {
-	Username       string `json:"username"`
-	HashedPassword string `json:"hashed_password"`
-	FullName       string `json:"full_name"`
-	Email          string `json:"email"`
+type InsertUserParams struct {
+	Username       string         `json:"username"`
+	HashedPassword string         `json:"hashed_password"`
+	FullName       string         `json:"full_name"`
+	Email          string         `json:"email"`
+	EmailIndex     sql.NullString `json:"email_index"`
+	DataKeyID      sql.NullInt64  `json:"data_key_id"`
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser,
+// Creates a user whose full name and email are already sealed by the store, see SQLStore.CreateUser.
+func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, insertUser,
 		arg.Username,
 		arg.HashedPassword,
 		arg.FullName,
 		arg.Email,
+		arg.EmailIndex,
+		arg.DataKeyID,
 	)
 	var i User
 	err := row.Scan(
@@ -42,17 +254,288 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
 	)
 	return i, err
 }
 
-const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users
-WHERE username = $1 LIMIT 1
+const listUsersToReencrypt = `-- name: ListUsersToReencrypt :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier FROM users
+WHERE data_key_id IS DISTINCT FROM $1::bigint
+ORDER BY username
+LIMIT $2
+FOR NO KEY UPDATE SKIP LOCKED
 `
 
-func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUser, username)
+type ListUsersToReencryptParams struct {
+	ActiveDataKeyID int64 `json:"active_data_key_id"`
+	Limit           int32 `json:"limit"`
+}
+
+// Locks a batch of users that are in plaintext or sealed with another data key than the active one.
+// Users locked by other transactions are skipped, and picked up by a later batch.
+func (q *Queries) ListUsersToReencrypt(ctx context.Context, arg ListUsersToReencryptParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersToReencrypt, arg.ActiveDataKeyID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.IsEmailVerified,
+			&i.LoginUnlockedAt,
+			&i.DeletedAt,
+			&i.ErasedAt,
+			&i.EmailIndex,
+			&i.DataKeyID,
+			&i.KycStatus,
+			&i.KycTier,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :one
+UPDATE users
+SET hashed_password = $1
+WHERE username = $2
+  AND hashed_password = $3
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
+`
+
+type RehashUserPasswordParams struct {
+	NewHashedPassword string `json:"new_hashed_password"`
+	Username          string `json:"username"`
+	OldHashedPassword string `json:"old_hashed_password"`
+}
+
+// Replaces a password hash with a new hash of the same password, unless the password changed in the meantime.
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, rehashUserPassword, arg.NewHashedPassword, arg.Username, arg.OldHashedPassword)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const setUserEmailVerified = `-- name: SetUserEmailVerified :one
+UPDATE users
+SET is_email_verified = true
+WHERE username = $1
+  AND (email_index = $2 OR (email_index IS NULL AND email = $3))
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
+`
+
+type SetUserEmailVerifiedParams struct {
+	Username   string         `json:"username"`
+	EmailIndex sql.NullString `json:"email_index"`
+	Email      string         `json:"email"`
+}
+
+func (q *Queries) SetUserEmailVerified(ctx context.Context, arg SetUserEmailVerifiedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserEmailVerified, arg.Username, arg.EmailIndex, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const setUserKYC = `-- name: SetUserKYC :one
+UPDATE users
+SET kyc_status = $1,
+  kyc_tier = COALESCE($2::varchar, kyc_tier)
+WHERE username = $3
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
+`
+
+type SetUserKYCParams struct {
+	KycStatus string         `json:"kyc_status"`
+	KycTier   sql.NullString `json:"kyc_tier"`
+	Username  string         `json:"username"`
+}
+
+// Sets the KYC status of a user and, when given, their tier.
+func (q *Queries) SetUserKYC(ctx context.Context, arg SetUserKYCParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserKYC, arg.KycStatus, arg.KycTier, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const unlockUserLogin = `-- name: UnlockUserLogin :one
+UPDATE users
+SET login_unlocked_at = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
+`
+
+type UnlockUserLoginParams struct {
+	LoginUnlockedAt time.Time `json:"login_unlocked_at"`
+	Username        string    `json:"username"`
+}
+
+func (q *Queries) UnlockUserLogin(ctx context.Context, arg UnlockUserLoginParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, unlockUserLogin, arg.LoginUnlockedAt, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const updateUserPII = `-- name: UpdateUserPII :one
+UPDATE users
+SET full_name = $1,
+  email = $2,
+  email_index = $3,
+  data_key_id = $4,
+  is_email_verified = is_email_verified AND NOT $5::boolean
+WHERE username = $6
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
+`
+
+type UpdateUserPIIParams struct {
+	FullName           string         `json:"full_name"`
+	Email              string         `json:"email"`
+	EmailIndex         sql.NullString `json:"email_index"`
+	DataKeyID          sql.NullInt64  `json:"data_key_id"`
+	ResetEmailVerified bool           `json:"reset_email_verified"`
+	Username           string         `json:"username"`
+}
+
+// Replaces the full name and email of a user, sealed together by the store with one data key.
+func (q *Queries) UpdateUserPII(ctx context.Context, arg UpdateUserPIIParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPII,
+		arg.FullName,
+		arg.Email,
+		arg.EmailIndex,
+		arg.DataKeyID,
+		arg.ResetEmailVerified,
+		arg.Username,
+	)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $1,
+  password_changed_at = $2
+WHERE username = $3
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, is_email_verified, login_unlocked_at, deleted_at, erased_at, email_index, data_key_id, kyc_status, kyc_tier
+`
+
+type UpdateUserPasswordParams struct {
+	HashedPassword    string    `json:"hashed_password"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	Username          string    `json:"username"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.HashedPassword, arg.PasswordChangedAt, arg.Username)
 	var i User
 	err := row.Scan(
 		&i.Username,
@@ -61,6 +544,15 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.IsEmailVerified,
+		&i.LoginUnlockedAt,
+		&i.DeletedAt,
+		&i.ErasedAt,
+		&i.EmailIndex,
+		&i.DataKeyID,
+		&i.KycStatus,
+		&i.KycTier,
 	)
 	return i, err
 }
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// TestExportUserDataTx tests that an export holds the user's records and is audited.
+func TestExportUserDataTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+	username := account1.Owner
+
+	ctx := WithAuditActor(context.Background(), AuditActor{Username: username, Role: util.CustomerRole})
+	transfer, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	attempt := createRandomLoginAttempt(t, username, "192.0.2.1", true)
+
+	export, err := store.ExportUserDataTx(context.Background(), username)
+	require.NoError(t, err)
+	require.Equal(t, username, export.Profile.Username)
+	require.Len(t, export.Accounts, 1)
+	require.Equal(t, account1.ID, export.Accounts[0].ID)
+	require.Len(t, export.Sessions, 1)
+	require.Equal(t, attempt.ID, export.Sessions[0].ID)
+
+	// Only the entries of the user's own account are exported
+	require.NotEmpty(t, export.Entries)
+	for _, entry := range export.Entries {
+		require.Equal(t, account1.ID, entry.AccountID)
+	}
+	require.Contains(t, export.Transfers, transfer.Transfer)
+
+	// The user made the transfer, so its audit event is theirs
+	require.NotEmpty(t, export.AuditEvents)
+	for _, event := range export.AuditEvents {
+		require.Equal(t, username, event.Actor)
+	}
+
+	events := listAuditEventsOf(t, AuditUser, username)
+	require.Equal(t, "user.export", events[len(events)-1].Action)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteZip(&buf))
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 6)
+
+	f, err := archive.Open("accounts.json")
+	require.NoError(t, err)
+	var accounts []Account
+	require.NoError(t, json.NewDecoder(f).Decode(&accounts))
+	require.Equal(t, export.Accounts[0].ID, accounts[0].ID)
+}
+
+// TestEraseUserTx tests that only a deactivated user is erased, once, and keeps their accounts.
+func TestEraseUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     user.Username,
+		Currency:  util.RandomCurrency(),
+		ProductID: fetchAccountProduct(t, ProductChecking).ID,
+	})
+	require.NoError(t, err)
+	createRandomLoginAttempt(t, user.Username, "192.0.2.1", true)
+	createRandomVerifyEmail(t, user, time.Hour)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err = store.EraseUserTx(context.Background(), EraseUserTxParams{Username: user.Username, Now: now})
+	require.ErrorIs(t, err, ErrUserNotDeleted)
+
+	_, err = store.DeleteUserTx(context.Background(), DeleteUserTxParams{Username: user.Username, Now: now})
+	require.NoError(t, err)
+
+	erased, err := store.EraseUserTx(context.Background(), EraseUserTxParams{Username: user.Username, Now: now})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, erased.Username)
+	require.Equal(t, ErasedFullName, erased.FullName)
+	require.Equal(t, user.Username+"@erased.invalid", erased.Email)
+	require.Empty(t, erased.HashedPassword)
+	require.True(t, erased.ErasedAt.Valid)
+
+	attempts, err := testQueries.ListLoginAttempts(context.Background(), ListLoginAttemptsParams{Username: user.Username, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, attempts)
+
+	// The financial records stay
+	closed, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, closed.Owner)
+
+	events := listAuditEventsOf(t, AuditUser, user.Username)
+	erase := events[len(events)-1]
+	require.Equal(t, "user.erase", erase.Action)
+	require.JSONEq(t, "null", string(erase.Before))
+	require.NotContains(t, string(erase.After), user.Email)
+
+	_, err = store.EraseUserTx(context.Background(), EraseUserTxParams{Username: user.Username, Now: now})
+	require.ErrorIs(t, err, ErrUserErased)
+}
+
+// TestEraseUserTxRedactsAuditLog tests that the erased name and email can't be recovered from the audit log,
+// from the sealed snapshots nor from the plaintext ones written before personal data was encrypted.
+func TestEraseUserTxRedactsAuditLog(t *testing.T) {
+	store := NewEncryptedStore(testDB, testPII)
+	user, arg := createRandomEncryptedUser(t, store)
+	raw, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	legacy, err := json.Marshal(newAuditUser(user))
+	require.NoError(t, err)
+	_, err = testQueries.CreateAuditEvent(context.Background(), CreateAuditEventParams{
+		Actor:        user.Username,
+		Action:       "user.update",
+		ResourceType: AuditUser,
+		ResourceID:   user.Username,
+		Before:       legacy,
+		After:        legacy,
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err = store.DeleteUserTx(context.Background(), DeleteUserTxParams{Username: user.Username, Now: now})
+	require.NoError(t, err)
+	_, err = store.EraseUserTx(context.Background(), EraseUserTxParams{Username: user.Username, Now: now})
+	require.NoError(t, err)
+
+	events := listAuditEventsOf(t, AuditUser, user.Username)
+	require.Len(t, events, 4)
+	for _, event := range events {
+		for _, snapshot := range []string{string(event.Before), string(event.After)} {
+			require.NotContains(t, snapshot, arg.FullName)
+			require.NotContains(t, snapshot, arg.Email)
+			require.NotContains(t, snapshot, raw.FullName)
+			require.NotContains(t, snapshot, raw.Email)
+		}
+	}
+	require.Equal(t, "user.update", events[1].Action)
+	var redacted auditUser
+	require.NoError(t, json.Unmarshal(events[1].After, &redacted))
+	require.Equal(t, ErasedFullName, redacted.FullName)
+	require.Equal(t, user.Username+"@erased.invalid", redacted.Email)
+	require.Equal(t, user.Username, redacted.Username)
+
+	// Outside of an erasure the audit log stays append-only
+	_, err = testDB.Exec("UPDATE audit_events SET after = 'null' WHERE id = $1", events[1].ID)
+	require.ErrorContains(t, err, "append-only")
+
+	// Setting the flag the redaction used to rely on does not open it up either
+	tx, err := testDB.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, err = tx.Exec("SELECT set_config('bank.audit_redaction', 'on', true)")
+	require.NoError(t, err)
+	_, err = tx.Exec("UPDATE audit_events SET after = 'null' WHERE id = $1", events[1].ID)
+	require.ErrorContains(t, err, "append-only")
+}
+
+// TestRedactUserAuditEventsActiveUser tests that the snapshots of a user who has not deactivated themselves
+// cannot be redacted.
+func TestRedactUserAuditEventsActiveUser(t *testing.T) {
+	user := createRandomUser(t)
+	snapshot, err := json.Marshal(newAuditUser(user))
+	require.NoError(t, err)
+	_, err = testQueries.CreateAuditEvent(context.Background(), CreateAuditEventParams{
+		Actor:        user.Username,
+		Action:       "user.update",
+		ResourceType: AuditUser,
+		ResourceID:   user.Username,
+		Before:       snapshot,
+		After:        snapshot,
+	})
+	require.NoError(t, err)
+	before := listAuditEventsOf(t, AuditUser, user.Username)
+
+	err = testQueries.RedactUserAuditEvents(context.Background(), RedactUserAuditEventsParams{
+		Username: user.Username,
+		FullName: ErasedFullName,
+		Email:    user.Username + "@erased.invalid",
+	})
+	require.NoError(t, err)
+	require.Equal(t, before, listAuditEventsOf(t, AuditUser, user.Username))
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -15,7 +16,7 @@ func createRandomUser(t *testing.T) User {
 	hashedPassword, err := util.HashPassword(util.RandomString(6))
 	require.NoError(t, err)
 	// Prepare the parameters to create a user
-	arg := CreateUserParams{
+	arg := InsertUserParams{
 		Username:       util.RandomOwner(),
 		HashedPassword: hashedPassword,
 		FullName:       util.RandomOwner(),
@@ -23,7 +24,7 @@ func createRandomUser(t *testing.T) User {
 	}
 
 	// Insert the user into the database
-	user, err := testQueries.CreateUser(context.Background(), arg)
+	user, err := testQueries.InsertUser(context.Background(), arg)
 	require.NoError(t, err)   // Ensure no error occurred during user creation
 	require.NotEmpty(t, user) // Assert that the created user is not empty
 
@@ -63,3 +64,175 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+// TestRehashUserPassword tests that a hash is only replaced while the password is unchanged.
+func TestRehashUserPassword(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	arg := RehashUserPasswordParams{
+		NewHashedPassword: util.RandomString(60),
+		Username:          user.Username,
+		OldHashedPassword: user.HashedPassword,
+	}
+	rehashed, err := store.RehashUserPassword(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewHashedPassword, rehashed.HashedPassword)
+	// The password is the same, so sessions stay valid
+	require.Equal(t, user.PasswordChangedAt, rehashed.PasswordChangedAt)
+
+	// The hash changed since it was read
+	_, err = store.RehashUserPassword(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// TestUpdateUser tests that a new email has to be verified again and a new name does not.
+func TestUpdateUser(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	user, err := testQueries.SetUserEmailVerified(context.Background(), SetUserEmailVerifiedParams{Username: user.Username, Email: user.Email})
+	require.NoError(t, err)
+	require.True(t, user.IsEmailVerified)
+
+	newName := util.RandomOwner()
+	updated, err := store.UpdateUser(context.Background(), UpdateUserParams{
+		FullName: sql.NullString{String: newName, Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newName, updated.FullName)
+	require.Equal(t, user.Email, updated.Email)
+	require.True(t, updated.IsEmailVerified)
+
+	// Setting the same email keeps it verified
+	updated, err = store.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: user.Email, Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, updated.IsEmailVerified)
+
+	newEmail := util.RandomEmail()
+	updated, err = store.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: newEmail, Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newEmail, updated.Email)
+	require.Equal(t, newName, updated.FullName)
+	require.False(t, updated.IsEmailVerified)
+
+	events := listAuditEventsOf(t, AuditUser, user.Username)
+	require.Equal(t, "user.update", events[len(events)-1].Action)
+}
+
+// TestDeleteUserTx tests that a user with empty accounts is deactivated and their accounts closed.
+func TestDeleteUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     user.Username,
+		Currency:  util.RandomCurrency(),
+		ProductID: fetchAccountProduct(t, ProductChecking).ID,
+	})
+	require.NoError(t, err)
+	key := createRandomAPIKey(t, user, util.ScopeAccountsRead)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	result, err := store.DeleteUserTx(context.Background(), DeleteUserTxParams{Username: user.Username, Now: now})
+	require.NoError(t, err)
+	require.True(t, result.User.DeletedAt.Valid)
+	require.WithinDuration(t, now, result.User.DeletedAt.Time, time.Second)
+	require.Len(t, result.ClosedAccounts, 1)
+	require.Equal(t, account.ID, result.ClosedAccounts[0].ID)
+	require.Equal(t, AccountClosed, result.ClosedAccounts[0].Status)
+
+	key, err = testQueries.GetAPIKeyByPrefix(context.Background(), key.Prefix)
+	require.NoError(t, err)
+	require.True(t, key.RevokedAt.Valid)
+
+	events := listAuditEventsOf(t, AuditUser, user.Username)
+	require.Equal(t, "user.delete", events[len(events)-1].Action)
+
+	// A user is only deactivated once
+	_, err = store.DeleteUserTx(context.Background(), DeleteUserTxParams{Username: user.Username, Now: now})
+	require.ErrorIs(t, err, ErrUserDeleted)
+}
+
+// TestDeleteUserTxWithBalance tests that deactivating a user closes their zero-balance accounts
+// and leaves those holding money open.
+func TestDeleteUserTxWithBalance(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	empty, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     user.Username,
+		Currency:  util.USD,
+		ProductID: fetchAccountProduct(t, ProductChecking).ID,
+	})
+	require.NoError(t, err)
+	funded, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     user.Username,
+		Currency:  util.EUR,
+		ProductID: fetchAccountProduct(t, ProductChecking).ID,
+	})
+	require.NoError(t, err)
+	funded = fundAccount(t, funded, 100)
+
+	result, err := store.DeleteUserTx(context.Background(), DeleteUserTxParams{Username: user.Username, Now: time.Now()})
+	require.NoError(t, err)
+	require.True(t, result.User.DeletedAt.Valid)
+	require.Len(t, result.ClosedAccounts, 1)
+	require.Equal(t, empty.ID, result.ClosedAccounts[0].ID)
+	require.Len(t, result.OpenAccounts, 1)
+	require.Equal(t, funded.ID, result.OpenAccounts[0].ID)
+
+	funded, err = testQueries.GetAccount(context.Background(), funded.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountActive, funded.Status)
+	require.Equal(t, int64(100), funded.Balance)
+}
+
+// TestDeleteUserTxCancelsPendingWork tests that deactivating a user cancels their transfers awaiting
+// approval and releases the holds on their accounts, so neither can be paid out afterwards.
+func TestDeleteUserTxCancelsPendingWork(t *testing.T) {
+	store := NewStore(testDB)
+	pending := createRandomTransferApproval(t, 10, time.Now().Add(time.Hour))
+	held := createRandomHold(t, 10, time.Now().Add(time.Hour))
+
+	result, err := store.DeleteUserTx(context.Background(), DeleteUserTxParams{
+		Username: pending.Approval.RequestedBy,
+		Now:      time.Now(),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.CancelledTransferApprovals, 1)
+	require.Equal(t, pending.Transfer.ID, result.CancelledTransferApprovals[0].TransferID)
+	require.Equal(t, TransferApprovalCancelled, result.CancelledTransferApprovals[0].Status)
+	require.Empty(t, result.ReleasedHolds)
+
+	// the cancelled transfer can no longer be approved
+	_, err = store.ApproveTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		ReviewedBy: createRandomUser(t).Username,
+		Now:        time.Now(),
+	})
+	require.Error(t, err)
+
+	account, err := testQueries.GetAccount(context.Background(), pending.FromAccount.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldBalance)
+
+	result, err = store.DeleteUserTx(context.Background(), DeleteUserTxParams{
+		Username: held.Account.Owner,
+		Now:      time.Now(),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.ReleasedHolds, 1)
+	require.Equal(t, held.Hold.ID, result.ReleasedHolds[0].ID)
+	require.Equal(t, HoldReleased, result.ReleasedHolds[0].Status)
+
+	account, err = testQueries.GetAccount(context.Background(), held.Account.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldBalance)
+	// the account still holds the funds and stays open for the bank to pay out
+	require.Equal(t, AccountActive, account.Status)
+}
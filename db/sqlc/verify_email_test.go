@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// createRandomVerifyEmail stores a verification code for the user's email that expires after ttl
+func createRandomVerifyEmail(t *testing.T, user User, ttl time.Duration) VerifyEmail {
+	verifyEmail, err := testQueries.CreateVerifyEmail(context.Background(), CreateVerifyEmailParams{
+		Username:       user.Username,
+		Email:          user.Email,
+		SecretCodeHash: util.RandomString(64),
+		ExpiresAt:      time.Now().Add(ttl),
+	})
+	require.NoError(t, err)
+	require.False(t, verifyEmail.UsedAt.Valid)
+	return verifyEmail
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.False(t, user.IsEmailVerified)
+
+	verifyEmail := createRandomVerifyEmail(t, user, time.Hour)
+	latest, err := testQueries.GetLatestVerifyEmail(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, verifyEmail.ID, latest.ID)
+
+	// A wrong secret code does not use up the link
+	arg := VerifyEmailTxParams{ID: verifyEmail.ID, SecretCodeHash: util.RandomString(64), Now: time.Now()}
+	_, err = store.VerifyEmailTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrVerifyEmailInvalid)
+
+	arg.SecretCodeHash = verifyEmail.SecretCodeHash
+	verified, err := store.VerifyEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, verified.IsEmailVerified)
+
+	_, err = store.VerifyEmailTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrVerifyEmailInvalid)
+}
+
+func TestVerifyEmailTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user, -time.Minute)
+
+	arg := VerifyEmailTxParams{ID: verifyEmail.ID, SecretCodeHash: verifyEmail.SecretCodeHash, Now: time.Now()}
+	_, err := store.VerifyEmailTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrVerifyEmailExpired)
+
+	unverified, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, unverified.IsEmailVerified)
+}
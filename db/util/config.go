@@ -1,6 +1,14 @@
 package util
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+)
 
 // Config holds all configurations of the application.
 // Viper will read the values from a config file or environment variables.
@@ -8,6 +16,134 @@ type Config struct {
 	DBDriver      string `mapstructure:"DB_DRIVER"` // `mapstructure` tag is used by Viper to map env variables or config file values to struct fields.
 	DBSource      string `mapstructure:"DB_SOURCE"`
 	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
+
+	ScheduledTransferInterval    time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`     // How often the scheduler looks for due scheduled transfers
+	ScheduledTransferMaxFailures int32         `mapstructure:"SCHEDULED_TRANSFER_MAX_FAILURES"` // Consecutive failures before a scheduled transfer is paused
+	ScheduledTransferRetryDelay  time.Duration `mapstructure:"SCHEDULED_TRANSFER_RETRY_DELAY"`  // Wait before retrying a failed scheduled transfer
+
+	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`   // Secret used to sign access tokens, at least 32 characters
+	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"` // How long an access token stays valid
+
+	LoginMaxFailures     int64         `mapstructure:"LOGIN_MAX_FAILURES"`     // Failed logins of a username before it is locked out
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"` // How long failed logins count, a locked out user waits for them to age out
+	LoginMaxIPFailures   int64         `mapstructure:"LOGIN_MAX_IP_FAILURES"`  // Failed logins from one IP address, over the lockout duration, before it is refused
+	LoginDelayBase       time.Duration `mapstructure:"LOGIN_DELAY_BASE"`       // Delay before checking a password after one recent failure, doubled for each further one
+	LoginMaxDelay        time.Duration `mapstructure:"LOGIN_MAX_DELAY"`        // Cap of the progressive login delay
+
+	TOTPEncryptionKey       string `mapstructure:"TOTP_ENCRYPTION_KEY"`        // Key encrypting the TOTP secrets at rest, exactly 32 characters
+	TOTPIssuer              string `mapstructure:"TOTP_ISSUER"`                // Name of the bank shown in authenticator apps
+	TransferStepUpThreshold int64  `mapstructure:"TRANSFER_STEP_UP_THRESHOLD"` // Transfers above this amount need a two-factor code from users who enabled it
+
+	PIIMasterKey     string `mapstructure:"PII_MASTER_KEY"`      // Master key wrapping the data keys of users' names and emails, exactly 32 characters
+	PIIMasterKeyFile string `mapstructure:"PII_MASTER_KEY_FILE"` // File of master keys, one per line: the first is current, the others are older ones being rotated out
+	PIIBlindIndexKey string `mapstructure:"PII_BLIND_INDEX_KEY"` // Key of the email blind index, exactly 32 characters; changing it loses every index
+
+	TransferApprovalThreshold      int64         `mapstructure:"TRANSFER_APPROVAL_THRESHOLD"`       // Transfers above this amount need a second user's approval
+	TransferApprovalTTL            time.Duration `mapstructure:"TRANSFER_APPROVAL_TTL"`             // How long a transfer can wait for approval before it expires
+	TransferApprovalExpiryInterval time.Duration `mapstructure:"TRANSFER_APPROVAL_EXPIRY_INTERVAL"` // How often expired approval requests are cancelled
+
+	SettlementInterval  time.Duration `mapstructure:"SETTLEMENT_INTERVAL"`   // How often pending transfers are settled
+	SettlementBatchSize int32         `mapstructure:"SETTLEMENT_BATCH_SIZE"` // Pending transfers settled per database transaction
+
+	HoldTTL            time.Duration `mapstructure:"HOLD_TTL"`             // How long a hold lasts when the client does not set an expiry
+	HoldExpiryInterval time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"` // How often expired holds are released
+
+	InterestInterval  time.Duration `mapstructure:"INTEREST_INTERVAL"`   // How often the interest engine checks for a day to accrue
+	InterestBatchSize int32         `mapstructure:"INTEREST_BATCH_SIZE"` // Accounts listed per page when accruing interest
+
+	MaintenanceFeeInterval  time.Duration `mapstructure:"MAINTENANCE_FEE_INTERVAL"`   // How often the last month's maintenance fees are charged
+	MaintenanceFeeBatchSize int32         `mapstructure:"MAINTENANCE_FEE_BATCH_SIZE"` // Accounts listed per page when charging maintenance fees
+
+	DailyBalanceInterval  time.Duration `mapstructure:"DAILY_BALANCE_INTERVAL"`   // How often the end-of-day balance job checks for a day to snapshot
+	DailyBalanceBatchSize int32         `mapstructure:"DAILY_BALANCE_BATCH_SIZE"` // Accounts listed per page when taking end-of-day snapshots
+
+	PasswordMinLength        int           `mapstructure:"PASSWORD_MIN_LENGTH"`         // Minimum length of new passwords
+	PasswordRequireMixedCase bool          `mapstructure:"PASSWORD_REQUIRE_MIXED_CASE"` // New passwords need upper and lower case letters
+	PasswordRequireDigit     bool          `mapstructure:"PASSWORD_REQUIRE_DIGIT"`      // New passwords need a digit
+	PasswordRequireSymbol    bool          `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`     // New passwords need a symbol
+	PasswordResetTokenTTL    time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_TTL"`    // How long a password reset token can be used
+
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"` // How new passwords are hashed: bcrypt or argon2id, older hashes are replaced at login
+	PasswordBcryptCost    int    `mapstructure:"PASSWORD_BCRYPT_COST"`    // bcrypt cost, bcrypt's default when not set
+	PasswordArgon2Time    uint32 `mapstructure:"PASSWORD_ARGON2_TIME"`    // Argon2id passes over the memory
+	PasswordArgon2Memory  uint32 `mapstructure:"PASSWORD_ARGON2_MEMORY"`  // Argon2id memory in KiB
+	PasswordArgon2Threads uint8  `mapstructure:"PASSWORD_ARGON2_THREADS"` // Argon2id degree of parallelism
+
+	Notifier     string `mapstructure:"NOTIFIER"`      // How messages reach users: log, file or smtp
+	NotifierFile string `mapstructure:"NOTIFIER_FILE"` // File the file notifier appends messages to
+	SMTPAddress  string `mapstructure:"SMTP_ADDRESS"`  // host:port of the SMTP server, such as a local mailpit
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"` // SMTP login, no authentication when empty
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"` // SMTP password
+	MailFrom     string `mapstructure:"MAIL_FROM"`     // Sender address of the emails
+
+	BlobStore      string `mapstructure:"BLOB_STORE"`        // Where uploaded files such as KYC documents are kept, only file for now
+	BlobDir        string `mapstructure:"BLOB_DIR"`          // Directory of the file blob store
+	KYCMaxFileSize int64  `mapstructure:"KYC_MAX_FILE_SIZE"` // Largest KYC document that can be uploaded, in bytes
+
+	AMLRulesFile        string        `mapstructure:"AML_RULES_FILE"`         // YAML or JSON file of the transaction monitoring rules, loaded at startup
+	AMLMonitorInterval  time.Duration `mapstructure:"AML_MONITOR_INTERVAL"`   // How often posted transfers are screened against the rules
+	AMLMonitorBatchSize int32         `mapstructure:"AML_MONITOR_BATCH_SIZE"` // Queued transfers read at a time when screening
+
+	RequireVerifiedEmail            bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL"`             // Users must verify their email before opening accounts and making transfers
+	EmailVerificationURL            string        `mapstructure:"EMAIL_VERIFICATION_URL"`             // Link sent to verify an email, the ID and secret code are added as query parameters
+	EmailVerificationTTL            time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`             // How long a verification link can be used
+	EmailVerificationResendInterval time.Duration `mapstructure:"EMAIL_VERIFICATION_RESEND_INTERVAL"` // Minimum wait between two verification emails to a user
+}
+
+// PasswordPolicy returns the rules new passwords must follow.
+func (config Config) PasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        config.PasswordMinLength,
+		RequireMixedCase: config.PasswordRequireMixedCase,
+		RequireDigit:     config.PasswordRequireDigit,
+		RequireSymbol:    config.PasswordRequireSymbol,
+	}
+}
+
+// PasswordHasher returns the hasher of new passwords, bcrypt when no algorithm is set.
+func (config Config) PasswordHasher() (PasswordHasher, error) {
+	switch config.PasswordHashAlgorithm {
+	case "", BcryptAlgorithm:
+		if config.PasswordBcryptCost != 0 && (config.PasswordBcryptCost < bcrypt.MinCost || config.PasswordBcryptCost > bcrypt.MaxCost) {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		return BcryptHasher{Cost: config.PasswordBcryptCost}, nil
+	case Argon2idAlgorithm:
+		return Argon2idHasher{
+			Time:    config.PasswordArgon2Time,
+			Memory:  config.PasswordArgon2Memory,
+			Threads: config.PasswordArgon2Threads,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", config.PasswordHashAlgorithm)
+	}
+}
+
+// PIIMasterKeys returns the master keys of the encryption of personal data, current first, from the key file
+// if one is set. It returns no keys when neither a key nor a key file is set, which leaves the data in plaintext.
+func (config Config) PIIMasterKeys() ([]string, error) {
+	if config.PIIMasterKeyFile == "" {
+		if config.PIIMasterKey == "" {
+			return nil, nil
+		}
+		return []string{config.PIIMasterKey}, nil
+	}
+
+	data, err := os.ReadFile(config.PIIMasterKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read master key file: %w", err)
+	}
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, line)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("master key file %s has no key", config.PIIMasterKeyFile)
+	}
+	return keys, nil
 }
 
 // LoadConfiguration reads configuration from a file at the given path or from environment variables.
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigPIIMasterKeys(t *testing.T) {
+	keys, err := Config{}.PIIMasterKeys()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	key := RandomString(32)
+	keys, err = Config{PIIMasterKey: key}.PIIMasterKeys()
+	require.NoError(t, err)
+	require.Equal(t, []string{key}, keys)
+
+	// The key file takes precedence, and lists the current key first
+	current, previous := RandomString(32), RandomString(32)
+	file := filepath.Join(t.TempDir(), "master.keys")
+	require.NoError(t, os.WriteFile(file, []byte("# rotated 2026-10-18\n"+current+"\n\n"+previous+"\n"), 0o600))
+	keys, err = Config{PIIMasterKey: key, PIIMasterKeyFile: file}.PIIMasterKeys()
+	require.NoError(t, err)
+	require.Equal(t, []string{current, previous}, keys)
+
+	empty := filepath.Join(t.TempDir(), "empty.keys")
+	require.NoError(t, os.WriteFile(empty, []byte("# no keys yet\n"), 0o600))
+	_, err = Config{PIIMasterKeyFile: empty}.PIIMasterKeys()
+	require.Error(t, err)
+
+	_, err = Config{PIIMasterKeyFile: filepath.Join(t.TempDir(), "missing.keys")}.PIIMasterKeys()
+	require.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// Wednesday 2026-03-11 10:30:45 UTC
+	from := time.Date(2026, time.March, 11, 10, 30, 45, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   time.Time
+	}{
+		{
+			name:       "EveryMinute",
+			expression: "* * * * *",
+			expected:   time.Date(2026, time.March, 11, 10, 31, 0, 0, time.UTC),
+		},
+		{
+			name:       "Daily",
+			expression: "@daily",
+			expected:   time.Date(2026, time.March, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "EveryFifteenMinutes",
+			expression: "*/15 * * * *",
+			expected:   time.Date(2026, time.March, 11, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name:       "FirstOfMonth",
+			expression: "0 9 1 * *",
+			expected:   time.Date(2026, time.April, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Weekdays",
+			expression: "0 8 * * 1-5",
+			expected:   time.Date(2026, time.March, 12, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "SundayAsSeven",
+			expression: "0 0 * * 7",
+			expected:   time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "DayOfMonthOrDayOfWeek",
+			expression: "0 0 13 * 5",
+			expected:   time.Date(2026, time.March, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "LeapDay",
+			expression: "0 0 29 2 *",
+			expected:   time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(tc.expression)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, schedule.Next(from))
+		})
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	expressions := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	}
+
+	for _, expression := range expressions {
+		_, err := ParseCronSchedule(expression)
+		require.Error(t, err, expression)
+	}
+}
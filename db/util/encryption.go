@@ -0,0 +1,59 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// encryptionKeySize is the key size of AES-256
+const encryptionKeySize = 32
+
+// ErrDecrypt is returned when a ciphertext was altered, or sealed with another key or associated data.
+var ErrDecrypt = errors.New("cannot decrypt: wrong key or corrupted data")
+
+// Encrypter seals secrets stored in the database with AES-256-GCM.
+type Encrypter struct {
+	aead cipher.AEAD
+}
+
+// NewEncrypter creates an encrypter from a key of exactly 32 characters.
+func NewEncrypter(key string) (*Encrypter, error) {
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", encryptionKeySize)
+	}
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Encrypter{aead: aead}, nil
+}
+
+// Encrypt returns a random nonce followed by the sealed plaintext. The associated data, such as the
+// owner of the secret, is not stored but must be given again to decrypt, which ties the ciphertext to it.
+func (encrypter *Encrypter) Encrypt(plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, encrypter.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return encrypter.aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+// Decrypt opens a ciphertext made by Encrypt with the same associated data.
+func (encrypter *Encrypter) Decrypt(ciphertext, associatedData []byte) ([]byte, error) {
+	nonceSize := encrypter.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := encrypter.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], associatedData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
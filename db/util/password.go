@@ -1,21 +1,201 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// return the bcrypt hash string of input password
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// Password hashing algorithms
+const (
+	BcryptAlgorithm   = "bcrypt"
+	Argon2idAlgorithm = "argon2id"
+)
+
+const (
+	// Argon2id parameters used when they are not configured, the OWASP minimum for a single thread
+	DefaultArgon2Time    = 2
+	DefaultArgon2Memory  = 19 * 1024 // KiB
+	DefaultArgon2Threads = 1
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	argon2Prefix     = "$argon2id$"
+)
+
+// ErrMismatchedPassword is returned when a password does not match its hash, whatever the algorithm
+var ErrMismatchedPassword = bcrypt.ErrMismatchedHashAndPassword
+
+// ErrInvalidPasswordHash is returned for an Argon2id hash that cannot be decoded
+var ErrInvalidPasswordHash = errors.New("invalid argon2id password hash")
+
+// PasswordHasher hashes new passwords with one algorithm and its parameters. The stored hashes carry
+// theirs, so CheckPassword checks a hash of any algorithm, and NeedsRehash tells which hashes the
+// hasher would no longer make, for them to be replaced the next time the user gives the password.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	NeedsRehash(hashedPassword string) bool
+}
+
+// BcryptHasher hashes passwords with bcrypt.
+type BcryptHasher struct {
+	Cost int // bcrypt.DefaultCost when not set
+}
+
+func (hasher BcryptHasher) cost() int {
+	if hasher.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return hasher.Cost
+}
+
+// Hash returns the bcrypt hash of the password.
+func (hasher BcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.cost())
 	if err != nil {
 		return "", fmt.Errorf("Failed to hash password: %w", err)
 	}
 	return string(hashedPassword), nil
 }
 
-// check if correct password is given during authentication
+// NeedsRehash reports whether the hash is not a bcrypt hash of the hasher's cost.
+func (hasher BcryptHasher) NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost != hasher.cost()
+}
+
+// Argon2idHasher hashes passwords with Argon2id. The hashes use the PHC string format,
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>, in unpadded base64.
+type Argon2idHasher struct {
+	Time    uint32 // passes over the memory, DefaultArgon2Time when not set
+	Memory  uint32 // memory in KiB, DefaultArgon2Memory when not set
+	Threads uint8  // degree of parallelism, DefaultArgon2Threads when not set
+}
+
+// argon2Params are the parameters an Argon2id hash was made with.
+type argon2Params struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+func (hasher Argon2idHasher) params() argon2Params {
+	params := argon2Params{Time: hasher.Time, Memory: hasher.Memory, Threads: hasher.Threads}
+	if params.Time == 0 {
+		params.Time = DefaultArgon2Time
+	}
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2Memory
+	}
+	if params.Threads == 0 {
+		params.Threads = DefaultArgon2Threads
+	}
+	return params
+}
+
+// Hash returns the Argon2id hash of the password with a random salt.
+func (hasher Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("Failed to hash password: %w", err)
+	}
+
+	params := hasher.params()
+	key := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, argon2KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version,
+		params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// NeedsRehash reports whether the hash is not an Argon2id hash with the hasher's parameters.
+func (hasher Argon2idHasher) NeedsRehash(hashedPassword string) bool {
+	params, salt, key, err := decodeArgon2Hash(hashedPassword)
+	return err != nil || params != hasher.params() || len(salt) != argon2SaltLength || len(key) != argon2KeyLength
+}
+
+// decodeArgon2Hash splits an Argon2id hash into its parameters, salt and key.
+func decodeArgon2Hash(hashedPassword string) (params argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != Argon2idAlgorithm {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+	if params.Time == 0 || params.Threads == 0 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+	return params, salt, key, nil
+}
+
+// return the bcrypt hash string of input password, at bcrypt's default cost.
+// The server hashes with the PasswordHasher of its configuration instead.
+func HashPassword(password string) (string, error) {
+	return BcryptHasher{}.Hash(password)
+}
+
+// check if correct password is given during authentication, against a bcrypt or an Argon2id hash
 func CheckPassword(password string, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(password), []byte(hashedPassword))
+	if !strings.HasPrefix(hashedPassword, argon2Prefix) {
+		return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hashedPassword)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatchedPassword
+	}
+	return nil
+}
+
+// dummyPasswordHashes holds a hash per hasher, checked against when a login names no existing user,
+// see CheckPasswordWithoutUser.
+var dummyPasswordHashes sync.Map
+
+// dummyPasswordHash returns a hash of no user's password made by the hasher.
+func dummyPasswordHash(hasher PasswordHasher) (string, error) {
+	if hashedPassword, ok := dummyPasswordHashes.Load(hasher); ok {
+		return hashedPassword.(string), nil
+	}
+	hashedPassword, err := hasher.Hash("not the password of any user")
+	if err != nil {
+		return "", err
+	}
+	actual, _ := dummyPasswordHashes.LoadOrStore(hasher, hashedPassword)
+	return actual.(string), nil
+}
+
+// CheckPasswordWithoutUser always fails, after spending as long as checking a hash of the hasher does.
+// Logins for usernames that do not exist call it, so that response times do not tell which users exist.
+func CheckPasswordWithoutUser(hasher PasswordHasher, password string) error {
+	hashedPassword, err := dummyPasswordHash(hasher)
+	if err != nil {
+		return err
+	}
+	_ = CheckPassword(password, hashedPassword)
+	return ErrMismatchedPassword
 }
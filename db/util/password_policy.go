@@ -0,0 +1,62 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+const (
+	// DefaultPasswordMinLength is the minimum password length when the policy does not set one
+	DefaultPasswordMinLength = 6
+	// maxPasswordBytes is the longest password bcrypt hashes, it ignores the bytes after it
+	maxPasswordBytes = 72
+)
+
+// PasswordPolicy is the set of rules new passwords must follow.
+type PasswordPolicy struct {
+	MinLength        int  // minimum number of characters, DefaultPasswordMinLength when not set
+	RequireMixedCase bool // at least one upper and one lower case letter
+	RequireDigit     bool // at least one digit
+	RequireSymbol    bool // at least one character that is neither a letter nor a digit
+}
+
+// Validate returns an error describing the first rule the password breaks.
+func (policy PasswordPolicy) Validate(password string) error {
+	minLength := policy.MinLength
+	if minLength <= 0 {
+		minLength = DefaultPasswordMinLength
+	}
+
+	if len([]rune(password)) < minLength {
+		return fmt.Errorf("password must have at least %d characters", minLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("password must not be longer than %d bytes", maxPasswordBytes)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+
+	if policy.RequireMixedCase && !(upper && lower) {
+		return errors.New("password must contain upper and lower case letters")
+	}
+	if policy.RequireDigit && !digit {
+		return errors.New("password must contain a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		return errors.New("password must contain a symbol")
+	}
+	return nil
+}
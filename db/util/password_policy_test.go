@@ -0,0 +1,42 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireMixedCase: true, RequireDigit: true, RequireSymbol: true}
+
+	testCases := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		valid    bool
+	}{
+		{"DefaultMinLength", PasswordPolicy{}, "abcdef", true},
+		{"DefaultTooShort", PasswordPolicy{}, "abcde", false},
+		{"TooLongForBcrypt", PasswordPolicy{}, strings.Repeat("a", 73), false},
+		{"Strict", strict, "Correct-horse9", true},
+		{"StrictTooShort", strict, "Short-1a", false},
+		{"NoUpperCase", strict, "correct-horse9", false},
+		{"NoDigit", strict, "Correct-horse", false},
+		{"NoSymbol", strict, "Correcthorse9", false},
+		{"MultiByteCharacters", PasswordPolicy{MinLength: 6}, "ééééé", false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate(tc.password)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -1,13 +1,17 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func testPassword(t *testing.T) {
+// testArgon2idHasher keeps the tests fast, real deployments use more memory
+var testArgon2idHasher = Argon2idHasher{Time: 1, Memory: 1024, Threads: 1}
+
+func TestPassword(t *testing.T) {
 	password := RandomString(6)
 
 	hashedPassword1, err := HashPassword(password)
@@ -28,3 +32,118 @@ func testPassword(t *testing.T) {
 	require.NotEqual(t, hashedPassword1, hashedPassword2)
 
 }
+
+func TestBcryptHasher(t *testing.T) {
+	password := RandomString(6)
+	hasher := BcryptHasher{Cost: bcrypt.MinCost}
+
+	hashedPassword, err := hasher.Hash(password)
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	require.NoError(t, err)
+	require.Equal(t, bcrypt.MinCost, cost)
+
+	require.NoError(t, CheckPassword(password, hashedPassword))
+	require.ErrorIs(t, CheckPassword(RandomString(6), hashedPassword), ErrMismatchedPassword)
+	require.False(t, hasher.NeedsRehash(hashedPassword))
+
+	// A hash of another cost is outdated
+	require.True(t, BcryptHasher{Cost: bcrypt.MinCost + 1}.NeedsRehash(hashedPassword))
+	require.True(t, BcryptHasher{}.NeedsRehash(hashedPassword))
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	password := RandomString(6)
+
+	hashedPassword1, err := testArgon2idHasher.Hash(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashedPassword1, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	require.NoError(t, CheckPassword(password, hashedPassword1))
+	require.ErrorIs(t, CheckPassword(RandomString(6), hashedPassword1), ErrMismatchedPassword)
+	require.False(t, testArgon2idHasher.NeedsRehash(hashedPassword1))
+
+	// The salt is random
+	hashedPassword2, err := testArgon2idHasher.Hash(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hashedPassword1, hashedPassword2)
+
+	// The parameters are read from the hash, so hashes stay valid when the configured ones change
+	stronger := Argon2idHasher{Time: 2, Memory: 2048, Threads: 2}
+	require.True(t, stronger.NeedsRehash(hashedPassword1))
+	hashedPassword3, err := stronger.Hash(password)
+	require.NoError(t, err)
+	require.NoError(t, CheckPassword(password, hashedPassword3))
+	require.True(t, testArgon2idHasher.NeedsRehash(hashedPassword3))
+}
+
+func TestArgon2idDefaults(t *testing.T) {
+	hashedPassword, err := Argon2idHasher{}.Hash(RandomString(6))
+	require.NoError(t, err)
+	require.Contains(t, hashedPassword, "$m=19456,t=2,p=1$")
+	require.False(t, Argon2idHasher{Time: DefaultArgon2Time, Memory: DefaultArgon2Memory, Threads: DefaultArgon2Threads}.NeedsRehash(hashedPassword))
+}
+
+func TestInvalidArgon2idHash(t *testing.T) {
+	hashedPassword, err := testArgon2idHasher.Hash(RandomString(6))
+	require.NoError(t, err)
+	parts := strings.Split(hashedPassword, "$")
+
+	invalid := []string{
+		"$argon2id$",
+		strings.Replace(hashedPassword, "v=19", "v=16", 1),
+		strings.Replace(hashedPassword, "t=1", "t=0", 1),
+		strings.Replace(hashedPassword, "m=1024", "m=lots", 1),
+		strings.Join(append(parts[:5:5], "!!"), "$"),
+	}
+	for _, hashedPassword := range invalid {
+		require.ErrorIs(t, CheckPassword("password", hashedPassword), ErrInvalidPasswordHash, hashedPassword)
+		require.True(t, testArgon2idHasher.NeedsRehash(hashedPassword), hashedPassword)
+	}
+}
+
+func TestPasswordHashMigration(t *testing.T) {
+	password := RandomString(6)
+	bcryptHasher := BcryptHasher{Cost: bcrypt.MinCost}
+
+	// Users keep logging in with bcrypt hashes after the switch to Argon2id, which replaces them
+	bcryptHash, err := bcryptHasher.Hash(password)
+	require.NoError(t, err)
+	require.True(t, testArgon2idHasher.NeedsRehash(bcryptHash))
+	require.NoError(t, CheckPassword(password, bcryptHash))
+
+	argon2Hash, err := testArgon2idHasher.Hash(password)
+	require.NoError(t, err)
+	require.False(t, testArgon2idHasher.NeedsRehash(argon2Hash))
+
+	// and switching back works the same way
+	require.True(t, bcryptHasher.NeedsRehash(argon2Hash))
+	require.NoError(t, CheckPassword(password, argon2Hash))
+}
+
+func TestCheckPasswordWithoutUser(t *testing.T) {
+	for _, hasher := range []PasswordHasher{BcryptHasher{}, BcryptHasher{Cost: bcrypt.MinCost}, testArgon2idHasher} {
+		err := CheckPasswordWithoutUser(hasher, RandomString(6))
+		require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+
+		// The dummy hash is made by the hasher itself, so both checks take about as long
+		hashedPassword, err := dummyPasswordHash(hasher)
+		require.NoError(t, err)
+		require.False(t, hasher.NeedsRehash(hashedPassword))
+	}
+}
+
+func TestConfigPasswordHasher(t *testing.T) {
+	hasher, err := Config{}.PasswordHasher()
+	require.NoError(t, err)
+	require.Equal(t, BcryptHasher{}, hasher)
+
+	hasher, err = Config{PasswordHashAlgorithm: Argon2idAlgorithm, PasswordArgon2Memory: 4096}.PasswordHasher()
+	require.NoError(t, err)
+	require.Equal(t, Argon2idHasher{Memory: 4096}, hasher)
+
+	_, err = Config{PasswordBcryptCost: bcrypt.MaxCost + 1}.PasswordHasher()
+	require.Error(t, err)
+	_, err = Config{PasswordHashAlgorithm: "md5"}.PasswordHasher()
+	require.Error(t, err)
+}
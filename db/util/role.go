@@ -0,0 +1,29 @@
+package util
+
+// Roles a user can have. Every new user is a customer; other roles are granted by an admin.
+const (
+	CustomerRole = "customer" // Manages their own accounts and transfers
+	TellerRole   = "teller"   // Serves customers at the counter: deposits, withdrawals and opening accounts
+	ApproverRole = "approver" // Approves or rejects transfers that need a second pair of eyes
+	AdminRole    = "admin"    // Runs the bank: account freezes, fees, reports, audit and roles
+	SystemRole   = "system"   // Owns the bank's own ledger accounts, nobody can log in with it
+)
+
+// Permissions granted to roles in the role_permissions table. Customers need none to manage their own accounts.
+const (
+	PermViewAnyAccount  = "accounts.view_any" // view any customer's accounts, balances and transfers
+	PermOpenAnyAccount  = "accounts.open_any" // open accounts for any customer
+	PermFreezeAccounts  = "accounts.freeze"   // freeze and unfreeze accounts
+	PermDepositCash     = "cash.deposit"      // deposit cash into any account
+	PermWithdrawAnyCash = "cash.withdraw_any" // withdraw cash from any account
+	PermReviewTransfers = "transfers.review"  // approve, reject and reverse transfers
+	PermManageFees      = "fees.manage"       // set and remove fee schedules and exchange rates
+	PermViewReports     = "reports.view"      // view the ledger reports
+	PermViewAudit       = "audit.view"        // search and export the audit log
+	PermAssignRoles     = "roles.assign"      // view roles and assign them to users
+	PermUnlockUsers     = "users.unlock"      // unlock users locked out after failed logins
+	PermExportUsers     = "users.export"      // export the personal data of any user
+	PermEraseUsers      = "users.erase"       // erase the personal data of deactivated users
+	PermReviewKYC       = "kyc.review"        // view, approve and reject the KYC profiles of users
+	PermInvestigateAML  = "aml.investigate"   // view AML alerts and work them as cases
+)
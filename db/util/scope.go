@@ -0,0 +1,23 @@
+package util
+
+// Scopes of API keys. A request made with an API key only reaches the routes of the key's scopes,
+// and there it can do no more than the key's owner could.
+const (
+	ScopeAccountsRead   = "accounts:read"   // view accounts and their balances
+	ScopeAccountsWrite  = "accounts:write"  // open and close accounts
+	ScopeTransfersRead  = "transfers:read"  // view transfers, batches and scheduled transfers, and quote fees
+	ScopeTransfersWrite = "transfers:write" // make transfers, batches and scheduled transfers
+	ScopeHoldsRead      = "holds:read"      // view holds
+	ScopeHoldsWrite     = "holds:write"     // place, capture and release holds
+	ScopeCashWrite      = "cash:write"      // deposit and withdraw cash
+)
+
+// IsSupportedScope returns true if API keys can be given the scope
+func IsSupportedScope(scope string) bool {
+	switch scope {
+	case ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersRead, ScopeTransfersWrite,
+		ScopeHoldsRead, ScopeHoldsWrite, ScopeCashWrite:
+		return true
+	}
+	return false
+}
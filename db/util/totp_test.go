@@ -0,0 +1,92 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 test key of RFC 6238, "12345678901234567890", in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// The test vectors of RFC 6238 appendix B, truncated to six digits
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, want, code, "at %d", unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := TOTPCode(secret, TOTPStep(now))
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTP(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(now), step)
+
+	// A code from the previous step is still accepted, one from two minutes ago is not
+	_, ok = ValidateTOTP(secret, code, now.Add(TOTPPeriod))
+	require.True(t, ok)
+	_, ok = ValidateTOTP(secret, code, now.Add(2*time.Minute))
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Go Bank Pro", "alice", rfc6238Secret)
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Go%20Bank%20Pro:alice?"))
+	require.Contains(t, uri, "secret="+rfc6238Secret)
+	require.Contains(t, uri, "issuer=Go+Bank+Pro")
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := NewRecoveryCode()
+	require.NoError(t, err)
+	require.Len(t, code, 19)
+	require.Equal(t, strings.ReplaceAll(code, "-", ""), NormalizeRecoveryCode(" "+strings.ToUpper(code)+" "))
+}
+
+func TestEncrypter(t *testing.T) {
+	encrypter, err := NewEncrypter(RandomString(32))
+	require.NoError(t, err)
+
+	plaintext := []byte(RandomString(20))
+	ciphertext, err := encrypter.Encrypt(plaintext, []byte("alice"))
+	require.NoError(t, err)
+	require.NotContains(t, string(ciphertext), string(plaintext))
+
+	decrypted, err := encrypter.Decrypt(ciphertext, []byte("alice"))
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// The ciphertext is tied to its associated data and key
+	_, err = encrypter.Decrypt(ciphertext, []byte("bob"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	other, err := NewEncrypter(RandomString(32))
+	require.NoError(t, err)
+	_, err = other.Decrypt(ciphertext, []byte("alice"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = NewEncrypter(RandomString(31))
+	require.Error(t, err)
+}
@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/suleimanodetoro/Go-Bank-Pro/aml"
 	"github.com/suleimanodetoro/Go-Bank-Pro/api"
 	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
 	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+	"github.com/suleimanodetoro/Go-Bank-Pro/worker"
 )
 
 // in order to create a server, we need to connect to the database and create a store
@@ -25,8 +34,79 @@ func main() {
 		log.Fatal("cannot connect to database", err)
 	}
 
-	store := db.NewStore(conn)
-	server := api.NewServer(store)
+	store, err := newStore(conn, config)
+	if err != nil {
+		log.Fatal("cannot create store:", err)
+	}
+
+	// Subcommands run a one-off job instead of starting the server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "interest-backfill":
+			backfillInterest(store, config, os.Args[2:])
+			return
+		case "verify-ledger":
+			verifyLedger(store, os.Args[2:])
+			return
+		case "bootstrap-admin":
+			bootstrapAdmin(store, config, os.Args[2:])
+			return
+		case "export-user":
+			exportUser(store, os.Args[2:])
+			return
+		case "erase-user":
+			eraseUser(store, os.Args[2:])
+			return
+		case "rotate-pii-keys":
+			rotatePIIKeys(store, os.Args[2:])
+			return
+		}
+	}
+
+	// run the scheduled transfer runner in the background alongside the HTTP server
+	runner := worker.NewScheduledTransferRunner(store, config)
+	go runner.Start(context.Background())
+
+	// cancel transfers that were not approved in time and release their held funds
+	expirer := worker.NewTransferApprovalExpirer(store, config)
+	go expirer.Start(context.Background())
+
+	// post pending transfers to their recipients in batches
+	settler := worker.NewTransferSettler(store, config)
+	go settler.Start(context.Background())
+
+	// release holds that were neither captured nor released before they expired
+	holdExpirer := worker.NewHoldExpirer(store, config)
+	go holdExpirer.Start(context.Background())
+
+	// accrue interest daily and post it at the end of each month
+	interestEngine := worker.NewInterestEngine(store, config)
+	go interestEngine.Start(context.Background())
+
+	// charge the monthly maintenance fee of each product once a month is over
+	feeCharger := worker.NewMaintenanceFeeCharger(store, config)
+	go feeCharger.Start(context.Background())
+
+	// snapshot every account's balance at the end of each day
+	snapshotter := worker.NewDailyBalanceSnapshotter(store, config)
+	go snapshotter.Start(context.Background())
+
+	// screen posted transfers against the AML rules and raise alerts for investigators.
+	// Every posted transfer is queued for screening, so the bank does not run without monitoring.
+	if config.AMLRulesFile == "" {
+		log.Fatal("AML_RULES_FILE is not set, transaction monitoring is required")
+	}
+	rules, err := aml.LoadRules(config.AMLRulesFile)
+	if err != nil {
+		log.Fatal("cannot load AML rules:", err)
+	}
+	monitor := worker.NewTransactionMonitor(store, rules, config)
+	go monitor.Start(context.Background())
+
+	server, err := api.NewServer(config, store)
+	if err != nil {
+		log.Fatal("cannot create server:", err)
+	}
 
 	err = server.Start(config.ServerAddress)
 	if err != nil {
@@ -35,3 +115,229 @@ func main() {
 	}
 
 }
+
+// newStore creates the store, encrypting users' names and emails when a master key is configured.
+func newStore(conn *sql.DB, config util.Config) (db.Store, error) {
+	masterKeys, err := config.PIIMasterKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(masterKeys) == 0 {
+		return db.NewStore(conn), nil
+	}
+	pii, err := db.NewPIICipher(masterKeys, config.PIIBlindIndexKey)
+	if err != nil {
+		return nil, err
+	}
+	return db.NewEncryptedStore(conn, pii), nil
+}
+
+// backfillInterest accrues, and posts at month ends, the interest of every day in a date range.
+// Days that were already run are skipped, so a backfill can be repeated or resumed.
+func backfillInterest(store db.Store, config util.Config, args []string) {
+	flags := flag.NewFlagSet("interest-backfill", flag.ExitOnError)
+	from := flags.String("from", "", "first day to run, as YYYY-MM-DD")
+	to := flags.String("to", "", "last day to run, as YYYY-MM-DD (default yesterday)")
+	flags.Parse(args)
+
+	fromDate, err := time.Parse(time.DateOnly, *from)
+	if err != nil {
+		log.Fatal("invalid -from date:", err)
+	}
+	toDate := time.Now().AddDate(0, 0, -1)
+	if *to != "" {
+		toDate, err = time.Parse(time.DateOnly, *to)
+		if err != nil {
+			log.Fatal("invalid -to date:", err)
+		}
+	}
+
+	engine := worker.NewInterestEngine(store, config)
+	run, err := engine.Backfill(context.Background(), fromDate, toDate)
+	log.Printf("interest backfill: %d accruals, %d postings", run.Accrued, run.Posted)
+	if err != nil {
+		log.Fatal("interest backfill failed:", err)
+	}
+}
+
+// verifyLedger walks the hash chains of the ledger entries and exits with status 1 at the first broken link.
+func verifyLedger(store db.Store, args []string) {
+	flags := flag.NewFlagSet("verify-ledger", flag.ExitOnError)
+	accountID := flags.Int64("account", 0, "verify only this account")
+	flags.Parse(args)
+
+	var result db.LedgerVerification
+	var err error
+	if *accountID != 0 {
+		result, err = db.VerifyAccountChain(context.Background(), store, *accountID, db.DefaultChainPageSize)
+	} else {
+		result, err = db.VerifyLedger(context.Background(), store, db.DefaultChainPageSize)
+	}
+	if err != nil {
+		log.Fatal("ledger verification failed:", err)
+	}
+
+	log.Printf("ledger verification: %d accounts, %d entries", result.Accounts, result.Entries)
+	if !result.Valid {
+		link := result.BrokenLink
+		log.Printf("broken link at entry %d of account %d: %s", link.EntryID, link.AccountID, link.Reason)
+		os.Exit(1)
+	}
+}
+
+// bootstrapAdmin makes the first admin, who can then assign roles through the API. An existing user is
+// promoted, otherwise the user is created. It refuses to run once there is an admin.
+func bootstrapAdmin(store db.Store, config util.Config, args []string) {
+	flags := flag.NewFlagSet("bootstrap-admin", flag.ExitOnError)
+	username := flags.String("username", "", "user to make admin")
+	fullName := flags.String("full-name", "", "full name, when the user is created")
+	email := flags.String("email", "", "email, when the user is created")
+	password := flags.String("password", "", "password, when the user is created")
+	flags.Parse(args)
+
+	if *username == "" {
+		log.Fatal("-username is required")
+	}
+
+	arg := db.CreateUserParams{
+		Username: *username,
+		FullName: *fullName,
+		Email:    *email,
+	}
+	// Without a password the user must exist already
+	if *password != "" {
+		if err := config.PasswordPolicy().Validate(*password); err != nil {
+			log.Fatal("invalid password:", err)
+		}
+		hasher, err := config.PasswordHasher()
+		if err != nil {
+			log.Fatal("cannot create password hasher:", err)
+		}
+		hashedPassword, err := hasher.Hash(*password)
+		if err != nil {
+			log.Fatal("cannot hash password:", err)
+		}
+		arg.HashedPassword = hashedPassword
+	}
+
+	result, err := store.BootstrapAdminTx(context.Background(), arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Fatalf("user %s does not exist, pass -password, -full-name and -email to create it", *username)
+	}
+	if err != nil {
+		log.Fatal("bootstrap admin failed:", err)
+	}
+
+	if result.Created {
+		log.Printf("bootstrap admin: created %s as admin", result.User.Username)
+	} else {
+		log.Printf("bootstrap admin: promoted %s to admin", result.User.Username)
+	}
+}
+
+// cliActor is the audit actor of the changes made by the subcommands, which run outside any request.
+func cliActor(command string) context.Context {
+	return db.WithAuditActor(context.Background(), db.AuditActor{
+		Username:  db.SystemUsername,
+		RequestID: "cli:" + command,
+	})
+}
+
+// exportUser writes everything the bank holds about a user to a file, as JSON or, for a .zip file, a ZIP
+// archive of JSON files. The export is recorded in the audit log.
+func exportUser(store db.Store, args []string) {
+	flags := flag.NewFlagSet("export-user", flag.ExitOnError)
+	username := flags.String("username", "", "user to export")
+	out := flags.String("out", "", "file to write, a ZIP archive when it ends in .zip (default <username>.json)")
+	flags.Parse(args)
+
+	if *username == "" {
+		log.Fatal("-username is required")
+	}
+	if *out == "" {
+		*out = *username + ".json"
+	}
+
+	export, err := store.ExportUserDataTx(cliActor("export-user"), *username)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Fatalf("user %s does not exist", *username)
+	}
+	if err != nil {
+		log.Fatal("export user failed:", err)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatal("cannot create export file:", err)
+	}
+	if strings.HasSuffix(*out, ".zip") {
+		err = export.WriteZip(file)
+	} else {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(export)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatal("cannot write export file:", err)
+	}
+
+	log.Printf("export user: wrote %d accounts, %d entries, %d transfers, %d sessions and %d audit events of %s to %s",
+		len(export.Accounts), len(export.Entries), len(export.Transfers), len(export.Sessions), len(export.AuditEvents), *username, *out)
+}
+
+// eraseUser pseudonymizes the personal data of a deactivated user, keeping their financial records.
+func eraseUser(store db.Store, args []string) {
+	flags := flag.NewFlagSet("erase-user", flag.ExitOnError)
+	username := flags.String("username", "", "deactivated user to erase")
+	flags.Parse(args)
+
+	if *username == "" {
+		log.Fatal("-username is required")
+	}
+
+	user, err := store.EraseUserTx(cliActor("erase-user"), db.EraseUserTxParams{Username: *username, Now: time.Now()})
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Fatalf("user %s does not exist", *username)
+	}
+	if err != nil {
+		log.Fatal("erase user failed:", err)
+	}
+
+	log.Printf("erase user: erased the personal data of %s", user.Username)
+}
+
+// rotatePIIKeys rotates the keys of users' names and emails while the bank is running. Data keys sealed
+// with an older master key of the key file are sealed with the current one, a new data key is created,
+// and users are sealed with it in batches, encrypting those still in plaintext.
+func rotatePIIKeys(store db.Store, args []string) {
+	flags := flag.NewFlagSet("rotate-pii-keys", flag.ExitOnError)
+	batchSize := flags.Int("batch-size", 100, "users sealed again per transaction")
+	flags.Parse(args)
+
+	ctx := cliActor("rotate-pii-keys")
+	rewrapped, err := store.RewrapDataKeys(ctx)
+	if err != nil {
+		log.Fatal("rewrap data keys failed:", err)
+	}
+	key, err := store.RotateDataKey(ctx)
+	if err != nil {
+		log.Fatal("rotate data key failed:", err)
+	}
+
+	var total int
+	for {
+		n, err := store.ReencryptUsersTx(ctx, int32(*batchSize))
+		if err != nil {
+			log.Fatal("re-encrypt users failed:", err)
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+	}
+
+	log.Printf("rotate pii keys: rewrapped %d data keys and sealed %d users with data key %d", rewrapped, total, key.ID)
+}
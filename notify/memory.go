@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryNotifier keeps the messages sent through it, for tests. It is safe for concurrent use.
+type MemoryNotifier struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryNotifier creates a notifier that keeps its messages in memory.
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+// Send keeps the message.
+func (notifier *MemoryNotifier) Send(ctx context.Context, msg Message) error {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	notifier.messages = append(notifier.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (notifier *MemoryNotifier) Messages() []Message {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	return append([]Message(nil), notifier.messages...)
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Notifier kinds that can be set in the config
+const (
+	KindLog  = "log"  // writes messages to the server log
+	KindFile = "file" // appends messages to a file as JSON lines
+	KindSMTP = "smtp" // emails messages through an SMTP server
+)
+
+// Message is a notification for a user, such as a password reset token.
+type Message struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Notifier delivers messages to users. The sinks here are meant for development;
+// an email or SMS gateway plugs in by implementing this interface.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New creates the notifier chosen in the config, the log notifier when none is set.
+func New(config util.Config) (Notifier, error) {
+	switch config.Notifier {
+	case "", KindLog:
+		return LogNotifier{}, nil
+	case KindFile:
+		if config.NotifierFile == "" {
+			return nil, fmt.Errorf("the %s notifier needs NOTIFIER_FILE", KindFile)
+		}
+		return NewFileNotifier(config.NotifierFile), nil
+	case KindSMTP:
+		return NewSMTPNotifier(config.SMTPAddress, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", config.Notifier)
+	}
+}
+
+// LogNotifier writes messages to the standard logger.
+type LogNotifier struct{}
+
+// Send logs the message.
+func (LogNotifier) Send(ctx context.Context, msg Message) error {
+	log.Printf("notification to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileNotifier appends messages to a file, one JSON object per line. It is safe for concurrent use.
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier creates a notifier writing to the file at path, which is created if needed.
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Send appends the message to the file.
+func (notifier *FileNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	file, err := os.OpenFile(notifier.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open notification file: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot write notification: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier, err := New(util.Config{Notifier: KindFile, NotifierFile: path})
+	require.NoError(t, err)
+
+	messages := []Message{
+		{To: util.RandomEmail(), Subject: "first", Body: util.RandomString(20)},
+		{To: util.RandomEmail(), Subject: "second", Body: util.RandomString(20)},
+	}
+	for _, msg := range messages {
+		require.NoError(t, notifier.Send(context.Background(), msg))
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for _, want := range messages {
+		require.True(t, scanner.Scan())
+
+		var got Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &got))
+		require.Equal(t, want.To, got.To)
+		require.Equal(t, want.Subject, got.Subject)
+		require.Equal(t, want.Body, got.Body)
+		require.NotZero(t, got.SentAt)
+	}
+	require.False(t, scanner.Scan())
+}
+
+func TestNewNotifier(t *testing.T) {
+	notifier, err := New(util.Config{})
+	require.NoError(t, err)
+	require.IsType(t, LogNotifier{}, notifier)
+
+	_, err = New(util.Config{Notifier: KindFile})
+	require.Error(t, err)
+
+	_, err = New(util.Config{Notifier: "pigeon"})
+	require.Error(t, err)
+}
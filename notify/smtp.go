@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier sends messages as plain text emails through an SMTP server.
+// Locally it can point at a mail catcher such as mailpit, which needs no credentials.
+type SMTPNotifier struct {
+	address string
+	from    string
+	auth    smtp.Auth
+}
+
+// NewSMTPNotifier creates a notifier sending from the given address through the server at address (host:port).
+// PLAIN authentication is used when a username is set, which net/smtp only allows over TLS or to localhost.
+func NewSMTPNotifier(address, username, password, from string) (*SMTPNotifier, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP address: %w", err)
+	}
+	if from == "" {
+		return nil, errors.New("the SMTP notifier needs a sender address")
+	}
+
+	notifier := &SMTPNotifier{address: address, from: from}
+	if username != "" {
+		notifier.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return notifier, nil
+}
+
+// Send emails the message to its recipient.
+func (notifier *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	mail, err := formatMail(notifier.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(notifier.address, notifier.auth, notifier.from, []string{msg.To}, mail); err != nil {
+		return fmt.Errorf("cannot send email: %w", err)
+	}
+	return nil
+}
+
+// formatMail returns the message as a plain text email. Header values with line breaks are
+// rejected, so that a crafted address or subject cannot add headers or recipients.
+func formatMail(from string, msg Message) ([]byte, error) {
+	for _, value := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, errors.New("email header contains a line break")
+		}
+	}
+
+	sentAt := msg.SentAt
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+
+	var mail bytes.Buffer
+	fmt.Fprintf(&mail, "From: %s\r\n", from)
+	fmt.Fprintf(&mail, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&mail, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&mail, "Date: %s\r\n", sentAt.Format(time.RFC1123Z))
+	mail.WriteString("MIME-Version: 1.0\r\n")
+	mail.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	mail.WriteString("\r\n")
+	mail.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	mail.WriteString("\r\n")
+	return mail.Bytes(), nil
+}
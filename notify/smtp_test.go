@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestFormatMail(t *testing.T) {
+	msg := Message{
+		To:      util.RandomEmail(),
+		Subject: "Verify your email",
+		Body:    "first line\nsecond line",
+		SentAt:  time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	mail, err := formatMail("no-reply@bank.local", msg)
+	require.NoError(t, err)
+
+	headers, body, found := strings.Cut(string(mail), "\r\n\r\n")
+	require.True(t, found)
+	require.Contains(t, headers, "From: no-reply@bank.local\r\n")
+	require.Contains(t, headers, "To: "+msg.To+"\r\n")
+	require.Contains(t, headers, "Subject: Verify your email\r\n")
+	require.Contains(t, headers, "Date: Fri, 01 Mar 2024 12:00:00 +0000")
+	require.Equal(t, "first line\r\nsecond line\r\n", body)
+}
+
+func TestFormatMailHeaderInjection(t *testing.T) {
+	msg := Message{To: "victim@email.com\r\nBcc: everyone@email.com", Subject: "hello"}
+	_, err := formatMail("no-reply@bank.local", msg)
+	require.Error(t, err)
+
+	msg = Message{To: util.RandomEmail(), Subject: "hello\nBcc: everyone@email.com"}
+	_, err = formatMail("no-reply@bank.local", msg)
+	require.Error(t, err)
+}
+
+func TestNewSMTPNotifier(t *testing.T) {
+	_, err := New(util.Config{Notifier: KindSMTP, SMTPAddress: "localhost:1025", MailFrom: "no-reply@bank.local"})
+	require.NoError(t, err)
+
+	_, err = New(util.Config{Notifier: KindSMTP, SMTPAddress: "localhost", MailFrom: "no-reply@bank.local"})
+	require.Error(t, err)
+
+	_, err = New(util.Config{Notifier: KindSMTP, SMTPAddress: "localhost:1025"})
+	require.Error(t, err)
+}
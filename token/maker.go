@@ -0,0 +1,13 @@
+package token
+
+import "time"
+
+// Maker is an interface for managing tokens.
+// It lets the API swap the token implementation without touching the handlers.
+type Maker interface {
+	// CreateToken creates a new token for a specific username, role and duration
+	CreateToken(username string, role string, duration time.Duration) (string, *Payload, error)
+
+	// VerifyToken checks if the token is valid or not, and returns its payload
+	VerifyToken(token string) (*Payload, error)
+}
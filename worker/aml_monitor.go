@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/suleimanodetoro/Go-Bank-Pro/aml"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Defaults used when transaction monitoring is not configured
+const (
+	defaultAMLMonitorInterval  = 10 * time.Second
+	defaultAMLMonitorBatchSize = 100
+)
+
+// amlScreeningMaxAttempts is how many times the screening of a transfer can fail before the monitor gives up on
+// it. The transfer stays queued with its last error, for someone to look into.
+const amlScreeningMaxAttempts = 5
+
+// TransactionMonitor screens posted transfers against the AML rules, after the fact so transfers are not
+// slowed down. Posted transfers are queued by the database and taken off the queue with their alerts in
+// Store.RecordAMLScreeningTx, so several monitors can run against the same database.
+type TransactionMonitor struct {
+	store     db.Store
+	engine    *aml.Engine
+	interval  time.Duration
+	batchSize int32
+}
+
+// NewTransactionMonitor creates a monitor evaluating the rules, using the monitoring settings from the config.
+func NewTransactionMonitor(store db.Store, rules []aml.Rule, config util.Config) *TransactionMonitor {
+	monitor := &TransactionMonitor{
+		store:     store,
+		engine:    aml.NewEngine(store, rules),
+		interval:  config.AMLMonitorInterval,
+		batchSize: config.AMLMonitorBatchSize,
+	}
+
+	if monitor.interval <= 0 {
+		monitor.interval = defaultAMLMonitorInterval
+	}
+	if monitor.batchSize <= 0 {
+		monitor.batchSize = defaultAMLMonitorBatchSize
+	}
+
+	return monitor
+}
+
+// Start runs the monitoring loop until the context is cancelled.
+func (monitor *TransactionMonitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(monitor.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := monitor.ScreenQueued(ctx); err != nil {
+			log.Printf("aml monitoring: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScreenQueued screens batches of queued transfers until none are left and returns how many were screened.
+// A transfer that cannot be evaluated stays queued with the failure recorded, to be tried again on the next
+// run, and does not hold up the transfers behind it.
+func (monitor *TransactionMonitor) ScreenQueued(ctx context.Context) (int, error) {
+	screened := 0
+	var afterID int64
+	for {
+		transfers, err := monitor.store.ListQueuedAMLScreenings(ctx, db.ListQueuedAMLScreeningsParams{
+			AfterID:     afterID,
+			MaxAttempts: amlScreeningMaxAttempts,
+			Limit:       monitor.batchSize,
+		})
+		if err != nil {
+			return screened, err
+		}
+
+		for _, transfer := range transfers {
+			afterID = transfer.ID
+			params, err := monitor.engine.Evaluate(ctx, transfer)
+			if err != nil {
+				log.Printf("aml monitoring: cannot screen transfer %d: %v", transfer.ID, err)
+				err = monitor.store.RecordAMLScreeningFailure(ctx, db.RecordAMLScreeningFailureParams{
+					LastError:  err.Error(),
+					TransferID: transfer.ID,
+				})
+				if err != nil {
+					return screened, err
+				}
+				continue
+			}
+
+			alerts, err := monitor.store.RecordAMLScreeningTx(ctx, db.RecordAMLScreeningTxParams{
+				TransferID: transfer.ID,
+				Alerts:     params,
+			})
+			if err != nil {
+				return screened, err
+			}
+			for _, alert := range alerts {
+				log.Printf("aml alert %d: rule %s raised on transfer %d of %s", alert.ID, alert.Rule, alert.TransferID, alert.Owner)
+			}
+			screened++
+		}
+
+		// A short batch means there was nothing else queued
+		if len(transfers) < int(monitor.batchSize) {
+			return screened, nil
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/suleimanodetoro/Go-Bank-Pro/aml"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestTransactionMonitorScreenQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	rules := []aml.Rule{{Name: "large", Type: aml.RuleLargeTransfer, Severity: db.AMLSeverityHigh, MinAmount: 1000}}
+	monitor := NewTransactionMonitor(store, rules, util.Config{AMLMonitorBatchSize: 2})
+
+	customer := db.GetAMLAccountRow{ID: 1, Owner: util.RandomOwner(), Currency: util.USD}
+	other := db.GetAMLAccountRow{ID: 2, Owner: util.RandomOwner(), Currency: util.USD}
+	store.EXPECT().GetAMLAccount(gomock.Any(), gomock.Eq(customer.ID)).AnyTimes().Return(customer, nil)
+	store.EXPECT().GetAMLAccount(gomock.Any(), gomock.Eq(other.ID)).AnyTimes().Return(other, nil)
+
+	large := db.Transfer{ID: 1, FromAccountID: customer.ID, ToAccountID: other.ID, Amount: 5000}
+	small := db.Transfer{ID: 2, FromAccountID: customer.ID, ToAccountID: other.ID, Amount: 10}
+	last := db.Transfer{ID: 3, FromAccountID: other.ID, ToAccountID: customer.ID, Amount: 20}
+
+	// a full batch is followed by another one, a short batch ends the run
+	gomock.InOrder(
+		store.EXPECT().
+			ListQueuedAMLScreenings(gomock.Any(), gomock.Eq(db.ListQueuedAMLScreeningsParams{AfterID: 0, MaxAttempts: amlScreeningMaxAttempts, Limit: 2})).
+			Return([]db.Transfer{large, small}, nil),
+		store.EXPECT().
+			ListQueuedAMLScreenings(gomock.Any(), gomock.Eq(db.ListQueuedAMLScreeningsParams{AfterID: small.ID, MaxAttempts: amlScreeningMaxAttempts, Limit: 2})).
+			Return([]db.Transfer{last}, nil),
+	)
+	store.EXPECT().
+		RecordAMLScreeningTx(gomock.Any(), gomock.Any()).
+		Times(3).
+		DoAndReturn(func(_ context.Context, arg db.RecordAMLScreeningTxParams) ([]db.AmlAlert, error) {
+			if arg.TransferID != large.ID {
+				require.Empty(t, arg.Alerts)
+				return []db.AmlAlert{}, nil
+			}
+			require.Len(t, arg.Alerts, 1)
+			require.Equal(t, customer.Owner, arg.Alerts[0].Owner)
+			require.Equal(t, "large", arg.Alerts[0].Rule)
+			return []db.AmlAlert{{ID: 1, TransferID: large.ID, Rule: "large", Owner: customer.Owner}}, nil
+		})
+
+	screened, err := monitor.ScreenQueued(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, screened)
+}
+
+func TestTransactionMonitorScreenQueuedError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	monitor := NewTransactionMonitor(store, nil, util.Config{})
+
+	customer := db.GetAMLAccountRow{ID: 1, Owner: util.RandomOwner(), Currency: util.USD}
+	other := db.GetAMLAccountRow{ID: 2, Owner: util.RandomOwner(), Currency: util.USD}
+	failing := db.Transfer{ID: 1, FromAccountID: 99, ToAccountID: other.ID, Amount: 10}
+	next := db.Transfer{ID: 2, FromAccountID: customer.ID, ToAccountID: other.ID, Amount: 10}
+	store.EXPECT().ListQueuedAMLScreenings(gomock.Any(), gomock.Any()).Times(1).Return([]db.Transfer{failing, next}, nil)
+	store.EXPECT().GetAMLAccount(gomock.Any(), gomock.Eq(int64(99))).Times(1).Return(db.GetAMLAccountRow{}, sql.ErrConnDone)
+	store.EXPECT().GetAMLAccount(gomock.Any(), gomock.Eq(customer.ID)).AnyTimes().Return(customer, nil)
+	store.EXPECT().GetAMLAccount(gomock.Any(), gomock.Eq(other.ID)).AnyTimes().Return(other, nil)
+
+	// the failing transfer stays queued with the failure counted, and the one behind it is still screened
+	store.EXPECT().
+		RecordAMLScreeningFailure(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordAMLScreeningFailureParams) error {
+			require.Equal(t, failing.ID, arg.TransferID)
+			require.Contains(t, arg.LastError, sql.ErrConnDone.Error())
+			return nil
+		})
+	store.EXPECT().
+		RecordAMLScreeningTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordAMLScreeningTxParams) ([]db.AmlAlert, error) {
+			require.Equal(t, next.ID, arg.TransferID)
+			return []db.AmlAlert{}, nil
+		})
+
+	screened, err := monitor.ScreenQueued(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, screened)
+}
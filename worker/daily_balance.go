@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Defaults used when the end-of-day balance job is not configured
+const (
+	defaultDailyBalanceInterval  = time.Hour
+	defaultDailyBalanceBatchSize = 500
+)
+
+// DailyBalanceSnapshotter records the balance of every account at the end of each day.
+// Point-in-time balances start from these snapshots instead of summing every entry.
+type DailyBalanceSnapshotter struct {
+	store     db.Store
+	interval  time.Duration
+	batchSize int32
+}
+
+// NewDailyBalanceSnapshotter creates a snapshotter using the daily balance settings from the config.
+func NewDailyBalanceSnapshotter(store db.Store, config util.Config) *DailyBalanceSnapshotter {
+	snapshotter := &DailyBalanceSnapshotter{
+		store:     store,
+		interval:  config.DailyBalanceInterval,
+		batchSize: config.DailyBalanceBatchSize,
+	}
+
+	if snapshotter.interval <= 0 {
+		snapshotter.interval = defaultDailyBalanceInterval
+	}
+	if snapshotter.batchSize <= 0 {
+		snapshotter.batchSize = defaultDailyBalanceBatchSize
+	}
+
+	return snapshotter
+}
+
+// Start runs the snapshot loop until the context is cancelled. Each run snapshots the last complete day,
+// which does nothing for accounts already snapshotted that day.
+func (snapshotter *DailyBalanceSnapshotter) Start(ctx context.Context) {
+	ticker := time.NewTicker(snapshotter.interval)
+	defer ticker.Stop()
+
+	for {
+		yesterday := db.InterestDate(time.Now()).AddDate(0, 0, -1)
+		if _, err := snapshotter.SnapshotDay(ctx, yesterday); err != nil {
+			log.Printf("daily balances: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SnapshotDay records the end-of-day balance of every account opened by the end of the given day,
+// and returns how many snapshots were taken.
+func (snapshotter *DailyBalanceSnapshotter) SnapshotDay(ctx context.Context, date time.Time) (int, error) {
+	date = db.InterestDate(date)
+	arg := db.ListAccountIDsOpenedBeforeParams{
+		OpenedBefore: date.AddDate(0, 0, 1),
+		Limit:        snapshotter.batchSize,
+	}
+
+	taken := 0
+	for {
+		ids, err := snapshotter.store.ListAccountIDsOpenedBefore(ctx, arg)
+		if err != nil {
+			return taken, err
+		}
+
+		for _, id := range ids {
+			_, ok, err := db.SnapshotDailyBalance(ctx, snapshotter.store, id, date)
+			if err != nil {
+				return taken, fmt.Errorf("snapshot balance of account %d: %w", id, err)
+			}
+			if ok {
+				taken++
+			}
+		}
+
+		// A short page means there are no accounts left
+		if len(ids) < int(snapshotter.batchSize) {
+			return taken, nil
+		}
+		arg.AfterID = ids[len(ids)-1]
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestDailyBalanceSnapshotterSnapshotDay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	snapshotter := NewDailyBalanceSnapshotter(store, util.Config{DailyBalanceBatchSize: 2})
+
+	date := time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)
+	dayEnd := date.AddDate(0, 0, 1)
+	first := db.ListAccountIDsOpenedBeforeParams{OpenedBefore: dayEnd, Limit: 2}
+	second := first
+	second.AfterID = 2
+
+	// a full page is followed by another one; an account already snapshotted that day does not count
+	gomock.InOrder(
+		store.EXPECT().ListAccountIDsOpenedBefore(gomock.Any(), gomock.Eq(first)).Return([]int64{1, 2}, nil),
+		store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Eq(db.GetAccountBalanceAtParams{At: dayEnd, AccountID: 1})).Return(int64(150), nil),
+		store.EXPECT().CreateDailyBalance(gomock.Any(), gomock.Eq(db.CreateDailyBalanceParams{AccountID: 1, BalanceDate: date, Balance: 150})).Return(db.DailyBalance{AccountID: 1}, nil),
+		store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Eq(db.GetAccountBalanceAtParams{At: dayEnd, AccountID: 2})).Return(int64(0), nil),
+		store.EXPECT().CreateDailyBalance(gomock.Any(), gomock.Eq(db.CreateDailyBalanceParams{AccountID: 2, BalanceDate: date})).Return(db.DailyBalance{}, sql.ErrNoRows),
+		store.EXPECT().ListAccountIDsOpenedBefore(gomock.Any(), gomock.Eq(second)).Return([]int64{}, nil),
+	)
+
+	taken, err := snapshotter.SnapshotDay(context.Background(), date.Add(23*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, taken)
+}
+
+func TestDailyBalanceSnapshotterSnapshotDayError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	snapshotter := NewDailyBalanceSnapshotter(store, util.Config{})
+
+	store.EXPECT().
+		ListAccountIDsOpenedBefore(gomock.Any(), gomock.Any()).
+		Return([]int64{1}, nil)
+	store.EXPECT().
+		GetAccountBalanceAt(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(int64(0), sql.ErrConnDone)
+	store.EXPECT().
+		CreateDailyBalance(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	taken, err := snapshotter.SnapshotDay(context.Background(), time.Now())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Zero(t, taken)
+}
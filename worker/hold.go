@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// defaultHoldExpiryInterval is used when the hold expiry interval is not configured
+const defaultHoldExpiryInterval = time.Minute
+
+// HoldExpirer releases holds that were neither captured nor released before they expired.
+// Several expirers can safely run against the same database.
+type HoldExpirer struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewHoldExpirer creates an expirer using the hold settings from the config.
+func NewHoldExpirer(store db.Store, config util.Config) *HoldExpirer {
+	expirer := &HoldExpirer{
+		store:    store,
+		interval: config.HoldExpiryInterval,
+	}
+
+	if expirer.interval <= 0 {
+		expirer.interval = defaultHoldExpiryInterval
+	}
+
+	return expirer
+}
+
+// Start runs the expiry loop until the context is cancelled.
+func (expirer *HoldExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(expirer.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := expirer.ExpireDue(ctx, time.Now()); err != nil {
+			log.Printf("holds: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireDue releases every hold that expired at or before the given time and returns how many were released.
+func (expirer *HoldExpirer) ExpireDue(ctx context.Context, now time.Time) (int, error) {
+	expired := 0
+	for {
+		result, err := expirer.store.ExpireHoldTx(ctx, now)
+		if err != nil {
+			// Nothing left to do until the next tick
+			if errors.Is(err, sql.ErrNoRows) {
+				return expired, nil
+			}
+			return expired, err
+		}
+		expired++
+
+		log.Printf("hold %d expired, released %d on account %d", result.Hold.ID, result.Hold.Amount, result.Hold.AccountID)
+	}
+}
@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestHoldExpirerExpireDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expirer := NewHoldExpirer(store, util.Config{})
+
+	now := time.Now()
+	expired := db.HoldTxResult{
+		Hold: db.Hold{ID: 1, Status: db.HoldExpired},
+	}
+
+	// two holds are released, then the store reports that nothing else has expired
+	gomock.InOrder(
+		store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(now)).Return(expired, nil),
+		store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(now)).Return(expired, nil),
+		store.EXPECT().ExpireHoldTx(gomock.Any(), gomock.Eq(now)).Return(db.HoldTxResult{}, sql.ErrNoRows),
+	)
+
+	count, err := expirer.ExpireDue(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
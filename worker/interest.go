@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Defaults used when the interest engine is not configured
+const (
+	defaultInterestInterval  = time.Hour
+	defaultInterestBatchSize = 100
+)
+
+// InterestEngine accrues daily interest on interest-bearing accounts and posts it at the end of each month.
+// Both steps are idempotent per account and date, so a day can be run again, or backfilled, safely.
+type InterestEngine struct {
+	store     db.Store
+	interval  time.Duration
+	batchSize int32
+}
+
+// NewInterestEngine creates an engine using the interest settings from the config.
+func NewInterestEngine(store db.Store, config util.Config) *InterestEngine {
+	engine := &InterestEngine{
+		store:     store,
+		interval:  config.InterestInterval,
+		batchSize: config.InterestBatchSize,
+	}
+
+	if engine.interval <= 0 {
+		engine.interval = defaultInterestInterval
+	}
+	if engine.batchSize <= 0 {
+		engine.batchSize = defaultInterestBatchSize
+	}
+
+	return engine
+}
+
+// Start runs the interest loop until the context is cancelled. Each run handles the last complete day,
+// which does nothing when that day was already run.
+func (engine *InterestEngine) Start(ctx context.Context) {
+	ticker := time.NewTicker(engine.interval)
+	defer ticker.Stop()
+
+	for {
+		yesterday := db.InterestDate(time.Now()).AddDate(0, 0, -1)
+		if _, err := engine.RunDay(ctx, yesterday); err != nil {
+			log.Printf("interest: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Backfill runs every day from one date to another, both included, in order.
+func (engine *InterestEngine) Backfill(ctx context.Context, from, to time.Time) (InterestRun, error) {
+	var total InterestRun
+	for date := db.InterestDate(from); !date.After(db.InterestDate(to)); date = date.AddDate(0, 0, 1) {
+		run, err := engine.RunDay(ctx, date)
+		total.Accrued += run.Accrued
+		total.Posted += run.Posted
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", date.Format(time.DateOnly), err)
+		}
+	}
+	return total, nil
+}
+
+// InterestRun counts the accruals and postings recorded by a run.
+type InterestRun struct {
+	Accrued int `json:"accrued"`
+	Posted  int `json:"posted"`
+}
+
+// RunDay accrues one day of interest on every interest-bearing account opened before the end of that day.
+// On the last day of a month, the interest accrued during the month is posted as well.
+func (engine *InterestEngine) RunDay(ctx context.Context, date time.Time) (InterestRun, error) {
+	var run InterestRun
+	date = db.InterestDate(date)
+	monthEnd := date.AddDate(0, 0, 1).Day() == 1
+
+	arg := db.ListInterestBearingAccountsParams{
+		OpenedBefore: date.AddDate(0, 0, 1),
+		Limit:        engine.batchSize,
+	}
+
+	for {
+		accounts, err := engine.store.ListInterestBearingAccounts(ctx, arg)
+		if err != nil {
+			return run, err
+		}
+
+		for _, account := range accounts {
+			accrual, err := engine.store.AccrueInterestTx(ctx, db.AccrueInterestTxParams{
+				AccountID: account.ID,
+				Date:      date,
+			})
+			if err != nil {
+				return run, fmt.Errorf("accrue interest on account %d: %w", account.ID, err)
+			}
+			if accrual.Accrued {
+				run.Accrued++
+			}
+
+			if !monthEnd {
+				continue
+			}
+
+			posting, err := engine.store.PostInterestTx(ctx, db.PostInterestTxParams{
+				AccountID: account.ID,
+				PeriodEnd: date,
+			})
+			if err != nil {
+				return run, fmt.Errorf("post interest on account %d: %w", account.ID, err)
+			}
+			if posting.Posted {
+				run.Posted++
+			}
+		}
+
+		// A short page means there are no accounts left
+		if len(accounts) < int(engine.batchSize) {
+			return run, nil
+		}
+		arg.AfterID = accounts[len(accounts)-1].ID
+	}
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestInterestEngineRunDay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	engine := NewInterestEngine(store, util.Config{InterestBatchSize: 2})
+
+	date := time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC)
+	first := db.ListInterestBearingAccountsParams{OpenedBefore: date.AddDate(0, 0, 1), Limit: 2}
+	second := first
+	second.AfterID = 2
+
+	// a full page is followed by another one, a day in the middle of the month posts nothing
+	gomock.InOrder(
+		store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Eq(first)).Return([]db.Account{{ID: 1}, {ID: 2}}, nil),
+		store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 1, Date: date})).Return(db.AccrueInterestTxResult{Accrued: true}, nil),
+		store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 2, Date: date})).Return(db.AccrueInterestTxResult{}, nil),
+		store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Eq(second)).Return([]db.Account{{ID: 3}}, nil),
+		store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 3, Date: date})).Return(db.AccrueInterestTxResult{Accrued: true}, nil),
+	)
+	store.EXPECT().PostInterestTx(gomock.Any(), gomock.Any()).Times(0)
+
+	run, err := engine.RunDay(context.Background(), date.Add(15*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, InterestRun{Accrued: 2}, run)
+}
+
+func TestInterestEngineRunDayMonthEnd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	engine := NewInterestEngine(store, util.Config{})
+
+	date := time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+
+	gomock.InOrder(
+		store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Any()).Return([]db.Account{{ID: 1}}, nil),
+		store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 1, Date: date})).Return(db.AccrueInterestTxResult{Accrued: true}, nil),
+		store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 1, PeriodEnd: date})).Return(db.PostInterestTxResult{Posted: true}, nil),
+	)
+
+	run, err := engine.RunDay(context.Background(), date)
+	require.NoError(t, err)
+	require.Equal(t, InterestRun{Accrued: 1, Posted: 1}, run)
+}
+
+func TestInterestEngineBackfill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	engine := NewInterestEngine(store, util.Config{})
+
+	from := time.Date(2024, time.January, 30, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+
+	// every day of the range is accrued once, and January is posted on its last day
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Any()).Times(3).Return([]db.Account{{ID: 1}}, nil)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		store.EXPECT().AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 1, Date: date})).Return(db.AccrueInterestTxResult{Accrued: true}, nil)
+	}
+	store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 1, PeriodEnd: from.AddDate(0, 0, 1)})).Return(db.PostInterestTxResult{Posted: true}, nil)
+
+	run, err := engine.Backfill(context.Background(), from, to)
+	require.NoError(t, err)
+	require.Equal(t, InterestRun{Accrued: 3, Posted: 1}, run)
+}
+
+func TestInterestEngineBackfillError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	engine := NewInterestEngine(store, util.Config{})
+
+	date := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	// the backfill stops at the first day that fails
+	store.EXPECT().
+		ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+
+	run, err := engine.Backfill(context.Background(), date, date.AddDate(0, 0, 5))
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Zero(t, run)
+}
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Defaults used when maintenance fees are not configured
+const (
+	defaultMaintenanceFeeInterval  = time.Hour
+	defaultMaintenanceFeeBatchSize = 100
+)
+
+// MaintenanceFeeCharger charges the monthly fee of their product to active accounts once a month is over.
+// Each account is charged at most once per month, so the charger can run as often as needed.
+type MaintenanceFeeCharger struct {
+	store     db.Store
+	interval  time.Duration
+	batchSize int32
+}
+
+// NewMaintenanceFeeCharger creates a charger using the maintenance fee settings from the config.
+func NewMaintenanceFeeCharger(store db.Store, config util.Config) *MaintenanceFeeCharger {
+	charger := &MaintenanceFeeCharger{
+		store:     store,
+		interval:  config.MaintenanceFeeInterval,
+		batchSize: config.MaintenanceFeeBatchSize,
+	}
+
+	if charger.interval <= 0 {
+		charger.interval = defaultMaintenanceFeeInterval
+	}
+	if charger.batchSize <= 0 {
+		charger.batchSize = defaultMaintenanceFeeBatchSize
+	}
+
+	return charger
+}
+
+// Start runs the charging loop until the context is cancelled. Each run charges the last complete month.
+func (charger *MaintenanceFeeCharger) Start(ctx context.Context) {
+	ticker := time.NewTicker(charger.interval)
+	defer ticker.Stop()
+
+	for {
+		today := db.InterestDate(time.Now())
+		lastMonthEnd := today.AddDate(0, 0, -today.Day())
+		if _, err := charger.ChargeMonth(ctx, lastMonthEnd); err != nil {
+			log.Printf("maintenance fees: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ChargeMonth charges the maintenance fee for the month ending on periodEnd to every active account
+// opened before the end of that month, and returns how many accounts were charged.
+func (charger *MaintenanceFeeCharger) ChargeMonth(ctx context.Context, periodEnd time.Time) (int, error) {
+	periodEnd = db.InterestDate(periodEnd)
+	arg := db.ListMaintenanceFeeAccountsParams{
+		OpenedBefore: periodEnd.AddDate(0, 0, 1),
+		Limit:        charger.batchSize,
+	}
+
+	charged := 0
+	for {
+		accounts, err := charger.store.ListMaintenanceFeeAccounts(ctx, arg)
+		if err != nil {
+			return charged, err
+		}
+
+		for _, account := range accounts {
+			result, err := charger.store.ChargeMaintenanceFeeTx(ctx, db.ChargeMaintenanceFeeTxParams{
+				AccountID: account.ID,
+				PeriodEnd: periodEnd,
+			})
+			if err != nil {
+				return charged, fmt.Errorf("charge maintenance fee to account %d: %w", account.ID, err)
+			}
+			if result.Charged {
+				charged++
+			}
+		}
+
+		// A short page means there are no accounts left
+		if len(accounts) < int(charger.batchSize) {
+			return charged, nil
+		}
+		arg.AfterID = accounts[len(accounts)-1].ID
+	}
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestMaintenanceFeeChargerChargeMonth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	charger := NewMaintenanceFeeCharger(store, util.Config{MaintenanceFeeBatchSize: 2})
+
+	periodEnd := time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC)
+	first := db.ListMaintenanceFeeAccountsParams{OpenedBefore: periodEnd.AddDate(0, 0, 1), Limit: 2}
+	second := first
+	second.AfterID = 2
+
+	// a full page is followed by another one; an account already charged for the month does not count
+	gomock.InOrder(
+		store.EXPECT().ListMaintenanceFeeAccounts(gomock.Any(), gomock.Eq(first)).Return([]db.Account{{ID: 1}, {ID: 2}}, nil),
+		store.EXPECT().ChargeMaintenanceFeeTx(gomock.Any(), gomock.Eq(db.ChargeMaintenanceFeeTxParams{AccountID: 1, PeriodEnd: periodEnd})).Return(db.ChargeMaintenanceFeeTxResult{Charged: true}, nil),
+		store.EXPECT().ChargeMaintenanceFeeTx(gomock.Any(), gomock.Eq(db.ChargeMaintenanceFeeTxParams{AccountID: 2, PeriodEnd: periodEnd})).Return(db.ChargeMaintenanceFeeTxResult{}, nil),
+		store.EXPECT().ListMaintenanceFeeAccounts(gomock.Any(), gomock.Eq(second)).Return([]db.Account{}, nil),
+	)
+
+	charged, err := charger.ChargeMonth(context.Background(), periodEnd.Add(10*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, charged)
+}
+
+func TestMaintenanceFeeChargerChargeMonthError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	charger := NewMaintenanceFeeCharger(store, util.Config{})
+
+	store.EXPECT().
+		ListMaintenanceFeeAccounts(gomock.Any(), gomock.Any()).
+		Return([]db.Account{{ID: 1}}, nil)
+	store.EXPECT().
+		ChargeMaintenanceFeeTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.ChargeMaintenanceFeeTxResult{}, sql.ErrConnDone)
+
+	charged, err := charger.ChargeMonth(context.Background(), time.Now())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Zero(t, charged)
+}
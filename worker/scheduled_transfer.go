@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Defaults used when the scheduler is not configured
+const (
+	defaultScheduledTransferInterval    = time.Minute
+	defaultScheduledTransferMaxFailures = 3
+	defaultScheduledTransferRetryDelay  = time.Hour
+)
+
+// ScheduledTransferRunner executes scheduled transfers (standing orders) once they are due.
+// Several runners can work against the same database at the same time: every due row is claimed
+// with FOR UPDATE SKIP LOCKED inside Store.ExecuteScheduledTransferTx, so a run is never executed twice.
+type ScheduledTransferRunner struct {
+	store             db.Store
+	interval          time.Duration
+	maxFailures       int32
+	retryDelay        time.Duration
+	approvalThreshold int64 // scheduled transfers are not reviewed, so those above it are not executed
+}
+
+// NewScheduledTransferRunner creates a runner using the scheduler settings from the config.
+func NewScheduledTransferRunner(store db.Store, config util.Config) *ScheduledTransferRunner {
+	runner := &ScheduledTransferRunner{
+		store:             store,
+		interval:          config.ScheduledTransferInterval,
+		maxFailures:       config.ScheduledTransferMaxFailures,
+		retryDelay:        config.ScheduledTransferRetryDelay,
+		approvalThreshold: config.TransferApprovalThreshold,
+	}
+
+	if runner.interval <= 0 {
+		runner.interval = defaultScheduledTransferInterval
+	}
+	if runner.maxFailures <= 0 {
+		runner.maxFailures = defaultScheduledTransferMaxFailures
+	}
+	// A positive delay is required, otherwise a failing transfer would be picked again straight away
+	if runner.retryDelay <= 0 {
+		runner.retryDelay = defaultScheduledTransferRetryDelay
+	}
+
+	return runner
+}
+
+// Start runs the scheduler loop until the context is cancelled.
+func (runner *ScheduledTransferRunner) Start(ctx context.Context) {
+	ticker := time.NewTicker(runner.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := runner.RunDue(ctx, time.Now()); err != nil {
+			log.Printf("scheduled transfers: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue executes every scheduled transfer that is due at the given time
+// and returns how many executions were attempted.
+func (runner *ScheduledTransferRunner) RunDue(ctx context.Context, now time.Time) (int, error) {
+	arg := db.ExecuteScheduledTransferTxParams{
+		Now:               now,
+		MaxFailures:       runner.maxFailures,
+		RetryDelay:        runner.retryDelay,
+		ApprovalThreshold: runner.approvalThreshold,
+	}
+
+	executed := 0
+	for {
+		result, err := runner.store.ExecuteScheduledTransferTx(ctx, arg)
+		if err != nil {
+			// Nothing left to do until the next tick
+			if errors.Is(err, sql.ErrNoRows) {
+				return executed, nil
+			}
+			return executed, err
+		}
+		executed++
+
+		run := result.Run
+		if run.Status == db.ScheduledTransferRunFailed {
+			log.Printf("scheduled transfer %d failed (%d in a row, now %s): %s",
+				run.ScheduledTransferID, result.ScheduledTransfer.FailureCount, result.ScheduledTransfer.Status, run.Error)
+			continue
+		}
+		log.Printf("scheduled transfer %d executed as transfer %d", run.ScheduledTransferID, run.TransferID.Int64)
+	}
+}
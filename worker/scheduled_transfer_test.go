@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestScheduledTransferRunnerRunDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	runner := NewScheduledTransferRunner(store, util.Config{})
+
+	now := time.Now()
+	arg := db.ExecuteScheduledTransferTxParams{
+		Now:         now,
+		MaxFailures: defaultScheduledTransferMaxFailures,
+		RetryDelay:  defaultScheduledTransferRetryDelay,
+	}
+
+	succeeded := db.ExecuteScheduledTransferTxResult{
+		Run: db.ScheduledTransferRun{ScheduledTransferID: 1, Status: db.ScheduledTransferRunSucceeded},
+	}
+	failed := db.ExecuteScheduledTransferTxResult{
+		Run: db.ScheduledTransferRun{ScheduledTransferID: 2, Status: db.ScheduledTransferRunFailed, Error: db.ErrInsufficientFunds.Error()},
+	}
+
+	// two due transfers are executed, then the store reports that nothing else is due
+	gomock.InOrder(
+		store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Return(succeeded, nil),
+		store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Return(failed, nil),
+		store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Return(db.ExecuteScheduledTransferTxResult{}, sql.ErrNoRows),
+	)
+
+	executed, err := runner.RunDue(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 2, executed)
+}
+
+func TestScheduledTransferRunnerRunDueError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	runner := NewScheduledTransferRunner(store, util.Config{})
+
+	store.EXPECT().
+		ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.ExecuteScheduledTransferTxResult{}, sql.ErrConnDone)
+
+	executed, err := runner.RunDue(context.Background(), time.Now())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Zero(t, executed)
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// Defaults used when settlement is not configured
+const (
+	defaultSettlementInterval  = 30 * time.Second
+	defaultSettlementBatchSize = 100
+)
+
+// TransferSettler credits the recipients of pending transfers, a batch at a time.
+// Several settlers can work against the same database: pending transfers are claimed
+// with FOR UPDATE SKIP LOCKED inside Store.SettleTransfersTx.
+type TransferSettler struct {
+	store     db.Store
+	interval  time.Duration
+	batchSize int32
+}
+
+// NewTransferSettler creates a settler using the settlement settings from the config.
+func NewTransferSettler(store db.Store, config util.Config) *TransferSettler {
+	settler := &TransferSettler{
+		store:     store,
+		interval:  config.SettlementInterval,
+		batchSize: config.SettlementBatchSize,
+	}
+
+	if settler.interval <= 0 {
+		settler.interval = defaultSettlementInterval
+	}
+	if settler.batchSize <= 0 {
+		settler.batchSize = defaultSettlementBatchSize
+	}
+
+	return settler
+}
+
+// Start runs the settlement loop until the context is cancelled.
+func (settler *TransferSettler) Start(ctx context.Context) {
+	ticker := time.NewTicker(settler.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := settler.SettlePending(ctx); err != nil {
+			log.Printf("settlement: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SettlePending settles batches of pending transfers until none are left
+// and returns how many transfers were settled, whether posted or failed.
+func (settler *TransferSettler) SettlePending(ctx context.Context) (int, error) {
+	arg := db.SettleTransfersTxParams{
+		BatchSize: settler.batchSize,
+	}
+
+	settled := 0
+	for {
+		result, err := settler.store.SettleTransfersTx(ctx, arg)
+		if err != nil {
+			return settled, err
+		}
+
+		for _, failed := range result.Failed {
+			log.Printf("transfer %d failed to settle and was refunded: %s", failed.Refund.Transfer.ID, failed.Error)
+		}
+
+		batch := len(result.Posted) + len(result.Failed)
+		settled += batch
+
+		// A short batch means there was nothing else to claim
+		if batch < int(settler.batchSize) {
+			return settled, nil
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestTransferSettlerSettlePending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	settler := NewTransferSettler(store, util.Config{SettlementBatchSize: 2})
+
+	arg := db.SettleTransfersTxParams{BatchSize: 2}
+	full := db.SettleTransfersTxResult{
+		Posted: []db.TransferTxResult{{Transfer: db.Transfer{ID: 1, Status: db.TransferStatusPosted}}},
+		Failed: []db.FailedSettlement{{Refund: db.TransferTxResult{Transfer: db.Transfer{ID: 2, Status: db.TransferStatusFailed}}}},
+	}
+	short := db.SettleTransfersTxResult{
+		Posted: []db.TransferTxResult{{Transfer: db.Transfer{ID: 3, Status: db.TransferStatusPosted}}},
+	}
+
+	// a full batch is followed by another one, a short batch ends the run
+	gomock.InOrder(
+		store.EXPECT().SettleTransfersTx(gomock.Any(), gomock.Eq(arg)).Return(full, nil),
+		store.EXPECT().SettleTransfersTx(gomock.Any(), gomock.Eq(arg)).Return(short, nil),
+	)
+
+	settled, err := settler.SettlePending(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, settled)
+}
+
+func TestTransferSettlerSettlePendingError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	settler := NewTransferSettler(store, util.Config{})
+
+	store.EXPECT().
+		SettleTransfersTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.SettleTransfersTxResult{}, sql.ErrConnDone)
+
+	settled, err := settler.SettlePending(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Zero(t, settled)
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+// defaultTransferApprovalExpiryInterval is used when the expiry interval is not configured
+const defaultTransferApprovalExpiryInterval = time.Minute
+
+// TransferApprovalExpirer cancels transfers that were not approved in time and releases their held funds.
+// Like the scheduled transfer runner, several expirers can safely run against the same database.
+type TransferApprovalExpirer struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewTransferApprovalExpirer creates an expirer using the approval settings from the config.
+func NewTransferApprovalExpirer(store db.Store, config util.Config) *TransferApprovalExpirer {
+	expirer := &TransferApprovalExpirer{
+		store:    store,
+		interval: config.TransferApprovalExpiryInterval,
+	}
+
+	if expirer.interval <= 0 {
+		expirer.interval = defaultTransferApprovalExpiryInterval
+	}
+
+	return expirer
+}
+
+// Start runs the expiry loop until the context is cancelled.
+func (expirer *TransferApprovalExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(expirer.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := expirer.ExpireDue(ctx, time.Now()); err != nil {
+			log.Printf("transfer approvals: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireDue cancels every transfer whose approval expired at or before the given time
+// and returns how many were cancelled.
+func (expirer *TransferApprovalExpirer) ExpireDue(ctx context.Context, now time.Time) (int, error) {
+	expired := 0
+	for {
+		result, err := expirer.store.ExpireTransferApprovalTx(ctx, now)
+		if err != nil {
+			// Nothing left to do until the next tick
+			if errors.Is(err, sql.ErrNoRows) {
+				return expired, nil
+			}
+			return expired, err
+		}
+		expired++
+
+		log.Printf("transfer %d expired without approval, released %d on account %d",
+			result.Transfer.ID, result.Transfer.Amount, result.Transfer.FromAccountID)
+	}
+}
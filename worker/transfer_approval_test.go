@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/suleimanodetoro/Go-Bank-Pro/db/mock"
+	db "github.com/suleimanodetoro/Go-Bank-Pro/db/sqlc"
+	"github.com/suleimanodetoro/Go-Bank-Pro/db/util"
+)
+
+func TestTransferApprovalExpirerExpireDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expirer := NewTransferApprovalExpirer(store, util.Config{})
+
+	now := time.Now()
+	expired := db.TransferApprovalTxResult{
+		Transfer: db.Transfer{ID: 1, Status: db.TransferStatusCancelled},
+		Approval: db.TransferApproval{TransferID: 1, Status: db.TransferApprovalExpired},
+	}
+
+	// one expired approval is cancelled, then the store reports that nothing else has expired
+	gomock.InOrder(
+		store.EXPECT().ExpireTransferApprovalTx(gomock.Any(), gomock.Eq(now)).Return(expired, nil),
+		store.EXPECT().ExpireTransferApprovalTx(gomock.Any(), gomock.Eq(now)).Return(db.TransferApprovalTxResult{}, sql.ErrNoRows),
+	)
+
+	count, err := expirer.ExpireDue(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}